@@ -51,6 +51,22 @@ const (
 	// CPULimitAnnotation enforces a strict CPU limit on a workspace by virtue of ws-daemon
 	CPULimitAnnotation = "gitpod.io/cpuLimit"
 
+	// EgressBandwidthAnnotation limits the egress bandwidth of a workspace by virtue of ws-daemon.
+	// The value is a quantity in bits per second, e.g. "100M".
+	EgressBandwidthAnnotation = "gitpod.io/egressBandwidth"
+
+	// IngressBandwidthAnnotation limits the ingress bandwidth of a workspace by virtue of ws-daemon.
+	// The value is a quantity in bits per second, e.g. "100M".
+	IngressBandwidthAnnotation = "gitpod.io/ingressBandwidth"
+
+	// EgressPolicyAnnotation restricts the network destinations a workspace can connect to by virtue of ws-daemon.
+	// The value is the JSON serialized EgressPolicy of the ws-manager API.
+	EgressPolicyAnnotation = "gitpod.io/egressPolicy"
+
+	// EgressViolationAnnotation is set by ws-daemon when the egress policy of a workspace blocked connections.
+	// The value describes the blocked connections.
+	EgressViolationAnnotation = "gitpod.io/egressViolation"
+
 	// RequiredNodeServicesAnnotation lists all Gitpod services required on the node
 	RequiredNodeServicesAnnotation = "gitpod.io/requiredNodeServices"
 
@@ -310,6 +310,26 @@ func (rs *DirectGCPStorage) ListObjects(ctx context.Context, prefix string) (obj
 	return objects, nil
 }
 
+// Delete removes an object from the remote storage. Deleting an object that does not exist is not an error.
+func (rs *DirectGCPStorage) Delete(ctx context.Context, name string) (err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "GCloudBucketRemotegcpStorage.Delete")
+	defer tracing.FinishSpan(span, &err)
+
+	if rs.client == nil {
+		return xerrors.Errorf("no gcloud client available - did you call Init()?")
+	}
+
+	err = rs.client.Bucket(rs.bucketName()).Object(rs.objectName(name)).Delete(ctx)
+	if errors.Is(err, gcpstorage.ErrBucketNotExist) || errors.Is(err, gcpstorage.ErrObjectNotExist) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("cannot delete %s: %w", name, err)
+	}
+	return nil
+}
+
 // Qualify fully qualifies a snapshot name so that it can be downloaded using DownloadSnapshot
 func (rs *DirectGCPStorage) Qualify(name string) string {
 	return fmt.Sprintf("%s@%s", rs.objectName(name), rs.bucketName())
@@ -342,7 +362,7 @@ func (rs *DirectGCPStorage) Upload(ctx context.Context, source string, name stri
 	}
 
 	// check if we have not yet exceeded the max number of backups
-	if name != DefaultBackup {
+	if name != DefaultBackup && name != DefaultCheckpoint {
 		if err = rs.ensureBackupSlotAvailable(); err != nil {
 			return
 		}
@@ -239,6 +239,26 @@ func (rs *DirectMinIOStorage) ListObjects(ctx context.Context, prefix string) (o
 	return objects, nil
 }
 
+// Delete removes an object from the remote storage. Deleting an object that does not exist is not an error.
+func (rs *DirectMinIOStorage) Delete(ctx context.Context, name string) (err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DirectDelete")
+	defer tracing.FinishSpan(span, &err)
+
+	if rs.client == nil {
+		return xerrors.Errorf("no minio client available - did you call Init()?")
+	}
+
+	err = rs.client.RemoveObject(ctx, rs.bucketName(), rs.objectName(name), minio.RemoveObjectOptions{})
+	if minio.ToErrorResponse(err).Code == "NoSuchBucket" {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("cannot delete %s: %w", name, err)
+	}
+	return nil
+}
+
 // Qualify fully qualifies a snapshot name so that it can be downloaded using DownloadSnapshot
 func (rs *DirectMinIOStorage) Qualify(name string) string {
 	return fmt.Sprintf("%s@%s", rs.objectName(name), rs.bucketName())
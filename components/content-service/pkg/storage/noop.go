@@ -55,6 +55,11 @@ func (rs *DirectNoopStorage) Upload(ctx context.Context, source string, name str
 	return "", "", nil
 }
 
+// Delete does nothing
+func (rs *DirectNoopStorage) Delete(ctx context.Context, name string) error {
+	return nil
+}
+
 // Bucket returns an empty string
 func (rs *DirectNoopStorage) Bucket(string) string {
 	return ""
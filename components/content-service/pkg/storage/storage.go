@@ -28,6 +28,9 @@ const (
 
 	// FmtFullWorkspaceBackup is the format for names of full workspace backups
 	FmtFullWorkspaceBackup = "wsfull-%d.tar"
+
+	// DefaultCheckpoint is the name of the process checkpoint we upload alongside the regular backup
+	DefaultCheckpoint = "checkpoint.tar"
 )
 
 var (
@@ -158,6 +161,9 @@ type DirectAccess interface {
 
 	// UploadInstance takes all files from a local location and uploads it to the remote storage
 	UploadInstance(ctx context.Context, source string, name string, options ...UploadOption) (bucket, obj string, err error)
+
+	// Delete removes an object from the remote storage. Deleting an object that does not exist is not an error.
+	Delete(ctx context.Context, name string) error
 }
 
 // UploadOptions configure remote storage upload
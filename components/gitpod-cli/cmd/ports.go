@@ -0,0 +1,244 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+var portsListOpts struct {
+	Watch bool
+	JSON  bool
+}
+
+var portsTunnelOpts struct {
+	Visibility string
+	UDP        bool
+	Close      bool
+}
+
+var portsCmd = &cobra.Command{
+	Use:   "ports",
+	Short: "Lists, exposes and tunnels the ports of this workspace",
+}
+
+var portsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the ports of this workspace and their state",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		conn, err := dialSupervisor()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+
+		stream, err := supervisor.NewStatusServiceClient(conn).PortsStatus(ctx, &supervisor.PortsStatusRequest{Observe: portsListOpts.Watch, IncludeUdp: true})
+		if err != nil {
+			log.Fatalf("cannot get ports: %s", err)
+		}
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Fatalf("cannot get ports: %s", err)
+			}
+
+			if portsListOpts.JSON {
+				out, err := protojson.Marshal(resp)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(string(out))
+				continue
+			}
+			if portsListOpts.Watch {
+				// clear the screen and move the cursor home
+				fmt.Print("\033[H\033[2J")
+			}
+			printPorts(os.Stdout, resp.Ports)
+		}
+	},
+}
+
+var portsExposeCmd = &cobra.Command{
+	Use:   "expose <port> [target-port]",
+	Short: "Makes a port available from outside the workspace, i.e. the internet",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		port := parsePort(args[0])
+		var target uint32
+		if len(args) > 1 {
+			target = parsePort(args[1])
+		}
+
+		controlPorts(func(ctx context.Context, conn *grpc.ClientConn) error {
+			_, err := supervisor.NewControlServiceClient(conn).ExposePort(ctx, &supervisor.ExposePortRequest{Port: port, TargetPort: target})
+			return err
+		})
+	},
+}
+
+var portsVisibilityCmd = &cobra.Command{
+	Use:       "visibility <port> public|private",
+	Short:     "Changes the visibility of an exposed port. Public ports are accessible without authentication.",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: []string{"public", "private"},
+	Run: func(cmd *cobra.Command, args []string) {
+		port := parsePort(args[0])
+		visibility, ok := supervisor.PortVisibility_value[args[1]]
+		if !ok {
+			log.Fatalf("visibility must be public or private, not %q", args[1])
+		}
+
+		controlPorts(func(ctx context.Context, conn *grpc.ClientConn) error {
+			_, err := supervisor.NewControlServiceClient(conn).SetPortVisibility(ctx, &supervisor.SetPortVisibilityRequest{
+				Port:       port,
+				Visibility: supervisor.PortVisibility(visibility),
+			})
+			return err
+		})
+	},
+}
+
+var portsTunnelCmd = &cobra.Command{
+	Use:   "tunnel <port> [target-port]",
+	Short: "Tunnels a port to the machines connected to this workspace, e.g. through the local companion app",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		port := parsePort(args[0])
+		var target uint32
+		if len(args) > 1 {
+			target = parsePort(args[1])
+		}
+		transport := supervisor.TransportProtocol_transport_tcp
+		if portsTunnelOpts.UDP {
+			transport = supervisor.TransportProtocol_transport_udp
+		}
+
+		if portsTunnelOpts.Close {
+			controlPorts(func(ctx context.Context, conn *grpc.ClientConn) error {
+				_, err := supervisor.NewPortServiceClient(conn).CloseTunnel(ctx, &supervisor.CloseTunnelRequest{Port: port, TransportProtocol: transport})
+				return err
+			})
+			return
+		}
+
+		visibility, ok := supervisor.TunnelVisiblity_value[portsTunnelOpts.Visibility]
+		if !ok || supervisor.TunnelVisiblity(visibility) == supervisor.TunnelVisiblity_none {
+			log.Fatalf("visibility must be host or network, not %q", portsTunnelOpts.Visibility)
+		}
+		controlPorts(func(ctx context.Context, conn *grpc.ClientConn) error {
+			_, err := supervisor.NewPortServiceClient(conn).Tunnel(ctx, &supervisor.TunnelPortRequest{
+				Port:              port,
+				TargetPort:        target,
+				Visibility:        supervisor.TunnelVisiblity(visibility),
+				TransportProtocol: transport,
+			})
+			return err
+		})
+	},
+}
+
+func controlPorts(do func(ctx context.Context, conn *grpc.ClientConn) error) {
+	conn, err := dialSupervisor()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	// exposing a port waits for the Gitpod server
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err = do(ctx, conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parsePort(s string) uint32 {
+	port, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		log.Fatalf("port %q is not a valid number", s)
+	}
+	return uint32(port)
+}
+
+func printPorts(out io.Writer, ports []*supervisor.PortsStatus) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "PORT\tSTATUS\tPROTOCOL\tVISIBILITY\tURL\tTUNNEL\t")
+	for _, p := range ports {
+		port := strconv.FormatUint(uint64(p.LocalPort), 10)
+		if p.TransportProtocol == supervisor.TransportProtocol_transport_udp {
+			port += "/udp"
+		}
+
+		status := "not served"
+		if p.Served {
+			status = "served"
+		}
+		if p.Owner != nil && p.Owner.Name != "" {
+			status += " by " + p.Owner.Name
+		}
+
+		protocol := "-"
+		if p.Protocol != supervisor.PortProtocol_protocol_unknown {
+			protocol = p.Protocol.String()
+		}
+
+		visibility, url := "-", "-"
+		switch {
+		case p.Exposed != nil:
+			visibility, url = p.Exposed.Visibility.String(), p.Exposed.Url
+		case p.AutoExposure == supervisor.PortAutoExposure_trying:
+			visibility = "exposing"
+		case p.AutoExposure == supervisor.PortAutoExposure_failed:
+			visibility = "exposure failed"
+		}
+
+		tunnel := "-"
+		if p.Tunneled != nil {
+			tunnel = fmt.Sprintf("%s:%d (%d clients)", p.Tunneled.Visibility, p.Tunneled.TargetPort, len(p.Tunneled.Clients))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", port, status, protocol, visibility, url, tunnel)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(portsCmd)
+	portsCmd.AddCommand(portsListCmd)
+	portsCmd.AddCommand(portsExposeCmd)
+	portsCmd.AddCommand(portsVisibilityCmd)
+	portsCmd.AddCommand(portsTunnelCmd)
+
+	portsListCmd.Flags().BoolVarP(&portsListOpts.Watch, "watch", "w", false, "keep updating the ports")
+	portsListCmd.Flags().BoolVar(&portsListOpts.JSON, "json", false, "print the ports as JSON, one line per update")
+	portsTunnelCmd.Flags().StringVar(&portsTunnelOpts.Visibility, "visibility", supervisor.TunnelVisiblity_host.String(), "accept connections on the remote machine from the host only (host) or from its network (network)")
+	portsTunnelCmd.Flags().BoolVar(&portsTunnelOpts.UDP, "udp", false, "tunnel a UDP rather than a TCP port")
+	portsTunnelCmd.Flags().BoolVar(&portsTunnelOpts.Close, "close", false, "close the tunnel of the port instead")
+}
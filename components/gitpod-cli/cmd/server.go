@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/xerrors"
+
+	serverapi "github.com/gitpod-io/gitpod/gitpod-protocol"
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+// workspaceServer is a connection to the Gitpod server on behalf of this workspace
+type workspaceServer struct {
+	Info   *supervisor.WorkspaceInfoResponse
+	Client *serverapi.APIoverJSONRPC
+}
+
+// Close closes the connection to the server
+func (s *workspaceServer) Close() error {
+	return s.Client.Close()
+}
+
+// workspaceResourceScope produces the scope of an owner token for operations on a resource related to this workspace.
+// The scopes must match the ones the server grants exactly, otherwise supervisor doesn't hand out the token.
+func workspaceResourceScope(kind, subjectID, operations string) string {
+	return "resource:" + kind + "::" + subjectID + "::" + operations
+}
+
+// connectToWorkspaceServer connects to the Gitpod server using an owner token which is restricted to the scopes
+// the operation needs. The scopes usually depend on the workspace, hence scopes is passed the workspace info.
+func connectToWorkspaceServer(ctx context.Context, scopes func(info *supervisor.WorkspaceInfoResponse) []string) (*workspaceServer, error) {
+	conn, err := dialSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	info, err := supervisor.NewInfoServiceClient(conn).WorkspaceInfo(ctx, &supervisor.WorkspaceInfoRequest{})
+	if err != nil {
+		return nil, xerrors.Errorf("failed getting workspace info from supervisor: %w", err)
+	}
+	if info.GitpodApi == nil {
+		return nil, xerrors.New("workspace info is missing the Gitpod API")
+	}
+	token, err := supervisor.NewTokenServiceClient(conn).GetToken(ctx, &supervisor.GetTokenRequest{
+		Host:  info.GitpodApi.Host,
+		Kind:  "gitpod",
+		Scope: scopes(info),
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed getting token from supervisor: %w", err)
+	}
+	client, err := serverapi.ConnectToServer(info.GitpodApi.Endpoint, serverapi.ConnectToServerOpts{
+		Token:   token.Token,
+		Context: ctx,
+		Log:     log.NewEntry(log.StandardLogger()),
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed connecting to server: %w", err)
+	}
+	return &workspaceServer{Info: info, Client: client}, nil
+}
+
+// withProgress prints msg followed by a dot per second to stderr until do returns
+func withProgress(msg string, do func() error) error {
+	fmt.Fprint(os.Stderr, msg)
+	done := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				fmt.Fprint(os.Stderr, ".")
+			}
+		}
+	}()
+
+	err := do()
+	close(done)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, " failed")
+		return err
+	}
+	fmt.Fprintln(os.Stderr, " done")
+	return nil
+}
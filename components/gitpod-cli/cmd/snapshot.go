@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	serverapi "github.com/gitpod-io/gitpod/gitpod-protocol"
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+// snapshotWorkspaceSubjectPrefix prefixes the workspace ID in the scope of snapshots of a workspace
+const snapshotWorkspaceSubjectPrefix = "ws-"
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Takes a snapshot of this workspace and prints the URL to share it",
+	Long: `Takes a snapshot of this workspace and prints the URL to share it.
+Anyone who opens the URL gets a new workspace with the content this workspace
+has right now.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		// snapshots of large workspaces take a while
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Minute)
+		defer cancel()
+
+		srv, err := connectToWorkspaceServer(ctx, func(info *supervisor.WorkspaceInfoResponse) []string {
+			return []string{
+				"function:takeSnapshot",
+				workspaceResourceScope("workspaceInstance", info.InstanceId, "get/update/delete"),
+				workspaceResourceScope("snapshot", snapshotWorkspaceSubjectPrefix+info.WorkspaceId, "create"),
+			}
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer srv.Close()
+
+		var id string
+		err = withProgress("Taking a snapshot of this workspace", func() (err error) {
+			id, err = srv.Client.TakeSnapshot(ctx, &serverapi.TakeSnapshotOptions{WorkspaceID: srv.Info.WorkspaceId})
+			return err
+		})
+		if err != nil {
+			log.Fatalf("cannot take snapshot: %s", err)
+		}
+		fmt.Printf("%s/#snapshot/%s\n", strings.TrimSuffix(srv.Info.GitpodHost, "/"), id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+var stopOpts struct {
+	NoWait bool
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stops this workspace",
+	Long: `Stops this workspace. Its content is backed up before it stops, just like
+when it times out. Unless --no-wait is given, the command prints how stopping
+progresses until the workspace shuts this very command down.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		srv, err := connectToWorkspaceServer(ctx, func(info *supervisor.WorkspaceInfoResponse) []string {
+			return []string{
+				"function:stopWorkspace",
+				workspaceResourceScope("workspace", info.WorkspaceId, "get/update"),
+			}
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer srv.Close()
+
+		// subscribe before stopping so that we don't miss any update
+		updates, err := srv.Client.InstanceUpdates(ctx, srv.Info.InstanceId)
+		if err != nil {
+			log.Fatalf("cannot observe the workspace: %s", err)
+		}
+
+		stopCtx, stopCancel := context.WithTimeout(ctx, 1*time.Minute)
+		defer stopCancel()
+		err = srv.Client.StopWorkspace(stopCtx, srv.Info.WorkspaceId)
+		if err != nil {
+			log.Fatalf("cannot stop workspace: %s", err)
+		}
+		fmt.Fprintln(os.Stderr, "Stopping workspace")
+		if stopOpts.NoWait {
+			return
+		}
+
+		var phase string
+		for instance := range updates {
+			if instance.Status == nil || instance.Status.Phase == phase {
+				continue
+			}
+			phase = instance.Status.Phase
+			fmt.Fprintf(os.Stderr, "workspace is %s\n", phase)
+			if phase == "stopped" {
+				return
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+	stopCmd.Flags().BoolVar(&stopOpts.NoWait, "no-wait", false, "do not wait for the workspace to stop")
+}
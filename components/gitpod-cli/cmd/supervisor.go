@@ -0,0 +1,30 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+)
+
+// dialSupervisor connects to the supervisor of this workspace
+func dialSupervisor() (*grpc.ClientConn, error) {
+	supervisorAddr := os.Getenv("SUPERVISOR_ADDR")
+	if supervisorAddr == "" {
+		supervisorAddr = "localhost:22999"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, supervisorAddr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, xerrors.Errorf("failed connecting to supervisor: %w", err)
+	}
+	return conn, nil
+}
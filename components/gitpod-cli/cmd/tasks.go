@@ -0,0 +1,318 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+// detachKey is Ctrl+], which detaches from a task terminal just like it leaves a telnet session
+const detachKey = 0x1d
+
+var tasksListOpts struct {
+	JSON bool
+}
+
+var tasksAttachOpts struct {
+	NoResize bool
+}
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "Lists, attaches to, stops and restarts the tasks of this workspace",
+}
+
+var tasksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the tasks of this workspace and their state",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		conn, err := dialSupervisor()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		tasks, err := listTasks(ctx, supervisor.NewStatusServiceClient(conn))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if tasksListOpts.JSON {
+			out, err := protojson.Marshal(&supervisor.TasksStatusResponse{Tasks: tasks})
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		defer w.Flush()
+		fmt.Fprintln(w, "ID\tNAME\tSTATE\tRESTARTS\tTERMINAL\t")
+		for _, t := range tasks {
+			state := t.State.String()
+			if t.State == supervisor.TaskState_blocked && t.BlockedReason != "" {
+				state += ": " + t.BlockedReason
+			} else if t.State == supervisor.TaskState_closed || t.State == supervisor.TaskState_restarting {
+				state += fmt.Sprintf(" (exit code %d)", t.LastExitCode)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t\n", t.Id, t.Presentation.GetName(), state, t.RestartCount, t.Terminal)
+		}
+	},
+}
+
+var tasksAttachCmd = &cobra.Command{
+	Use:   "attach <id|name>",
+	Short: "Attaches to the terminal of a running task",
+	Long:  "Attaches to the terminal of a running task. Press Ctrl+] to detach.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		conn, err := dialSupervisor()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		task, err := findTask(ctx, supervisor.NewStatusServiceClient(conn), args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if task.State != supervisor.TaskState_running || task.Terminal == "" {
+			log.Fatalf("task %s is %s, only running tasks can be attached to", task.Id, task.State)
+		}
+
+		exitCode, err := attachToTask(ctx, supervisor.NewTerminalServiceClient(conn), task.Terminal)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if exitCode != 0 {
+			// deferred calls don't run, but there's nothing left to clean up
+			os.Exit(exitCode)
+		}
+	},
+}
+
+var tasksStopCmd = &cobra.Command{
+	Use:   "stop <id|name>",
+	Short: "Stops a task. Stopped tasks are not restarted according to their restart policy.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		controlTask(args[0], func(ctx context.Context, client supervisor.StatusServiceClient, id string) error {
+			_, err := client.StopTask(ctx, &supervisor.StopTaskRequest{Id: id})
+			return err
+		})
+	},
+}
+
+var tasksRestartCmd = &cobra.Command{
+	Use:   "restart <id|name>",
+	Short: "Restarts a task right away, no matter if it's running or closed",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		controlTask(args[0], func(ctx context.Context, client supervisor.StatusServiceClient, id string) error {
+			_, err := client.RestartTask(ctx, &supervisor.RestartTaskRequest{Id: id})
+			return err
+		})
+	},
+}
+
+func controlTask(idOrName string, do func(ctx context.Context, client supervisor.StatusServiceClient, id string) error) {
+	conn, err := dialSupervisor()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	// stopping a task waits for it to terminate
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := supervisor.NewStatusServiceClient(conn)
+	task, err := findTask(ctx, client, idOrName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = do(ctx, client, task.Id)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func listTasks(ctx context.Context, client supervisor.StatusServiceClient) ([]*supervisor.TaskStatus, error) {
+	stream, err := client.TasksStatus(ctx, &supervisor.TasksStatusRequest{})
+	if err != nil {
+		return nil, xerrors.Errorf("cannot get tasks: %w", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, xerrors.Errorf("cannot get tasks: %w", err)
+	}
+	return resp.Tasks, nil
+}
+
+// findTask finds a task by its ID or, failing that, by its name
+func findTask(ctx context.Context, client supervisor.StatusServiceClient, idOrName string) (*supervisor.TaskStatus, error) {
+	tasks, err := listTasks(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if t.Id == idOrName {
+			return t, nil
+		}
+	}
+
+	var res *supervisor.TaskStatus
+	for _, t := range tasks {
+		if t.Presentation.GetName() != idOrName {
+			continue
+		}
+		if res != nil {
+			return nil, xerrors.Errorf("there are several tasks named %s, please use the task ID instead", idOrName)
+		}
+		res = t
+	}
+	if res == nil {
+		return nil, xerrors.Errorf("task %s not found", idOrName)
+	}
+	return res, nil
+}
+
+// attachToTask streams the output of a task terminal and forwards stdin to it until the terminal exits,
+// the user detaches or ctx is canceled. It returns the exit code of the terminal.
+func attachToTask(ctx context.Context, client supervisor.TerminalServiceClient, alias string) (exitCode int, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	listen, err := client.Listen(ctx, &supervisor.ListenTerminalRequest{Alias: alias})
+	if err != nil {
+		return 0, xerrors.Errorf("cannot attach to task terminal: %w", err)
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if term.IsTerminal(stdin) {
+		oldState, err := term.MakeRaw(stdin)
+		if err != nil {
+			return 0, xerrors.Errorf("cannot put the terminal into raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(stdin, oldState) }()
+		fmt.Fprint(os.Stderr, "attached to the task terminal, press Ctrl+] to detach\r\n")
+
+		if !tasksAttachOpts.NoResize {
+			go resizeTaskTerminal(ctx, client, alias, stdin)
+		}
+		go forwardStdin(ctx, cancel, client, alias)
+	}
+
+	for {
+		resp, err := listen.Recv()
+		if err == io.EOF || ctx.Err() != nil {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, xerrors.Errorf("cannot listen to task terminal: %w", err)
+		}
+
+		switch out := resp.Output.(type) {
+		case *supervisor.ListenTerminalResponse_Data:
+			_, _ = os.Stdout.Write(out.Data)
+		case *supervisor.ListenTerminalResponse_ExitCode:
+			return int(out.ExitCode), nil
+		}
+	}
+}
+
+// forwardStdin writes stdin to a task terminal until stdin ends or the user detaches
+func forwardStdin(ctx context.Context, detach func(), client supervisor.TerminalServiceClient, alias string) {
+	defer detach()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := os.Stdin.Read(buf)
+		data := buf[:n]
+		i := bytes.IndexByte(data, detachKey)
+		if i >= 0 {
+			data = data[:i]
+		}
+		if len(data) > 0 {
+			_, werr := client.Write(ctx, &supervisor.WriteTerminalRequest{Alias: alias, Stdin: data})
+			if werr != nil {
+				return
+			}
+		}
+		if i >= 0 || err != nil {
+			return
+		}
+	}
+}
+
+// resizeTaskTerminal keeps the size of a task terminal in sync with the size of our terminal.
+// We don't own the task terminal, hence we have to force the size onto it.
+func resizeTaskTerminal(ctx context.Context, client supervisor.TerminalServiceClient, alias string, fd int) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+
+	var reported bool
+	for {
+		size, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+		if err == nil {
+			req := &supervisor.SetTerminalSizeRequest{
+				Alias: alias,
+				Size: &supervisor.TerminalSize{
+					Rows:     uint32(size.Row),
+					Cols:     uint32(size.Col),
+					WidthPx:  uint32(size.Xpixel),
+					HeightPx: uint32(size.Ypixel),
+				},
+				Priority: &supervisor.SetTerminalSizeRequest_Force{Force: true},
+			}
+			_, err = client.SetSize(ctx, req)
+			if err != nil && ctx.Err() == nil && !reported {
+				// we're in raw mode and report only once, so that we don't mangle the task's output
+				fmt.Fprintf(os.Stderr, "cannot resize the task terminal: %v\r\n", err)
+				reported = true
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-winch:
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tasksCmd)
+	tasksCmd.AddCommand(tasksListCmd)
+	tasksCmd.AddCommand(tasksAttachCmd)
+	tasksCmd.AddCommand(tasksStopCmd)
+	tasksCmd.AddCommand(tasksRestartCmd)
+
+	tasksListCmd.Flags().BoolVar(&tasksListOpts.JSON, "json", false, "print the tasks as JSON")
+	tasksAttachCmd.Flags().BoolVar(&tasksAttachOpts.NoResize, "no-resize", false, "leave the size of the task terminal as it is, rather than resizing it to this terminal")
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	serverapi "github.com/gitpod-io/gitpod/gitpod-protocol"
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+var workspaceTimeoutDurations = []string{
+	serverapi.WorkspaceTimeoutDuration30m,
+	serverapi.WorkspaceTimeoutDuration60m,
+	serverapi.WorkspaceTimeoutDuration180m,
+}
+
+var timeoutCmd = &cobra.Command{
+	Use:   "timeout",
+	Short: "Shows and extends the time this workspace keeps running without activity",
+}
+
+var timeoutShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the timeout of this workspace",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		srv := connectForTimeout(ctx, "function:getWorkspaceTimeout")
+		defer srv.Close()
+
+		res, err := srv.Client.GetWorkspaceTimeout(ctx, srv.Info.WorkspaceId)
+		if err != nil {
+			log.Fatalf("cannot get workspace timeout: %s", err)
+		}
+		fmt.Println(res.Duration)
+		if !res.CanChange {
+			log.Print("your plan does not allow to change the timeout")
+		}
+	},
+}
+
+var timeoutSetCmd = &cobra.Command{
+	Use:       "set <duration>",
+	Short:     "Sets the timeout of this workspace to one of " + strings.Join(workspaceTimeoutDurations, ", "),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: workspaceTimeoutDurations,
+	Run: func(cmd *cobra.Command, args []string) {
+		duration := serverapi.WorkspaceTimeoutDuration(args[0])
+		var valid bool
+		for _, d := range workspaceTimeoutDurations {
+			valid = valid || string(duration) == d
+		}
+		if !valid {
+			log.Fatalf("duration must be one of %s, not %q", strings.Join(workspaceTimeoutDurations, ", "), args[0])
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		srv := connectForTimeout(ctx, "function:setWorkspaceTimeout")
+		defer srv.Close()
+
+		res, err := srv.Client.SetWorkspaceTimeout(ctx, srv.Info.WorkspaceId, &duration)
+		if err != nil {
+			log.Fatalf("cannot set workspace timeout: %s", err)
+		}
+		fmt.Printf("workspace timeout is set to %s\n", duration)
+		if len(res.ResetTimeoutOnWorkspaces) > 0 {
+			fmt.Printf("the timeout of your other running workspaces was reset: %s\n", strings.Join(res.ResetTimeoutOnWorkspaces, ", "))
+		}
+	},
+}
+
+func connectForTimeout(ctx context.Context, function string) *workspaceServer {
+	srv, err := connectToWorkspaceServer(ctx, func(info *supervisor.WorkspaceInfoResponse) []string {
+		return []string{
+			function,
+			workspaceResourceScope("workspace", info.WorkspaceId, "get/update"),
+			workspaceResourceScope("workspaceInstance", info.InstanceId, "get/update/delete"),
+		}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return srv
+}
+
+func init() {
+	rootCmd.AddCommand(timeoutCmd)
+	timeoutCmd.AddCommand(timeoutShowCmd)
+	timeoutCmd.AddCommand(timeoutSetCmd)
+}
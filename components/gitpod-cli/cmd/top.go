@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	supervisor "github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+var topOpts struct {
+	Watch bool
+	JSON  bool
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Shows the CPU, memory and disk usage of this workspace against its limits",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		conn, err := dialSupervisor()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+
+		stream, err := supervisor.NewStatusServiceClient(conn).ResourcesStatus(ctx, &supervisor.ResourcesStatusRequest{Observe: topOpts.Watch})
+		if err != nil {
+			log.Fatalf("cannot get resource usage: %s", err)
+		}
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Fatalf("cannot get resource usage: %s", err)
+			}
+
+			if topOpts.JSON {
+				out, err := protojson.Marshal(resp)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(string(out))
+				continue
+			}
+			if topOpts.Watch {
+				// clear the screen and move the cursor home
+				fmt.Print("\033[H\033[2J")
+			}
+			printResources(os.Stdout, resp)
+		}
+	},
+}
+
+func printResources(out io.Writer, resp *supervisor.ResourcesStatusResponse) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "RESOURCE\tUSED\tLIMIT\tUSAGE\t")
+	printResource(w, "CPU", resp.Cpu, formatCores)
+	printResource(w, "Memory", resp.Memory, formatBytes)
+	printResource(w, "Disk", resp.Disk, formatBytes)
+}
+
+func printResource(w io.Writer, name string, res *supervisor.ResourceStatus, format func(int64) string) {
+	if res == nil {
+		fmt.Fprintf(w, "%s\tn/a\tn/a\t\t\n", name)
+		return
+	}
+	if res.Limit <= 0 {
+		fmt.Fprintf(w, "%s\t%s\tunlimited\t\t\n", name, format(res.Used))
+		return
+	}
+
+	var hint string
+	switch res.Severity {
+	case supervisor.ResourceStatusSeverity_warning:
+		hint = " - close to the limit"
+	case supervisor.ResourceStatusSeverity_danger:
+		hint = " - limit almost reached"
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%d%%%s\t\n", name, format(res.Used), format(res.Limit), res.Used*100/res.Limit, hint)
+}
+
+func formatCores(v int64) string {
+	return fmt.Sprintf("%.2f cores", float64(v)/1000)
+}
+
+func formatBytes(v int64) string {
+	const unit = 1024
+	if v < unit {
+		return fmt.Sprintf("%d B", v)
+	}
+	div, exp := int64(unit), 0
+	for n := v / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(v)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().BoolVarP(&topOpts.Watch, "watch", "w", false, "keep updating the resource usage")
+	topCmd.Flags().BoolVar(&topOpts.JSON, "json", false, "print the resource usage as JSON")
+}
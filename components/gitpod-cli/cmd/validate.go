@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
+)
+
+var validateOpts struct {
+	JSON bool
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validates a .gitpod.yml file and lists its problems",
+	Long: `Validates a .gitpod.yml file against the Gitpod config schema and lists its problems,
+e.g. unknown keys, invalid values or ports out of range. Validates .gitpod.yml in the
+current directory by default and exits with 1 if the file is invalid.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		location := ".gitpod.yml"
+		if len(args) > 0 {
+			location = args[0]
+		}
+		content, err := os.ReadFile(location)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		problems := gitpod.ValidateConfig(content)
+		switch {
+		case validateOpts.JSON:
+			if problems == nil {
+				problems = []*gitpod.ConfigValidationError{}
+			}
+			out, err := json.MarshalIndent(problems, "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(out))
+		case len(problems) == 0:
+			fmt.Printf("%s is valid\n", location)
+		default:
+			for _, p := range problems {
+				fmt.Printf("%s:%s: %s\n", location, problemPosition(p), problemDescription(p))
+			}
+			fmt.Fprintf(os.Stderr, "%s is invalid, found %d problem(s)\n", location, len(problems))
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func problemPosition(p *gitpod.ConfigValidationError) string {
+	if p.Column == 0 {
+		return strconv.Itoa(p.Line)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+func problemDescription(p *gitpod.ConfigValidationError) string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().BoolVar(&validateOpts.JSON, "json", false, "print the problems as JSON")
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+// TaskDependencyCycles finds the cycles in the task dependency graph, where dependsOn lists the
+// indices of the tasks each task depends on. Every cycle is a path of task indices which starts
+// and ends with the same task, e.g. [0 2 1 0] if task 0 depends on 2, 2 on 1 and 1 on 0.
+func TaskDependencyCycles(dependsOn [][]int) (cycles [][]int) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state = make([]int, len(dependsOn))
+		stack []int
+		visit func(i int)
+	)
+	visit = func(i int) {
+		state[i] = visiting
+		stack = append(stack, i)
+		for _, d := range dependsOn[i] {
+			switch state[d] {
+			case unvisited:
+				visit(d)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != d {
+					start--
+				}
+				cycle := make([]int, 0, len(stack)-start+1)
+				cycle = append(cycle, stack[start:]...)
+				cycle = append(cycle, d)
+				cycles = append(cycles, cycle)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+	}
+	for i := range dependsOn {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+	return cycles
+}
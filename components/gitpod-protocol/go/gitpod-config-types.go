@@ -36,6 +36,9 @@ type GitpodConfig struct {
 	// Configures Gitpod's GitHub app
 	Github *Github `yaml:"github,omitempty"`
 
+	// Additional entries for the workspace's /etc/hosts file.
+	Hosts []*HostsItems `yaml:"hosts,omitempty"`
+
 	// Controls what ide should be used for a workspace.
 	Ide interface{} `yaml:"ide,omitempty"`
 
@@ -55,6 +58,32 @@ type GitpodConfig struct {
 	WorkspaceLocation string `yaml:"workspaceLocation,omitempty"`
 }
 
+// HealthCheck Checks the health of the running task. Once the check failed `failureThreshold` times in a row after succeeding at least once, the task is restarted according to its restart policy.
+type HealthCheck struct {
+
+	// A shell command which exits with 0 while the task is healthy.
+	Command string `yaml:"command,omitempty"`
+
+	// The number of consecutive failed checks after which the task is considered unhealthy. Defaults to 3.
+	FailureThreshold int `yaml:"failureThreshold,omitempty"`
+
+	// The interval between checks in seconds. Defaults to 10.
+	Interval int `yaml:"interval,omitempty"`
+
+	// A port which is served while the task is healthy.
+	Port int `yaml:"port,omitempty"`
+}
+
+// HostsItems
+type HostsItems struct {
+
+	// The IPv4 or IPv6 address the hostname resolves to.
+	Ip string `yaml:"ip"`
+
+	// The hostname to resolve (e.g. db.example.com).
+	Name string `yaml:"name"`
+}
+
 // Image_object The Docker image to run your workspace in.
 type Image_object struct {
 
@@ -77,7 +106,7 @@ type PortsItems struct {
 	// The port number (e.g. 1337) or range (e.g. 3000-3999) to expose.
 	Port interface{} `yaml:"port"`
 
-	// The protocol to be used. (deprecated)
+	// The transport protocol of the port. UDP ports are only reported and tunneled if configured with 'UDP'. Other values are deprecated.
 	Protocol string `yaml:"protocol,omitempty"`
 
 	// Whether the port visibility should be private or public. 'public' (default) will allow everyone with the port URL to access the port. 'private' will only allow users with workspace access to access the port.
@@ -106,6 +135,19 @@ type Prebuilds_object struct {
 	PullRequestsFromForks bool `yaml:"pullRequestsFromForks,omitempty"`
 }
 
+// Readiness Condition under which the task is considered ready. Tasks which depend on this task start once it is ready. Without a condition the task is ready once its `before` and `init` commands succeeded.
+type Readiness struct {
+
+	// A shell command which exits with 0 once the task is ready.
+	Command string `yaml:"command,omitempty"`
+
+	// A file which exists once the task is ready. Relative paths are resolved against the repository root.
+	File string `yaml:"file,omitempty"`
+
+	// A port which is served once the task is ready.
+	Port int `yaml:"port,omitempty"`
+}
+
 // TasksItems
 type TasksItems struct {
 
@@ -115,9 +157,15 @@ type TasksItems struct {
 	// The main shell command to run after `before` and `init`. This command is executed last on every start and doesn't have to terminate.
 	Command string `yaml:"command,omitempty" json:"command,omitempty"`
 
+	// Names of the tasks which must be ready before this task starts.
+	DependsOn []string `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`
+
 	// Environment variables to set.
 	Env *Env `yaml:"env,omitempty" json:"env,omitempty"`
 
+	// Checks the health of the running task. Once the check failed `failureThreshold` times in a row after succeeding at least once, the task is restarted according to its restart policy.
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+
 	// A shell command to run between `before` and the main `command`. This command is executed only on after initializing a workspace with a fresh clone, but not on restarts and snapshots. This command is expected to terminate. If it fails, the `command` property will not be executed.
 	Init string `yaml:"init,omitempty" json:"init,omitempty"`
 
@@ -132,6 +180,12 @@ type TasksItems struct {
 
 	// A shell command to run after `before`. This command is executed only on during workspace prebuilds. This command is expected to terminate. If it fails, the workspace build fails.
 	Prebuild string `yaml:"prebuild,omitempty" json:"prebuild,omitempty"`
+
+	// Condition under which the task is considered ready. Tasks which depend on this task start once it is ready. Without a condition the task is ready once its `before` and `init` commands succeeded.
+	Readiness *Readiness `yaml:"readiness,omitempty" json:"readiness,omitempty"`
+
+	// When to restart the task after its terminal exited. Restarts run `before` and `command`, but not `init`. Defaults to 'never', or 'on-failure' if a health check is configured. Ignored during prebuilds.
+	RestartPolicy string `yaml:"restartPolicy,omitempty" json:"restartPolicy,omitempty"`
 }
 
 // Vscode Configure VS Code integration
@@ -218,6 +272,17 @@ func (strct *GitpodConfig) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "hosts" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"hosts\": ")
+	if tmp, err := json.Marshal(strct.Hosts); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// Marshal the "ide" field
 	if comma {
 		buf.WriteString(",")
@@ -310,6 +375,10 @@ func (strct *GitpodConfig) UnmarshalJSON(b []byte) error {
 			if err := json.Unmarshal([]byte(v), &strct.Github); err != nil {
 				return err
 			}
+		case "hosts":
+			if err := json.Unmarshal([]byte(v), &strct.Hosts); err != nil {
+				return err
+			}
 		case "ide":
 			if err := json.Unmarshal([]byte(v), &strct.Ide); err != nil {
 				return err
@@ -341,6 +410,162 @@ func (strct *GitpodConfig) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+func (strct *HealthCheck) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "command" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"command\": ")
+	if tmp, err := json.Marshal(strct.Command); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "failureThreshold" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"failureThreshold\": ")
+	if tmp, err := json.Marshal(strct.FailureThreshold); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "interval" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"interval\": ")
+	if tmp, err := json.Marshal(strct.Interval); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "port" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"port\": ")
+	if tmp, err := json.Marshal(strct.Port); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (strct *HealthCheck) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "command":
+			if err := json.Unmarshal([]byte(v), &strct.Command); err != nil {
+				return err
+			}
+		case "failureThreshold":
+			if err := json.Unmarshal([]byte(v), &strct.FailureThreshold); err != nil {
+				return err
+			}
+		case "interval":
+			if err := json.Unmarshal([]byte(v), &strct.Interval); err != nil {
+				return err
+			}
+		case "port":
+			if err := json.Unmarshal([]byte(v), &strct.Port); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("additional property not allowed: \"" + k + "\"")
+		}
+	}
+	return nil
+}
+
+func (strct *HostsItems) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// "Ip" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "ip" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"ip\": ")
+	if tmp, err := json.Marshal(strct.Ip); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// "Name" field is required
+	// only required object types supported for marshal checking (for now)
+	// Marshal the "name" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"name\": ")
+	if tmp, err := json.Marshal(strct.Name); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (strct *HostsItems) UnmarshalJSON(b []byte) error {
+	ipReceived := false
+	nameReceived := false
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "ip":
+			if err := json.Unmarshal([]byte(v), &strct.Ip); err != nil {
+				return err
+			}
+			ipReceived = true
+		case "name":
+			if err := json.Unmarshal([]byte(v), &strct.Name); err != nil {
+				return err
+			}
+			nameReceived = true
+		default:
+			return fmt.Errorf("additional property not allowed: \"" + k + "\"")
+		}
+	}
+	// check if ip (a required property) was received
+	if !ipReceived {
+		return errors.New("\"ip\" is required but was not present")
+	}
+	// check if name (a required property) was received
+	if !nameReceived {
+		return errors.New("\"name\" is required but was not present")
+	}
+	return nil
+}
+
 func (strct *Image_object) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
@@ -512,6 +737,76 @@ func (strct *PortsItems) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+func (strct *Readiness) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	buf.WriteString("{")
+	comma := false
+	// Marshal the "command" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"command\": ")
+	if tmp, err := json.Marshal(strct.Command); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "file" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"file\": ")
+	if tmp, err := json.Marshal(strct.File); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "port" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"port\": ")
+	if tmp, err := json.Marshal(strct.Port); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+
+	buf.WriteString("}")
+	rv := buf.Bytes()
+	return rv, nil
+}
+
+func (strct *Readiness) UnmarshalJSON(b []byte) error {
+	var jsonMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &jsonMap); err != nil {
+		return err
+	}
+	// parse all the defined properties
+	for k, v := range jsonMap {
+		switch k {
+		case "command":
+			if err := json.Unmarshal([]byte(v), &strct.Command); err != nil {
+				return err
+			}
+		case "file":
+			if err := json.Unmarshal([]byte(v), &strct.File); err != nil {
+				return err
+			}
+		case "port":
+			if err := json.Unmarshal([]byte(v), &strct.Port); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("additional property not allowed: \"" + k + "\"")
+		}
+	}
+	return nil
+}
+
 func (strct *TasksItems) MarshalJSON() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0))
 	buf.WriteString("{")
@@ -538,6 +833,17 @@ func (strct *TasksItems) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "dependsOn" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"dependsOn\": ")
+	if tmp, err := json.Marshal(strct.DependsOn); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// Marshal the "env" field
 	if comma {
 		buf.WriteString(",")
@@ -549,6 +855,17 @@ func (strct *TasksItems) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "healthCheck" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"healthCheck\": ")
+	if tmp, err := json.Marshal(strct.HealthCheck); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 	// Marshal the "init" field
 	if comma {
 		buf.WriteString(",")
@@ -604,6 +921,28 @@ func (strct *TasksItems) MarshalJSON() ([]byte, error) {
 		buf.Write(tmp)
 	}
 	comma = true
+	// Marshal the "readiness" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"readiness\": ")
+	if tmp, err := json.Marshal(strct.Readiness); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
+	// Marshal the "restartPolicy" field
+	if comma {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\"restartPolicy\": ")
+	if tmp, err := json.Marshal(strct.RestartPolicy); err != nil {
+		return nil, err
+	} else {
+		buf.Write(tmp)
+	}
+	comma = true
 
 	buf.WriteString("}")
 	rv := buf.Bytes()
@@ -626,10 +965,18 @@ func (strct *TasksItems) UnmarshalJSON(b []byte) error {
 			if err := json.Unmarshal([]byte(v), &strct.Command); err != nil {
 				return err
 			}
+		case "dependsOn":
+			if err := json.Unmarshal([]byte(v), &strct.DependsOn); err != nil {
+				return err
+			}
 		case "env":
 			if err := json.Unmarshal([]byte(v), &strct.Env); err != nil {
 				return err
 			}
+		case "healthCheck":
+			if err := json.Unmarshal([]byte(v), &strct.HealthCheck); err != nil {
+				return err
+			}
 		case "init":
 			if err := json.Unmarshal([]byte(v), &strct.Init); err != nil {
 				return err
@@ -650,6 +997,14 @@ func (strct *TasksItems) UnmarshalJSON(b []byte) error {
 			if err := json.Unmarshal([]byte(v), &strct.Prebuild); err != nil {
 				return err
 			}
+		case "readiness":
+			if err := json.Unmarshal([]byte(v), &strct.Readiness); err != nil {
+				return err
+			}
+		case "restartPolicy":
+			if err := json.Unmarshal([]byte(v), &strct.RestartPolicy); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("additional property not allowed: \"" + k + "\"")
 		}
@@ -0,0 +1,506 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// gitpodSchema is a copy of ../data/gitpod-schema.json which go:embed cannot reach.
+// hack/generate-config.sh keeps it in sync.
+//
+//go:embed gitpod-schema.json
+var gitpodSchema []byte
+
+var configSchema = mustParseConfigSchema(gitpodSchema)
+
+// ConfigValidationError is a problem found in a gitpod config file
+type ConfigValidationError struct {
+	// Line and Column locate the problem in the config file, starting at 1.
+	// Column is 0 if only the line is known.
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Path is the offending key in the config, e.g. tasks[0].openMode
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *ConfigValidationError) Error() string {
+	var res strings.Builder
+	if e.Column > 0 {
+		fmt.Fprintf(&res, "line %d, column %d: ", e.Line, e.Column)
+	} else if e.Line > 0 {
+		fmt.Fprintf(&res, "line %d: ", e.Line)
+	}
+	if e.Path != "" {
+		res.WriteString(e.Path + ": ")
+	}
+	res.WriteString(e.Message)
+	return res.String()
+}
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// ValidateConfig validates the content of a gitpod config file against the gitpod config schema
+// and the rules the schema cannot express, e.g. port ranges or unique task names.
+// It returns nil if the config is valid and the problems ordered by their position otherwise.
+func ValidateConfig(content []byte) []*ConfigValidationError {
+	var doc yaml.Node
+	err := yaml.Unmarshal(content, &doc)
+	if err != nil {
+		res := &ConfigValidationError{Message: err.Error()}
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			res.Line, _ = strconv.Atoi(m[1])
+			res.Message = m[2]
+		}
+		return []*ConfigValidationError{res}
+	}
+	if len(doc.Content) == 0 {
+		// an empty config file is a valid config
+		return nil
+	}
+
+	var res []*ConfigValidationError
+	report := func(node *yaml.Node, path, format string, args ...interface{}) {
+		res = append(res, &ConfigValidationError{
+			Line:    node.Line,
+			Column:  node.Column,
+			Path:    path,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	root := resolveAlias(doc.Content[0])
+	configSchema.validate(root, "", report)
+	validatePortsConfig(mappingValue(root, "ports"), "ports", report)
+	validateTasksConfig(mappingValue(root, "tasks"), "tasks", report)
+	validateHostsConfig(mappingValue(root, "hosts"), "hosts", report)
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Line != res[j].Line {
+			return res[i].Line < res[j].Line
+		}
+		return res[i].Column < res[j].Column
+	})
+	return res
+}
+
+type reportFunc func(node *yaml.Node, path, format string, args ...interface{})
+
+// jsonSchema is the subset of JSON schema the gitpod config schema uses
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Required             []string               `json:"required"`
+	Enum                 []string               `json:"enum"`
+	Items                *jsonSchema            `json:"items"`
+	Pattern              string                 `json:"pattern"`
+
+	pattern *regexp.Regexp
+	// closed is true if the schema allows no other properties than the ones listed
+	closed bool
+	// additional is the schema of properties which are not listed, if any
+	additional *jsonSchema
+}
+
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	err := json.Unmarshal(data, &multiple)
+	if err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+func (t schemaTypes) allows(actual string) bool {
+	if len(t) == 0 {
+		return true
+	}
+	for _, tpe := range t {
+		if tpe == actual || (tpe == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseConfigSchema(data []byte) *jsonSchema {
+	var res jsonSchema
+	err := json.Unmarshal(data, &res)
+	if err != nil {
+		panic(fmt.Sprintf("cannot parse the gitpod config schema: %v", err))
+	}
+	err = res.compile()
+	if err != nil {
+		panic(fmt.Sprintf("cannot compile the gitpod config schema: %v", err))
+	}
+	return &res
+}
+
+func (s *jsonSchema) compile() (err error) {
+	if s.Pattern != "" {
+		s.pattern, err = regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+	}
+	switch props := strings.TrimSpace(string(s.AdditionalProperties)); {
+	case props == "false":
+		s.closed = true
+	case strings.HasPrefix(props, "{"):
+		err = json.Unmarshal(s.AdditionalProperties, &s.additional)
+		if err != nil {
+			return err
+		}
+	}
+
+	children := []*jsonSchema{s.Items, s.additional}
+	for _, prop := range s.Properties {
+		children = append(children, prop)
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		err = child.compile()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validate(node *yaml.Node, path string, report reportFunc) {
+	node = resolveAlias(node)
+	actual := nodeType(node)
+	if !s.Type.allows(actual) {
+		report(node, path, "expected %s, got %s", strings.Join(s.Type, " or "), actual)
+		return
+	}
+	if len(s.Enum) > 0 && node.Kind == yaml.ScalarNode && !containsString(s.Enum, node.Value) {
+		report(node, path, "must be one of %s, not %q", strings.Join(s.Enum, ", "), node.Value)
+	}
+	if s.pattern != nil && actual == "string" && !s.pattern.MatchString(node.Value) {
+		report(node, path, "%q does not match the pattern %s", node.Value, s.Pattern)
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		s.validateProperties(node, path, report)
+	case yaml.SequenceNode:
+		if s.Items == nil {
+			return
+		}
+		for i, item := range node.Content {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	}
+}
+
+func (s *jsonSchema) validateProperties(node *yaml.Node, path string, report reportFunc) {
+	seen := make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.ShortTag() == "!!merge" {
+			continue
+		}
+		keyPath := joinConfigPath(path, key.Value)
+		if first, exists := seen[key.Value]; exists {
+			report(key, keyPath, "key is already defined in line %d", first.Line)
+			continue
+		}
+		seen[key.Value] = key
+
+		if prop, ok := s.Properties[key.Value]; ok {
+			prop.validate(value, keyPath, report)
+			continue
+		}
+		if s.additional != nil {
+			s.additional.validate(value, keyPath, report)
+			continue
+		}
+		if !s.closed {
+			continue
+		}
+		if suggestion := s.closestProperty(key.Value); suggestion != "" {
+			report(key, keyPath, "unknown key %q, did you mean %q?", key.Value, suggestion)
+		} else {
+			report(key, keyPath, "unknown key %q", key.Value)
+		}
+	}
+
+	for _, required := range s.Required {
+		if _, ok := seen[required]; !ok {
+			report(node, path, "missing required key %q", required)
+		}
+	}
+}
+
+// closestProperty returns the property name most likely meant by a misspelled key, if any
+func (s *jsonSchema) closestProperty(key string) string {
+	var (
+		res     string
+		minDist = len(key)/3 + 1
+	)
+	for prop := range s.Properties {
+		if strings.EqualFold(prop, key) {
+			return prop
+		}
+		dist := editDistance(strings.ToLower(prop), strings.ToLower(key))
+		if dist < minDist || (dist == minDist && res != "" && prop < res) {
+			res, minDist = prop, dist
+		}
+	}
+	return res
+}
+
+func validatePortsConfig(ports *yaml.Node, path string, report reportFunc) {
+	if ports == nil || ports.Kind != yaml.SequenceNode {
+		return
+	}
+	configured := make(map[int]*yaml.Node)
+	for i, item := range ports.Content {
+		portPath := fmt.Sprintf("%s[%d].port", path, i)
+		port := mappingValue(resolveAlias(item), "port")
+		if port == nil || port.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		switch port.ShortTag() {
+		case "!!int":
+			p, ok := validatePortNumber(port, port.Value, portPath, report)
+			if !ok {
+				continue
+			}
+			if first, exists := configured[p]; exists {
+				report(port, portPath, "port %d is already configured in line %d", p, first.Line)
+				continue
+			}
+			configured[p] = port
+		case "!!str":
+			bounds := portRangeSeparator.Split(port.Value, 2)
+			if len(bounds) != 2 {
+				// reported by the schema already
+				continue
+			}
+			start, startOk := validatePortNumber(port, bounds[0], portPath, report)
+			end, endOk := validatePortNumber(port, bounds[1], portPath, report)
+			if startOk && endOk && start > end {
+				report(port, portPath, "port range %s starts after it ends", port.Value)
+			}
+		}
+	}
+}
+
+var portRangeSeparator = regexp.MustCompile(`[:-]`)
+
+func validatePortNumber(node *yaml.Node, value, path string, report reportFunc) (port int, ok bool) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		report(node, path, "port %s is not between 1 and 65535", value)
+		return 0, false
+	}
+	return port, true
+}
+
+func validateTasksConfig(tasks *yaml.Node, path string, report reportFunc) {
+	if tasks == nil || tasks.Kind != yaml.SequenceNode {
+		return
+	}
+
+	var (
+		names   = make(map[string]*yaml.Node)
+		indices = make(map[string]int)
+		deps    = make([][]int, len(tasks.Content))
+		// depRefs are the dependsOn entries by task and dependency index, which is where we report cycles
+		depRefs = make([]map[int]taskDependencyRef, len(tasks.Content))
+	)
+	for i, item := range tasks.Content {
+		name := mappingValue(resolveAlias(item), "name")
+		if name == nil || name.Kind != yaml.ScalarNode || name.Value == "" {
+			continue
+		}
+		if first, exists := names[name.Value]; exists {
+			report(name, fmt.Sprintf("%s[%d].name", path, i), "task name %q is already used in line %d", name.Value, first.Line)
+			continue
+		}
+		names[name.Value] = name
+		indices[name.Value] = i
+	}
+
+	for i, item := range tasks.Content {
+		taskPath := fmt.Sprintf("%s[%d]", path, i)
+		task := resolveAlias(item)
+		name := mappingValue(task, "name")
+
+		if dependsOn := mappingValue(task, "dependsOn"); dependsOn != nil && dependsOn.Kind == yaml.SequenceNode {
+			for j, dep := range dependsOn.Content {
+				depPath := fmt.Sprintf("%s.dependsOn[%d]", taskPath, j)
+				if dep.Kind != yaml.ScalarNode {
+					continue
+				}
+				if name != nil && dep.Value == name.Value {
+					report(dep, depPath, "task cannot depend on itself")
+				} else if idx, exists := indices[dep.Value]; !exists {
+					report(dep, depPath, "unknown task %q", dep.Value)
+				} else {
+					if depRefs[i] == nil {
+						depRefs[i] = make(map[int]taskDependencyRef)
+					}
+					deps[i] = append(deps[i], idx)
+					depRefs[i][idx] = taskDependencyRef{dep, depPath}
+				}
+			}
+		}
+
+		for _, check := range []string{"healthCheck", "readiness"} {
+			port := mappingValue(mappingValue(task, check), "port")
+			if port == nil || port.ShortTag() != "!!int" {
+				continue
+			}
+			validatePortNumber(port, port.Value, taskPath+"."+check+".port", report)
+		}
+	}
+
+	for _, cycle := range TaskDependencyCycles(deps) {
+		cycleNames := make([]string, len(cycle))
+		for j, c := range cycle {
+			cycleNames[j] = mappingValue(resolveAlias(tasks.Content[c]), "name").Value
+		}
+		ref := depRefs[cycle[0]][cycle[1]]
+		report(ref.Node, ref.Path, "dependency cycle: %s", strings.Join(cycleNames, " -> "))
+	}
+}
+
+type taskDependencyRef struct {
+	Node *yaml.Node
+	Path string
+}
+
+func validateHostsConfig(hosts *yaml.Node, path string, report reportFunc) {
+	if hosts == nil || hosts.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, item := range hosts.Content {
+		ip := mappingValue(resolveAlias(item), "ip")
+		if ip == nil || ip.ShortTag() != "!!str" {
+			continue
+		}
+		if net.ParseIP(ip.Value) == nil {
+			report(ip, fmt.Sprintf("%s[%d].ip", path, i), "%q is not a valid IP address", ip.Value)
+		}
+	}
+}
+
+// mappingValue returns the value of key if node is a mapping which contains key, and nil otherwise
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// nodeType returns the JSON schema type of a YAML node
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	default:
+		return "string"
+	}
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters needed to turn a into b
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = minInt(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = minInt(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+func minInt(values ...int) int {
+	res := values[0]
+	for _, v := range values[1:] {
+		if v < res {
+			res = v
+		}
+	}
+	return res
+}
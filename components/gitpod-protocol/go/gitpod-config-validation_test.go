@@ -0,0 +1,194 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package protocol
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Content     string
+		Expectation []*ConfigValidationError
+	}{
+		{
+			Desc: "valid",
+			Content: `
+image:
+  file: .gitpod.Dockerfile
+ports:
+  - port: 3000
+    onOpen: open-preview
+  - port: 8000-8999
+    visibility: public
+tasks:
+  - name: db
+    init: make db
+    readiness:
+      port: 5432
+  - name: server
+    command: make run
+    dependsOn: [db]
+    openMode: split-right
+    env:
+      PORT: 3000
+hosts:
+  - name: db.example.com
+    ip: 127.0.0.1
+gitConfig:
+  core.autocrlf: input
+github:
+  prebuilds:
+    master: true
+vscode:
+  extensions:
+    - golang.go`,
+		},
+		{
+			Desc: "empty",
+		},
+		{
+			Desc:    "invalid YAML",
+			Content: "tasks:\n  - command: make: run",
+			Expectation: []*ConfigValidationError{
+				{Line: 2, Message: "mapping values are not allowed in this context"},
+			},
+		},
+		{
+			Desc:    "not an object",
+			Content: "- port: 3000",
+			Expectation: []*ConfigValidationError{
+				{Line: 1, Column: 1, Message: "expected object, got array"},
+			},
+		},
+		{
+			Desc: "unknown keys",
+			Content: `
+tasks:
+  - comand: make run
+    foo: bar
+prots:
+  - port: 3000`,
+			Expectation: []*ConfigValidationError{
+				{Line: 3, Column: 5, Path: "tasks[0].comand", Message: `unknown key "comand", did you mean "command"?`},
+				{Line: 4, Column: 5, Path: "tasks[0].foo", Message: `unknown key "foo"`},
+				{Line: 5, Column: 1, Path: "prots", Message: `unknown key "prots", did you mean "ports"?`},
+			},
+		},
+		{
+			Desc: "duplicate keys",
+			Content: `
+tasks:
+  - command: make run
+    command: make serve`,
+			Expectation: []*ConfigValidationError{
+				{Line: 4, Column: 5, Path: "tasks[0].command", Message: "key is already defined in line 3"},
+			},
+		},
+		{
+			Desc: "types and enums",
+			Content: `
+tasks:
+  - openMode: split-top
+    openIn: main
+    dependsOn: db
+    healthCheck:
+      port: "8080"
+vscode: true`,
+			Expectation: []*ConfigValidationError{
+				{Line: 3, Column: 15, Path: "tasks[0].openMode", Message: `must be one of split-left, split-right, tab-before, tab-after, not "split-top"`},
+				{Line: 5, Column: 16, Path: "tasks[0].dependsOn", Message: "expected array, got string"},
+				{Line: 7, Column: 13, Path: "tasks[0].healthCheck.port", Message: "expected integer, got string"},
+				{Line: 8, Column: 9, Path: "vscode", Message: "expected object, got boolean"},
+			},
+		},
+		{
+			Desc: "ports",
+			Content: `
+ports:
+  - port: 0
+  - port: 3000
+  - port: 3000
+  - port: 9000-8000
+  - port: 8000-70000
+  - port: "8080"
+  - onOpen: notify`,
+			Expectation: []*ConfigValidationError{
+				{Line: 3, Column: 11, Path: "ports[0].port", Message: "port 0 is not between 1 and 65535"},
+				{Line: 5, Column: 11, Path: "ports[2].port", Message: "port 3000 is already configured in line 4"},
+				{Line: 6, Column: 11, Path: "ports[3].port", Message: "port range 9000-8000 starts after it ends"},
+				{Line: 7, Column: 11, Path: "ports[4].port", Message: "port 70000 is not between 1 and 65535"},
+				{Line: 8, Column: 11, Path: "ports[5].port", Message: `"8080" does not match the pattern ^\d+[:-]\d+$`},
+				{Line: 9, Column: 5, Path: "ports[6]", Message: `missing required key "port"`},
+			},
+		},
+		{
+			Desc: "tasks",
+			Content: `
+tasks:
+  - name: server
+    dependsOn: [server, db]
+  - name: server
+    readiness:
+      port: 100000`,
+			Expectation: []*ConfigValidationError{
+				{Line: 4, Column: 17, Path: "tasks[0].dependsOn[0]", Message: "task cannot depend on itself"},
+				{Line: 4, Column: 25, Path: "tasks[0].dependsOn[1]", Message: `unknown task "db"`},
+				{Line: 5, Column: 11, Path: "tasks[1].name", Message: `task name "server" is already used in line 3`},
+				{Line: 7, Column: 13, Path: "tasks[1].readiness.port", Message: "port 100000 is not between 1 and 65535"},
+			},
+		},
+		{
+			Desc: "dependency cycle",
+			Content: `
+tasks:
+  - name: a
+    dependsOn: [c]
+  - name: b
+    dependsOn: [a]
+  - name: c
+    dependsOn: [b]`,
+			Expectation: []*ConfigValidationError{
+				{Line: 4, Column: 17, Path: "tasks[0].dependsOn[0]", Message: "dependency cycle: a -> c -> b -> a"},
+			},
+		},
+		{
+			Desc: "hosts",
+			Content: `
+hosts:
+  - name: db.example.com
+    ip: 10.0.0.300`,
+			Expectation: []*ConfigValidationError{
+				{Line: 4, Column: 9, Path: "hosts[0].ip", Message: `"10.0.0.300" is not a valid IP address`},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			act := ValidateConfig([]byte(test.Content))
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected ValidateConfig (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigSchemaIsInSync(t *testing.T) {
+	original, err := os.ReadFile("../data/gitpod-schema.json")
+	if os.IsNotExist(err) {
+		t.Skip("the original schema is not available, e.g. in a leeway build")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original, gitpodSchema) {
+		t.Error("gitpod-schema.json is out of sync with ../data/gitpod-schema.json, please run hack/generate-config.sh")
+	}
+}
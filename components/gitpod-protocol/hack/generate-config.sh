@@ -7,4 +7,7 @@ schema-generate -p protocol ../data/gitpod-schema.json > ../go/gitpod-config-typ
 sed -i 's/json:/yaml:/g' ../go/gitpod-config-types.go
 gofmt -w ../go/gitpod-config-types.go
 
+# go:embed cannot reach ../data, see gitpod-config-validation.go
+cp ../data/gitpod-schema.json ../go/gitpod-schema.json
+
 leeway run components:update-license-header
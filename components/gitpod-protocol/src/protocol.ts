@@ -154,7 +154,7 @@ export interface UserFeatureSettings {
  * The values of this type MUST MATCH enum values in WorkspaceFeatureFlag from ws-manager/client/core_pb.d.ts
  * If they don't we'll break things during workspace startup.
  */
-export const WorkspaceFeatureFlags = { "full_workspace_backup": undefined, "fixed_resources": undefined };
+export const WorkspaceFeatureFlags = { "full_workspace_backup": undefined, "fixed_resources": undefined, "process_checkpoint": undefined };
 export type NamedWorkspaceFeatureFlag = keyof (typeof WorkspaceFeatureFlags);
 
 export interface UserEnvVarValue {
@@ -558,6 +558,7 @@ export interface WorkspaceConfig {
     gitConfig?: { [config: string]: string };
     github?: GithubAppConfig;
     vscode?: VSCodeConfig;
+    hosts?: HostConfig[];
 
     /**
      * Where the config object originates from.
@@ -699,6 +700,11 @@ export namespace PortRangeConfig {
     }
 }
 
+export interface HostConfig {
+    name: string;
+    ip: string;
+}
+
 export interface TaskConfig {
     name?: string;
     before?: string;
@@ -708,6 +714,23 @@ export interface TaskConfig {
     env?: { [env: string]: string };
     openIn?: 'bottom' | 'main' | 'left' | 'right';
     openMode?: 'split-top' | 'split-left' | 'split-right' | 'split-bottom' | 'tab-before' | 'tab-after';
+    dependsOn?: string[];
+    readiness?: TaskReadinessConfig;
+    restartPolicy?: 'never' | 'on-failure' | 'always';
+    healthCheck?: TaskHealthCheckConfig;
+}
+
+export interface TaskReadinessConfig {
+    port?: number;
+    file?: string;
+    command?: string;
+}
+
+export interface TaskHealthCheckConfig {
+    port?: number;
+    command?: string;
+    interval?: number;
+    failureThreshold?: number;
 }
 
 export namespace TaskConfig {
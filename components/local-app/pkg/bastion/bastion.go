@@ -63,10 +63,17 @@ type TunnelListener struct {
 	LocalAddr  string
 	LocalPort  uint32
 	Visibility supervisor.TunnelVisiblity
+	Transport  supervisor.TransportProtocol
 	Ctx        context.Context
 	Cancel     func()
 }
 
+// tunnelKey identifies a tunneled port. TCP and UDP ports with the same number are tunneled independently.
+type tunnelKey struct {
+	RemotePort uint32
+	Transport  supervisor.TransportProtocol
+}
+
 type Workspace struct {
 	InstanceID  string
 	WorkspaceID string
@@ -78,7 +85,7 @@ type Workspace struct {
 	supervisorClient   *grpc.ClientConn
 
 	tunnelMu        sync.RWMutex
-	tunnelListeners map[uint32]*TunnelListener
+	tunnelListeners map[tunnelKey]*TunnelListener
 	tunnelEnabled   bool
 	cancelTunnel    context.CancelFunc
 
@@ -99,9 +106,10 @@ func (ws *Workspace) Status() []*app.TunnelStatus {
 	res := make([]*app.TunnelStatus, 0, len(ws.tunnelListeners))
 	for _, listener := range ws.tunnelListeners {
 		res = append(res, &app.TunnelStatus{
-			RemotePort: listener.RemotePort,
-			LocalPort:  listener.LocalPort,
-			Visibility: listener.Visibility,
+			RemotePort:        listener.RemotePort,
+			LocalPort:         listener.LocalPort,
+			Visibility:        listener.Visibility,
+			TransportProtocol: listener.Transport,
 		})
 	}
 	return res
@@ -294,7 +302,7 @@ func (b *Bastion) handleUpdate(ur *WorkspaceUpdateRequest) {
 			cancel: cancel,
 
 			tunnelClient:    make(chan chan *TunnelClient, 1),
-			tunnelListeners: make(map[uint32]*TunnelListener),
+			tunnelListeners: make(map[tunnelKey]*TunnelListener),
 			tunnelEnabled:   true,
 		}
 	}
@@ -612,6 +620,160 @@ func (b *Bastion) establishTunnel(ctx context.Context, ws *Workspace, logprefix
 	}, nil
 }
 
+const (
+	// udpTunnelIdleTimeout is the time after which we close the tunnel of a UDP client which stopped sending datagrams
+	udpTunnelIdleTimeout = 1 * time.Minute
+	// udpTunnelQueueSize is the number of datagrams we queue per UDP client before dropping them
+	udpTunnelQueueSize = 64
+)
+
+// establishUDPTunnel forwards the datagrams received on a local UDP port to a UDP port in the workspace.
+// UDP has no connections, so we open a tunnel per client address and close it once the client goes quiet.
+// Like UDP itself, the tunnel drops datagrams if it cannot keep up.
+func (b *Bastion) establishUDPTunnel(ctx context.Context, ws *Workspace, logprefix string, remotePort int, targetPort int, visibility supervisor.TunnelVisiblity) (*TunnelListener, error) {
+	if !ws.tunnelClientConnected {
+		return nil, fmt.Errorf("tunnel client is not connected")
+	}
+	if visibility == supervisor.TunnelVisiblity_none {
+		return nil, fmt.Errorf("tunnel visibility is none")
+	}
+
+	targetHost := "127.0.0.1"
+	if visibility == supervisor.TunnelVisiblity_network {
+		targetHost = "0.0.0.0"
+	}
+
+	packetConn, err := net.ListenPacket("udp", targetHost+":"+strconv.Itoa(targetPort))
+	if err != nil {
+		packetConn, err = net.ListenPacket("udp", targetHost+":0")
+		if err != nil {
+			return nil, err
+		}
+	}
+	localPort := packetConn.LocalAddr().(*net.UDPAddr).Port
+	logrus.WithField("workspace", ws.WorkspaceID).Info(logprefix + ": listening on " + packetConn.LocalAddr().String() + "...")
+	listenerCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-listenerCtx.Done()
+		packetConn.Close()
+		logrus.WithField("workspace", ws.WorkspaceID).Info(logprefix + ": closed")
+	}()
+	go func() {
+		var (
+			mu       sync.Mutex
+			sessions = make(map[string]chan []byte)
+			buf      = make([]byte, supervisor.MaxTunnelDatagramSize)
+		)
+		for {
+			n, addr, err := packetConn.ReadFrom(buf)
+			if listenerCtx.Err() != nil {
+				return
+			}
+			if err != nil {
+				logrus.WithError(err).WithField("workspace", ws.WorkspaceID).Warn(logprefix + ": failed to receive datagram")
+				continue
+			}
+
+			mu.Lock()
+			datagrams, exists := sessions[addr.String()]
+			if !exists {
+				logrus.WithField("workspace", ws.WorkspaceID).WithField("client", addr.String()).Debug(logprefix + ": new client")
+				datagrams = make(chan []byte, udpTunnelQueueSize)
+				sessions[addr.String()] = datagrams
+				go func(addr net.Addr) {
+					defer func() {
+						mu.Lock()
+						delete(sessions, addr.String())
+						mu.Unlock()
+						logrus.WithField("workspace", ws.WorkspaceID).WithField("client", addr.String()).Debug(logprefix + ": client gone")
+					}()
+					b.tunnelUDPClient(listenerCtx, ws, logprefix, packetConn, addr, remotePort, localPort, datagrams)
+				}(addr)
+			}
+			select {
+			case datagrams <- append([]byte(nil), buf[:n]...):
+			default:
+			}
+			mu.Unlock()
+		}
+	}()
+	return &TunnelListener{
+		RemotePort: uint32(remotePort),
+		LocalAddr:  packetConn.LocalAddr().String(),
+		LocalPort:  uint32(localPort),
+		Visibility: visibility,
+		Transport:  supervisor.TransportProtocol_transport_udp,
+		Ctx:        listenerCtx,
+		Cancel:     cancel,
+	}, nil
+}
+
+// tunnelUDPClient forwards the datagrams of a single UDP client through its own tunnel, and sends the replies back to it
+func (b *Bastion) tunnelUDPClient(ctx context.Context, ws *Workspace, logprefix string, packetConn net.PacketConn, addr net.Addr, remotePort int, localPort int, datagrams <-chan []byte) {
+	clientCh := make(chan *TunnelClient, 1)
+	select {
+	case <-ctx.Done():
+		return
+	case ws.tunnelClient <- clientCh:
+	}
+	client := <-clientCh
+
+	payload, err := proto.Marshal(&supervisor.TunnelPortRequest{
+		ClientId:          client.ID,
+		Port:              uint32(remotePort),
+		TargetPort:        uint32(localPort),
+		TransportProtocol: supervisor.TransportProtocol_transport_udp,
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("workspace", ws.WorkspaceID).WithField("id", client.ID).Error(logprefix + ": failed to marshal tunnel payload")
+		return
+	}
+	sshChan, reqs, err := client.Conn.OpenChannel("tunnel", payload)
+	if err != nil {
+		logrus.WithError(err).WithField("workspace", ws.WorkspaceID).WithField("id", client.ID).Warn(logprefix + ": failed to establish tunnel")
+		return
+	}
+	defer sshChan.Close()
+	go ssh.DiscardRequests(reqs)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer cancel()
+		buf := make([]byte, supervisor.MaxTunnelDatagramSize)
+		for {
+			n, err := supervisor.ReadTunnelDatagram(sshChan, buf)
+			if err != nil {
+				return
+			}
+			_, err = packetConn.WriteTo(buf[:n], addr)
+			if err != nil {
+				logrus.WithError(err).WithField("workspace", ws.WorkspaceID).WithField("client", addr.String()).Debug(logprefix + ": failed to send datagram")
+			}
+		}
+	}()
+
+	idle := time.NewTimer(udpTunnelIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-idle.C:
+			return
+		case datagram := <-datagrams:
+			err := supervisor.WriteTunnelDatagram(sshChan, datagram)
+			if err != nil {
+				return
+			}
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(udpTunnelIdleTimeout)
+		}
+	}
+}
+
 func (b *Bastion) establishSSHTunnel(ws *Workspace) (listener *TunnelListener, err error) {
 	if ws.SSHPublicKey == "" {
 		return nil, fmt.Errorf("no public key generated")
@@ -723,7 +885,8 @@ func (b *Bastion) tunnelPorts(ws *Workspace) {
 func (b *Bastion) doTunnelPorts(ctx context.Context, ws *Workspace) error {
 	statusService := supervisor.NewStatusServiceClient(ws.supervisorClient)
 	status, err := statusService.PortsStatus(ctx, &supervisor.PortsStatusRequest{
-		Observe: true,
+		Observe:    true,
+		IncludeUdp: true,
 	})
 	if err != nil {
 		return err
@@ -732,8 +895,8 @@ func (b *Bastion) doTunnelPorts(ctx context.Context, ws *Workspace) error {
 	defer func() {
 		ws.tunnelMu.Lock()
 		defer ws.tunnelMu.Unlock()
-		for port, t := range ws.tunnelListeners {
-			delete(ws.tunnelListeners, port)
+		for key, t := range ws.tunnelListeners {
+			delete(ws.tunnelListeners, key)
 			t.Cancel()
 		}
 	}()
@@ -743,22 +906,23 @@ func (b *Bastion) doTunnelPorts(ctx context.Context, ws *Workspace) error {
 			return err
 		}
 		ws.tunnelMu.Lock()
-		currentTunneled := make(map[uint32]struct{})
+		currentTunneled := make(map[tunnelKey]struct{})
 		for _, port := range resp.Ports {
+			key := tunnelKey{RemotePort: port.LocalPort, Transport: port.TransportProtocol}
 			visibility := supervisor.TunnelVisiblity_none
 			if port.Tunneled != nil {
 				visibility = port.Tunneled.Visibility
 			}
-			listener, alreadyTunneled := ws.tunnelListeners[port.LocalPort]
+			listener, alreadyTunneled := ws.tunnelListeners[key]
 			if alreadyTunneled && listener.Visibility != visibility {
 				listener.Cancel()
-				delete(ws.tunnelListeners, port.LocalPort)
+				delete(ws.tunnelListeners, key)
 			}
 			if visibility == supervisor.TunnelVisiblity_none {
 				continue
 			}
-			currentTunneled[port.LocalPort] = struct{}{}
-			_, alreadyTunneled = ws.tunnelListeners[port.LocalPort]
+			currentTunneled[key] = struct{}{}
+			_, alreadyTunneled = ws.tunnelListeners[key]
 			if alreadyTunneled {
 				continue
 			}
@@ -767,18 +931,24 @@ func (b *Bastion) doTunnelPorts(ctx context.Context, ws *Workspace) error {
 				continue
 			}
 
-			logprefix := "tunnel[" + supervisor.TunnelVisiblity_name[int32(port.Tunneled.Visibility)] + ":" + strconv.Itoa(int(port.LocalPort)) + "]"
-			listener, err := b.establishTunnel(ws.ctx, ws, logprefix, int(port.LocalPort), int(port.Tunneled.TargetPort), port.Tunneled.Visibility)
+			logprefix := "tunnel[" + supervisor.TunnelVisiblity_name[int32(port.Tunneled.Visibility)] + ":" + strconv.Itoa(int(port.LocalPort))
+			if port.TransportProtocol == supervisor.TransportProtocol_transport_udp {
+				logprefix += "/udp]"
+				listener, err = b.establishUDPTunnel(ws.ctx, ws, logprefix, int(port.LocalPort), int(port.Tunneled.TargetPort), port.Tunneled.Visibility)
+			} else {
+				logprefix += "]"
+				listener, err = b.establishTunnel(ws.ctx, ws, logprefix, int(port.LocalPort), int(port.Tunneled.TargetPort), port.Tunneled.Visibility)
+			}
 			if err != nil {
 				logrus.WithError(err).WithField("workspace", ws.WorkspaceID).WithField("port", port.LocalPort).Error("cannot establish port tunnel")
 			} else {
-				ws.tunnelListeners[port.LocalPort] = listener
+				ws.tunnelListeners[key] = listener
 			}
 		}
-		for port, listener := range ws.tunnelListeners {
-			_, exists := currentTunneled[port]
+		for key, listener := range ws.tunnelListeners {
+			_, exists := currentTunneled[key]
 			if !exists {
-				delete(ws.tunnelListeners, port)
+				delete(ws.tunnelListeners, key)
 				listener.Cancel()
 			}
 		}
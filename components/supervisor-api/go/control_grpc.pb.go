@@ -24,6 +24,8 @@ const _ = grpc.SupportPackageIsVersion7
 type ControlServiceClient interface {
 	// ExposePort exposes a port
 	ExposePort(ctx context.Context, in *ExposePortRequest, opts ...grpc.CallOption) (*ExposePortResponse, error)
+	// SetPortVisibility changes the visibility of an exposed port
+	SetPortVisibility(ctx context.Context, in *SetPortVisibilityRequest, opts ...grpc.CallOption) (*SetPortVisibilityResponse, error)
 }
 
 type controlServiceClient struct {
@@ -43,12 +45,23 @@ func (c *controlServiceClient) ExposePort(ctx context.Context, in *ExposePortReq
 	return out, nil
 }
 
+func (c *controlServiceClient) SetPortVisibility(ctx context.Context, in *SetPortVisibilityRequest, opts ...grpc.CallOption) (*SetPortVisibilityResponse, error) {
+	out := new(SetPortVisibilityResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.ControlService/SetPortVisibility", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlServiceServer is the server API for ControlService service.
 // All implementations must embed UnimplementedControlServiceServer
 // for forward compatibility
 type ControlServiceServer interface {
 	// ExposePort exposes a port
 	ExposePort(context.Context, *ExposePortRequest) (*ExposePortResponse, error)
+	// SetPortVisibility changes the visibility of an exposed port
+	SetPortVisibility(context.Context, *SetPortVisibilityRequest) (*SetPortVisibilityResponse, error)
 	mustEmbedUnimplementedControlServiceServer()
 }
 
@@ -59,6 +72,9 @@ type UnimplementedControlServiceServer struct {
 func (UnimplementedControlServiceServer) ExposePort(context.Context, *ExposePortRequest) (*ExposePortResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ExposePort not implemented")
 }
+func (UnimplementedControlServiceServer) SetPortVisibility(context.Context, *SetPortVisibilityRequest) (*SetPortVisibilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPortVisibility not implemented")
+}
 func (UnimplementedControlServiceServer) mustEmbedUnimplementedControlServiceServer() {}
 
 // UnsafeControlServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -90,6 +106,24 @@ func _ControlService_ExposePort_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ControlService_SetPortVisibility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPortVisibilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).SetPortVisibility(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.ControlService/SetPortVisibility",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).SetPortVisibility(ctx, req.(*SetPortVisibilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ControlService_ServiceDesc is the grpc.ServiceDesc for ControlService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -101,6 +135,10 @@ var ControlService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ExposePort",
 			Handler:    _ControlService_ExposePort_Handler,
 		},
+		{
+			MethodName: "SetPortVisibility",
+			Handler:    _ControlService_SetPortVisibility_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "control.proto",
@@ -0,0 +1,189 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// HostsServiceClient is the client API for HostsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HostsServiceClient interface {
+	// List returns all additional hosts entries of the workspace, i.e. those
+	// from .gitpod.yml and those added through this service.
+	List(ctx context.Context, in *ListHostsRequest, opts ...grpc.CallOption) (*ListHostsResponse, error)
+	// Add maps a hostname to an IP address. If the hostname is also configured in
+	// .gitpod.yml, the entry added through this service takes precedence.
+	Add(ctx context.Context, in *AddHostRequest, opts ...grpc.CallOption) (*AddHostResponse, error)
+	// Remove removes a hostname previously added through this service.
+	// Entries from .gitpod.yml cannot be removed.
+	Remove(ctx context.Context, in *RemoveHostRequest, opts ...grpc.CallOption) (*RemoveHostResponse, error)
+}
+
+type hostsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHostsServiceClient(cc grpc.ClientConnInterface) HostsServiceClient {
+	return &hostsServiceClient{cc}
+}
+
+func (c *hostsServiceClient) List(ctx context.Context, in *ListHostsRequest, opts ...grpc.CallOption) (*ListHostsResponse, error) {
+	out := new(ListHostsResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.HostsService/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostsServiceClient) Add(ctx context.Context, in *AddHostRequest, opts ...grpc.CallOption) (*AddHostResponse, error) {
+	out := new(AddHostResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.HostsService/Add", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostsServiceClient) Remove(ctx context.Context, in *RemoveHostRequest, opts ...grpc.CallOption) (*RemoveHostResponse, error) {
+	out := new(RemoveHostResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.HostsService/Remove", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HostsServiceServer is the server API for HostsService service.
+// All implementations must embed UnimplementedHostsServiceServer
+// for forward compatibility
+type HostsServiceServer interface {
+	// List returns all additional hosts entries of the workspace, i.e. those
+	// from .gitpod.yml and those added through this service.
+	List(context.Context, *ListHostsRequest) (*ListHostsResponse, error)
+	// Add maps a hostname to an IP address. If the hostname is also configured in
+	// .gitpod.yml, the entry added through this service takes precedence.
+	Add(context.Context, *AddHostRequest) (*AddHostResponse, error)
+	// Remove removes a hostname previously added through this service.
+	// Entries from .gitpod.yml cannot be removed.
+	Remove(context.Context, *RemoveHostRequest) (*RemoveHostResponse, error)
+	mustEmbedUnimplementedHostsServiceServer()
+}
+
+// UnimplementedHostsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedHostsServiceServer struct {
+}
+
+func (UnimplementedHostsServiceServer) List(context.Context, *ListHostsRequest) (*ListHostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedHostsServiceServer) Add(context.Context, *AddHostRequest) (*AddHostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedHostsServiceServer) Remove(context.Context, *RemoveHostRequest) (*RemoveHostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Remove not implemented")
+}
+func (UnimplementedHostsServiceServer) mustEmbedUnimplementedHostsServiceServer() {}
+
+// UnsafeHostsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HostsServiceServer will
+// result in compilation errors.
+type UnsafeHostsServiceServer interface {
+	mustEmbedUnimplementedHostsServiceServer()
+}
+
+func RegisterHostsServiceServer(s grpc.ServiceRegistrar, srv HostsServiceServer) {
+	s.RegisterService(&HostsService_ServiceDesc, srv)
+}
+
+func _HostsService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostsServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.HostsService/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostsServiceServer).List(ctx, req.(*ListHostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostsService_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddHostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostsServiceServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.HostsService/Add",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostsServiceServer).Add(ctx, req.(*AddHostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostsService_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveHostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostsServiceServer).Remove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.HostsService/Remove",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostsServiceServer).Remove(ctx, req.(*RemoveHostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HostsService_ServiceDesc is the grpc.ServiceDesc for HostsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HostsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "supervisor.HostsService",
+	HandlerType: (*HostsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _HostsService_List_Handler,
+		},
+		{
+			MethodName: "Add",
+			Handler:    _HostsService_Add_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _HostsService_Remove_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "hosts.proto",
+}
@@ -24,12 +24,18 @@ const _ = grpc.SupportPackageIsVersion7
 type NotificationServiceClient interface {
 	// Prompts the user and asks for a decision. Typically called by some external process.
 	// If the list of actions is empty this service returns immediately,
-	// otherwise it blocks until the user has made their choice.
+	// otherwise it blocks until the user has made their choice or the notification timed out.
+	// Notifications are kept in a bounded history, so that they're not lost if no IDE is subscribed.
 	Notify(ctx context.Context, in *NotifyRequest, opts ...grpc.CallOption) (*NotifyResponse, error)
-	// Subscribe to notifications. Typically called by the IDE.
+	// Subscribe to notifications. Typically called by the IDE. Notifications which have not been delivered
+	// yet or still wait for a response are replayed first.
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (NotificationService_SubscribeClient, error)
 	// Report a user's choice as a response to a notification. Typically called by the IDE.
 	Respond(ctx context.Context, in *RespondRequest, opts ...grpc.CallOption) (*RespondResponse, error)
+	// ListNotifications lists the notifications of the history, oldest first.
+	ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error)
+	// MarkRead marks notifications as read.
+	MarkRead(ctx context.Context, in *MarkReadRequest, opts ...grpc.CallOption) (*MarkReadResponse, error)
 }
 
 type notificationServiceClient struct {
@@ -90,18 +96,42 @@ func (c *notificationServiceClient) Respond(ctx context.Context, in *RespondRequ
 	return out, nil
 }
 
+func (c *notificationServiceClient) ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error) {
+	out := new(ListNotificationsResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.NotificationService/ListNotifications", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) MarkRead(ctx context.Context, in *MarkReadRequest, opts ...grpc.CallOption) (*MarkReadResponse, error) {
+	out := new(MarkReadResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.NotificationService/MarkRead", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NotificationServiceServer is the server API for NotificationService service.
 // All implementations must embed UnimplementedNotificationServiceServer
 // for forward compatibility
 type NotificationServiceServer interface {
 	// Prompts the user and asks for a decision. Typically called by some external process.
 	// If the list of actions is empty this service returns immediately,
-	// otherwise it blocks until the user has made their choice.
+	// otherwise it blocks until the user has made their choice or the notification timed out.
+	// Notifications are kept in a bounded history, so that they're not lost if no IDE is subscribed.
 	Notify(context.Context, *NotifyRequest) (*NotifyResponse, error)
-	// Subscribe to notifications. Typically called by the IDE.
+	// Subscribe to notifications. Typically called by the IDE. Notifications which have not been delivered
+	// yet or still wait for a response are replayed first.
 	Subscribe(*SubscribeRequest, NotificationService_SubscribeServer) error
 	// Report a user's choice as a response to a notification. Typically called by the IDE.
 	Respond(context.Context, *RespondRequest) (*RespondResponse, error)
+	// ListNotifications lists the notifications of the history, oldest first.
+	ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error)
+	// MarkRead marks notifications as read.
+	MarkRead(context.Context, *MarkReadRequest) (*MarkReadResponse, error)
 	mustEmbedUnimplementedNotificationServiceServer()
 }
 
@@ -118,6 +148,12 @@ func (UnimplementedNotificationServiceServer) Subscribe(*SubscribeRequest, Notif
 func (UnimplementedNotificationServiceServer) Respond(context.Context, *RespondRequest) (*RespondResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Respond not implemented")
 }
+func (UnimplementedNotificationServiceServer) ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNotifications not implemented")
+}
+func (UnimplementedNotificationServiceServer) MarkRead(context.Context, *MarkReadRequest) (*MarkReadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkRead not implemented")
+}
 func (UnimplementedNotificationServiceServer) mustEmbedUnimplementedNotificationServiceServer() {}
 
 // UnsafeNotificationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -188,6 +224,42 @@ func _NotificationService_Respond_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NotificationService_ListNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.NotificationService/ListNotifications",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListNotifications(ctx, req.(*ListNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_MarkRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).MarkRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.NotificationService/MarkRead",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).MarkRead(ctx, req.(*MarkReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // NotificationService_ServiceDesc is the grpc.ServiceDesc for NotificationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -203,6 +275,14 @@ var NotificationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Respond",
 			Handler:    _NotificationService_Respond_Handler,
 		},
+		{
+			MethodName: "ListNotifications",
+			Handler:    _NotificationService_ListNotifications_Handler,
+		},
+		{
+			MethodName: "MarkRead",
+			Handler:    _NotificationService_MarkRead_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -37,6 +37,12 @@ type StatusServiceClient interface {
 	PortsStatus(ctx context.Context, in *PortsStatusRequest, opts ...grpc.CallOption) (StatusService_PortsStatusClient, error)
 	// TasksStatus provides tasks status information.
 	TasksStatus(ctx context.Context, in *TasksStatusRequest, opts ...grpc.CallOption) (StatusService_TasksStatusClient, error)
+	// StopTask closes the terminal of a task. Stopped tasks are not restarted according to their restart policy.
+	StopTask(ctx context.Context, in *StopTaskRequest, opts ...grpc.CallOption) (*StopTaskResponse, error)
+	// RestartTask runs a task again in a new terminal, right away and regardless of its restart policy.
+	RestartTask(ctx context.Context, in *RestartTaskRequest, opts ...grpc.CallOption) (*RestartTaskResponse, error)
+	// ResourcesStatus provides the CPU, memory and disk usage of the workspace against its limits.
+	ResourcesStatus(ctx context.Context, in *ResourcesStatusRequest, opts ...grpc.CallOption) (StatusService_ResourcesStatusClient, error)
 }
 
 type statusServiceClient struct {
@@ -147,6 +153,56 @@ func (x *statusServiceTasksStatusClient) Recv() (*TasksStatusResponse, error) {
 	return m, nil
 }
 
+func (c *statusServiceClient) StopTask(ctx context.Context, in *StopTaskRequest, opts ...grpc.CallOption) (*StopTaskResponse, error) {
+	out := new(StopTaskResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.StatusService/StopTask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusServiceClient) RestartTask(ctx context.Context, in *RestartTaskRequest, opts ...grpc.CallOption) (*RestartTaskResponse, error) {
+	out := new(RestartTaskResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.StatusService/RestartTask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusServiceClient) ResourcesStatus(ctx context.Context, in *ResourcesStatusRequest, opts ...grpc.CallOption) (StatusService_ResourcesStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StatusService_ServiceDesc.Streams[2], "/supervisor.StatusService/ResourcesStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &statusServiceResourcesStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StatusService_ResourcesStatusClient interface {
+	Recv() (*ResourcesStatusResponse, error)
+	grpc.ClientStream
+}
+
+type statusServiceResourcesStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *statusServiceResourcesStatusClient) Recv() (*ResourcesStatusResponse, error) {
+	m := new(ResourcesStatusResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // StatusServiceServer is the server API for StatusService service.
 // All implementations must embed UnimplementedStatusServiceServer
 // for forward compatibility
@@ -166,6 +222,12 @@ type StatusServiceServer interface {
 	PortsStatus(*PortsStatusRequest, StatusService_PortsStatusServer) error
 	// TasksStatus provides tasks status information.
 	TasksStatus(*TasksStatusRequest, StatusService_TasksStatusServer) error
+	// StopTask closes the terminal of a task. Stopped tasks are not restarted according to their restart policy.
+	StopTask(context.Context, *StopTaskRequest) (*StopTaskResponse, error)
+	// RestartTask runs a task again in a new terminal, right away and regardless of its restart policy.
+	RestartTask(context.Context, *RestartTaskRequest) (*RestartTaskResponse, error)
+	// ResourcesStatus provides the CPU, memory and disk usage of the workspace against its limits.
+	ResourcesStatus(*ResourcesStatusRequest, StatusService_ResourcesStatusServer) error
 	mustEmbedUnimplementedStatusServiceServer()
 }
 
@@ -191,6 +253,15 @@ func (UnimplementedStatusServiceServer) PortsStatus(*PortsStatusRequest, StatusS
 func (UnimplementedStatusServiceServer) TasksStatus(*TasksStatusRequest, StatusService_TasksStatusServer) error {
 	return status.Errorf(codes.Unimplemented, "method TasksStatus not implemented")
 }
+func (UnimplementedStatusServiceServer) StopTask(context.Context, *StopTaskRequest) (*StopTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopTask not implemented")
+}
+func (UnimplementedStatusServiceServer) RestartTask(context.Context, *RestartTaskRequest) (*RestartTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestartTask not implemented")
+}
+func (UnimplementedStatusServiceServer) ResourcesStatus(*ResourcesStatusRequest, StatusService_ResourcesStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method ResourcesStatus not implemented")
+}
 func (UnimplementedStatusServiceServer) mustEmbedUnimplementedStatusServiceServer() {}
 
 // UnsafeStatusServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -318,6 +389,63 @@ func (x *statusServiceTasksStatusServer) Send(m *TasksStatusResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _StatusService_StopTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServiceServer).StopTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.StatusService/StopTask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServiceServer).StopTask(ctx, req.(*StopTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusService_RestartTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServiceServer).RestartTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.StatusService/RestartTask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServiceServer).RestartTask(ctx, req.(*RestartTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusService_ResourcesStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ResourcesStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatusServiceServer).ResourcesStatus(m, &statusServiceResourcesStatusServer{stream})
+}
+
+type StatusService_ResourcesStatusServer interface {
+	Send(*ResourcesStatusResponse) error
+	grpc.ServerStream
+}
+
+type statusServiceResourcesStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *statusServiceResourcesStatusServer) Send(m *ResourcesStatusResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // StatusService_ServiceDesc is the grpc.ServiceDesc for StatusService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -341,6 +469,14 @@ var StatusService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BackupStatus",
 			Handler:    _StatusService_BackupStatus_Handler,
 		},
+		{
+			MethodName: "StopTask",
+			Handler:    _StatusService_StopTask_Handler,
+		},
+		{
+			MethodName: "RestartTask",
+			Handler:    _StatusService_RestartTask_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -353,6 +489,11 @@ var StatusService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _StatusService_TasksStatus_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ResourcesStatus",
+			Handler:       _StatusService_ResourcesStatus_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "status.proto",
 }
@@ -73,6 +73,54 @@ func (TerminalTitleSource) EnumDescriptor() ([]byte, []int) {
 	return file_terminal_proto_rawDescGZIP(), []int{0}
 }
 
+type TerminalRole int32
+
+const (
+	// Participant can listen to the terminal
+	TerminalRole_read_only TerminalRole = 0
+	// Participant can listen, write to and resize the terminal
+	TerminalRole_read_write TerminalRole = 1
+)
+
+// Enum value maps for TerminalRole.
+var (
+	TerminalRole_name = map[int32]string{
+		0: "read_only",
+		1: "read_write",
+	}
+	TerminalRole_value = map[string]int32{
+		"read_only":  0,
+		"read_write": 1,
+	}
+)
+
+func (x TerminalRole) Enum() *TerminalRole {
+	p := new(TerminalRole)
+	*p = x
+	return p
+}
+
+func (x TerminalRole) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TerminalRole) Descriptor() protoreflect.EnumDescriptor {
+	return file_terminal_proto_enumTypes[1].Descriptor()
+}
+
+func (TerminalRole) Type() protoreflect.EnumType {
+	return &file_terminal_proto_enumTypes[1]
+}
+
+func (x TerminalRole) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TerminalRole.Descriptor instead.
+func (TerminalRole) EnumDescriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{1}
+}
+
 type TerminalSize struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -155,6 +203,11 @@ type OpenTerminalRequest struct {
 	Shell       string            `protobuf:"bytes,4,opt,name=shell,proto3" json:"shell,omitempty"`
 	ShellArgs   []string          `protobuf:"bytes,5,rep,name=shell_args,json=shellArgs,proto3" json:"shell_args,omitempty"`
 	Size        *TerminalSize     `protobuf:"bytes,6,opt,name=size,proto3" json:"size,omitempty"`
+	// record enables an asciicast recording of the terminal session which can be replayed using Replay
+	Record bool `protobuf:"varint,7,opt,name=record,proto3" json:"record,omitempty"`
+	// shared terminals only accept input and size changes from their starter
+	// and from participants with the read_write role.
+	Shared bool `protobuf:"varint,8,opt,name=shared,proto3" json:"shared,omitempty"`
 }
 
 func (x *OpenTerminalRequest) Reset() {
@@ -231,6 +284,20 @@ func (x *OpenTerminalRequest) GetSize() *TerminalSize {
 	return nil
 }
 
+func (x *OpenTerminalRequest) GetRecord() bool {
+	if x != nil {
+		return x.Record
+	}
+	return false
+}
+
+func (x *OpenTerminalRequest) GetShared() bool {
+	if x != nil {
+		return x.Shared
+	}
+	return false
+}
+
 type OpenTerminalResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -294,6 +361,8 @@ type ShutdownTerminalRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	// token is the starter_token or a participant token. Required for shared terminals.
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
 }
 
 func (x *ShutdownTerminalRequest) Reset() {
@@ -335,6 +404,13 @@ func (x *ShutdownTerminalRequest) GetAlias() string {
 	return ""
 }
 
+func (x *ShutdownTerminalRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type ShutdownTerminalResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -386,6 +462,11 @@ type Terminal struct {
 	CurrentWorkdir string              `protobuf:"bytes,6,opt,name=current_workdir,json=currentWorkdir,proto3" json:"current_workdir,omitempty"`
 	Annotations    map[string]string   `protobuf:"bytes,7,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	TitleSource    TerminalTitleSource `protobuf:"varint,8,opt,name=title_source,json=titleSource,proto3,enum=supervisor.TerminalTitleSource" json:"title_source,omitempty"`
+	// recorded is true if the terminal session is being recorded
+	Recorded bool `protobuf:"varint,9,opt,name=recorded,proto3" json:"recorded,omitempty"`
+	// shared is true if access to the terminal is restricted to its participants
+	Shared       bool                   `protobuf:"varint,10,opt,name=shared,proto3" json:"shared,omitempty"`
+	Participants []*TerminalParticipant `protobuf:"bytes,11,rep,name=participants,proto3" json:"participants,omitempty"`
 }
 
 func (x *Terminal) Reset() {
@@ -476,6 +557,91 @@ func (x *Terminal) GetTitleSource() TerminalTitleSource {
 	return TerminalTitleSource_process
 }
 
+func (x *Terminal) GetRecorded() bool {
+	if x != nil {
+		return x.Recorded
+	}
+	return false
+}
+
+func (x *Terminal) GetShared() bool {
+	if x != nil {
+		return x.Shared
+	}
+	return false
+}
+
+func (x *Terminal) GetParticipants() []*TerminalParticipant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+type TerminalParticipant struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Role TerminalRole `protobuf:"varint,2,opt,name=role,proto3,enum=supervisor.TerminalRole" json:"role,omitempty"`
+	// attached is true while the participant listens to the terminal
+	Attached bool `protobuf:"varint,3,opt,name=attached,proto3" json:"attached,omitempty"`
+}
+
+func (x *TerminalParticipant) Reset() {
+	*x = TerminalParticipant{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TerminalParticipant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminalParticipant) ProtoMessage() {}
+
+func (x *TerminalParticipant) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminalParticipant.ProtoReflect.Descriptor instead.
+func (*TerminalParticipant) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TerminalParticipant) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TerminalParticipant) GetRole() TerminalRole {
+	if x != nil {
+		return x.Role
+	}
+	return TerminalRole_read_only
+}
+
+func (x *TerminalParticipant) GetAttached() bool {
+	if x != nil {
+		return x.Attached
+	}
+	return false
+}
+
 type GetTerminalRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -487,7 +653,7 @@ type GetTerminalRequest struct {
 func (x *GetTerminalRequest) Reset() {
 	*x = GetTerminalRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[6]
+		mi := &file_terminal_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -500,7 +666,7 @@ func (x *GetTerminalRequest) String() string {
 func (*GetTerminalRequest) ProtoMessage() {}
 
 func (x *GetTerminalRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[6]
+	mi := &file_terminal_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -513,7 +679,7 @@ func (x *GetTerminalRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTerminalRequest.ProtoReflect.Descriptor instead.
 func (*GetTerminalRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{6}
+	return file_terminal_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GetTerminalRequest) GetAlias() string {
@@ -532,7 +698,7 @@ type ListTerminalsRequest struct {
 func (x *ListTerminalsRequest) Reset() {
 	*x = ListTerminalsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[7]
+		mi := &file_terminal_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -545,7 +711,7 @@ func (x *ListTerminalsRequest) String() string {
 func (*ListTerminalsRequest) ProtoMessage() {}
 
 func (x *ListTerminalsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[7]
+	mi := &file_terminal_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -558,7 +724,7 @@ func (x *ListTerminalsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListTerminalsRequest.ProtoReflect.Descriptor instead.
 func (*ListTerminalsRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{7}
+	return file_terminal_proto_rawDescGZIP(), []int{8}
 }
 
 type ListTerminalsResponse struct {
@@ -572,7 +738,7 @@ type ListTerminalsResponse struct {
 func (x *ListTerminalsResponse) Reset() {
 	*x = ListTerminalsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[8]
+		mi := &file_terminal_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -585,7 +751,7 @@ func (x *ListTerminalsResponse) String() string {
 func (*ListTerminalsResponse) ProtoMessage() {}
 
 func (x *ListTerminalsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[8]
+	mi := &file_terminal_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -598,7 +764,7 @@ func (x *ListTerminalsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListTerminalsResponse.ProtoReflect.Descriptor instead.
 func (*ListTerminalsResponse) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{8}
+	return file_terminal_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ListTerminalsResponse) GetTerminals() []*Terminal {
@@ -614,12 +780,14 @@ type ListenTerminalRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	// token identifies the participant that listens. Required for shared terminals.
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
 }
 
 func (x *ListenTerminalRequest) Reset() {
 	*x = ListenTerminalRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[9]
+		mi := &file_terminal_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -632,7 +800,7 @@ func (x *ListenTerminalRequest) String() string {
 func (*ListenTerminalRequest) ProtoMessage() {}
 
 func (x *ListenTerminalRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[9]
+	mi := &file_terminal_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -645,7 +813,7 @@ func (x *ListenTerminalRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListenTerminalRequest.ProtoReflect.Descriptor instead.
 func (*ListenTerminalRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{9}
+	return file_terminal_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ListenTerminalRequest) GetAlias() string {
@@ -655,6 +823,13 @@ func (x *ListenTerminalRequest) GetAlias() string {
 	return ""
 }
 
+func (x *ListenTerminalRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type ListenTerminalResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -664,6 +839,7 @@ type ListenTerminalResponse struct {
 	//	*ListenTerminalResponse_Data
 	//	*ListenTerminalResponse_ExitCode
 	//	*ListenTerminalResponse_Title
+	//	*ListenTerminalResponse_Presence
 	Output isListenTerminalResponse_Output `protobuf_oneof:"output"`
 	// only present if output is title
 	TitleSource TerminalTitleSource `protobuf:"varint,4,opt,name=title_source,json=titleSource,proto3,enum=supervisor.TerminalTitleSource" json:"title_source,omitempty"`
@@ -672,7 +848,7 @@ type ListenTerminalResponse struct {
 func (x *ListenTerminalResponse) Reset() {
 	*x = ListenTerminalResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[10]
+		mi := &file_terminal_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -685,7 +861,7 @@ func (x *ListenTerminalResponse) String() string {
 func (*ListenTerminalResponse) ProtoMessage() {}
 
 func (x *ListenTerminalResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[10]
+	mi := &file_terminal_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -698,7 +874,7 @@ func (x *ListenTerminalResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListenTerminalResponse.ProtoReflect.Descriptor instead.
 func (*ListenTerminalResponse) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{10}
+	return file_terminal_proto_rawDescGZIP(), []int{11}
 }
 
 func (m *ListenTerminalResponse) GetOutput() isListenTerminalResponse_Output {
@@ -729,6 +905,13 @@ func (x *ListenTerminalResponse) GetTitle() string {
 	return ""
 }
 
+func (x *ListenTerminalResponse) GetPresence() *TerminalPresence {
+	if x, ok := x.GetOutput().(*ListenTerminalResponse_Presence); ok {
+		return x.Presence
+	}
+	return nil
+}
+
 func (x *ListenTerminalResponse) GetTitleSource() TerminalTitleSource {
 	if x != nil {
 		return x.TitleSource
@@ -752,12 +935,65 @@ type ListenTerminalResponse_Title struct {
 	Title string `protobuf:"bytes,3,opt,name=title,proto3,oneof"`
 }
 
+type ListenTerminalResponse_Presence struct {
+	Presence *TerminalPresence `protobuf:"bytes,5,opt,name=presence,proto3,oneof"`
+}
+
 func (*ListenTerminalResponse_Data) isListenTerminalResponse_Output() {}
 
 func (*ListenTerminalResponse_ExitCode) isListenTerminalResponse_Output() {}
 
 func (*ListenTerminalResponse_Title) isListenTerminalResponse_Output() {}
 
+func (*ListenTerminalResponse_Presence) isListenTerminalResponse_Output() {}
+
+type TerminalPresence struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Participants []*TerminalParticipant `protobuf:"bytes,1,rep,name=participants,proto3" json:"participants,omitempty"`
+}
+
+func (x *TerminalPresence) Reset() {
+	*x = TerminalPresence{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TerminalPresence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminalPresence) ProtoMessage() {}
+
+func (x *TerminalPresence) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminalPresence.ProtoReflect.Descriptor instead.
+func (*TerminalPresence) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TerminalPresence) GetParticipants() []*TerminalParticipant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
 type WriteTerminalRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -765,12 +1001,14 @@ type WriteTerminalRequest struct {
 
 	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
 	Stdin []byte `protobuf:"bytes,2,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	// token is the starter_token or a participant token. Required for shared terminals.
+	Token string `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
 }
 
 func (x *WriteTerminalRequest) Reset() {
 	*x = WriteTerminalRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[11]
+		mi := &file_terminal_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -783,7 +1021,7 @@ func (x *WriteTerminalRequest) String() string {
 func (*WriteTerminalRequest) ProtoMessage() {}
 
 func (x *WriteTerminalRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[11]
+	mi := &file_terminal_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -796,7 +1034,7 @@ func (x *WriteTerminalRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteTerminalRequest.ProtoReflect.Descriptor instead.
 func (*WriteTerminalRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{11}
+	return file_terminal_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *WriteTerminalRequest) GetAlias() string {
@@ -813,6 +1051,13 @@ func (x *WriteTerminalRequest) GetStdin() []byte {
 	return nil
 }
 
+func (x *WriteTerminalRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type WriteTerminalResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -824,7 +1069,7 @@ type WriteTerminalResponse struct {
 func (x *WriteTerminalResponse) Reset() {
 	*x = WriteTerminalResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[12]
+		mi := &file_terminal_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -837,7 +1082,7 @@ func (x *WriteTerminalResponse) String() string {
 func (*WriteTerminalResponse) ProtoMessage() {}
 
 func (x *WriteTerminalResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[12]
+	mi := &file_terminal_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -850,7 +1095,7 @@ func (x *WriteTerminalResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteTerminalResponse.ProtoReflect.Descriptor instead.
 func (*WriteTerminalResponse) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{12}
+	return file_terminal_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *WriteTerminalResponse) GetBytesWritten() uint32 {
@@ -870,6 +1115,8 @@ type SetTerminalSizeRequest struct {
 	// Without token it's possible that the request is ignored.
 	// If you want to force your size, indendently of all other listener,
 	// use force.
+	// For shared terminals token can also be the token of a read_write participant,
+	// and force is not permitted.
 	//
 	// Types that are assignable to Priority:
 	//	*SetTerminalSizeRequest_Token
@@ -881,7 +1128,7 @@ type SetTerminalSizeRequest struct {
 func (x *SetTerminalSizeRequest) Reset() {
 	*x = SetTerminalSizeRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[13]
+		mi := &file_terminal_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -894,7 +1141,7 @@ func (x *SetTerminalSizeRequest) String() string {
 func (*SetTerminalSizeRequest) ProtoMessage() {}
 
 func (x *SetTerminalSizeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[13]
+	mi := &file_terminal_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -907,7 +1154,7 @@ func (x *SetTerminalSizeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetTerminalSizeRequest.ProtoReflect.Descriptor instead.
 func (*SetTerminalSizeRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{13}
+	return file_terminal_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *SetTerminalSizeRequest) GetAlias() string {
@@ -970,7 +1217,7 @@ type SetTerminalSizeResponse struct {
 func (x *SetTerminalSizeResponse) Reset() {
 	*x = SetTerminalSizeResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[14]
+		mi := &file_terminal_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -983,7 +1230,7 @@ func (x *SetTerminalSizeResponse) String() string {
 func (*SetTerminalSizeResponse) ProtoMessage() {}
 
 func (x *SetTerminalSizeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[14]
+	mi := &file_terminal_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -996,7 +1243,7 @@ func (x *SetTerminalSizeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetTerminalSizeResponse.ProtoReflect.Descriptor instead.
 func (*SetTerminalSizeResponse) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{14}
+	return file_terminal_proto_rawDescGZIP(), []int{16}
 }
 
 type SetTerminalTitleRequest struct {
@@ -1007,12 +1254,14 @@ type SetTerminalTitleRequest struct {
 	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
 	// omitting title will reset to process title
 	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// token is the starter_token or a participant token. Required for shared terminals.
+	Token string `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
 }
 
 func (x *SetTerminalTitleRequest) Reset() {
 	*x = SetTerminalTitleRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[15]
+		mi := &file_terminal_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1025,7 +1274,7 @@ func (x *SetTerminalTitleRequest) String() string {
 func (*SetTerminalTitleRequest) ProtoMessage() {}
 
 func (x *SetTerminalTitleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[15]
+	mi := &file_terminal_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1038,7 +1287,7 @@ func (x *SetTerminalTitleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetTerminalTitleRequest.ProtoReflect.Descriptor instead.
 func (*SetTerminalTitleRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{15}
+	return file_terminal_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *SetTerminalTitleRequest) GetAlias() string {
@@ -1055,6 +1304,13 @@ func (x *SetTerminalTitleRequest) GetTitle() string {
 	return ""
 }
 
+func (x *SetTerminalTitleRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type SetTerminalTitleResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1064,7 +1320,7 @@ type SetTerminalTitleResponse struct {
 func (x *SetTerminalTitleResponse) Reset() {
 	*x = SetTerminalTitleResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[16]
+		mi := &file_terminal_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1077,7 +1333,7 @@ func (x *SetTerminalTitleResponse) String() string {
 func (*SetTerminalTitleResponse) ProtoMessage() {}
 
 func (x *SetTerminalTitleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[16]
+	mi := &file_terminal_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1090,7 +1346,7 @@ func (x *SetTerminalTitleResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetTerminalTitleResponse.ProtoReflect.Descriptor instead.
 func (*SetTerminalTitleResponse) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{16}
+	return file_terminal_proto_rawDescGZIP(), []int{18}
 }
 
 type UpdateTerminalAnnotationsRequest struct {
@@ -1103,12 +1359,14 @@ type UpdateTerminalAnnotationsRequest struct {
 	Changed map[string]string `protobuf:"bytes,2,rep,name=changed,proto3" json:"changed,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// annotations to remove
 	Deleted []string `protobuf:"bytes,3,rep,name=deleted,proto3" json:"deleted,omitempty"`
+	// token is the starter_token or a participant token. Required for shared terminals.
+	Token string `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
 }
 
 func (x *UpdateTerminalAnnotationsRequest) Reset() {
 	*x = UpdateTerminalAnnotationsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[17]
+		mi := &file_terminal_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1121,7 +1379,7 @@ func (x *UpdateTerminalAnnotationsRequest) String() string {
 func (*UpdateTerminalAnnotationsRequest) ProtoMessage() {}
 
 func (x *UpdateTerminalAnnotationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[17]
+	mi := &file_terminal_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1134,7 +1392,7 @@ func (x *UpdateTerminalAnnotationsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateTerminalAnnotationsRequest.ProtoReflect.Descriptor instead.
 func (*UpdateTerminalAnnotationsRequest) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{17}
+	return file_terminal_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *UpdateTerminalAnnotationsRequest) GetAlias() string {
@@ -1158,6 +1416,13 @@ func (x *UpdateTerminalAnnotationsRequest) GetDeleted() []string {
 	return nil
 }
 
+func (x *UpdateTerminalAnnotationsRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type UpdateTerminalAnnotationsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1167,7 +1432,7 @@ type UpdateTerminalAnnotationsResponse struct {
 func (x *UpdateTerminalAnnotationsResponse) Reset() {
 	*x = UpdateTerminalAnnotationsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_terminal_proto_msgTypes[18]
+		mi := &file_terminal_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1180,7 +1445,7 @@ func (x *UpdateTerminalAnnotationsResponse) String() string {
 func (*UpdateTerminalAnnotationsResponse) ProtoMessage() {}
 
 func (x *UpdateTerminalAnnotationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_terminal_proto_msgTypes[18]
+	mi := &file_terminal_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1193,23 +1458,399 @@ func (x *UpdateTerminalAnnotationsResponse) ProtoReflect() protoreflect.Message
 
 // Deprecated: Use UpdateTerminalAnnotationsResponse.ProtoReflect.Descriptor instead.
 func (*UpdateTerminalAnnotationsResponse) Descriptor() ([]byte, []int) {
-	return file_terminal_proto_rawDescGZIP(), []int{18}
+	return file_terminal_proto_rawDescGZIP(), []int{20}
 }
 
-var File_terminal_proto protoreflect.FileDescriptor
+type ReplayTerminalRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_terminal_proto_rawDesc = []byte{
-	0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x0a, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x1a, 0x1c, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6c, 0x0a, 0x0c, 0x54, 0x65,
-	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f,
-	0x77, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x12,
-	0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f,
-	0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x69, 0x64, 0x74, 0x68, 0x50, 0x78, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x07, 0x77, 0x69, 0x64, 0x74, 0x68, 0x50, 0x78, 0x12, 0x1a, 0x0a, 0x08,
-	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x50, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
-	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x50, 0x78, 0x22, 0x9a, 0x03, 0x0a, 0x13, 0x4f, 0x70, 0x65,
+	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	// speed is the playback speed factor, defaults to 1
+	Speed float64 `protobuf:"fixed64,2,opt,name=speed,proto3" json:"speed,omitempty"`
+	// max_idle caps the pauses between outputs in seconds. Zero replays pauses as recorded.
+	MaxIdle float64 `protobuf:"fixed64,3,opt,name=max_idle,json=maxIdle,proto3" json:"max_idle,omitempty"`
+}
+
+func (x *ReplayTerminalRequest) Reset() {
+	*x = ReplayTerminalRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayTerminalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayTerminalRequest) ProtoMessage() {}
+
+func (x *ReplayTerminalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayTerminalRequest.ProtoReflect.Descriptor instead.
+func (*ReplayTerminalRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReplayTerminalRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ReplayTerminalRequest) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *ReplayTerminalRequest) GetMaxIdle() float64 {
+	if x != nil {
+		return x.MaxIdle
+	}
+	return 0
+}
+
+type ReplayTerminalResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Output:
+	//	*ReplayTerminalResponse_Data
+	//	*ReplayTerminalResponse_Size
+	Output isReplayTerminalResponse_Output `protobuf_oneof:"output"`
+}
+
+func (x *ReplayTerminalResponse) Reset() {
+	*x = ReplayTerminalResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayTerminalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayTerminalResponse) ProtoMessage() {}
+
+func (x *ReplayTerminalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayTerminalResponse.ProtoReflect.Descriptor instead.
+func (*ReplayTerminalResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{22}
+}
+
+func (m *ReplayTerminalResponse) GetOutput() isReplayTerminalResponse_Output {
+	if m != nil {
+		return m.Output
+	}
+	return nil
+}
+
+func (x *ReplayTerminalResponse) GetData() []byte {
+	if x, ok := x.GetOutput().(*ReplayTerminalResponse_Data); ok {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ReplayTerminalResponse) GetSize() *TerminalSize {
+	if x, ok := x.GetOutput().(*ReplayTerminalResponse_Size); ok {
+		return x.Size
+	}
+	return nil
+}
+
+type isReplayTerminalResponse_Output interface {
+	isReplayTerminalResponse_Output()
+}
+
+type ReplayTerminalResponse_Data struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3,oneof"`
+}
+
+type ReplayTerminalResponse_Size struct {
+	Size *TerminalSize `protobuf:"bytes,2,opt,name=size,proto3,oneof"`
+}
+
+func (*ReplayTerminalResponse_Data) isReplayTerminalResponse_Output() {}
+
+func (*ReplayTerminalResponse_Size) isReplayTerminalResponse_Output() {}
+
+type JoinTerminalRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	// name identifies the participant, e.g. in presence updates. Must be unique per terminal.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// token is the starter_token or the token of a read_write participant who invites the new participant.
+	Token string `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *JoinTerminalRequest) Reset() {
+	*x = JoinTerminalRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JoinTerminalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinTerminalRequest) ProtoMessage() {}
+
+func (x *JoinTerminalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinTerminalRequest.ProtoReflect.Descriptor instead.
+func (*JoinTerminalRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *JoinTerminalRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *JoinTerminalRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *JoinTerminalRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type JoinTerminalResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// token authenticates the participant in Listen, Write and SetSize
+	Token string       `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Role  TerminalRole `protobuf:"varint,2,opt,name=role,proto3,enum=supervisor.TerminalRole" json:"role,omitempty"`
+}
+
+func (x *JoinTerminalResponse) Reset() {
+	*x = JoinTerminalResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JoinTerminalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinTerminalResponse) ProtoMessage() {}
+
+func (x *JoinTerminalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinTerminalResponse.ProtoReflect.Descriptor instead.
+func (*JoinTerminalResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *JoinTerminalResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *JoinTerminalResponse) GetRole() TerminalRole {
+	if x != nil {
+		return x.Role
+	}
+	return TerminalRole_read_only
+}
+
+type SetTerminalParticipantRoleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Alias string `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	// starter_token is the starter_token that Open() returned
+	StarterToken string       `protobuf:"bytes,2,opt,name=starter_token,json=starterToken,proto3" json:"starter_token,omitempty"`
+	Name         string       `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Role         TerminalRole `protobuf:"varint,4,opt,name=role,proto3,enum=supervisor.TerminalRole" json:"role,omitempty"`
+}
+
+func (x *SetTerminalParticipantRoleRequest) Reset() {
+	*x = SetTerminalParticipantRoleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetTerminalParticipantRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTerminalParticipantRoleRequest) ProtoMessage() {}
+
+func (x *SetTerminalParticipantRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTerminalParticipantRoleRequest.ProtoReflect.Descriptor instead.
+func (*SetTerminalParticipantRoleRequest) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SetTerminalParticipantRoleRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *SetTerminalParticipantRoleRequest) GetStarterToken() string {
+	if x != nil {
+		return x.StarterToken
+	}
+	return ""
+}
+
+func (x *SetTerminalParticipantRoleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SetTerminalParticipantRoleRequest) GetRole() TerminalRole {
+	if x != nil {
+		return x.Role
+	}
+	return TerminalRole_read_only
+}
+
+type SetTerminalParticipantRoleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SetTerminalParticipantRoleResponse) Reset() {
+	*x = SetTerminalParticipantRoleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_terminal_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetTerminalParticipantRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTerminalParticipantRoleResponse) ProtoMessage() {}
+
+func (x *SetTerminalParticipantRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_terminal_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTerminalParticipantRoleResponse.ProtoReflect.Descriptor instead.
+func (*SetTerminalParticipantRoleResponse) Descriptor() ([]byte, []int) {
+	return file_terminal_proto_rawDescGZIP(), []int{26}
+}
+
+var File_terminal_proto protoreflect.FileDescriptor
+
+var file_terminal_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0a, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x1a, 0x1c, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6c, 0x0a, 0x0c, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f,
+	0x77, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f,
+	0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x69, 0x64, 0x74, 0x68, 0x50, 0x78, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x07, 0x77, 0x69, 0x64, 0x74, 0x68, 0x50, 0x78, 0x12, 0x1a, 0x0a, 0x08,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x50, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x50, 0x78, 0x22, 0xca, 0x03, 0x0a, 0x13, 0x4f, 0x70, 0x65,
 	0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x18, 0x0a, 0x07, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x07, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x12, 0x3a, 0x0a, 0x03, 0x65, 0x6e,
@@ -1227,7 +1868,10 @@ var file_terminal_proto_rawDesc = []byte{
 	0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x73, 0x68, 0x65, 0x6c, 0x6c, 0x41, 0x72, 0x67, 0x73, 0x12,
 	0x2c, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
 	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69,
-	0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x1a, 0x36, 0x0a,
+	0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x1a, 0x36, 0x0a,
 	0x08, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
 	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
@@ -1242,167 +1886,257 @@ var file_terminal_proto_rawDesc = []byte{
 	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x08, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x12,
 	0x23, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
 	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x72, 0x54,
-	0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x2f, 0x0a, 0x17, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e,
+	0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x45, 0x0a, 0x17, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e,
 	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
 	0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0x1a, 0x0a, 0x18, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77,
-	0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x81, 0x03, 0x0a, 0x08, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x12, 0x14,
-	0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61,
-	0x6c, 0x69, 0x61, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x14,
-	0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
-	0x69, 0x74, 0x6c, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61,
-	0x6c, 0x5f, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0e, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x57, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x12,
-	0x27, 0x0a, 0x0f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x77, 0x6f, 0x72, 0x6b, 0x64,
-	0x69, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
-	0x74, 0x57, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x12, 0x47, 0x0a, 0x0b, 0x61, 0x6e, 0x6e, 0x6f,
-	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e,
+	0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x1a, 0x0a, 0x18, 0x53,
+	0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xfa, 0x03, 0x0a, 0x08, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x27, 0x0a, 0x0f,
+	0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x57, 0x6f,
+	0x72, 0x6b, 0x64, 0x69, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x5f, 0x77, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x64, 0x69, 0x72, 0x12, 0x47,
+	0x0a, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
+	0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x42, 0x0a, 0x0c, 0x74, 0x69, 0x74, 0x6c, 0x65,
+	0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e,
 	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69,
-	0x6e, 0x61, 0x6c, 0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45,
-	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x12, 0x42, 0x0a, 0x0c, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
-	0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74,
-	0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x0b, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x53,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x1a, 0x3e, 0x0a, 0x10, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x2a, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d,
-	0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61,
-	0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61,
-	0x73, 0x22, 0x16, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
-	0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4b, 0x0a, 0x15, 0x4c, 0x69, 0x73,
-	0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
-	0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x09, 0x74, 0x65, 0x72,
-	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x22, 0x2d, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e,
+	0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x0b,
+	0x74, 0x69, 0x74, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x12,
+	0x43, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x18,
+	0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
+	0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70,
+	0x61, 0x6e, 0x74, 0x73, 0x1a, 0x3e, 0x0a, 0x10, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x73, 0x0a, 0x13, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
+	0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x2c, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e,
+	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x6c, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x65, 0x64, 0x22, 0x2a, 0x0a, 0x12, 0x47, 0x65, 0x74,
 	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
 	0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0xb3, 0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e,
-	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x14, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63,
-	0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x08, 0x65, 0x78, 0x69,
-	0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x42, 0x0a,
-	0x0c, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
-	0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x53, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x52, 0x0b, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x42, 0x08, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x42, 0x0a, 0x14, 0x57,
-	0x72, 0x69, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0x16, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4b, 0x0a,
+	0x15, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52,
+	0x09, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x22, 0x43, 0x0a, 0x15, 0x4c, 0x69,
+	0x73, 0x74, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x64,
-	0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x22,
-	0x3c, 0x0a, 0x15, 0x57, 0x72, 0x69, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x79, 0x74, 0x65,
-	0x73, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0c, 0x62, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x22, 0x98, 0x01,
-	0x0a, 0x16, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61,
-	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x16,
-	0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
-	0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x2c,
-	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73,
-	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x0a, 0x0a, 0x08,
-	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x19, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x54,
-	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x45, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14,
-	0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61,
-	0x6c, 0x69, 0x61, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x1a, 0x0a, 0x18, 0x53, 0x65,
-	0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xe3, 0x01, 0x0a, 0x20, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22,
+	0xef, 0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x1d, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12,
+	0x16, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x73, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x50,
+	0x72, 0x65, 0x73, 0x65, 0x6e, 0x63, 0x65, 0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x65, 0x73, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x42, 0x0a, 0x0c, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x5f, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54,
+	0x69, 0x74, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x0b, 0x74, 0x69, 0x74, 0x6c,
+	0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x22, 0x57, 0x0a, 0x10, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x72, 0x65,
+	0x73, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x43, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69,
+	0x70, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x75,
+	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x0c, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x22, 0x58, 0x0a, 0x14, 0x57, 0x72,
+	0x69, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x64, 0x69,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x3c, 0x0a, 0x15, 0x57, 0x72, 0x69, 0x74, 0x65, 0x54, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a,
+	0x0d, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x62, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74,
+	0x65, 0x6e, 0x22, 0x98, 0x01, 0x0a, 0x16, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c,
+	0x69, 0x61, 0x73, 0x12, 0x16, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x05, 0x66,
+	0x6f, 0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x05, 0x66, 0x6f,
+	0x72, 0x63, 0x65, 0x12, 0x2c, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x42, 0x0a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x22, 0x19, 0x0a,
+	0x17, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x5b, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x54,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74,
+	0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x1a, 0x0a, 0x18, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0xf9, 0x01, 0x0a, 0x20, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x53, 0x0a, 0x07,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x39, 0x2e,
+	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
 	0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x1a, 0x3a, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x23, 0x0a,
+	0x21, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x41,
+	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x5e, 0x0a, 0x15, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61,
 	0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61,
-	0x73, 0x12, 0x53, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x39, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e,
-	0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x63,
-	0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x64, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
-	0x1a, 0x3a, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
-	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x23, 0x0a, 0x21,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e,
-	0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x2a, 0x2b, 0x0a, 0x13, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74,
-	0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63,
-	0x65, 0x73, 0x73, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x61, 0x70, 0x69, 0x10, 0x01, 0x32, 0xb0,
-	0x07, 0x0a, 0x0f, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x12, 0x4b, 0x0a, 0x04, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x1f, 0x2e, 0x73, 0x75, 0x70,
-	0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d,
-	0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x75,
-	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x54, 0x65, 0x72,
-	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
-	0x7c, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x23, 0x2e, 0x73, 0x75,
-	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77,
-	0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x24, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x68,
-	0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1f, 0x12, 0x1d,
-	0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x68, 0x75,
-	0x74, 0x64, 0x6f, 0x77, 0x6e, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x7d, 0x12, 0x5d, 0x0a,
-	0x03, 0x47, 0x65, 0x74, 0x12, 0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x1a, 0x12, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
-	0x2f, 0x67, 0x65, 0x74, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x7d, 0x12, 0x66, 0x0a, 0x04,
-	0x4c, 0x69, 0x73, 0x74, 0x12, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
-	0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02,
-	0x13, 0x12, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f,
-	0x6c, 0x69, 0x73, 0x74, 0x12, 0x76, 0x0a, 0x06, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x12, 0x21,
-	0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x69,
+	0x64, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x49, 0x64,
+	0x6c, 0x65, 0x22, 0x68, 0x0a, 0x16, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x12, 0x2e, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x48, 0x00, 0x52, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x55, 0x0a, 0x13,
+	0x4a, 0x6f, 0x69, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x22, 0x5a, 0x0a, 0x14, 0x4a, 0x6f, 0x69, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x18, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x22,
+	0xa0, 0x01, 0x0a, 0x21, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x65, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2c, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x18, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
+	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f,
+	0x6c, 0x65, 0x22, 0x24, 0x0a, 0x22, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x6c, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a, 0x2b, 0x0a, 0x13, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12,
+	0x0b, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03,
+	0x61, 0x70, 0x69, 0x10, 0x01, 0x2a, 0x2d, 0x0a, 0x0c, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e,
+	0x6c, 0x79, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x77, 0x72, 0x69,
+	0x74, 0x65, 0x10, 0x01, 0x32, 0xec, 0x09, 0x0a, 0x0f, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4b, 0x0a, 0x04, 0x4f, 0x70, 0x65, 0x6e,
+	0x12, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f, 0x70,
 	0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x22, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c,
-	0x69, 0x73, 0x74, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x12, 0x1b, 0x2f,
-	0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x6c, 0x69, 0x73, 0x74,
-	0x65, 0x6e, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x7d, 0x30, 0x01, 0x12, 0x70, 0x0a, 0x05,
-	0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
-	0x6f, 0x72, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
-	0x69, 0x73, 0x6f, 0x72, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x1c, 0x22, 0x1a, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
-	0x2f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x7d, 0x12, 0x54,
-	0x0a, 0x07, 0x53, 0x65, 0x74, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x22, 0x2e, 0x73, 0x75, 0x70, 0x65,
-	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
-	0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e,
-	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x65,
-	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x54, 0x69, 0x74, 0x6c, 0x65,
-	0x12, 0x23, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65,
-	0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
-	0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69,
-	0x74, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x72, 0x0a,
-	0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x12, 0x2c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e,
-	0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x2d, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e, 0x6e, 0x6f,
-	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x00, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x67, 0x69, 0x74, 0x70, 0x6f, 0x64, 0x2d, 0x69, 0x6f, 0x2f, 0x67, 0x69, 0x74, 0x70, 0x6f, 0x64,
-	0x2f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x1a, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4f,
+	0x70, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x7c, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77,
+	0x6e, 0x12, 0x23, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53,
+	0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x1f, 0x12, 0x1d, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x2f, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x2f, 0x7b, 0x61, 0x6c, 0x69,
+	0x61, 0x73, 0x7d, 0x12, 0x5d, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x1e, 0x2e, 0x73, 0x75, 0x70,
+	0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x73, 0x75, 0x70,
+	0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
+	0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x12, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x65, 0x74, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61,
+	0x73, 0x7d, 0x12, 0x66, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x20, 0x2e, 0x73, 0x75, 0x70,
+	0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73,
+	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x13, 0x12, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x76, 0x0a, 0x06, 0x4c, 0x69,
+	0x73, 0x74, 0x65, 0x6e, 0x12, 0x21, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
+	0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
+	0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x1d, 0x12, 0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x2f, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x7d,
+	0x30, 0x01, 0x12, 0x70, 0x0a, 0x05, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x20, 0x2e, 0x73, 0x75,
+	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e,
+	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65,
+	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x22, 0x1a, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x2f, 0x7b, 0x61, 0x6c,
+	0x69, 0x61, 0x73, 0x7d, 0x12, 0x54, 0x0a, 0x07, 0x53, 0x65, 0x74, 0x53, 0x69, 0x7a, 0x65, 0x12,
+	0x22, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74,
+	0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
+	0x2e, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x08, 0x53, 0x65,
+	0x74, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x23, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x54,
+	0x69, 0x74, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73, 0x75,
+	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x72, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x6e,
+	0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x6c, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x76, 0x0a, 0x06, 0x52, 0x65, 0x70, 0x6c, 0x61,
+	0x79, 0x12, 0x21, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x52,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
+	0x72, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d,
+	0x12, 0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x2f, 0x72,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x2f, 0x7b, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x7d, 0x30, 0x01, 0x12,
+	0x4b, 0x0a, 0x04, 0x4a, 0x6f, 0x69, 0x6e, 0x12, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
+	0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4a, 0x6f, 0x69, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x4a, 0x6f, 0x69, 0x6e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x75, 0x0a, 0x12,
+	0x53, 0x65, 0x74, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x6f,
+	0x6c, 0x65, 0x12, 0x2d, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
+	0x53, 0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x2e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53,
+	0x65, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63,
+	0x69, 0x70, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x67, 0x69, 0x74, 0x70, 0x6f, 0x64, 0x2d, 0x69, 0x6f, 0x2f, 0x67, 0x69, 0x74, 0x70,
+	0x6f, 0x64, 0x2f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2f, 0x61, 0x70,
+	0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1417,68 +2151,90 @@ func file_terminal_proto_rawDescGZIP() []byte {
 	return file_terminal_proto_rawDescData
 }
 
-var file_terminal_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_terminal_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_terminal_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_terminal_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_terminal_proto_goTypes = []interface{}{
-	(TerminalTitleSource)(0),                  // 0: supervisor.TerminalTitleSource
-	(*TerminalSize)(nil),                      // 1: supervisor.TerminalSize
-	(*OpenTerminalRequest)(nil),               // 2: supervisor.OpenTerminalRequest
-	(*OpenTerminalResponse)(nil),              // 3: supervisor.OpenTerminalResponse
-	(*ShutdownTerminalRequest)(nil),           // 4: supervisor.ShutdownTerminalRequest
-	(*ShutdownTerminalResponse)(nil),          // 5: supervisor.ShutdownTerminalResponse
-	(*Terminal)(nil),                          // 6: supervisor.Terminal
-	(*GetTerminalRequest)(nil),                // 7: supervisor.GetTerminalRequest
-	(*ListTerminalsRequest)(nil),              // 8: supervisor.ListTerminalsRequest
-	(*ListTerminalsResponse)(nil),             // 9: supervisor.ListTerminalsResponse
-	(*ListenTerminalRequest)(nil),             // 10: supervisor.ListenTerminalRequest
-	(*ListenTerminalResponse)(nil),            // 11: supervisor.ListenTerminalResponse
-	(*WriteTerminalRequest)(nil),              // 12: supervisor.WriteTerminalRequest
-	(*WriteTerminalResponse)(nil),             // 13: supervisor.WriteTerminalResponse
-	(*SetTerminalSizeRequest)(nil),            // 14: supervisor.SetTerminalSizeRequest
-	(*SetTerminalSizeResponse)(nil),           // 15: supervisor.SetTerminalSizeResponse
-	(*SetTerminalTitleRequest)(nil),           // 16: supervisor.SetTerminalTitleRequest
-	(*SetTerminalTitleResponse)(nil),          // 17: supervisor.SetTerminalTitleResponse
-	(*UpdateTerminalAnnotationsRequest)(nil),  // 18: supervisor.UpdateTerminalAnnotationsRequest
-	(*UpdateTerminalAnnotationsResponse)(nil), // 19: supervisor.UpdateTerminalAnnotationsResponse
-	nil, // 20: supervisor.OpenTerminalRequest.EnvEntry
-	nil, // 21: supervisor.OpenTerminalRequest.AnnotationsEntry
-	nil, // 22: supervisor.Terminal.AnnotationsEntry
-	nil, // 23: supervisor.UpdateTerminalAnnotationsRequest.ChangedEntry
+	(TerminalTitleSource)(0),                   // 0: supervisor.TerminalTitleSource
+	(TerminalRole)(0),                          // 1: supervisor.TerminalRole
+	(*TerminalSize)(nil),                       // 2: supervisor.TerminalSize
+	(*OpenTerminalRequest)(nil),                // 3: supervisor.OpenTerminalRequest
+	(*OpenTerminalResponse)(nil),               // 4: supervisor.OpenTerminalResponse
+	(*ShutdownTerminalRequest)(nil),            // 5: supervisor.ShutdownTerminalRequest
+	(*ShutdownTerminalResponse)(nil),           // 6: supervisor.ShutdownTerminalResponse
+	(*Terminal)(nil),                           // 7: supervisor.Terminal
+	(*TerminalParticipant)(nil),                // 8: supervisor.TerminalParticipant
+	(*GetTerminalRequest)(nil),                 // 9: supervisor.GetTerminalRequest
+	(*ListTerminalsRequest)(nil),               // 10: supervisor.ListTerminalsRequest
+	(*ListTerminalsResponse)(nil),              // 11: supervisor.ListTerminalsResponse
+	(*ListenTerminalRequest)(nil),              // 12: supervisor.ListenTerminalRequest
+	(*ListenTerminalResponse)(nil),             // 13: supervisor.ListenTerminalResponse
+	(*TerminalPresence)(nil),                   // 14: supervisor.TerminalPresence
+	(*WriteTerminalRequest)(nil),               // 15: supervisor.WriteTerminalRequest
+	(*WriteTerminalResponse)(nil),              // 16: supervisor.WriteTerminalResponse
+	(*SetTerminalSizeRequest)(nil),             // 17: supervisor.SetTerminalSizeRequest
+	(*SetTerminalSizeResponse)(nil),            // 18: supervisor.SetTerminalSizeResponse
+	(*SetTerminalTitleRequest)(nil),            // 19: supervisor.SetTerminalTitleRequest
+	(*SetTerminalTitleResponse)(nil),           // 20: supervisor.SetTerminalTitleResponse
+	(*UpdateTerminalAnnotationsRequest)(nil),   // 21: supervisor.UpdateTerminalAnnotationsRequest
+	(*UpdateTerminalAnnotationsResponse)(nil),  // 22: supervisor.UpdateTerminalAnnotationsResponse
+	(*ReplayTerminalRequest)(nil),              // 23: supervisor.ReplayTerminalRequest
+	(*ReplayTerminalResponse)(nil),             // 24: supervisor.ReplayTerminalResponse
+	(*JoinTerminalRequest)(nil),                // 25: supervisor.JoinTerminalRequest
+	(*JoinTerminalResponse)(nil),               // 26: supervisor.JoinTerminalResponse
+	(*SetTerminalParticipantRoleRequest)(nil),  // 27: supervisor.SetTerminalParticipantRoleRequest
+	(*SetTerminalParticipantRoleResponse)(nil), // 28: supervisor.SetTerminalParticipantRoleResponse
+	nil, // 29: supervisor.OpenTerminalRequest.EnvEntry
+	nil, // 30: supervisor.OpenTerminalRequest.AnnotationsEntry
+	nil, // 31: supervisor.Terminal.AnnotationsEntry
+	nil, // 32: supervisor.UpdateTerminalAnnotationsRequest.ChangedEntry
 }
 var file_terminal_proto_depIdxs = []int32{
-	20, // 0: supervisor.OpenTerminalRequest.env:type_name -> supervisor.OpenTerminalRequest.EnvEntry
-	21, // 1: supervisor.OpenTerminalRequest.annotations:type_name -> supervisor.OpenTerminalRequest.AnnotationsEntry
-	1,  // 2: supervisor.OpenTerminalRequest.size:type_name -> supervisor.TerminalSize
-	6,  // 3: supervisor.OpenTerminalResponse.terminal:type_name -> supervisor.Terminal
-	22, // 4: supervisor.Terminal.annotations:type_name -> supervisor.Terminal.AnnotationsEntry
+	29, // 0: supervisor.OpenTerminalRequest.env:type_name -> supervisor.OpenTerminalRequest.EnvEntry
+	30, // 1: supervisor.OpenTerminalRequest.annotations:type_name -> supervisor.OpenTerminalRequest.AnnotationsEntry
+	2,  // 2: supervisor.OpenTerminalRequest.size:type_name -> supervisor.TerminalSize
+	7,  // 3: supervisor.OpenTerminalResponse.terminal:type_name -> supervisor.Terminal
+	31, // 4: supervisor.Terminal.annotations:type_name -> supervisor.Terminal.AnnotationsEntry
 	0,  // 5: supervisor.Terminal.title_source:type_name -> supervisor.TerminalTitleSource
-	6,  // 6: supervisor.ListTerminalsResponse.terminals:type_name -> supervisor.Terminal
-	0,  // 7: supervisor.ListenTerminalResponse.title_source:type_name -> supervisor.TerminalTitleSource
-	1,  // 8: supervisor.SetTerminalSizeRequest.size:type_name -> supervisor.TerminalSize
-	23, // 9: supervisor.UpdateTerminalAnnotationsRequest.changed:type_name -> supervisor.UpdateTerminalAnnotationsRequest.ChangedEntry
-	2,  // 10: supervisor.TerminalService.Open:input_type -> supervisor.OpenTerminalRequest
-	4,  // 11: supervisor.TerminalService.Shutdown:input_type -> supervisor.ShutdownTerminalRequest
-	7,  // 12: supervisor.TerminalService.Get:input_type -> supervisor.GetTerminalRequest
-	8,  // 13: supervisor.TerminalService.List:input_type -> supervisor.ListTerminalsRequest
-	10, // 14: supervisor.TerminalService.Listen:input_type -> supervisor.ListenTerminalRequest
-	12, // 15: supervisor.TerminalService.Write:input_type -> supervisor.WriteTerminalRequest
-	14, // 16: supervisor.TerminalService.SetSize:input_type -> supervisor.SetTerminalSizeRequest
-	16, // 17: supervisor.TerminalService.SetTitle:input_type -> supervisor.SetTerminalTitleRequest
-	18, // 18: supervisor.TerminalService.UpdateAnnotations:input_type -> supervisor.UpdateTerminalAnnotationsRequest
-	3,  // 19: supervisor.TerminalService.Open:output_type -> supervisor.OpenTerminalResponse
-	5,  // 20: supervisor.TerminalService.Shutdown:output_type -> supervisor.ShutdownTerminalResponse
-	6,  // 21: supervisor.TerminalService.Get:output_type -> supervisor.Terminal
-	9,  // 22: supervisor.TerminalService.List:output_type -> supervisor.ListTerminalsResponse
-	11, // 23: supervisor.TerminalService.Listen:output_type -> supervisor.ListenTerminalResponse
-	13, // 24: supervisor.TerminalService.Write:output_type -> supervisor.WriteTerminalResponse
-	15, // 25: supervisor.TerminalService.SetSize:output_type -> supervisor.SetTerminalSizeResponse
-	17, // 26: supervisor.TerminalService.SetTitle:output_type -> supervisor.SetTerminalTitleResponse
-	19, // 27: supervisor.TerminalService.UpdateAnnotations:output_type -> supervisor.UpdateTerminalAnnotationsResponse
-	19, // [19:28] is the sub-list for method output_type
-	10, // [10:19] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	8,  // 6: supervisor.Terminal.participants:type_name -> supervisor.TerminalParticipant
+	1,  // 7: supervisor.TerminalParticipant.role:type_name -> supervisor.TerminalRole
+	7,  // 8: supervisor.ListTerminalsResponse.terminals:type_name -> supervisor.Terminal
+	14, // 9: supervisor.ListenTerminalResponse.presence:type_name -> supervisor.TerminalPresence
+	0,  // 10: supervisor.ListenTerminalResponse.title_source:type_name -> supervisor.TerminalTitleSource
+	8,  // 11: supervisor.TerminalPresence.participants:type_name -> supervisor.TerminalParticipant
+	2,  // 12: supervisor.SetTerminalSizeRequest.size:type_name -> supervisor.TerminalSize
+	32, // 13: supervisor.UpdateTerminalAnnotationsRequest.changed:type_name -> supervisor.UpdateTerminalAnnotationsRequest.ChangedEntry
+	2,  // 14: supervisor.ReplayTerminalResponse.size:type_name -> supervisor.TerminalSize
+	1,  // 15: supervisor.JoinTerminalResponse.role:type_name -> supervisor.TerminalRole
+	1,  // 16: supervisor.SetTerminalParticipantRoleRequest.role:type_name -> supervisor.TerminalRole
+	3,  // 17: supervisor.TerminalService.Open:input_type -> supervisor.OpenTerminalRequest
+	5,  // 18: supervisor.TerminalService.Shutdown:input_type -> supervisor.ShutdownTerminalRequest
+	9,  // 19: supervisor.TerminalService.Get:input_type -> supervisor.GetTerminalRequest
+	10, // 20: supervisor.TerminalService.List:input_type -> supervisor.ListTerminalsRequest
+	12, // 21: supervisor.TerminalService.Listen:input_type -> supervisor.ListenTerminalRequest
+	15, // 22: supervisor.TerminalService.Write:input_type -> supervisor.WriteTerminalRequest
+	17, // 23: supervisor.TerminalService.SetSize:input_type -> supervisor.SetTerminalSizeRequest
+	19, // 24: supervisor.TerminalService.SetTitle:input_type -> supervisor.SetTerminalTitleRequest
+	21, // 25: supervisor.TerminalService.UpdateAnnotations:input_type -> supervisor.UpdateTerminalAnnotationsRequest
+	23, // 26: supervisor.TerminalService.Replay:input_type -> supervisor.ReplayTerminalRequest
+	25, // 27: supervisor.TerminalService.Join:input_type -> supervisor.JoinTerminalRequest
+	27, // 28: supervisor.TerminalService.SetParticipantRole:input_type -> supervisor.SetTerminalParticipantRoleRequest
+	4,  // 29: supervisor.TerminalService.Open:output_type -> supervisor.OpenTerminalResponse
+	6,  // 30: supervisor.TerminalService.Shutdown:output_type -> supervisor.ShutdownTerminalResponse
+	7,  // 31: supervisor.TerminalService.Get:output_type -> supervisor.Terminal
+	11, // 32: supervisor.TerminalService.List:output_type -> supervisor.ListTerminalsResponse
+	13, // 33: supervisor.TerminalService.Listen:output_type -> supervisor.ListenTerminalResponse
+	16, // 34: supervisor.TerminalService.Write:output_type -> supervisor.WriteTerminalResponse
+	18, // 35: supervisor.TerminalService.SetSize:output_type -> supervisor.SetTerminalSizeResponse
+	20, // 36: supervisor.TerminalService.SetTitle:output_type -> supervisor.SetTerminalTitleResponse
+	22, // 37: supervisor.TerminalService.UpdateAnnotations:output_type -> supervisor.UpdateTerminalAnnotationsResponse
+	24, // 38: supervisor.TerminalService.Replay:output_type -> supervisor.ReplayTerminalResponse
+	26, // 39: supervisor.TerminalService.Join:output_type -> supervisor.JoinTerminalResponse
+	28, // 40: supervisor.TerminalService.SetParticipantRole:output_type -> supervisor.SetTerminalParticipantRoleResponse
+	29, // [29:41] is the sub-list for method output_type
+	17, // [17:29] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_terminal_proto_init() }
@@ -1560,7 +2316,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetTerminalRequest); i {
+			switch v := v.(*TerminalParticipant); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1572,7 +2328,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListTerminalsRequest); i {
+			switch v := v.(*GetTerminalRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1584,7 +2340,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListTerminalsResponse); i {
+			switch v := v.(*ListTerminalsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1596,7 +2352,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListenTerminalRequest); i {
+			switch v := v.(*ListTerminalsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1608,7 +2364,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ListenTerminalResponse); i {
+			switch v := v.(*ListenTerminalRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1620,7 +2376,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteTerminalRequest); i {
+			switch v := v.(*ListenTerminalResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1632,7 +2388,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteTerminalResponse); i {
+			switch v := v.(*TerminalPresence); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1644,7 +2400,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetTerminalSizeRequest); i {
+			switch v := v.(*WriteTerminalRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1656,7 +2412,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetTerminalSizeResponse); i {
+			switch v := v.(*WriteTerminalResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1668,7 +2424,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetTerminalTitleRequest); i {
+			switch v := v.(*SetTerminalSizeRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1680,7 +2436,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetTerminalTitleResponse); i {
+			switch v := v.(*SetTerminalSizeResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1692,7 +2448,7 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateTerminalAnnotationsRequest); i {
+			switch v := v.(*SetTerminalTitleRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1704,6 +2460,30 @@ func file_terminal_proto_init() {
 			}
 		}
 		file_terminal_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetTerminalTitleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateTerminalAnnotationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*UpdateTerminalAnnotationsResponse); i {
 			case 0:
 				return &v.state
@@ -1715,23 +2495,100 @@ func file_terminal_proto_init() {
 				return nil
 			}
 		}
+		file_terminal_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayTerminalRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayTerminalResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JoinTerminalRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JoinTerminalResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetTerminalParticipantRoleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_terminal_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetTerminalParticipantRoleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_terminal_proto_msgTypes[10].OneofWrappers = []interface{}{
+	file_terminal_proto_msgTypes[11].OneofWrappers = []interface{}{
 		(*ListenTerminalResponse_Data)(nil),
 		(*ListenTerminalResponse_ExitCode)(nil),
 		(*ListenTerminalResponse_Title)(nil),
+		(*ListenTerminalResponse_Presence)(nil),
 	}
-	file_terminal_proto_msgTypes[13].OneofWrappers = []interface{}{
+	file_terminal_proto_msgTypes[15].OneofWrappers = []interface{}{
 		(*SetTerminalSizeRequest_Token)(nil),
 		(*SetTerminalSizeRequest_Force)(nil),
 	}
+	file_terminal_proto_msgTypes[22].OneofWrappers = []interface{}{
+		(*ReplayTerminalResponse_Data)(nil),
+		(*ReplayTerminalResponse_Size)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_terminal_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   23,
+			NumEnums:      2,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -41,6 +41,14 @@ type TerminalServiceClient interface {
 	SetTitle(ctx context.Context, in *SetTerminalTitleRequest, opts ...grpc.CallOption) (*SetTerminalTitleResponse, error)
 	// UpdateAnnotations updates the terminal's annotations
 	UpdateAnnotations(ctx context.Context, in *UpdateTerminalAnnotationsRequest, opts ...grpc.CallOption) (*UpdateTerminalAnnotationsResponse, error)
+	// Replay replays the recording of a terminal session with its original timing.
+	// The terminal itself need not be open anymore.
+	Replay(ctx context.Context, in *ReplayTerminalRequest, opts ...grpc.CallOption) (TerminalService_ReplayClient, error)
+	// Join adds a participant to a shared terminal. New participants are read-only.
+	Join(ctx context.Context, in *JoinTerminalRequest, opts ...grpc.CallOption) (*JoinTerminalResponse, error)
+	// SetParticipantRole changes the role of a participant of a shared terminal.
+	// Only the starter of the terminal can change roles.
+	SetParticipantRole(ctx context.Context, in *SetTerminalParticipantRoleRequest, opts ...grpc.CallOption) (*SetTerminalParticipantRoleResponse, error)
 }
 
 type terminalServiceClient struct {
@@ -155,6 +163,56 @@ func (c *terminalServiceClient) UpdateAnnotations(ctx context.Context, in *Updat
 	return out, nil
 }
 
+func (c *terminalServiceClient) Replay(ctx context.Context, in *ReplayTerminalRequest, opts ...grpc.CallOption) (TerminalService_ReplayClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TerminalService_ServiceDesc.Streams[1], "/supervisor.TerminalService/Replay", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &terminalServiceReplayClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TerminalService_ReplayClient interface {
+	Recv() (*ReplayTerminalResponse, error)
+	grpc.ClientStream
+}
+
+type terminalServiceReplayClient struct {
+	grpc.ClientStream
+}
+
+func (x *terminalServiceReplayClient) Recv() (*ReplayTerminalResponse, error) {
+	m := new(ReplayTerminalResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *terminalServiceClient) Join(ctx context.Context, in *JoinTerminalRequest, opts ...grpc.CallOption) (*JoinTerminalResponse, error) {
+	out := new(JoinTerminalResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.TerminalService/Join", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *terminalServiceClient) SetParticipantRole(ctx context.Context, in *SetTerminalParticipantRoleRequest, opts ...grpc.CallOption) (*SetTerminalParticipantRoleResponse, error) {
+	out := new(SetTerminalParticipantRoleResponse)
+	err := c.cc.Invoke(ctx, "/supervisor.TerminalService/SetParticipantRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TerminalServiceServer is the server API for TerminalService service.
 // All implementations must embed UnimplementedTerminalServiceServer
 // for forward compatibility
@@ -178,6 +236,14 @@ type TerminalServiceServer interface {
 	SetTitle(context.Context, *SetTerminalTitleRequest) (*SetTerminalTitleResponse, error)
 	// UpdateAnnotations updates the terminal's annotations
 	UpdateAnnotations(context.Context, *UpdateTerminalAnnotationsRequest) (*UpdateTerminalAnnotationsResponse, error)
+	// Replay replays the recording of a terminal session with its original timing.
+	// The terminal itself need not be open anymore.
+	Replay(*ReplayTerminalRequest, TerminalService_ReplayServer) error
+	// Join adds a participant to a shared terminal. New participants are read-only.
+	Join(context.Context, *JoinTerminalRequest) (*JoinTerminalResponse, error)
+	// SetParticipantRole changes the role of a participant of a shared terminal.
+	// Only the starter of the terminal can change roles.
+	SetParticipantRole(context.Context, *SetTerminalParticipantRoleRequest) (*SetTerminalParticipantRoleResponse, error)
 	mustEmbedUnimplementedTerminalServiceServer()
 }
 
@@ -212,6 +278,15 @@ func (UnimplementedTerminalServiceServer) SetTitle(context.Context, *SetTerminal
 func (UnimplementedTerminalServiceServer) UpdateAnnotations(context.Context, *UpdateTerminalAnnotationsRequest) (*UpdateTerminalAnnotationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateAnnotations not implemented")
 }
+func (UnimplementedTerminalServiceServer) Replay(*ReplayTerminalRequest, TerminalService_ReplayServer) error {
+	return status.Errorf(codes.Unimplemented, "method Replay not implemented")
+}
+func (UnimplementedTerminalServiceServer) Join(context.Context, *JoinTerminalRequest) (*JoinTerminalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Join not implemented")
+}
+func (UnimplementedTerminalServiceServer) SetParticipantRole(context.Context, *SetTerminalParticipantRoleRequest) (*SetTerminalParticipantRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetParticipantRole not implemented")
+}
 func (UnimplementedTerminalServiceServer) mustEmbedUnimplementedTerminalServiceServer() {}
 
 // UnsafeTerminalServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -390,6 +465,63 @@ func _TerminalService_UpdateAnnotations_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TerminalService_Replay_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplayTerminalRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TerminalServiceServer).Replay(m, &terminalServiceReplayServer{stream})
+}
+
+type TerminalService_ReplayServer interface {
+	Send(*ReplayTerminalResponse) error
+	grpc.ServerStream
+}
+
+type terminalServiceReplayServer struct {
+	grpc.ServerStream
+}
+
+func (x *terminalServiceReplayServer) Send(m *ReplayTerminalResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TerminalService_Join_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinTerminalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TerminalServiceServer).Join(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.TerminalService/Join",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TerminalServiceServer).Join(ctx, req.(*JoinTerminalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TerminalService_SetParticipantRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTerminalParticipantRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TerminalServiceServer).SetParticipantRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/supervisor.TerminalService/SetParticipantRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TerminalServiceServer).SetParticipantRole(ctx, req.(*SetTerminalParticipantRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TerminalService_ServiceDesc is the grpc.ServiceDesc for TerminalService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -429,6 +561,14 @@ var TerminalService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateAnnotations",
 			Handler:    _TerminalService_UpdateAnnotations_Handler,
 		},
+		{
+			MethodName: "Join",
+			Handler:    _TerminalService_Join_Handler,
+		},
+		{
+			MethodName: "SetParticipantRole",
+			Handler:    _TerminalService_SetParticipantRole_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -436,6 +576,11 @@ var TerminalService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _TerminalService_Listen_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Replay",
+			Handler:       _TerminalService_Replay_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "terminal.proto",
 }
@@ -81,6 +81,66 @@ func (TokenReuse) EnumDescriptor() ([]byte, []int) {
 	return file_token_proto_rawDescGZIP(), []int{0}
 }
 
+type TokenEventsResponse_Type int32
+
+const (
+	// CACHED means a token was added to the cache.
+	TokenEventsResponse_CACHED TokenEventsResponse_Type = 0
+	// REFRESHED means a token was replaced by a fresh one before it expired.
+	TokenEventsResponse_REFRESHED TokenEventsResponse_Type = 1
+	// REFRESH_FAILED means a token is about to expire, but none of its providers could refresh it.
+	TokenEventsResponse_REFRESH_FAILED TokenEventsResponse_Type = 2
+	// EXPIRED means an expired token was removed from the cache.
+	TokenEventsResponse_EXPIRED TokenEventsResponse_Type = 3
+	// CLEARED means a token was removed from the cache on request.
+	TokenEventsResponse_CLEARED TokenEventsResponse_Type = 4
+)
+
+// Enum value maps for TokenEventsResponse_Type.
+var (
+	TokenEventsResponse_Type_name = map[int32]string{
+		0: "CACHED",
+		1: "REFRESHED",
+		2: "REFRESH_FAILED",
+		3: "EXPIRED",
+		4: "CLEARED",
+	}
+	TokenEventsResponse_Type_value = map[string]int32{
+		"CACHED":         0,
+		"REFRESHED":      1,
+		"REFRESH_FAILED": 2,
+		"EXPIRED":        3,
+		"CLEARED":        4,
+	}
+)
+
+func (x TokenEventsResponse_Type) Enum() *TokenEventsResponse_Type {
+	p := new(TokenEventsResponse_Type)
+	*p = x
+	return p
+}
+
+func (x TokenEventsResponse_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TokenEventsResponse_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_token_proto_enumTypes[1].Descriptor()
+}
+
+func (TokenEventsResponse_Type) Type() protoreflect.EnumType {
+	return &file_token_proto_enumTypes[1]
+}
+
+func (x TokenEventsResponse_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TokenEventsResponse_Type.Descriptor instead.
+func (TokenEventsResponse_Type) EnumDescriptor() ([]byte, []int) {
+	return file_token_proto_rawDescGZIP(), []int{9, 0}
+}
+
 type GetTokenRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -594,6 +654,142 @@ func (x *ProvideTokenResponse) GetRequest() *GetTokenRequest {
 	return nil
 }
 
+type TokenEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// kind limits the events to tokens of this kind. Events of all kinds are sent if empty.
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+}
+
+func (x *TokenEventsRequest) Reset() {
+	*x = TokenEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_token_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenEventsRequest) ProtoMessage() {}
+
+func (x *TokenEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_token_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenEventsRequest.ProtoReflect.Descriptor instead.
+func (*TokenEventsRequest) Descriptor() ([]byte, []int) {
+	return file_token_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TokenEventsRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type TokenEventsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type       TokenEventsResponse_Type `protobuf:"varint,1,opt,name=type,proto3,enum=supervisor.TokenEventsResponse_Type" json:"type,omitempty"`
+	Kind       string                   `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Host       string                   `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Scope      []string                 `protobuf:"bytes,4,rep,name=scope,proto3" json:"scope,omitempty"`
+	ExpiryDate *timestamppb.Timestamp   `protobuf:"bytes,5,opt,name=expiry_date,json=expiryDate,proto3" json:"expiry_date,omitempty"`
+	// error explains why a token could not be refreshed
+	Error string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *TokenEventsResponse) Reset() {
+	*x = TokenEventsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_token_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenEventsResponse) ProtoMessage() {}
+
+func (x *TokenEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_token_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenEventsResponse.ProtoReflect.Descriptor instead.
+func (*TokenEventsResponse) Descriptor() ([]byte, []int) {
+	return file_token_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TokenEventsResponse) GetType() TokenEventsResponse_Type {
+	if x != nil {
+		return x.Type
+	}
+	return TokenEventsResponse_CACHED
+}
+
+func (x *TokenEventsResponse) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *TokenEventsResponse) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *TokenEventsResponse) GetScope() []string {
+	if x != nil {
+		return x.Scope
+	}
+	return nil
+}
+
+func (x *TokenEventsResponse) GetExpiryDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiryDate
+	}
+	return nil
+}
+
+func (x *TokenEventsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type ProvideTokenRequest_RegisterProvider struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -605,7 +801,7 @@ type ProvideTokenRequest_RegisterProvider struct {
 func (x *ProvideTokenRequest_RegisterProvider) Reset() {
 	*x = ProvideTokenRequest_RegisterProvider{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_token_proto_msgTypes[8]
+		mi := &file_token_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -618,7 +814,7 @@ func (x *ProvideTokenRequest_RegisterProvider) String() string {
 func (*ProvideTokenRequest_RegisterProvider) ProtoMessage() {}
 
 func (x *ProvideTokenRequest_RegisterProvider) ProtoReflect() protoreflect.Message {
-	mi := &file_token_proto_msgTypes[8]
+	mi := &file_token_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -702,45 +898,73 @@ var file_token_proto_rawDesc = []byte{
 	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
 	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f,
 	0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x2a, 0x49, 0x0a, 0x0a, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x75, 0x73,
-	0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x45, 0x55, 0x53, 0x45, 0x5f, 0x4e, 0x45, 0x56, 0x45, 0x52,
-	0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x52, 0x45, 0x55, 0x53, 0x45, 0x5f, 0x45, 0x58, 0x41, 0x43,
-	0x54, 0x4c, 0x59, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x55, 0x53, 0x45, 0x5f, 0x57,
-	0x48, 0x45, 0x4e, 0x5f, 0x50, 0x4f, 0x53, 0x53, 0x49, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x32, 0xdb,
-	0x03, 0x0a, 0x0c, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x6e, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x2e, 0x73, 0x75,
-	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
-	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x27, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x21, 0x12, 0x1f,
-	0x2f, 0x76, 0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e, 0x64, 0x7d,
-	0x2f, 0x7b, 0x68, 0x6f, 0x73, 0x74, 0x7d, 0x2f, 0x7b, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x7d, 0x12,
-	0x69, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x2e, 0x73, 0x75,
-	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
-	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x22, 0x17,
+	0x65, 0x73, 0x74, 0x22, 0x28, 0x0a, 0x12, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x22, 0xb1, 0x02,
+	0x0a, 0x13, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x24, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
+	0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x3b, 0x0a,
+	0x0b, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x44, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x22, 0x4f, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x41, 0x43, 0x48,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x46, 0x52, 0x45, 0x53, 0x48, 0x45,
+	0x44, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x45, 0x46, 0x52, 0x45, 0x53, 0x48, 0x5f, 0x46,
+	0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x45, 0x58, 0x50, 0x49, 0x52,
+	0x45, 0x44, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4c, 0x45, 0x41, 0x52, 0x45, 0x44, 0x10,
+	0x04, 0x2a, 0x49, 0x0a, 0x0a, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x75, 0x73, 0x65, 0x12,
+	0x0f, 0x0a, 0x0b, 0x52, 0x45, 0x55, 0x53, 0x45, 0x5f, 0x4e, 0x45, 0x56, 0x45, 0x52, 0x10, 0x00,
+	0x12, 0x11, 0x0a, 0x0d, 0x52, 0x45, 0x55, 0x53, 0x45, 0x5f, 0x45, 0x58, 0x41, 0x43, 0x54, 0x4c,
+	0x59, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x55, 0x53, 0x45, 0x5f, 0x57, 0x48, 0x45,
+	0x4e, 0x5f, 0x50, 0x4f, 0x53, 0x53, 0x49, 0x42, 0x4c, 0x45, 0x10, 0x02, 0x32, 0xc7, 0x04, 0x0a,
+	0x0c, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6e, 0x0a,
+	0x08, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x27, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x21, 0x12, 0x1f, 0x2f, 0x76,
+	0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e, 0x64, 0x7d, 0x2f, 0x7b,
+	0x68, 0x6f, 0x73, 0x74, 0x7d, 0x2f, 0x7b, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x7d, 0x12, 0x69, 0x0a,
+	0x08, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x22, 0x17, 0x2f, 0x76,
+	0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e, 0x64, 0x7d, 0x2f, 0x7b,
+	0x68, 0x6f, 0x73, 0x74, 0x7d, 0x3a, 0x01, 0x2a, 0x12, 0x96, 0x01, 0x0a, 0x0a, 0x43, 0x6c, 0x65,
+	0x61, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1d, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
+	0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69,
+	0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x49, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x43, 0x2a, 0x18,
 	0x2f, 0x76, 0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e, 0x64, 0x7d,
-	0x2f, 0x7b, 0x68, 0x6f, 0x73, 0x74, 0x7d, 0x3a, 0x01, 0x2a, 0x12, 0x96, 0x01, 0x0a, 0x0a, 0x43,
-	0x6c, 0x65, 0x61, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1d, 0x2e, 0x73, 0x75, 0x70, 0x65,
-	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x54, 0x6f, 0x6b, 0x65,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
-	0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x49, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x43,
-	0x2a, 0x18, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e,
-	0x64, 0x7d, 0x2f, 0x7b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x7d, 0x5a, 0x27, 0x2a, 0x25, 0x2f, 0x76,
-	0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e, 0x64, 0x7d, 0x2f, 0x63,
-	0x6c, 0x65, 0x61, 0x72, 0x2f, 0x61, 0x6c, 0x6c, 0x2f, 0x7b, 0x61, 0x6c, 0x6c, 0x3d, 0x74, 0x72,
-	0x75, 0x65, 0x7d, 0x12, 0x57, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x54, 0x6f,
-	0x6b, 0x65, 0x6e, 0x12, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
-	0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2c, 0x5a, 0x2a,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x69, 0x74, 0x70, 0x6f,
-	0x64, 0x2d, 0x69, 0x6f, 0x2f, 0x67, 0x69, 0x74, 0x70, 0x6f, 0x64, 0x2f, 0x73, 0x75, 0x70, 0x65,
-	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x2f, 0x7b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x7d, 0x5a, 0x27, 0x2a, 0x25, 0x2f, 0x76, 0x31, 0x2f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x7b, 0x6b, 0x69, 0x6e, 0x64, 0x7d, 0x2f, 0x63, 0x6c, 0x65,
+	0x61, 0x72, 0x2f, 0x61, 0x6c, 0x6c, 0x2f, 0x7b, 0x61, 0x6c, 0x6c, 0x3d, 0x74, 0x72, 0x75, 0x65,
+	0x7d, 0x12, 0x57, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x50,
+	0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e,
+	0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x6a, 0x0a, 0x0b, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x18, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x12, 0x12, 0x10, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2f, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x30, 0x01, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x69, 0x74, 0x70, 0x6f, 0x64, 0x2d, 0x69, 0x6f, 0x2f, 0x67,
+	0x69, 0x74, 0x70, 0x6f, 0x64, 0x2f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72,
+	0x2f, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -755,40 +979,47 @@ func file_token_proto_rawDescGZIP() []byte {
 	return file_token_proto_rawDescData
 }
 
-var file_token_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_token_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_token_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_token_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_token_proto_goTypes = []interface{}{
 	(TokenReuse)(0),                              // 0: supervisor.TokenReuse
-	(*GetTokenRequest)(nil),                      // 1: supervisor.GetTokenRequest
-	(*GetTokenResponse)(nil),                     // 2: supervisor.GetTokenResponse
-	(*SetTokenRequest)(nil),                      // 3: supervisor.SetTokenRequest
-	(*SetTokenResponse)(nil),                     // 4: supervisor.SetTokenResponse
-	(*ClearTokenRequest)(nil),                    // 5: supervisor.ClearTokenRequest
-	(*ClearTokenResponse)(nil),                   // 6: supervisor.ClearTokenResponse
-	(*ProvideTokenRequest)(nil),                  // 7: supervisor.ProvideTokenRequest
-	(*ProvideTokenResponse)(nil),                 // 8: supervisor.ProvideTokenResponse
-	(*ProvideTokenRequest_RegisterProvider)(nil), // 9: supervisor.ProvideTokenRequest.RegisterProvider
-	(*timestamppb.Timestamp)(nil),                // 10: google.protobuf.Timestamp
+	(TokenEventsResponse_Type)(0),                // 1: supervisor.TokenEventsResponse.Type
+	(*GetTokenRequest)(nil),                      // 2: supervisor.GetTokenRequest
+	(*GetTokenResponse)(nil),                     // 3: supervisor.GetTokenResponse
+	(*SetTokenRequest)(nil),                      // 4: supervisor.SetTokenRequest
+	(*SetTokenResponse)(nil),                     // 5: supervisor.SetTokenResponse
+	(*ClearTokenRequest)(nil),                    // 6: supervisor.ClearTokenRequest
+	(*ClearTokenResponse)(nil),                   // 7: supervisor.ClearTokenResponse
+	(*ProvideTokenRequest)(nil),                  // 8: supervisor.ProvideTokenRequest
+	(*ProvideTokenResponse)(nil),                 // 9: supervisor.ProvideTokenResponse
+	(*TokenEventsRequest)(nil),                   // 10: supervisor.TokenEventsRequest
+	(*TokenEventsResponse)(nil),                  // 11: supervisor.TokenEventsResponse
+	(*ProvideTokenRequest_RegisterProvider)(nil), // 12: supervisor.ProvideTokenRequest.RegisterProvider
+	(*timestamppb.Timestamp)(nil),                // 13: google.protobuf.Timestamp
 }
 var file_token_proto_depIdxs = []int32{
-	10, // 0: supervisor.SetTokenRequest.expiry_date:type_name -> google.protobuf.Timestamp
+	13, // 0: supervisor.SetTokenRequest.expiry_date:type_name -> google.protobuf.Timestamp
 	0,  // 1: supervisor.SetTokenRequest.reuse:type_name -> supervisor.TokenReuse
-	9,  // 2: supervisor.ProvideTokenRequest.registration:type_name -> supervisor.ProvideTokenRequest.RegisterProvider
-	3,  // 3: supervisor.ProvideTokenRequest.answer:type_name -> supervisor.SetTokenRequest
-	1,  // 4: supervisor.ProvideTokenResponse.request:type_name -> supervisor.GetTokenRequest
-	1,  // 5: supervisor.TokenService.GetToken:input_type -> supervisor.GetTokenRequest
-	3,  // 6: supervisor.TokenService.SetToken:input_type -> supervisor.SetTokenRequest
-	5,  // 7: supervisor.TokenService.ClearToken:input_type -> supervisor.ClearTokenRequest
-	7,  // 8: supervisor.TokenService.ProvideToken:input_type -> supervisor.ProvideTokenRequest
-	2,  // 9: supervisor.TokenService.GetToken:output_type -> supervisor.GetTokenResponse
-	4,  // 10: supervisor.TokenService.SetToken:output_type -> supervisor.SetTokenResponse
-	6,  // 11: supervisor.TokenService.ClearToken:output_type -> supervisor.ClearTokenResponse
-	8,  // 12: supervisor.TokenService.ProvideToken:output_type -> supervisor.ProvideTokenResponse
-	9,  // [9:13] is the sub-list for method output_type
-	5,  // [5:9] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	12, // 2: supervisor.ProvideTokenRequest.registration:type_name -> supervisor.ProvideTokenRequest.RegisterProvider
+	4,  // 3: supervisor.ProvideTokenRequest.answer:type_name -> supervisor.SetTokenRequest
+	2,  // 4: supervisor.ProvideTokenResponse.request:type_name -> supervisor.GetTokenRequest
+	1,  // 5: supervisor.TokenEventsResponse.type:type_name -> supervisor.TokenEventsResponse.Type
+	13, // 6: supervisor.TokenEventsResponse.expiry_date:type_name -> google.protobuf.Timestamp
+	2,  // 7: supervisor.TokenService.GetToken:input_type -> supervisor.GetTokenRequest
+	4,  // 8: supervisor.TokenService.SetToken:input_type -> supervisor.SetTokenRequest
+	6,  // 9: supervisor.TokenService.ClearToken:input_type -> supervisor.ClearTokenRequest
+	8,  // 10: supervisor.TokenService.ProvideToken:input_type -> supervisor.ProvideTokenRequest
+	10, // 11: supervisor.TokenService.TokenEvents:input_type -> supervisor.TokenEventsRequest
+	3,  // 12: supervisor.TokenService.GetToken:output_type -> supervisor.GetTokenResponse
+	5,  // 13: supervisor.TokenService.SetToken:output_type -> supervisor.SetTokenResponse
+	7,  // 14: supervisor.TokenService.ClearToken:output_type -> supervisor.ClearTokenResponse
+	9,  // 15: supervisor.TokenService.ProvideToken:output_type -> supervisor.ProvideTokenResponse
+	11, // 16: supervisor.TokenService.TokenEvents:output_type -> supervisor.TokenEventsResponse
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_token_proto_init() }
@@ -894,6 +1125,30 @@ func file_token_proto_init() {
 			}
 		}
 		file_token_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_token_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenEventsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_token_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ProvideTokenRequest_RegisterProvider); i {
 			case 0:
 				return &v.state
@@ -919,8 +1174,8 @@ func file_token_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_token_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   9,
+			NumEnums:      2,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
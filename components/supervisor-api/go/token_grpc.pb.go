@@ -26,6 +26,8 @@ type TokenServiceClient interface {
 	SetToken(ctx context.Context, in *SetTokenRequest, opts ...grpc.CallOption) (*SetTokenResponse, error)
 	ClearToken(ctx context.Context, in *ClearTokenRequest, opts ...grpc.CallOption) (*ClearTokenResponse, error)
 	ProvideToken(ctx context.Context, opts ...grpc.CallOption) (TokenService_ProvideTokenClient, error)
+	// TokenEvents streams changes of the cached tokens, e.g. when they are refreshed or expire.
+	TokenEvents(ctx context.Context, in *TokenEventsRequest, opts ...grpc.CallOption) (TokenService_TokenEventsClient, error)
 }
 
 type tokenServiceClient struct {
@@ -94,6 +96,38 @@ func (x *tokenServiceProvideTokenClient) Recv() (*ProvideTokenResponse, error) {
 	return m, nil
 }
 
+func (c *tokenServiceClient) TokenEvents(ctx context.Context, in *TokenEventsRequest, opts ...grpc.CallOption) (TokenService_TokenEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TokenService_ServiceDesc.Streams[1], "/supervisor.TokenService/TokenEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tokenServiceTokenEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TokenService_TokenEventsClient interface {
+	Recv() (*TokenEventsResponse, error)
+	grpc.ClientStream
+}
+
+type tokenServiceTokenEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tokenServiceTokenEventsClient) Recv() (*TokenEventsResponse, error) {
+	m := new(TokenEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TokenServiceServer is the server API for TokenService service.
 // All implementations must embed UnimplementedTokenServiceServer
 // for forward compatibility
@@ -102,6 +136,8 @@ type TokenServiceServer interface {
 	SetToken(context.Context, *SetTokenRequest) (*SetTokenResponse, error)
 	ClearToken(context.Context, *ClearTokenRequest) (*ClearTokenResponse, error)
 	ProvideToken(TokenService_ProvideTokenServer) error
+	// TokenEvents streams changes of the cached tokens, e.g. when they are refreshed or expire.
+	TokenEvents(*TokenEventsRequest, TokenService_TokenEventsServer) error
 	mustEmbedUnimplementedTokenServiceServer()
 }
 
@@ -121,6 +157,9 @@ func (UnimplementedTokenServiceServer) ClearToken(context.Context, *ClearTokenRe
 func (UnimplementedTokenServiceServer) ProvideToken(TokenService_ProvideTokenServer) error {
 	return status.Errorf(codes.Unimplemented, "method ProvideToken not implemented")
 }
+func (UnimplementedTokenServiceServer) TokenEvents(*TokenEventsRequest, TokenService_TokenEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TokenEvents not implemented")
+}
 func (UnimplementedTokenServiceServer) mustEmbedUnimplementedTokenServiceServer() {}
 
 // UnsafeTokenServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -214,6 +253,27 @@ func (x *tokenServiceProvideTokenServer) Recv() (*ProvideTokenRequest, error) {
 	return m, nil
 }
 
+func _TokenService_TokenEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TokenEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TokenServiceServer).TokenEvents(m, &tokenServiceTokenEventsServer{stream})
+}
+
+type TokenService_TokenEventsServer interface {
+	Send(*TokenEventsResponse) error
+	grpc.ServerStream
+}
+
+type tokenServiceTokenEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tokenServiceTokenEventsServer) Send(m *TokenEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // TokenService_ServiceDesc is the grpc.ServiceDesc for TokenService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -241,6 +301,11 @@ var TokenService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "TokenEvents",
+			Handler:       _TokenService_TokenEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "token.proto",
 }
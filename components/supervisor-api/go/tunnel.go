@@ -0,0 +1,46 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package api
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MaxTunnelDatagramSize is the largest datagram a UDP tunnel can carry
+const MaxTunnelDatagramSize = 0xFFFF
+
+// WriteTunnelDatagram writes a single datagram to a tunnel established with TransportProtocol_transport_udp.
+// UDP tunnels carry a stream of datagrams, each prefixed with its length as big-endian uint16.
+func WriteTunnelDatagram(w io.Writer, datagram []byte) error {
+	if len(datagram) > MaxTunnelDatagramSize {
+		return fmt.Errorf("datagram too large: %d bytes", len(datagram))
+	}
+	frame := make([]byte, 2+len(datagram))
+	binary.BigEndian.PutUint16(frame, uint16(len(datagram)))
+	copy(frame[2:], datagram)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadTunnelDatagram reads a single datagram written by WriteTunnelDatagram into buf,
+// which should be at least MaxTunnelDatagramSize bytes long.
+func ReadTunnelDatagram(r io.Reader, buf []byte) (n int, err error) {
+	var hdr [2]byte
+	_, err = io.ReadFull(r, hdr[:])
+	if err != nil {
+		return 0, err
+	}
+	size := int(binary.BigEndian.Uint16(hdr[:]))
+	if size > len(buf) {
+		return 0, fmt.Errorf("datagram too large: %d bytes", size)
+	}
+	_, err = io.ReadFull(r, buf[:size])
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return size, err
+}
@@ -31,14 +31,16 @@ var notifyCmd = &cobra.Command{
 		var (
 			message     = args[0]
 			actions, _  = cmd.Flags().GetStringArray("actions")
+			timeout, _  = cmd.Flags().GetUint32("timeout")
 			ctx, cancel = context.WithTimeout(context.Background(), 1*time.Minute)
 		)
 		defer cancel()
 
 		response, err := client.Notify(ctx, &api.NotifyRequest{
-			Level:   level,
-			Message: message,
-			Actions: actions,
+			Level:          level,
+			Message:        message,
+			Actions:        actions,
+			TimeoutSeconds: timeout,
 		})
 		if err != nil {
 			log.WithError(err).Fatal("cannot notify client")
@@ -56,4 +58,5 @@ func init() {
 	}
 	notifyCmd.Flags().String("level", "info", "notification severity - must be one of "+strings.Join(levels, ", "))
 	notifyCmd.Flags().StringArray("actions", nil, "actions to offer to the user")
+	notifyCmd.Flags().Uint32("timeout", 0, "seconds to wait for the user to answer - 0 waits until the user answers")
 }
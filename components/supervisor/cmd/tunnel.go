@@ -41,9 +41,10 @@ var tunnelCmd = &cobra.Command{
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 		_, err = client.Tunnel(ctx, &api.TunnelPortRequest{
-			Port:       uint32(localPort),
-			TargetPort: uint32(targetPort),
-			Visibility: visiblity,
+			Port:              uint32(localPort),
+			TargetPort:        uint32(targetPort),
+			Visibility:        visiblity,
+			TransportProtocol: tunnelTransport(cmd),
 		})
 		if err != nil {
 			log.WithError(err).Fatal("cannot tunnel")
@@ -67,7 +68,8 @@ var closeTunnelCmd = &cobra.Command{
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 		_, err = client.CloseTunnel(ctx, &api.CloseTunnelRequest{
-			Port: uint32(localPort),
+			Port:              uint32(localPort),
+			TransportProtocol: tunnelTransport(cmd),
 		})
 		if err != nil {
 			log.WithError(err).Fatal("cannot close the tunnel")
@@ -99,7 +101,17 @@ var autoTunnelCmd = &cobra.Command{
 	},
 }
 
+func tunnelTransport(cmd *cobra.Command) api.TransportProtocol {
+	udp, _ := cmd.Flags().GetBool("udp")
+	if udp {
+		return api.TransportProtocol_transport_udp
+	}
+	return api.TransportProtocol_transport_tcp
+}
+
 func init() {
+	tunnelCmd.Flags().Bool("udp", false, "tunnel a UDP port instead of a TCP port")
+	closeTunnelCmd.Flags().Bool("udp", false, "close the tunnel of a UDP port instead of a TCP port")
 	rootCmd.AddCommand(tunnelCmd)
 	tunnelCmd.AddCommand(closeTunnelCmd)
 	tunnelCmd.AddCommand(autoTunnelCmd)
@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package ports
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Connection is an established TCP connection to or from the workspace
+type Connection struct {
+	LocalPort  uint32
+	RemotePort uint32
+	// FromLocalhost is true if the remote end of the connection is within the workspace
+	FromLocalhost bool
+}
+
+// EstablishedConnections lists the established TCP connections of the workspace
+func EstablishedConnections() ([]Connection, error) {
+	var res []Connection
+	for _, fn := range []string{fnNetTCP, fnNetTCP6} {
+		f, err := os.Open(fn)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		conns, err := readEstablishedConnections(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, conns...)
+	}
+	return res, nil
+}
+
+func readEstablishedConnections(fc io.Reader) ([]Connection, error) {
+	var res []Connection
+	scanner := bufio.NewScanner(fc)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// "01" is TCP_ESTABLISHED
+		if len(fields) < 4 || fields[3] != "01" {
+			continue
+		}
+		_, localPort, ok := parseNetAddress(fields[1])
+		if !ok {
+			continue
+		}
+		remoteAddr, remotePort, ok := parseNetAddress(fields[2])
+		if !ok {
+			continue
+		}
+		res = append(res, Connection{
+			LocalPort:     localPort,
+			RemotePort:    remotePort,
+			FromLocalhost: isLoopbackNetAddress(remoteAddr),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseNetAddress(field string) (addr string, port uint32, ok bool) {
+	segs := strings.Split(field, ":")
+	if len(segs) != 2 {
+		return "", 0, false
+	}
+	p, err := strconv.ParseUint(segs[1], 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+	return segs[0], uint32(p), true
+}
+
+// isLoopbackNetAddress checks if a /proc/net address is a loopback address. The kernel prints
+// addresses as 32 bit words in host byte order, hence 127.0.0.1 reads 0100007F.
+func isLoopbackNetAddress(addr string) bool {
+	switch len(addr) {
+	case 8:
+		return strings.HasSuffix(addr, "7F")
+	case 32:
+		if addr == "00000000000000000000000001000000" {
+			return true
+		}
+		// IPv4-mapped IPv6 address
+		return strings.HasPrefix(addr, "0000000000000000FFFF0000") && strings.HasSuffix(addr, "7F")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package ports
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadEstablishedConnections(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Input       string
+		Expectation []Connection
+	}{
+		{
+			Name:  "tcp",
+			Input: validTCPInput,
+			Expectation: []Connection{
+				{LocalPort: 60260, RemotePort: 22999, FromLocalhost: true},
+				{LocalPort: 23000, RemotePort: 49148},
+			},
+		},
+		{
+			Name:  "tcp6",
+			Input: validTCP6Input,
+			Expectation: []Connection{
+				{LocalPort: 22999, RemotePort: 56318},
+				{LocalPort: 22999, RemotePort: 60260, FromLocalhost: true},
+			},
+		},
+		{
+			Name: "empty",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act, err := readEstablishedConnections(strings.NewReader(test.Input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected connections (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
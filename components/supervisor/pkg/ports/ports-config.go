@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 
 	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
 )
@@ -27,6 +28,9 @@ type Configs struct {
 	workspaceConfigs     map[uint32]*gitpod.PortConfig
 	instancePortConfigs  map[uint32]*gitpod.PortConfig
 	instanceRangeConfigs []*RangeConfig
+	// UDP ports are configured separately, so that they aren't mistaken for TCP ports
+	instanceUDPPortConfigs  map[uint32]*gitpod.PortConfig
+	instanceUDPRangeConfigs []*RangeConfig
 }
 
 // ForEach iterates over all configured ports
@@ -47,6 +51,24 @@ func (configs *Configs) ForEach(callback func(port uint32, config *gitpod.PortCo
 	}
 }
 
+// UDP returns true if the port is configured with protocol UDP in .gitpod.yml.
+// Unlike TCP ports, only configured UDP ports are reported and auto-tunneled: every process which sends
+// datagrams has an unconnected UDP socket, e.g. for DNS lookups, hence most served UDP ports are no services.
+func (configs *Configs) UDP(port uint32) bool {
+	if configs == nil {
+		return false
+	}
+	if _, exists := configs.instanceUDPPortConfigs[port]; exists {
+		return true
+	}
+	for _, rangeConfig := range configs.instanceUDPRangeConfigs {
+		if rangeConfig.Start <= port && port <= rangeConfig.End {
+			return true
+		}
+	}
+	return false
+}
+
 // ConfigKind indicates a type of config
 type ConfigKind uint8
 
@@ -140,9 +162,11 @@ func (service *ConfigService) Observe(ctx context.Context) (<-chan *Configs, <-c
 					continue
 				}
 				updatesChan <- &Configs{
-					workspaceConfigs:     current.workspaceConfigs,
-					instancePortConfigs:  current.instancePortConfigs,
-					instanceRangeConfigs: current.instanceRangeConfigs,
+					workspaceConfigs:        current.workspaceConfigs,
+					instancePortConfigs:     current.instancePortConfigs,
+					instanceRangeConfigs:    current.instanceRangeConfigs,
+					instanceUDPPortConfigs:  current.instanceUDPPortConfigs,
+					instanceUDPRangeConfigs: current.instanceUDPRangeConfigs,
 				}
 			}
 		}
@@ -151,15 +175,18 @@ func (service *ConfigService) Observe(ctx context.Context) (<-chan *Configs, <-c
 }
 
 func (service *ConfigService) update(config *gitpod.GitpodConfig, current *Configs) bool {
-	currentPortConfigs, currentRangeConfigs := current.instancePortConfigs, current.instanceRangeConfigs
+	previous := *current
 	var ports []*gitpod.PortsItems
 	if config != nil {
 		ports = config.Ports
 	}
-	portConfigs, rangeConfigs := parseInstanceConfigs(ports)
-	current.instancePortConfigs = portConfigs
-	current.instanceRangeConfigs = rangeConfigs
-	return !reflect.DeepEqual(currentPortConfigs, portConfigs) || !reflect.DeepEqual(currentRangeConfigs, rangeConfigs)
+	tcpPorts, udpPorts := splitUDPConfigs(ports)
+	current.instancePortConfigs, current.instanceRangeConfigs = parseInstanceConfigs(tcpPorts)
+	current.instanceUDPPortConfigs, current.instanceUDPRangeConfigs = parseInstanceConfigs(udpPorts)
+	return !reflect.DeepEqual(previous.instancePortConfigs, current.instancePortConfigs) ||
+		!reflect.DeepEqual(previous.instanceRangeConfigs, current.instanceRangeConfigs) ||
+		!reflect.DeepEqual(previous.instanceUDPPortConfigs, current.instanceUDPPortConfigs) ||
+		!reflect.DeepEqual(previous.instanceUDPRangeConfigs, current.instanceUDPRangeConfigs)
 }
 
 var portRangeRegexp = regexp.MustCompile(`^(\d+)[-:](\d+)$`)
@@ -179,6 +206,18 @@ func parseWorkspaceConfigs(ports []*gitpod.PortConfig) (portConfigs map[uint32]*
 	return portConfigs
 }
 
+// splitUDPConfigs separates the configs of ports with protocol UDP from all others, which are TCP ports
+func splitUDPConfigs(ports []*gitpod.PortsItems) (tcp []*gitpod.PortsItems, udp []*gitpod.PortsItems) {
+	for _, config := range ports {
+		if config != nil && strings.EqualFold(config.Protocol, "udp") {
+			udp = append(udp, config)
+		} else {
+			tcp = append(tcp, config)
+		}
+	}
+	return tcp, udp
+}
+
 func parseInstanceConfigs(ports []*gitpod.PortsItems) (portConfigs map[uint32]*gitpod.PortConfig, rangeConfigs []*RangeConfig) {
 	for _, config := range ports {
 		if config == nil {
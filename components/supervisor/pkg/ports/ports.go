@@ -50,11 +50,15 @@ func NewManager(exposed ExposedPortsInterface, served ServedPortsObserver, confi
 		internal:     internal,
 		proxies:      make(map[uint32]*localhostProxy),
 		autoExposed:  make(map[uint32]*autoExposure),
-		autoTunneled: make(map[uint32]struct{}),
+		autoTunneled: make(map[tunnelKey]struct{}),
+		probes:       make(map[uint32]*portProbing),
 
 		state:         state,
+		udpState:      make(map[uint32]*managedPort),
 		subscriptions: make(map[*Subscription]struct{}),
 		proxyStarter:  startLocalhostProxy,
+		portProber:    probePort,
+		healthChecker: checkPortHealth,
 
 		autoTunnelEnabled: true,
 	}
@@ -65,6 +69,11 @@ type localhostProxy struct {
 	proxyPort uint32
 }
 
+type portProbing struct {
+	cancel context.CancelFunc
+	result portProbe
+}
+
 type autoExposure struct {
 	state      api.PortAutoExposure
 	ctx        context.Context
@@ -74,6 +83,10 @@ type autoExposure struct {
 
 // Manager brings together served and exposed ports. It keeps track of which port is exposed, which one is served,
 // auto-exposes ports and proxies ports served on localhost only.
+//
+// UDP ports are tracked separately from TCP ports. They are reported and can be tunneled, but are never
+// exposed or proxied, because exposed ports are served through HTTP. Served UDP ports are only reported and
+// auto-tunneled if they are configured, see Configs.UDP.
 type Manager struct {
 	E ExposedPortsInterface
 	S ServedPortsObserver
@@ -87,16 +100,22 @@ type Manager struct {
 	proxyStarter func(LocalhostPort uint32, GlobalPort uint32) (proxy io.Closer, err error)
 	autoExposed  map[uint32]*autoExposure
 
-	autoTunneled      map[uint32]struct{}
+	autoTunneled      map[tunnelKey]struct{}
 	autoTunnelEnabled bool
 
-	configs  *Configs
-	exposed  []ExposedPort
-	served   []ServedPort
-	tunneled []PortTunnelState
+	probes        map[uint32]*portProbing
+	portProber    portProber
+	healthChecker portHealthChecker
 
-	state map[uint32]*managedPort
-	mu    sync.RWMutex
+	configs   *Configs
+	exposed   []ExposedPort
+	served    []ServedPort
+	servedUDP []ServedPort
+	tunneled  []PortTunnelState
+
+	state    map[uint32]*managedPort
+	udpState map[uint32]*managedPort
+	mu       sync.RWMutex
 
 	subscriptions map[*Subscription]struct{}
 	closed        bool
@@ -117,6 +136,14 @@ type managedPort struct {
 	TunneledTargetPort uint32
 	TunneledVisibility api.TunnelVisiblity
 	TunneledClients    map[string]uint32
+
+	Protocol api.PortProtocol
+	Health   api.PortHealth
+
+	OwnerPID  int
+	OwnerName string
+
+	Transport api.TransportProtocol
 }
 
 // Subscription is a Subscription to status updates
@@ -245,35 +272,39 @@ func (pm *Manager) updateState(ctx context.Context, exposed []ExposedPort, serve
 	}
 
 	if served != nil {
-		var servedKeys []uint32 // to preserve insertion order
-		servedMap := make(map[uint32]ServedPort)
+		var servedTCP, servedUDP []ServedPort
 		for _, port := range served {
-			current, exists := servedMap[port.Port]
-			if !exists {
-				servedKeys = append(servedKeys, port.Port)
-			}
-			if !exists || (!port.BoundToLocalhost && current.BoundToLocalhost) {
-				servedMap[port.Port] = port
+			if port.Transport == api.TransportProtocol_transport_udp {
+				servedUDP = append(servedUDP, port)
+			} else {
+				servedTCP = append(servedTCP, port)
 			}
 		}
-		var newServed []ServedPort
-		for _, key := range servedKeys {
-			newServed = append(newServed, servedMap[key])
-		}
+		newServed := dedupServedPorts(servedTCP)
+		newServedUDP := dedupServedPorts(servedUDP)
 		if !reflect.DeepEqual(pm.served, newServed) {
 			pm.served = newServed
 			pm.updateProxies()
+			pm.updateProbes(ctx)
+			pm.autoTunnel(ctx)
+		}
+		if !reflect.DeepEqual(pm.servedUDP, newServedUDP) {
+			pm.servedUDP = newServedUDP
 			pm.autoTunnel(ctx)
 		}
 	}
 
 	if configured != nil {
 		pm.configs = configured
+		// whether served UDP ports are auto-tunneled depends on the config
+		pm.autoTunnel(ctx)
 	}
 
 	newState := pm.nextState(ctx)
-	stateChanged := !reflect.DeepEqual(newState, pm.state)
+	newUDPState := pm.nextUDPState()
+	stateChanged := !reflect.DeepEqual(newState, pm.state) || !reflect.DeepEqual(newUDPState, pm.udpState)
 	pm.state = newState
+	pm.udpState = newUDPState
 
 	if !stateChanged {
 		return
@@ -291,6 +322,26 @@ func (pm *Manager) updateState(ctx context.Context, exposed []ExposedPort, serve
 	}
 }
 
+// dedupServedPorts removes ports served more than once, e.g. for IPv4 and IPv6, preferring the globally bound ones
+func dedupServedPorts(served []ServedPort) []ServedPort {
+	var servedKeys []uint32 // to preserve insertion order
+	servedMap := make(map[uint32]ServedPort)
+	for _, port := range served {
+		current, exists := servedMap[port.Port]
+		if !exists {
+			servedKeys = append(servedKeys, port.Port)
+		}
+		if !exists || (!port.BoundToLocalhost && current.BoundToLocalhost) {
+			servedMap[port.Port] = port
+		}
+	}
+	var res []ServedPort
+	for _, key := range servedKeys {
+		res = append(res, servedMap[key])
+	}
+	return res
+}
+
 func (pm *Manager) nextState(ctx context.Context) map[uint32]*managedPort {
 	state := make(map[uint32]*managedPort)
 
@@ -312,13 +363,13 @@ func (pm *Manager) nextState(ctx context.Context) map[uint32]*managedPort {
 			Exposed:       true,
 			Visibility:    Visibility,
 			URL:           exposed.URL,
-			OnExposed:     getOnExposedAction(config, port),
+			OnExposed:     getOnExposedAction(config, port, pm.protocol(port)),
 		}
 	}
 
 	for _, tunneled := range pm.tunneled {
 		port := tunneled.Desc.LocalPort
-		if pm.boundInternally(port) {
+		if tunneled.Desc.Transport == api.TransportProtocol_transport_udp || pm.boundInternally(port) {
 			continue
 		}
 		mp, exists := state[port]
@@ -354,7 +405,7 @@ func (pm *Manager) nextState(ctx context.Context) map[uint32]*managedPort {
 			if mp.Exposed {
 				return
 			}
-			mp.OnExposed = getOnExposedAction(config, port)
+			mp.OnExposed = getOnExposedAction(config, port, pm.protocol(port))
 
 			if autoExposed {
 				return
@@ -386,6 +437,12 @@ func (pm *Manager) nextState(ctx context.Context) map[uint32]*managedPort {
 
 		mp.LocalhostPort = port
 		mp.Served = true
+		mp.OwnerPID = served.OwnerPID
+		mp.OwnerName = served.OwnerName
+		if probing, probed := pm.probes[port]; probed {
+			mp.Protocol = probing.result.Protocol
+			mp.Health = probing.result.Health
+		}
 
 		var exposedGlobalPort uint32
 		autoExposure, autoExposed := pm.autoExposed[port]
@@ -426,6 +483,44 @@ func (pm *Manager) nextState(ctx context.Context) map[uint32]*managedPort {
 	return state
 }
 
+// nextUDPState captures the served and tunneled UDP ports.
+// Internal ports are TCP ports, hence UDP ports with the same number are not considered internal.
+func (pm *Manager) nextUDPState() map[uint32]*managedPort {
+	state := make(map[uint32]*managedPort)
+	get := func(port uint32) *managedPort {
+		mp, exists := state[port]
+		if !exists {
+			mp = &managedPort{
+				LocalhostPort: port,
+				Transport:     api.TransportProtocol_transport_udp,
+			}
+			state[port] = mp
+		}
+		return mp
+	}
+
+	for _, tunneled := range pm.tunneled {
+		if tunneled.Desc.Transport != api.TransportProtocol_transport_udp {
+			continue
+		}
+		mp := get(tunneled.Desc.LocalPort)
+		mp.Tunneled = true
+		mp.TunneledTargetPort = tunneled.Desc.TargetPort
+		mp.TunneledVisibility = tunneled.Desc.Visibility
+		mp.TunneledClients = tunneled.Clients
+	}
+	for _, served := range pm.servedUDP {
+		if !pm.configs.UDP(served.Port) {
+			continue
+		}
+		mp := get(served.Port)
+		mp.Served = true
+		mp.OwnerPID = served.OwnerPID
+		mp.OwnerName = served.OwnerName
+	}
+	return state
+}
+
 // clients should guard a call with check whether such port is already exposed or auto exposed
 func (pm *Manager) autoExpose(ctx context.Context, localPort uint32, globalPort uint32, public bool) *autoExposure {
 	exposing := pm.E.Expose(ctx, localPort, globalPort, public)
@@ -471,41 +566,54 @@ func (pm *Manager) forceUpdate() {
 }
 
 func (pm *Manager) autoTunnel(ctx context.Context) {
+	transports := []api.TransportProtocol{api.TransportProtocol_transport_tcp, api.TransportProtocol_transport_udp}
 	if !pm.autoTunnelEnabled {
-		var localPorts []uint32
-		for localPort := range pm.autoTunneled {
-			localPorts = append(localPorts, localPort)
+		localPorts := make(map[api.TransportProtocol][]uint32)
+		for key := range pm.autoTunneled {
+			localPorts[key.Transport] = append(localPorts[key.Transport], key.LocalPort)
 		}
 		// CloseTunnel ensures that everything is closed
-		pm.autoTunneled = make(map[uint32]struct{})
-		_, err := pm.T.CloseTunnel(ctx, localPorts...)
-		if err != nil {
-			log.WithError(err).Error("cannot close auto tunneled ports")
+		pm.autoTunneled = make(map[tunnelKey]struct{})
+		for _, transport := range transports {
+			_, err := pm.T.CloseTunnel(ctx, transport, localPorts[transport]...)
+			if err != nil {
+				log.WithError(err).WithField("transport", transport).Error("cannot close auto tunneled ports")
+			}
 		}
 		return
 	}
-	var descs []*PortTunnelDescription
-	for _, served := range pm.served {
-		if pm.boundInternally(served.Port) {
-			continue
+	for _, transport := range transports {
+		served := pm.served
+		if transport == api.TransportProtocol_transport_udp {
+			served = pm.servedUDP
 		}
-		_, autoTunneled := pm.autoTunneled[served.Port]
-		if !autoTunneled {
-			descs = append(descs, &PortTunnelDescription{
-				LocalPort:  served.Port,
-				TargetPort: served.Port,
-				Visibility: api.TunnelVisiblity_host,
-			})
+		var descs []*PortTunnelDescription
+		for _, served := range served {
+			if transport == api.TransportProtocol_transport_tcp && pm.boundInternally(served.Port) {
+				continue
+			}
+			if transport == api.TransportProtocol_transport_udp && !pm.configs.UDP(served.Port) {
+				continue
+			}
+			_, autoTunneled := pm.autoTunneled[tunnelKey{LocalPort: served.Port, Transport: transport}]
+			if !autoTunneled {
+				descs = append(descs, &PortTunnelDescription{
+					LocalPort:  served.Port,
+					TargetPort: served.Port,
+					Visibility: api.TunnelVisiblity_host,
+					Transport:  transport,
+				})
+			}
+		}
+		autoTunneled, err := pm.T.Tunnel(ctx, &TunnelOptions{
+			SkipIfExists: true,
+		}, descs...)
+		if err != nil {
+			log.WithError(err).WithField("transport", transport).Error("cannot auto tunnel ports")
+		}
+		for _, localPort := range autoTunneled {
+			pm.autoTunneled[tunnelKey{LocalPort: localPort, Transport: transport}] = struct{}{}
 		}
-	}
-	autoTunneled, err := pm.T.Tunnel(ctx, &TunnelOptions{
-		SkipIfExists: true,
-	}, descs...)
-	if err != nil {
-		log.WithError(err).Error("cannot auto tunnel ports")
-	}
-	for _, localPort := range autoTunneled {
-		pm.autoTunneled[localPort] = struct{}{}
 	}
 }
 
@@ -575,7 +683,7 @@ func (pm *Manager) updateProxies() {
 	}
 }
 
-func getOnExposedAction(config *gitpod.PortConfig, port uint32) api.OnPortExposedAction {
+func getOnExposedAction(config *gitpod.PortConfig, port uint32, protocol api.PortProtocol) api.OnPortExposedAction {
 	if config == nil {
 		// anything above 32767 seems odd (e.g. used by language servers)
 		unusualRange := !(0 < port && port < 32767)
@@ -583,6 +691,10 @@ func getOnExposedAction(config *gitpod.PortConfig, port uint32) api.OnPortExpose
 		if unusualRange || !wellKnown {
 			return api.OnPortExposedAction_ignore
 		}
+		// there's nothing to open in a browser for ports which don't speak HTTP
+		if protocol == api.PortProtocol_tcp || protocol == api.PortProtocol_grpc {
+			return api.OnPortExposedAction_ignore
+		}
 		return api.OnPortExposedAction_notify_private
 	}
 	if config.OnOpen == "ignore" {
@@ -597,6 +709,46 @@ func getOnExposedAction(config *gitpod.PortConfig, port uint32) api.OnPortExpose
 	return api.OnPortExposedAction_notify
 }
 
+// updateProbes starts probing newly served ports and stops probing ports which are no longer served.
+// Callers are expected to hold mu.
+func (pm *Manager) updateProbes(ctx context.Context) {
+	served := make(map[uint32]struct{}, len(pm.served))
+	for _, p := range pm.served {
+		if pm.boundInternally(p.Port) {
+			continue
+		}
+		served[p.Port] = struct{}{}
+	}
+
+	for port, probing := range pm.probes {
+		if _, ok := served[port]; ok {
+			continue
+		}
+		probing.cancel()
+		delete(pm.probes, port)
+	}
+
+	for port := range served {
+		if _, ok := pm.probes[port]; ok {
+			continue
+		}
+		probeCtx, cancel := context.WithCancel(ctx)
+		probing := &portProbing{cancel: cancel}
+		pm.probes[port] = probing
+		go pm.runProbe(probeCtx, port, probing)
+	}
+}
+
+// protocol returns the protocol a port was detected to speak.
+// Callers are expected to hold mu.
+func (pm *Manager) protocol(port uint32) api.PortProtocol {
+	probing, ok := pm.probes[port]
+	if !ok {
+		return api.PortProtocol_protocol_unknown
+	}
+	return probing.result.Protocol
+}
+
 func (pm *Manager) boundInternally(port uint32) bool {
 	_, exists := pm.internal[port]
 	return exists
@@ -645,24 +797,46 @@ func (pm *Manager) Expose(ctx context.Context, port uint32, targetPort uint32) e
 	return err
 }
 
+// SetVisibility changes the visibility of an exposed port
+func (pm *Manager) SetVisibility(ctx context.Context, port uint32, visibility api.PortVisibility) error {
+	pm.mu.RLock()
+	mp, ok := pm.state[port]
+	if !ok || !mp.Exposed {
+		pm.mu.RUnlock()
+		return xerrors.Errorf("port %d is not exposed", port)
+	}
+	global, current := mp.GlobalPort, mp.Visibility
+	pm.mu.RUnlock()
+
+	if current == visibility {
+		return nil
+	}
+
+	err := <-pm.E.Expose(ctx, port, global, visibility == api.PortVisibility_public)
+	if err != nil && err != context.Canceled {
+		log.WithError(err).WithField("port", port).WithField("visibility", visibility.String()).Error("cannot change port visibility")
+	}
+	return err
+}
+
 // Tunnel opens a new tunnel.
 func (pm *Manager) Tunnel(ctx context.Context, desc *PortTunnelDescription) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
-	if pm.boundInternally(desc.LocalPort) {
+	if desc.Transport == api.TransportProtocol_transport_tcp && pm.boundInternally(desc.LocalPort) {
 		return xerrors.New("cannot tunnel internal port")
 	}
 	tunneled, err := pm.T.Tunnel(ctx, &TunnelOptions{
 		SkipIfExists: false,
 	}, desc)
 	for _, localPort := range tunneled {
-		delete(pm.autoTunneled, localPort)
+		delete(pm.autoTunneled, tunnelKey{LocalPort: localPort, Transport: desc.Transport})
 	}
 	return err
 }
 
 // CloseTunnel closes the tunnel.
-func (pm *Manager) CloseTunnel(ctx context.Context, port uint32) error {
+func (pm *Manager) CloseTunnel(ctx context.Context, port uint32, transport api.TransportProtocol) error {
 	unlock := true
 	pm.mu.RLock()
 	defer func() {
@@ -670,7 +844,7 @@ func (pm *Manager) CloseTunnel(ctx context.Context, port uint32) error {
 			pm.mu.RUnlock()
 		}
 	}()
-	if pm.boundInternally(port) {
+	if transport == api.TransportProtocol_transport_tcp && pm.boundInternally(port) {
 		return xerrors.New("cannot close internal port tunnel")
 	}
 	// we don't need the lock anymore. Let's unlock and make sure the defer doesn't try
@@ -678,13 +852,13 @@ func (pm *Manager) CloseTunnel(ctx context.Context, port uint32) error {
 	pm.mu.RUnlock()
 	unlock = false
 
-	_, err := pm.T.CloseTunnel(ctx, port)
+	_, err := pm.T.CloseTunnel(ctx, transport, port)
 	return err
 }
 
 // EstablishTunnel actually establishes the tunnel
-func (pm *Manager) EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32) (net.Conn, error) {
-	return pm.T.EstablishTunnel(ctx, clientID, localPort, targetPort)
+func (pm *Manager) EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32, transport api.TransportProtocol) (net.Conn, error) {
+	return pm.T.EstablishTunnel(ctx, clientID, localPort, targetPort, transport)
 }
 
 // AutoTunnel controls enablement of auto tunneling
@@ -738,19 +912,22 @@ func (pm *Manager) Subscribe() (*Subscription, error) {
 // getStatus produces an API compatible port status list.
 // Callers are expected to hold mu.
 func (pm *Manager) getStatus() []*api.PortsStatus {
-	res := make([]*api.PortsStatus, 0, len(pm.state))
-	for port := range pm.state {
-		res = append(res, pm.getPortStatus(port))
+	res := make([]*api.PortsStatus, 0, len(pm.state)+len(pm.udpState))
+	for _, mp := range pm.state {
+		res = append(res, getPortStatus(mp))
+	}
+	for _, mp := range pm.udpState {
+		res = append(res, getPortStatus(mp))
 	}
 	return res
 }
 
-func (pm *Manager) getPortStatus(port uint32) *api.PortsStatus {
-	mp := pm.state[port]
+func getPortStatus(mp *managedPort) *api.PortsStatus {
 	ps := &api.PortsStatus{
-		GlobalPort: mp.GlobalPort,
-		LocalPort:  mp.LocalhostPort,
-		Served:     mp.Served,
+		GlobalPort:        mp.GlobalPort,
+		LocalPort:         mp.LocalhostPort,
+		Served:            mp.Served,
+		TransportProtocol: mp.Transport,
 	}
 	if mp.Exposed && mp.URL != "" {
 		ps.Exposed = &api.ExposedPortInfo{
@@ -760,6 +937,14 @@ func (pm *Manager) getPortStatus(port uint32) *api.PortsStatus {
 		}
 	}
 	ps.AutoExposure = mp.AutoExposure
+	ps.Protocol = mp.Protocol
+	ps.Health = mp.Health
+	if mp.OwnerPID != 0 {
+		ps.Owner = &api.PortOwner{
+			Pid:  int64(mp.OwnerPID),
+			Name: mp.OwnerName,
+		}
+	}
 	if mp.Tunneled {
 		ps.Tunneled = &api.TunneledPortInfo{
 			TargetPort: mp.TunneledTargetPort,
@@ -49,10 +49,10 @@ func TestPortsUpdateState(t *testing.T) {
 		{
 			Desc: "basic locally served",
 			Changes: []Change{
-				{Served: []ServedPort{{"0100007F", 8080, true}}},
+				{Served: []ServedPort{{Address: "0100007F", Port: 8080, BoundToLocalhost: true}}},
 				{Exposed: []ExposedPort{{LocalPort: 8080, GlobalPort: 60000, URL: "foobar"}}},
-				{Served: []ServedPort{{"0100007F", 8080, true}, {"00000000", 60000, false}}},
-				{Served: []ServedPort{{"00000000", 60000, false}}},
+				{Served: []ServedPort{{Address: "0100007F", Port: 8080, BoundToLocalhost: true}, {Address: "00000000", Port: 60000, BoundToLocalhost: false}}},
+				{Served: []ServedPort{{Address: "00000000", Port: 60000, BoundToLocalhost: false}}},
 				{Served: []ServedPort{}},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -68,7 +68,7 @@ func TestPortsUpdateState(t *testing.T) {
 		{
 			Desc: "basic globally served",
 			Changes: []Change{
-				{Served: []ServedPort{{"00000000", 8080, false}}},
+				{Served: []ServedPort{{Address: "00000000", Port: 8080, BoundToLocalhost: false}}},
 				{Served: []ServedPort{}},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -94,12 +94,40 @@ func TestPortsUpdateState(t *testing.T) {
 				[]*api.PortsStatus{{LocalPort: 8080, GlobalPort: 8080, Served: true, Exposed: &api.ExposedPortInfo{Visibility: api.PortVisibility_public, Url: "foobar", OnExposed: api.OnPortExposedAction_notify_private}}},
 			},
 		},
+		{
+			Desc: "udp port served and tunneled",
+			Changes: []Change{
+				{Config: &ConfigChange{instance: []*gitpod.PortsItems{{Port: 53, Protocol: "UDP"}}}},
+				{Served: []ServedPort{{Address: "00000000", Port: 53, Transport: api.TransportProtocol_transport_udp}}},
+				{Tunneled: []PortTunnelState{{Desc: PortTunnelDescription{LocalPort: 53, TargetPort: 5353, Visibility: api.TunnelVisiblity_host, Transport: api.TransportProtocol_transport_udp}, Clients: map[string]uint32{"foo": 5353}}}},
+				{Served: []ServedPort{}},
+			},
+			ExpectedExposure: ExposureExpectation(nil),
+			ExpectedUpdates: UpdateExpectation{
+				{},
+				{{LocalPort: 53, Served: true, TransportProtocol: api.TransportProtocol_transport_udp}},
+				{{LocalPort: 53, Served: true, TransportProtocol: api.TransportProtocol_transport_udp, Tunneled: &api.TunneledPortInfo{TargetPort: 5353, Visibility: api.TunnelVisiblity_host, Clients: map[string]uint32{"foo": 5353}}}},
+				{{LocalPort: 53, TransportProtocol: api.TransportProtocol_transport_udp, Tunneled: &api.TunneledPortInfo{TargetPort: 5353, Visibility: api.TunnelVisiblity_host, Clients: map[string]uint32{"foo": 5353}}}},
+			},
+		},
+		{
+			Desc: "unconfigured udp port served",
+			Changes: []Change{
+				{Served: []ServedPort{{Address: "00000000", Port: 5353, Transport: api.TransportProtocol_transport_udp}}},
+				{Tunneled: []PortTunnelState{{Desc: PortTunnelDescription{LocalPort: 5353, TargetPort: 5353, Visibility: api.TunnelVisiblity_host, Transport: api.TransportProtocol_transport_udp}}}},
+			},
+			ExpectedExposure: ExposureExpectation(nil),
+			ExpectedUpdates: UpdateExpectation{
+				{},
+				{{LocalPort: 5353, TransportProtocol: api.TransportProtocol_transport_udp, Tunneled: &api.TunneledPortInfo{TargetPort: 5353, Visibility: api.TunnelVisiblity_host}}},
+			},
+		},
 		{
 			Desc:          "internal ports served",
 			InternalPorts: []uint32{8080},
 			Changes: []Change{
 				{Served: []ServedPort{}},
-				{Served: []ServedPort{{"00000000", 8080, false}}},
+				{Served: []ServedPort{{Address: "00000000", Port: 8080, BoundToLocalhost: false}}},
 			},
 
 			ExpectedExposure: ExposureExpectation(nil),
@@ -122,8 +150,8 @@ func TestPortsUpdateState(t *testing.T) {
 				},
 				{
 					Served: []ServedPort{
-						{"00000000", 8080, false},
-						{"0100007F", 9229, true},
+						{Address: "00000000", Port: 8080, BoundToLocalhost: false},
+						{Address: "0100007F", Port: 9229, BoundToLocalhost: true},
 					},
 				},
 			},
@@ -154,9 +182,9 @@ func TestPortsUpdateState(t *testing.T) {
 						Port:   "4000-5000",
 					}},
 				}},
-				{Served: []ServedPort{{"0100007F", 4040, true}}},
+				{Served: []ServedPort{{Address: "0100007F", Port: 4040, BoundToLocalhost: true}}},
 				{Exposed: []ExposedPort{{LocalPort: 4040, GlobalPort: 60000, Public: true, URL: "4040-foobar"}}},
-				{Served: []ServedPort{{"0100007F", 4040, true}, {"00000000", 60000, false}}},
+				{Served: []ServedPort{{Address: "0100007F", Port: 4040, BoundToLocalhost: true}, {Address: "00000000", Port: 60000, BoundToLocalhost: false}}},
 			},
 			ExpectedExposure: []ExposedPort{
 				{LocalPort: 4040, GlobalPort: 60000},
@@ -184,22 +212,22 @@ func TestPortsUpdateState(t *testing.T) {
 					Exposed: []ExposedPort{{LocalPort: 8080, GlobalPort: 8080, Public: true, URL: "foobar"}},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 8080, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 8080, BoundToLocalhost: true}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 8080, GlobalPort: 60000, Public: true, URL: "foobar"}},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 8080, true}, {"00000000", 60000, false}},
+					Served: []ServedPort{{Address: "0100007F", Port: 8080, BoundToLocalhost: true}, {Address: "00000000", Port: 60000, BoundToLocalhost: false}},
 				},
 				{
-					Served: []ServedPort{{"00000000", 60000, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 60000, BoundToLocalhost: false}},
 				},
 				{
 					Served: []ServedPort{},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 8080, false}},
+					Served: []ServedPort{{Address: "0100007F", Port: 8080, BoundToLocalhost: false}},
 				},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -221,7 +249,7 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "starting multiple proxies for the same served event",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"0100007F", 8080, true}, {"00000000", 3000, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 8080, BoundToLocalhost: true}, {Address: "00000000", Port: 3000, BoundToLocalhost: true}},
 				},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -245,7 +273,7 @@ func TestPortsUpdateState(t *testing.T) {
 					}},
 				},
 				{
-					Served: []ServedPort{{"00000000", 8080, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 8080, BoundToLocalhost: false}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 8080, GlobalPort: 8080, Public: false, URL: "foobar"}},
@@ -265,13 +293,13 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served locally and then globally too, prefer globally (exposed in between)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 60000, URL: "foobar"}},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}, {"00000000", 5900, false}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}, {Address: "00000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 5900, URL: "foobar"}},
@@ -292,10 +320,10 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served locally and then globally too, prefer globally (exposed after)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}, {"00000000", 5900, false}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}, {Address: "00000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 60000, URL: "foobar"}},
@@ -319,13 +347,13 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served globally and then locally too, prefer globally (exposed in between)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"00000000", 5900, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 5900, URL: "foobar"}},
 				},
 				{
-					Served: []ServedPort{{"00000000", 5900, false}, {"0100007F", 5900, true}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}, {Address: "0100007F", Port: 5900, BoundToLocalhost: true}},
 				},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -341,10 +369,10 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served globally and then locally too, prefer globally (exposed after)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"00000000", 5900, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
-					Served: []ServedPort{{"00000000", 5900, false}, {"0100007F", 5900, true}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}, {Address: "0100007F", Port: 5900, BoundToLocalhost: true}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 5900, URL: "foobar"}},
@@ -363,13 +391,13 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served locally on ip4 and then locally on ip6 too, prefer first (exposed in between)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 60000, URL: "foobar"}},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}, {"00000000000000000000010000000000", 5900, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}, {Address: "00000000000000000000010000000000", Port: 5900, BoundToLocalhost: true}},
 				},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -385,10 +413,10 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served locally on ip4 and then locally on ip6 too, prefer first (exposed after)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}},
 				},
 				{
-					Served: []ServedPort{{"0100007F", 5900, true}, {"00000000000000000000010000000000", 5900, true}},
+					Served: []ServedPort{{Address: "0100007F", Port: 5900, BoundToLocalhost: true}, {Address: "00000000000000000000010000000000", Port: 5900, BoundToLocalhost: true}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 60000, URL: "foobar"}},
@@ -407,13 +435,13 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served locally on ip4 and then globally on ip6 too, prefer first (exposed in between)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"00000000", 5900, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 5900, URL: "foobar"}},
 				},
 				{
-					Served: []ServedPort{{"00000000", 5900, false}, {"00000000000000000000000000000000", 5900, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}, {Address: "00000000000000000000000000000000", Port: 5900, BoundToLocalhost: false}},
 				},
 			},
 			ExpectedExposure: []ExposedPort{
@@ -429,10 +457,10 @@ func TestPortsUpdateState(t *testing.T) {
 			Desc: "the same port served locally on ip4 and then globally on ip6 too, prefer first (exposed after)",
 			Changes: []Change{
 				{
-					Served: []ServedPort{{"00000000", 5900, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
-					Served: []ServedPort{{"00000000", 5900, false}, {"00000000000000000000000000000000", 5900, false}},
+					Served: []ServedPort{{Address: "00000000", Port: 5900, BoundToLocalhost: false}, {Address: "00000000000000000000000000000000", Port: 5900, BoundToLocalhost: false}},
 				},
 				{
 					Exposed: []ExposedPort{{LocalPort: 5900, GlobalPort: 5900, URL: "foobar"}},
@@ -477,6 +505,9 @@ func TestPortsUpdateState(t *testing.T) {
 			pm.proxyStarter = func(localPort uint32, globalPort uint32) (io.Closer, error) {
 				return io.NopCloser(nil), nil
 			}
+			pm.portProber = func(ctx context.Context, port uint32) portProbe {
+				return portProbe{}
+			}
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -510,9 +541,9 @@ func TestPortsUpdateState(t *testing.T) {
 					if c.Config != nil {
 						change := &Configs{}
 						change.workspaceConfigs = parseWorkspaceConfigs(c.Config.workspace)
-						portConfigs, rangeConfigs := parseInstanceConfigs(c.Config.instance)
-						change.instancePortConfigs = portConfigs
-						change.instanceRangeConfigs = rangeConfigs
+						tcp, udp := splitUDPConfigs(c.Config.instance)
+						change.instancePortConfigs, change.instanceRangeConfigs = parseInstanceConfigs(tcp)
+						change.instanceUDPPortConfigs, change.instanceUDPRangeConfigs = parseInstanceConfigs(udp)
 						config.Changes <- change
 					} else if c.ConfigErr != nil {
 						config.Error <- c.ConfigErr
@@ -541,6 +572,7 @@ func TestPortsUpdateState(t *testing.T) {
 				ignoreUnexported = cmpopts.IgnoreUnexported(
 					api.PortsStatus{},
 					api.ExposedPortInfo{},
+					api.TunneledPortInfo{},
 				)
 			)
 			if diff := cmp.Diff(test.ExpectedExposure, ExposureExpectation(exposed.Exposures), sortExposed, ignoreUnexported); diff != "" {
@@ -565,10 +597,10 @@ func (tep *testTunneledPorts) Observe(ctx context.Context) (<-chan []PortTunnelS
 func (tep *testTunneledPorts) Tunnel(ctx context.Context, options *TunnelOptions, descs ...*PortTunnelDescription) ([]uint32, error) {
 	return nil, nil
 }
-func (tep *testTunneledPorts) CloseTunnel(ctx context.Context, localPorts ...uint32) ([]uint32, error) {
+func (tep *testTunneledPorts) CloseTunnel(ctx context.Context, transport api.TransportProtocol, localPorts ...uint32) ([]uint32, error) {
 	return nil, nil
 }
-func (tep *testTunneledPorts) EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32) (net.Conn, error) {
+func (tep *testTunneledPorts) EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32, transport api.TransportProtocol) (net.Conn, error) {
 	return nil, nil
 }
 
@@ -643,6 +675,9 @@ func TestPortsConcurrentSubscribe(t *testing.T) {
 	pm.proxyStarter = func(localPort uint32, globalPort uint32) (io.Closer, error) {
 		return io.NopCloser(nil), nil
 	}
+	pm.portProber = func(ctx context.Context, port uint32) portProbe {
+		return portProbe{}
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -700,3 +735,67 @@ func TestPortsConcurrentSubscribe(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestPortsSetVisibility(t *testing.T) {
+	tests := []struct {
+		Desc             string
+		Port             uint32
+		Visibility       api.PortVisibility
+		ExpectedExposure []ExposedPort
+		ExpectErr        bool
+	}{
+		{
+			Desc:             "make public",
+			Port:             8080,
+			Visibility:       api.PortVisibility_public,
+			ExpectedExposure: []ExposedPort{{LocalPort: 8080, GlobalPort: 60000, Public: true}},
+		},
+		{
+			Desc:       "unchanged",
+			Port:       8080,
+			Visibility: api.PortVisibility_private,
+		},
+		{
+			Desc:       "served, but not exposed",
+			Port:       3000,
+			Visibility: api.PortVisibility_public,
+			ExpectErr:  true,
+		},
+		{
+			Desc:       "unknown",
+			Port:       5000,
+			Visibility: api.PortVisibility_public,
+			ExpectErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			exposed := &exposingExposedPorts{}
+			pm := NewManager(exposed, &testServedPorts{}, &testConfigService{}, &testTunneledPorts{})
+			pm.state = map[uint32]*managedPort{
+				8080: {LocalhostPort: 8080, GlobalPort: 60000, Served: true, Exposed: true, Visibility: api.PortVisibility_private},
+				3000: {LocalhostPort: 3000, GlobalPort: 3000, Served: true},
+			}
+
+			err := pm.SetVisibility(context.Background(), test.Port, test.Visibility)
+			if (err != nil) != test.ExpectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.ExpectedExposure, exposed.Exposures); diff != "" {
+				t.Errorf("unexpected exposures (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// exposingExposedPorts is a testExposedPorts whose exposures succeed right away
+type exposingExposedPorts struct {
+	testExposedPorts
+}
+
+func (tep *exposingExposedPorts) Expose(ctx context.Context, local, global uint32, public bool) <-chan error {
+	tep.testExposedPorts.Expose(ctx, local, global, public)
+	done := make(chan error)
+	close(done)
+	return done
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package ports
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+const (
+	// probeInterval is the time between two health checks of a served port, and the initial
+	// time between two protocol detection attempts
+	probeInterval = 10 * time.Second
+	// maxProbeBackoff is the maximum time between two protocol detection attempts
+	maxProbeBackoff = 5 * time.Minute
+	// probeTimeout is the time a single protocol detection attempt or health check may take
+	probeTimeout = 2 * time.Second
+
+	// maxProbeBodySize is the number of bytes of a response body we read when probing
+	maxProbeBodySize = 4 << 10
+)
+
+// portProbe is the result of probing a served port
+type portProbe struct {
+	Protocol api.PortProtocol
+	Health   api.PortHealth
+}
+
+// portProber detects the protocol of a port served on localhost
+type portProber func(ctx context.Context, port uint32) portProbe
+
+// portHealthChecker checks the health of a port served on localhost whose protocol is known
+type portHealthChecker func(ctx context.Context, port uint32, protocol api.PortProtocol) api.PortHealth
+
+// probePort detects the protocol a port speaks and checks its health.
+// We try HTTP first, because servers commonly log TLS handshakes and HTTP/2 prefaces they don't understand as errors,
+// and HTTP/1.1 is by far the most common protocol served in a workspace.
+func probePort(ctx context.Context, port uint32) portProbe {
+	addr := fmt.Sprintf("localhost:%d", port)
+
+	dialer := net.Dialer{Timeout: probeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return portProbe{}
+	}
+	conn.Close()
+
+	if res, ok := probeHTTP(ctx, addr, false); ok {
+		return res
+	}
+	if res, ok := probeHTTP(ctx, addr, true); ok {
+		return res
+	}
+	if res, ok := probeGRPC(ctx, addr); ok {
+		return res
+	}
+	return portProbe{Protocol: api.PortProtocol_tcp}
+}
+
+// probeHTTP sends a WebSocket upgrade request to the root of addr. Servers which don't support WebSockets
+// answer it like a regular GET request, which we use as health check.
+func probeHTTP(ctx context.Context, addr string, useTLS bool) (res portProbe, ok bool) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+addr+"/", nil)
+	if err != nil {
+		return portProbe{}, false
+	}
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key))
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			// workspace services commonly use self-signed certificates
+			// #nosec G402
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return portProbe{}, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return portProbe{Protocol: api.PortProtocol_websocket, Health: api.PortHealth_healthy}, true
+	}
+	if !useTLS && resp.StatusCode == http.StatusBadRequest {
+		// Go's TLS servers answer plain HTTP requests with this message
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+		if bytes.Contains(body, []byte("HTTP request to an HTTPS server")) {
+			return portProbe{}, false
+		}
+	}
+
+	res.Protocol = api.PortProtocol_http
+	if useTLS {
+		res.Protocol = api.PortProtocol_https
+	}
+	res.Health = api.PortHealth_healthy
+	if resp.StatusCode >= http.StatusInternalServerError {
+		res.Health = api.PortHealth_unhealthy
+	}
+	return res, true
+}
+
+// probeGRPC calls the standard gRPC health service using HTTP/2 without TLS.
+// See https://github.com/grpc/grpc/blob/master/doc/health-checking.md
+func probeGRPC(ctx context.Context, addr string) (res portProbe, ok bool) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	// an empty HealthCheckRequest: uncompressed and zero bytes long
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+addr+"/grpc.health.v1.Health/Check", bytes.NewReader([]byte{0, 0, 0, 0, 0}))
+	if err != nil {
+		return portProbe{}, false
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.DialTimeout(network, addr, probeTimeout)
+			},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return portProbe{}, false
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/grpc") {
+		return portProbe{}, false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+	if err != nil {
+		return portProbe{Protocol: api.PortProtocol_grpc}, true
+	}
+
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		// trailers-only responses carry the status in the headers
+		status = resp.Header.Get("Grpc-Status")
+	}
+	switch status {
+	case "0":
+		// a HealthCheckResponse with status SERVING
+		if bytes.Equal(body, []byte{0, 0, 0, 0, 2, 0x08, 0x01}) {
+			return portProbe{Protocol: api.PortProtocol_grpc, Health: api.PortHealth_healthy}, true
+		}
+		return portProbe{Protocol: api.PortProtocol_grpc, Health: api.PortHealth_unhealthy}, true
+	case "12":
+		// the server does not implement the health service
+		return portProbe{Protocol: api.PortProtocol_grpc}, true
+	default:
+		return portProbe{Protocol: api.PortProtocol_grpc, Health: api.PortHealth_unhealthy}, true
+	}
+}
+
+// checkPortHealth checks the health of a port using the cheapest request the protocol allows
+func checkPortHealth(ctx context.Context, port uint32, protocol api.PortProtocol) api.PortHealth {
+	addr := fmt.Sprintf("localhost:%d", port)
+	switch protocol {
+	case api.PortProtocol_http:
+		return checkHTTP(ctx, addr, false)
+	case api.PortProtocol_https:
+		return checkHTTP(ctx, addr, true)
+	case api.PortProtocol_grpc:
+		res, _ := probeGRPC(ctx, addr)
+		return res.Health
+	default:
+		// WebSocket servers keep the port open while they can upgrade connections, hence accepting
+		// connections is as much as we can tell without handshaking
+		dialer := net.Dialer{Timeout: probeTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return api.PortHealth_unhealthy
+		}
+		conn.Close()
+		return api.PortHealth_healthy
+	}
+}
+
+// checkHTTP sends a plain GET request to the root of addr
+func checkHTTP(ctx context.Context, addr string, useTLS bool) api.PortHealth {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+addr+"/", nil)
+	if err != nil {
+		return api.PortHealth_unhealthy
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			// #nosec G402
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return api.PortHealth_unhealthy
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return api.PortHealth_unhealthy
+	}
+	return api.PortHealth_healthy
+}
+
+// runProbe detects the protocol of a served port and then checks its health until ctx is canceled.
+// Protocol detection is retried with exponential backoff until the port answers one of the protocols we know.
+// Once detected, only the health check of that protocol runs. Ports which speak plain TCP, or a protocol
+// without health check, are not probed any further.
+func (pm *Manager) runProbe(ctx context.Context, port uint32, probing *portProbing) {
+	var (
+		res     portProbe
+		backoff = probeInterval
+	)
+	for {
+		res = pm.portProber(ctx, port)
+		if ctx.Err() != nil {
+			return
+		}
+		pm.setProbeResult(probing, res)
+		if res.Protocol != api.PortProtocol_protocol_unknown {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxProbeBackoff {
+			backoff = maxProbeBackoff
+		}
+	}
+	if res.Health == api.PortHealth_health_unknown {
+		return
+	}
+
+	t := time.NewTicker(probeInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		health := pm.healthChecker(ctx, port, res.Protocol)
+		if ctx.Err() != nil {
+			return
+		}
+		pm.setProbeResult(probing, portProbe{Protocol: res.Protocol, Health: health})
+	}
+}
+
+func (pm *Manager) setProbeResult(probing *portProbing, res portProbe) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if probing.result != res {
+		probing.result = res
+		pm.forceUpdate()
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package ports
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+func listenerPort(t *testing.T, addr net.Addr) uint32 {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		t.Fatalf("not a TCP address: %v", addr)
+	}
+	return uint32(tcpAddr.Port)
+}
+
+func serveHTTP(handler http.HandlerFunc, useTLS bool) func(t *testing.T) (port uint32, stop func()) {
+	return func(t *testing.T) (uint32, func()) {
+		var srv *httptest.Server
+		if useTLS {
+			srv = httptest.NewTLSServer(handler)
+		} else {
+			srv = httptest.NewServer(handler)
+		}
+		return listenerPort(t, srv.Listener.Addr()), srv.Close
+	}
+}
+
+func serveGRPC(withHealth bool) func(t *testing.T) (port uint32, stop func()) {
+	return func(t *testing.T) (uint32, func()) {
+		lis, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := grpc.NewServer()
+		if withHealth {
+			healthpb.RegisterHealthServer(srv, health.NewServer())
+		}
+		//nolint:errcheck
+		go srv.Serve(lis)
+		return listenerPort(t, lis.Addr()), srv.Stop
+	}
+}
+
+func TestProbePort(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	tests := []struct {
+		Desc        string
+		Serve       func(t *testing.T) (port uint32, stop func())
+		Expectation portProbe
+	}{
+		{
+			Desc:        "http",
+			Serve:       serveHTTP(func(w http.ResponseWriter, r *http.Request) {}, false),
+			Expectation: portProbe{Protocol: api.PortProtocol_http, Health: api.PortHealth_healthy},
+		},
+		{
+			Desc: "unhealthy http",
+			Serve: serveHTTP(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}, false),
+			Expectation: portProbe{Protocol: api.PortProtocol_http, Health: api.PortHealth_unhealthy},
+		},
+		{
+			Desc:        "https",
+			Serve:       serveHTTP(func(w http.ResponseWriter, r *http.Request) {}, true),
+			Expectation: portProbe{Protocol: api.PortProtocol_https, Health: api.PortHealth_healthy},
+		},
+		{
+			Desc: "websocket",
+			Serve: serveHTTP(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}, false),
+			Expectation: portProbe{Protocol: api.PortProtocol_websocket, Health: api.PortHealth_healthy},
+		},
+		{
+			Desc:        "grpc",
+			Serve:       serveGRPC(true),
+			Expectation: portProbe{Protocol: api.PortProtocol_grpc, Health: api.PortHealth_healthy},
+		},
+		{
+			Desc:        "grpc without health service",
+			Serve:       serveGRPC(false),
+			Expectation: portProbe{Protocol: api.PortProtocol_grpc},
+		},
+		{
+			Desc: "tcp",
+			Serve: func(t *testing.T) (uint32, func()) {
+				lis, err := net.Listen("tcp", "localhost:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				go func() {
+					for {
+						conn, err := lis.Accept()
+						if err != nil {
+							return
+						}
+						_, _ = conn.Write([]byte("-ERR unknown command\r\n"))
+						conn.Close()
+					}
+				}()
+				return listenerPort(t, lis.Addr()), func() { lis.Close() }
+			},
+			Expectation: portProbe{Protocol: api.PortProtocol_tcp},
+		},
+		{
+			Desc: "not served",
+			Serve: func(t *testing.T) (uint32, func()) {
+				lis, err := net.Listen("tcp", "localhost:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				port := listenerPort(t, lis.Addr())
+				lis.Close()
+				return port, func() {}
+			},
+			Expectation: portProbe{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			port, stop := test.Serve(t)
+			defer stop()
+
+			act := probePort(context.Background(), port)
+			if act != test.Expectation {
+				t.Errorf("unexpected probe result: want %+v, got %+v", test.Expectation, act)
+			}
+		})
+	}
+}
+
+func TestCheckPortHealth(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	tests := []struct {
+		Desc        string
+		Serve       func(t *testing.T) (port uint32, stop func())
+		Protocol    api.PortProtocol
+		Expectation api.PortHealth
+	}{
+		{
+			Desc:        "http",
+			Serve:       serveHTTP(func(w http.ResponseWriter, r *http.Request) {}, false),
+			Protocol:    api.PortProtocol_http,
+			Expectation: api.PortHealth_healthy,
+		},
+		{
+			Desc: "unhealthy http",
+			Serve: serveHTTP(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}, false),
+			Protocol:    api.PortProtocol_http,
+			Expectation: api.PortHealth_unhealthy,
+		},
+		{
+			Desc:        "https",
+			Serve:       serveHTTP(func(w http.ResponseWriter, r *http.Request) {}, true),
+			Protocol:    api.PortProtocol_https,
+			Expectation: api.PortHealth_healthy,
+		},
+		{
+			Desc: "websocket",
+			Serve: serveHTTP(func(w http.ResponseWriter, r *http.Request) {
+				if websocket.IsWebSocketUpgrade(r) {
+					t.Error("health check must not upgrade connections")
+				}
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}, false),
+			Protocol:    api.PortProtocol_websocket,
+			Expectation: api.PortHealth_healthy,
+		},
+		{
+			Desc:        "grpc",
+			Serve:       serveGRPC(true),
+			Protocol:    api.PortProtocol_grpc,
+			Expectation: api.PortHealth_healthy,
+		},
+		{
+			Desc: "gone",
+			Serve: func(t *testing.T) (uint32, func()) {
+				lis, err := net.Listen("tcp", "localhost:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				port := listenerPort(t, lis.Addr())
+				lis.Close()
+				return port, func() {}
+			},
+			Protocol:    api.PortProtocol_http,
+			Expectation: api.PortHealth_unhealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			port, stop := test.Serve(t)
+			defer stop()
+
+			act := checkPortHealth(context.Background(), port, test.Protocol)
+			if act != test.Expectation {
+				t.Errorf("unexpected health: want %s, got %s", test.Expectation, act)
+			}
+		})
+	}
+}
+
+func TestRunProbeWithoutHealthCheck(t *testing.T) {
+	tests := []struct {
+		Desc  string
+		Probe portProbe
+	}{
+		{Desc: "tcp", Probe: portProbe{Protocol: api.PortProtocol_tcp}},
+		{Desc: "grpc without health service", Probe: portProbe{Protocol: api.PortProtocol_grpc}},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			var probes int
+			pm := &Manager{
+				forceUpdates: make(chan struct{}, 1),
+				portProber: func(ctx context.Context, port uint32) portProbe {
+					probes++
+					return test.Probe
+				},
+				healthChecker: func(ctx context.Context, port uint32, protocol api.PortProtocol) api.PortHealth {
+					t.Error("port without health check was checked")
+					return api.PortHealth_health_unknown
+				},
+			}
+			probing := &portProbing{}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				pm.runProbe(context.Background(), 8080, probing)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("probing did not stop after detecting the protocol")
+			}
+
+			if probes != 1 {
+				t.Errorf("expected the protocol to be detected once, was %d times", probes)
+			}
+			if probing.result != test.Probe {
+				t.Errorf("unexpected probe result: want %+v, got %+v", test.Probe, probing.result)
+			}
+		})
+	}
+}
+
+func TestOnExposedActionForProtocol(t *testing.T) {
+	tests := []struct {
+		Protocol    api.PortProtocol
+		Expectation api.OnPortExposedAction
+	}{
+		{Protocol: api.PortProtocol_protocol_unknown, Expectation: api.OnPortExposedAction_notify_private},
+		{Protocol: api.PortProtocol_http, Expectation: api.OnPortExposedAction_notify_private},
+		{Protocol: api.PortProtocol_websocket, Expectation: api.OnPortExposedAction_notify_private},
+		{Protocol: api.PortProtocol_tcp, Expectation: api.OnPortExposedAction_ignore},
+		{Protocol: api.PortProtocol_grpc, Expectation: api.OnPortExposedAction_ignore},
+	}
+	for _, test := range tests {
+		if act := getOnExposedAction(nil, 8080, test.Protocol); act != test.Expectation {
+			t.Errorf("unexpected action for %s: want %s, got %s", test.Protocol, test.Expectation, act)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package ports
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+// sock_diag constants which golang.org/x/sys/unix does not provide.
+// See linux/sock_diag.h and linux/inet_diag.h
+const (
+	sockDiagByFamily = 20
+
+	sknlgrpInetTCPDestroy  = 1
+	sknlgrpInetUDPDestroy  = 2
+	sknlgrpInet6TCPDestroy = 3
+	sknlgrpInet6UDPDestroy = 4
+
+	// unconnected UDP sockets are in the TCP_CLOSE state
+	tcpClose  = 7
+	tcpListen = 10
+
+	sizeofInetDiagReqV2 = 56
+	sizeofInetDiagMsg   = 72
+
+	netlinkEventTimeout = 1 * time.Second
+)
+
+// NetlinkServedPortsObserver observes the served ports using the sock_diag netlink interface.
+//
+// This observer still polls: listening sockets are dumped from the kernel rather than parsed from /proc,
+// which is cheap enough to do frequently. The kernel does not notify about new listening sockets, so new
+// ports are only found by the next dump and listeners which live shorter than the interval can be missed.
+// The kernel does notify about destroyed sockets. We subscribe to those notifications if we're allowed to,
+// so that closed ports disappear without waiting for the next dump.
+//
+// Sockets are dumped every RefreshInterval. While the served ports don't change the interval doubles up to
+// MaxRefreshInterval, any change brings it back to RefreshInterval. Keep MaxRefreshInterval at or below the
+// interval of the polling observer, lest new ports are noticed later than they used to be.
+type NetlinkServedPortsObserver struct {
+	RefreshInterval time.Duration
+	// MaxRefreshInterval is the longest time between two refreshes. Defaults to RefreshInterval.
+	MaxRefreshInterval time.Duration
+
+	// Fallback observes the served ports if netlink is not available
+	Fallback ServedPortsObserver
+
+	procRoot string
+}
+
+// Observe starts observing the served ports until the context is canceled.
+func (n *NetlinkServedPortsObserver) Observe(ctx context.Context) (<-chan []ServedPort, <-chan error) {
+	if n.procRoot == "" {
+		n.procRoot = "/proc"
+	}
+
+	dump, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err == nil {
+		_, err = dumpListeningSockets(dump)
+		if err != nil {
+			unix.Close(dump)
+		}
+	}
+	if err != nil {
+		log.WithError(err).Warn("cannot use netlink to observe served ports - falling back to polling")
+		return n.Fallback.Observe(ctx)
+	}
+
+	var (
+		errchan = make(chan error, 1)
+		reschan = make(chan []ServedPort)
+		changed = make(chan struct{}, 1)
+	)
+
+	events, err := subscribeSocketDestroyEvents()
+	if err != nil {
+		log.WithError(err).Debug("cannot subscribe to socket destroy events - closed ports are noticed on refresh only")
+	} else {
+		go func() {
+			defer unix.Close(events)
+			buf := make([]byte, 4096)
+			for ctx.Err() == nil {
+				_, _, err := unix.Recvfrom(events, buf, 0)
+				if err == unix.EAGAIN || err == unix.EINTR {
+					continue
+				}
+				if err != nil {
+					log.WithError(err).Debug("stopped receiving socket destroy events")
+					return
+				}
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(errchan)
+		defer close(reschan)
+		defer unix.Close(dump)
+
+		maxInterval := n.MaxRefreshInterval
+		if maxInterval < n.RefreshInterval {
+			maxInterval = n.RefreshInterval
+		}
+
+		var (
+			last     []ServedPort
+			owners   = make(map[uint64]socketOwner)
+			interval = n.RefreshInterval
+		)
+		for {
+			socks, err := dumpListeningSockets(dump)
+			if err != nil {
+				select {
+				case errchan <- err:
+				default:
+				}
+			} else {
+				owners = n.resolveOwners(socks, owners)
+				ports := make([]ServedPort, 0, len(socks))
+				for _, sock := range socks {
+					owner := owners[sock.Inode]
+					sock.Port.OwnerPID = owner.PID
+					sock.Port.OwnerName = owner.Name
+					ports = append(ports, sock.Port)
+				}
+				if last == nil || !reflect.DeepEqual(last, ports) {
+					last = ports
+					interval = n.RefreshInterval
+					select {
+					case reschan <- ports:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				interval *= 2
+				if interval > maxInterval {
+					interval = maxInterval
+				}
+			case <-changed:
+				timer.Stop()
+				interval = n.RefreshInterval
+			}
+		}
+	}()
+
+	return reschan, errchan
+}
+
+func subscribeSocketDestroyEvents() (int, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return -1, err
+	}
+	err = unix.Bind(fd, &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: 1<<(sknlgrpInetTCPDestroy-1) | 1<<(sknlgrpInetUDPDestroy-1) | 1<<(sknlgrpInet6TCPDestroy-1) | 1<<(sknlgrpInet6UDPDestroy-1),
+	})
+	if err == nil {
+		// we need to check the context regularly
+		err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Sec: int64(netlinkEventTimeout / time.Second)})
+	}
+	if err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+type listeningSocket struct {
+	Port  ServedPort
+	Inode uint64
+}
+
+// dumpListeningSockets returns all listening TCP sockets followed by all unconnected UDP sockets.
+// IPv4 comes first for each protocol, and each family is ordered by port.
+func dumpListeningSockets(fd int) ([]listeningSocket, error) {
+	var res []listeningSocket
+	for _, protocol := range []uint8{unix.IPPROTO_TCP, unix.IPPROTO_UDP} {
+		for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+			socks, err := dumpListeningSocketsOfFamily(fd, family, protocol)
+			if err != nil {
+				return nil, err
+			}
+			sort.SliceStable(socks, func(i, j int) bool { return socks[i].Port.Port < socks[j].Port.Port })
+			res = append(res, socks...)
+		}
+	}
+	return res, nil
+}
+
+func dumpListeningSocketsOfFamily(fd int, family uint8, protocol uint8) ([]listeningSocket, error) {
+	var (
+		states    uint32 = 1 << tcpListen
+		transport        = api.TransportProtocol_transport_tcp
+	)
+	if protocol == unix.IPPROTO_UDP {
+		states = 1 << tcpClose
+		transport = api.TransportProtocol_transport_udp
+	}
+
+	req := make([]byte, unix.SizeofNlMsghdr+sizeofInetDiagReqV2)
+	binary.LittleEndian.PutUint32(req[0:4], uint32(len(req)))
+	binary.LittleEndian.PutUint16(req[4:6], sockDiagByFamily)
+	binary.LittleEndian.PutUint16(req[6:8], unix.NLM_F_REQUEST|unix.NLM_F_DUMP)
+	req[unix.SizeofNlMsghdr] = family
+	req[unix.SizeofNlMsghdr+1] = protocol
+	binary.LittleEndian.PutUint32(req[unix.SizeofNlMsghdr+4:], states)
+
+	err := unix.Sendto(fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+	if err != nil {
+		return nil, xerrors.Errorf("cannot request socket dump: %w", err)
+	}
+
+	var (
+		res []listeningSocket
+		buf = make([]byte, 32<<10)
+	)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, xerrors.Errorf("cannot receive socket dump: %w", err)
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, xerrors.Errorf("cannot parse socket dump: %w", err)
+		}
+		for _, msg := range msgs {
+			switch msg.Header.Type {
+			case unix.NLMSG_DONE:
+				return res, nil
+			case unix.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := int32(binary.LittleEndian.Uint32(msg.Data)); errno != 0 {
+						return nil, xerrors.Errorf("cannot dump sockets: %w", unix.Errno(-errno))
+					}
+				}
+				return res, nil
+			case sockDiagByFamily:
+				port, inode, ok := parseInetDiagMsg(msg.Data)
+				if !ok {
+					continue
+				}
+				if protocol == unix.IPPROTO_UDP && binary.BigEndian.Uint16(msg.Data[6:8]) != 0 {
+					// the socket is connected to a remote address and receives datagrams only from there
+					continue
+				}
+				port.Transport = transport
+				res = append(res, listeningSocket{Port: port, Inode: inode})
+			}
+		}
+	}
+}
+
+// parseInetDiagMsg parses a struct inet_diag_msg. The address has the same format as in /proc/net/tcp*.
+func parseInetDiagMsg(data []byte) (port ServedPort, inode uint64, ok bool) {
+	if len(data) < sizeofInetDiagMsg {
+		return ServedPort{}, 0, false
+	}
+
+	addrLen := 4
+	if data[0] == unix.AF_INET6 {
+		addrLen = 16
+	}
+	var (
+		addr     strings.Builder
+		globally = true
+	)
+	for i := 0; i < addrLen; i += 4 {
+		word := binary.LittleEndian.Uint32(data[8+i : 12+i])
+		fmt.Fprintf(&addr, "%08X", word)
+		globally = globally && word == 0
+	}
+
+	return ServedPort{
+		Address:          addr.String(),
+		Port:             uint32(binary.BigEndian.Uint16(data[4:6])),
+		BoundToLocalhost: !globally,
+	}, uint64(binary.LittleEndian.Uint32(data[68:72])), true
+}
+
+type socketOwner struct {
+	PID  int
+	Name string
+}
+
+// resolveOwners finds the processes which hold the sockets of the served ports. Owners of sockets
+// we've seen before are taken from known, so that we scan the processes only when new sockets appear.
+// Sockets whose owner we could not find are remembered with an empty owner, e.g. those of processes we
+// may not look at - otherwise every refresh would scan all processes again.
+func (n *NetlinkServedPortsObserver) resolveOwners(socks []listeningSocket, known map[uint64]socketOwner) map[uint64]socketOwner {
+	var (
+		res     = make(map[uint64]socketOwner, len(socks))
+		unknown = make(map[uint64]struct{})
+	)
+	for _, sock := range socks {
+		owner, ok := known[sock.Inode]
+		if ok {
+			res[sock.Inode] = owner
+			continue
+		}
+		unknown[sock.Inode] = struct{}{}
+	}
+	if len(unknown) == 0 {
+		return res
+	}
+
+	found := findSocketOwners(n.procRoot, unknown)
+	for inode := range unknown {
+		res[inode] = found[inode]
+	}
+	return res
+}
+
+// findSocketOwners scans the file descriptors of all processes for the given socket inodes
+func findSocketOwners(procRoot string, inodes map[uint64]struct{}) map[uint64]socketOwner {
+	res := make(map[uint64]socketOwner, len(inodes))
+
+	procs, err := os.ReadDir(procRoot)
+	if err != nil {
+		return res
+	}
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procRoot, proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// the process is gone or we're not allowed to look at it
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, wanted := inodes[inode]; !wanted {
+				continue
+			}
+			if _, found := res[inode]; found {
+				// sockets can be shared, e.g. after fork - we report the first process we find
+				continue
+			}
+			name, _ := os.ReadFile(filepath.Join(procRoot, proc.Name(), "comm"))
+			res[inode] = socketOwner{PID: pid, Name: strings.TrimSpace(string(name))}
+		}
+		if len(res) == len(inodes) {
+			break
+		}
+	}
+	return res
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package ports
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/unix"
+)
+
+func inetDiagMsg(family uint8, addr net.IP, port uint16, inode uint32) []byte {
+	msg := make([]byte, sizeofInetDiagMsg)
+	msg[0] = family
+	msg[1] = tcpListen
+	binary.BigEndian.PutUint16(msg[4:6], port)
+	if family == unix.AF_INET {
+		copy(msg[8:12], addr.To4())
+	} else {
+		copy(msg[8:24], addr.To16())
+	}
+	binary.LittleEndian.PutUint32(msg[68:72], inode)
+	return msg
+}
+
+func TestParseInetDiagMsg(t *testing.T) {
+	type Expectation struct {
+		Port  ServedPort
+		Inode uint64
+		OK    bool
+	}
+	tests := []struct {
+		Name        string
+		Input       []byte
+		Expectation Expectation
+	}{
+		{
+			Name:        "ip4 localhost",
+			Input:       inetDiagMsg(unix.AF_INET, net.IPv4(127, 0, 0, 1), 5900, 42),
+			Expectation: Expectation{Port: ServedPort{Address: "0100007F", Port: 5900, BoundToLocalhost: true}, Inode: 42, OK: true},
+		},
+		{
+			Name:        "ip4 any",
+			Input:       inetDiagMsg(unix.AF_INET, net.IPv4zero, 8080, 43),
+			Expectation: Expectation{Port: ServedPort{Address: "00000000", Port: 8080}, Inode: 43, OK: true},
+		},
+		{
+			Name:        "ip6 localhost",
+			Input:       inetDiagMsg(unix.AF_INET6, net.IPv6loopback, 5900, 44),
+			Expectation: Expectation{Port: ServedPort{Address: "00000000000000000000000001000000", Port: 5900, BoundToLocalhost: true}, Inode: 44, OK: true},
+		},
+		{
+			Name:        "ip6 any",
+			Input:       inetDiagMsg(unix.AF_INET6, net.IPv6unspecified, 22999, 45),
+			Expectation: Expectation{Port: ServedPort{Address: "00000000000000000000000000000000", Port: 22999}, Inode: 45, OK: true},
+		},
+		{
+			Name:  "truncated",
+			Input: make([]byte, 10),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var act Expectation
+			act.Port, act.Inode, act.OK = parseInetDiagMsg(test.Input)
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindSocketOwners(t *testing.T) {
+	procRoot := t.TempDir()
+	procs := map[string]struct {
+		Comm string
+		FDs  map[string]string
+	}{
+		"12":   {Comm: "node\n", FDs: map[string]string{"0": "/dev/null", "3": "socket:[1001]"}},
+		"34":   {Comm: "python3\n", FDs: map[string]string{"5": "socket:[1002]", "6": "socket:[9999]"}},
+		"self": {Comm: "supervisor\n", FDs: map[string]string{"7": "socket:[1003]"}},
+	}
+	for pid, proc := range procs {
+		fdDir := filepath.Join(procRoot, pid, "fd")
+		err := os.MkdirAll(fdDir, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(procRoot, pid, "comm"), []byte(proc.Comm), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for fd, target := range proc.FDs {
+			err = os.Symlink(target, filepath.Join(fdDir, fd))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	act := findSocketOwners(procRoot, map[uint64]struct{}{1001: {}, 1002: {}, 1003: {}, 1004: {}})
+	expectation := map[uint64]socketOwner{
+		1001: {PID: 12, Name: "node"},
+		1002: {PID: 34, Name: "python3"},
+	}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("unexpected owners (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveOwners(t *testing.T) {
+	procRoot := t.TempDir()
+	addProc := func(pid string, comm string, inode string) {
+		fdDir := filepath.Join(procRoot, pid, "fd")
+		err := os.MkdirAll(fdDir, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(procRoot, pid, "comm"), []byte(comm+"\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Symlink("socket:["+inode+"]", filepath.Join(fdDir, "3"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	socks := func(inodes ...uint64) []listeningSocket {
+		res := make([]listeningSocket, 0, len(inodes))
+		for _, inode := range inodes {
+			res = append(res, listeningSocket{Inode: inode})
+		}
+		return res
+	}
+	obs := &NetlinkServedPortsObserver{procRoot: procRoot}
+
+	addProc("12", "node", "1001")
+	owners := obs.resolveOwners(socks(1001, 1002), nil)
+	expectation := map[uint64]socketOwner{1001: {PID: 12, Name: "node"}, 1002: {}}
+	if diff := cmp.Diff(expectation, owners); diff != "" {
+		t.Errorf("unexpected owners (-want +got):\n%s", diff)
+	}
+
+	// known sockets, including those without owner, must not cause another scan
+	addProc("34", "python3", "1002")
+	owners = obs.resolveOwners(socks(1001, 1002), owners)
+	if diff := cmp.Diff(expectation, owners); diff != "" {
+		t.Errorf("known sockets were resolved again (-want +got):\n%s", diff)
+	}
+
+	// new sockets are resolved, sockets which are gone are forgotten
+	addProc("56", "java", "1003")
+	owners = obs.resolveOwners(socks(1002, 1003), owners)
+	expectation = map[uint64]socketOwner{1002: {}, 1003: {PID: 56, Name: "java"}}
+	if diff := cmp.Diff(expectation, owners); diff != "" {
+		t.Errorf("unexpected owners (-want +got):\n%s", diff)
+	}
+}
+
+func TestNetlinkObserve(t *testing.T) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		t.Skipf("netlink is not available: %v", err)
+	}
+	_, err = dumpListeningSockets(fd)
+	unix.Close(fd)
+	if err != nil {
+		t.Skipf("cannot dump sockets: %v", err)
+	}
+
+	lis, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := uint32(lis.Addr().(*net.TCPAddr).Port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	obs := &NetlinkServedPortsObserver{RefreshInterval: 50 * time.Millisecond}
+	updates, _ := obs.Observe(ctx)
+
+	find := func(ports []ServedPort) *ServedPort {
+		for _, p := range ports {
+			if p.Port == port {
+				return &p
+			}
+		}
+		return nil
+	}
+
+	var served *ServedPort
+	for served == nil {
+		ports, ok := <-updates
+		if !ok {
+			t.Fatal("observer stopped before the port was served")
+		}
+		served = find(ports)
+	}
+	if served.OwnerPID != os.Getpid() {
+		t.Errorf("unexpected owner: want %d, got %d (%s)", os.Getpid(), served.OwnerPID, served.OwnerName)
+	}
+	if !served.BoundToLocalhost {
+		t.Error("port is not bound to localhost")
+	}
+
+	lis.Close()
+	for {
+		ports, ok := <-updates
+		if !ok {
+			t.Fatal("observer stopped before the port was closed")
+		}
+		if find(ports) == nil {
+			break
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
 )
 
 // ServedPort describes a port served by a local service
@@ -22,6 +23,12 @@ type ServedPort struct {
 	Address          string
 	Port             uint32
 	BoundToLocalhost bool
+	Transport        api.TransportProtocol
+
+	// OwnerPID is the ID of the process serving the port, zero if unknown
+	OwnerPID int
+	// OwnerName is the name of the process serving the port
+	OwnerName string
 }
 
 // ServedPortsObserver observes the locally served ports and provides
@@ -40,6 +47,8 @@ const (
 
 	fnNetTCP  = "/proc/net/tcp"
 	fnNetTCP6 = "/proc/net/tcp6"
+	fnNetUDP  = "/proc/net/udp"
+	fnNetUDP6 = "/proc/net/udp6"
 )
 
 // PollingServedPortsObserver regularly polls "/proc" to observe port changes
@@ -79,13 +88,18 @@ func (p *PollingServedPortsObserver) Observe(ctx context.Context) (<-chan []Serv
 				visited = make(map[string]struct{})
 				ports   []ServedPort
 			)
-			for _, fn := range []string{fnNetTCP, fnNetTCP6} {
+			for _, fn := range []string{fnNetTCP, fnNetTCP6, fnNetUDP, fnNetUDP6} {
 				fc, err := p.fileOpener(fn)
 				if err != nil {
 					errchan <- err
 					continue
 				}
-				ps, err := readNetTCPFile(fc, true)
+				var ps []ServedPort
+				if fn == fnNetUDP || fn == fnNetUDP6 {
+					ps, err = readNetUDPFile(fc)
+				} else {
+					ps, err = readNetTCPFile(fc, true)
+				}
 				fc.Close()
 
 				if err != nil {
@@ -93,7 +107,7 @@ func (p *PollingServedPortsObserver) Observe(ctx context.Context) (<-chan []Serv
 					continue
 				}
 				for _, port := range ps {
-					key := fmt.Sprintf("%s:%d", port.Address, port.Port)
+					key := fmt.Sprintf("%s:%s:%d", port.Transport, port.Address, port.Port)
 					_, exists := visited[key]
 					if exists {
 						log.WithField("addr", port.Address).WithField("port", port.Port).Error("unexpected duplicate served port")
@@ -114,13 +128,31 @@ func (p *PollingServedPortsObserver) Observe(ctx context.Context) (<-chan []Serv
 }
 
 func readNetTCPFile(fc io.Reader, listeningOnly bool) (ports []ServedPort, err error) {
+	return readNetFile(fc, func(fields []string) bool {
+		return !listeningOnly || fields[3] == "0A"
+	}, api.TransportProtocol_transport_tcp)
+}
+
+// readNetUDPFile reads the UDP sockets which are bound, but not connected to a remote address,
+// i.e. the ones which receive datagrams from anyone.
+func readNetUDPFile(fc io.Reader) (ports []ServedPort, err error) {
+	return readNetFile(fc, func(fields []string) bool {
+		if fields[3] != "07" {
+			return false
+		}
+		segs := strings.Split(fields[2], ":")
+		return len(segs) == 2 && strings.Trim(segs[1], "0") == ""
+	}, api.TransportProtocol_transport_udp)
+}
+
+func readNetFile(fc io.Reader, include func(fields []string) bool, transport api.TransportProtocol) (ports []ServedPort, err error) {
 	scanner := bufio.NewScanner(fc)
 	for scanner.Scan() {
 		fields := strings.Fields(scanner.Text())
 		if len(fields) < 4 {
 			continue
 		}
-		if listeningOnly && fields[3] != "0A" {
+		if !include(fields) {
 			continue
 		}
 
@@ -133,7 +165,7 @@ func readNetTCPFile(fc io.Reader, listeningOnly bool) (ports []ServedPort, err e
 		globallyBound := addr == "00000000" || addr == "00000000000000000000000000000000"
 		port, err := strconv.ParseUint(prt, 16, 32)
 		if err != nil {
-			log.WithError(err).WithField("port", prt).Warn("cannot parse port entry from /proc/net/tcp* file")
+			log.WithError(err).WithField("port", prt).Warn("cannot parse port entry from /proc/net file")
 			continue
 		}
 
@@ -141,6 +173,7 @@ func readNetTCPFile(fc io.Reader, listeningOnly bool) (ports []ServedPort, err e
 			BoundToLocalhost: !globallyBound,
 			Address:          addr,
 			Port:             uint32(port),
+			Transport:        transport,
 		})
 	}
 	if err = scanner.Err(); err != nil {
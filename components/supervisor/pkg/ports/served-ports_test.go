@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
 )
 
 const validTCPInput = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
@@ -140,6 +142,9 @@ func TestObserve(t *testing.T) {
 			obs := PollingServedPortsObserver{
 				RefreshInterval: 100 * time.Millisecond,
 				fileOpener: func(fn string) (io.ReadCloser, error) {
+					if strings.HasPrefix(fn, "/proc/net/udp") {
+						return io.NopCloser(strings.NewReader("")), nil
+					}
 					if f >= len(test.FileContents) {
 						return nil, os.ErrNotExist
 					}
@@ -228,3 +233,23 @@ func TestReadNetTCPFile(t *testing.T) {
 		})
 	}
 }
+
+const validUDPInput = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  123: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000 33333        0 57031201 2 0000000000000000 0
+  124: 0100007F:1F90 00000000:0000 07 00000000:00000000 00:00000000 00000000 33333        0 57031202 2 0000000000000000 0
+  125: 0100007F:C35A 0100007F:0035 01 00000000:00000000 00:00000000 00000000 33333        0 57031203 2 0000000000000000 0
+`
+
+func TestReadNetUDPFile(t *testing.T) {
+	act, err := readNetUDPFile(strings.NewReader(validUDPInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectation := []ServedPort{
+		{Address: "00000000", Port: 53, Transport: api.TransportProtocol_transport_udp},
+		{Address: "0100007F", Port: 8080, BoundToLocalhost: true, Transport: api.TransportProtocol_transport_udp},
+	}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+}
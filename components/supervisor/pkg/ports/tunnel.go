@@ -5,7 +5,9 @@
 package ports
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"net"
@@ -21,6 +23,17 @@ type PortTunnelDescription struct {
 	LocalPort  uint32
 	TargetPort uint32
 	Visibility api.TunnelVisiblity
+	Transport  api.TransportProtocol
+}
+
+// tunnelKey identifies a tunnel. TCP and UDP ports with the same number are tunneled independently.
+type tunnelKey struct {
+	LocalPort uint32
+	Transport api.TransportProtocol
+}
+
+func (desc *PortTunnelDescription) key() tunnelKey {
+	return tunnelKey{LocalPort: desc.LocalPort, Transport: desc.Transport}
 }
 
 type PortTunnelState struct {
@@ -50,18 +63,19 @@ type TunneledPortsInterface interface {
 	// After that such clients should call EstablishTunnel to forward incoming connections.
 	Tunnel(ctx context.Context, options *TunnelOptions, descs ...*PortTunnelDescription) ([]uint32, error)
 
-	// CloseTunnel closes tunnels.
-	CloseTunnel(ctx context.Context, localPorts ...uint32) ([]uint32, error)
+	// CloseTunnel closes tunnels of the given transport protocol.
+	CloseTunnel(ctx context.Context, transport api.TransportProtocol, localPorts ...uint32) ([]uint32, error)
 
 	// EstablishTunnel actually establishes the tunnel for an incoming connection on a remote machine.
-	EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32) (net.Conn, error)
+	// UDP tunnels carry datagrams framed as described by api.WriteTunnelDatagram.
+	EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32, transport api.TransportProtocol) (net.Conn, error)
 }
 
 // TunneledPortsService observes the tunneled ports.
 type TunneledPortsService struct {
 	mu      *sync.RWMutex
 	cond    *sync.Cond
-	tunnels map[uint32]*PortTunnel
+	tunnels map[tunnelKey]*PortTunnel
 }
 
 // NewTunneledPortsService creates a new instance
@@ -70,7 +84,7 @@ func NewTunneledPortsService(debugEnable bool) *TunneledPortsService {
 	return &TunneledPortsService{
 		mu:      &mu,
 		cond:    sync.NewCond(&mu),
-		tunnels: make(map[uint32]*PortTunnel),
+		tunnels: make(map[tunnelKey]*PortTunnel),
 	}
 }
 
@@ -89,6 +103,61 @@ func (c *tunnelConn) Close() error {
 	return c.closeErr
 }
 
+// udpTunnelConn carries the datagrams of a connected UDP socket as a stream of frames
+type udpTunnelConn struct {
+	net.Conn
+
+	datagram []byte
+	pending  []byte
+	partial  bytes.Buffer
+}
+
+func newUDPTunnelConn(conn net.Conn) *udpTunnelConn {
+	return &udpTunnelConn{
+		Conn:     conn,
+		datagram: make([]byte, api.MaxTunnelDatagramSize),
+	}
+}
+
+// Read reads the frames of the datagrams received on the socket
+func (c *udpTunnelConn) Read(b []byte) (n int, err error) {
+	if len(c.pending) == 0 {
+		var buf bytes.Buffer
+		n, err = c.Conn.Read(c.datagram)
+		if err != nil {
+			return 0, err
+		}
+		err = api.WriteTunnelDatagram(&buf, c.datagram[:n])
+		if err != nil {
+			return 0, err
+		}
+		c.pending = buf.Bytes()
+	}
+	n = copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// Write sends a datagram to the socket for each complete frame written. Frames can span multiple writes.
+func (c *udpTunnelConn) Write(b []byte) (n int, err error) {
+	c.partial.Write(b)
+	for c.partial.Len() >= 2 {
+		size := int(binary.BigEndian.Uint16(c.partial.Bytes()))
+		if c.partial.Len() < 2+size {
+			break
+		}
+		_, err = api.ReadTunnelDatagram(&c.partial, c.datagram)
+		if err != nil {
+			return 0, err
+		}
+		_, err = c.Conn.Write(c.datagram[:size])
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
 // Observe starts observing the tunneled ports until the context is canceled.
 func (p *TunneledPortsService) Observe(ctx context.Context) (<-chan []PortTunnelState, <-chan error) {
 	var (
@@ -146,7 +215,7 @@ func (p *TunneledPortsService) Tunnel(ctx context.Context, options *TunnelOption
 			}
 			continue
 		}
-		tunnel, tunnelExists := p.tunnels[desc.LocalPort]
+		tunnel, tunnelExists := p.tunnels[desc.key()]
 		if !tunnelExists {
 			tunnel = &PortTunnel{
 				State: PortTunnelState{
@@ -154,7 +223,7 @@ func (p *TunneledPortsService) Tunnel(ctx context.Context, options *TunnelOption
 				},
 				Conns: make(map[string]map[net.Conn]struct{}),
 			}
-			p.tunnels[desc.LocalPort] = tunnel
+			p.tunnels[desc.key()] = tunnel
 		} else if options.SkipIfExists {
 			continue
 		}
@@ -168,16 +237,17 @@ func (p *TunneledPortsService) Tunnel(ctx context.Context, options *TunnelOption
 	return tunneled, err
 }
 
-// CloseTunnel closes tunnels.
-func (p *TunneledPortsService) CloseTunnel(ctx context.Context, localPorts ...uint32) (closedPorts []uint32, err error) {
+// CloseTunnel closes tunnels of the given transport protocol.
+func (p *TunneledPortsService) CloseTunnel(ctx context.Context, transport api.TransportProtocol, localPorts ...uint32) (closedPorts []uint32, err error) {
 	var closed []*PortTunnel
 	p.cond.L.Lock()
 	for _, localPort := range localPorts {
-		tunnel, existsTunnel := p.tunnels[localPort]
+		key := tunnelKey{LocalPort: localPort, Transport: transport}
+		tunnel, existsTunnel := p.tunnels[key]
 		if !existsTunnel {
 			continue
 		}
-		delete(p.tunnels, localPort)
+		delete(p.tunnels, key)
 		closed = append(closed, tunnel)
 		closedPorts = append(closedPorts, localPort)
 	}
@@ -203,12 +273,29 @@ func (p *TunneledPortsService) CloseTunnel(ctx context.Context, localPorts ...ui
 	return closedPorts, err
 }
 
+// dialLocalhost connects to a port on the IPv4 loopback address, or on the IPv6 one if nothing listens on IPv4.
+// We don't dial "localhost", because it may resolve to ::1 only, while most services listen on IPv4.
+// UDP sockets cannot tell whether anybody listens, hence UDP ports are always dialed on IPv4.
+func dialLocalhost(network string, port uint32) (net.Conn, error) {
+	p := strconv.FormatInt(int64(port), 10)
+	conn, err := net.Dial(network, net.JoinHostPort("127.0.0.1", p))
+	if err == nil || network != "tcp" {
+		return conn, err
+	}
+	conn, err6 := net.Dial(network, net.JoinHostPort("::1", p))
+	if err6 != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
 // EstablishTunnel actually establishes the tunnel
-func (p *TunneledPortsService) EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32) (net.Conn, error) {
+func (p *TunneledPortsService) EstablishTunnel(ctx context.Context, clientID string, localPort uint32, targetPort uint32, transport api.TransportProtocol) (net.Conn, error) {
 	p.cond.L.Lock()
 	defer p.cond.L.Unlock()
 
-	tunnel, tunnelExists := p.tunnels[localPort]
+	key := tunnelKey{LocalPort: localPort, Transport: transport}
+	tunnel, tunnelExists := p.tunnels[key]
 	if tunnelExists {
 		expectedTargetPort, clientExists := tunnel.State.Clients[clientID]
 		if clientExists && expectedTargetPort != targetPort {
@@ -218,8 +305,18 @@ func (p *TunneledPortsService) EstablishTunnel(ctx context.Context, clientID str
 		return nil, xerrors.Errorf("client '%s': '%d' tunnel does not exist", clientID, localPort)
 	}
 
-	addr := net.JoinHostPort("localhost", strconv.FormatInt(int64(localPort), 10))
-	conn, err := net.Dial("tcp", addr)
+	var (
+		conn net.Conn
+		err  error
+	)
+	if transport == api.TransportProtocol_transport_udp {
+		conn, err = dialLocalhost("udp", localPort)
+		if err == nil {
+			conn = newUDPTunnelConn(conn)
+		}
+	} else {
+		conn, err = dialLocalhost("tcp", localPort)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +326,7 @@ func (p *TunneledPortsService) EstablishTunnel(ctx context.Context, clientID str
 		onDidClose: func() {
 			p.cond.L.Lock()
 			defer p.cond.L.Unlock()
-			_, existsTunnel := p.tunnels[localPort]
+			_, existsTunnel := p.tunnels[key]
 			if !existsTunnel {
 				return
 			}
@@ -254,15 +351,21 @@ func (p *TunneledPortsService) Snapshot(w io.Writer) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	localPorts := make([]uint32, 0, len(p.tunnels))
+	keys := make([]tunnelKey, 0, len(p.tunnels))
 	for k := range p.tunnels {
-		localPorts = append(localPorts, k)
+		keys = append(keys, k)
 	}
-	sort.Slice(localPorts, func(i, j int) bool { return localPorts[i] < localPorts[j] })
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].LocalPort == keys[j].LocalPort {
+			return keys[i].Transport < keys[j].Transport
+		}
+		return keys[i].LocalPort < keys[j].LocalPort
+	})
 
-	for _, localPort := range localPorts {
-		tunnel := p.tunnels[localPort]
+	for _, key := range keys {
+		tunnel := p.tunnels[key]
 		fmt.Fprintf(w, "Local Port: %d\n", tunnel.State.Desc.LocalPort)
+		fmt.Fprintf(w, "Transport: %s\n", tunnel.State.Desc.Transport)
 		fmt.Fprintf(w, "Target Port: %d\n", tunnel.State.Desc.TargetPort)
 		visibilty := api.TunnelVisiblity_name[int32(tunnel.State.Desc.Visibility)]
 		fmt.Fprintf(w, "Visibility: %s\n", visibilty)
@@ -5,6 +5,7 @@
 package ports
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -118,7 +119,7 @@ func TestLocalPortTunneling(t *testing.T) {
 		}
 		defer src.Close()
 
-		dst, err := service.EstablishTunnel(ctx, "test", localPort, targetPort)
+		dst, err := service.EstablishTunnel(ctx, "test", localPort, targetPort, api.TransportProtocol_transport_tcp)
 		if err != nil {
 			return err
 		}
@@ -152,7 +153,7 @@ func TestLocalPortTunneling(t *testing.T) {
 	}
 	assertUpdate([]PortTunnelState{{Desc: desc, Clients: map[string]uint32{"test": targetPort}}})
 
-	_, err = service.CloseTunnel(ctx, localPort)
+	_, err = service.CloseTunnel(ctx, api.TransportProtocol_transport_tcp, localPort)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -182,3 +183,74 @@ func availablePort() (uint32, error) {
 	}
 	return uint32(port), nil
 }
+
+func TestUDPTunneling(t *testing.T) {
+	echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := echo.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = echo.WriteTo(append(buf[:n:n], '!'), addr)
+		}
+	}()
+	localPort := uint32(echo.LocalAddr().(*net.UDPAddr).Port)
+
+	ctx := context.Background()
+	service := NewTunneledPortsService(false)
+	_, err = service.Tunnel(ctx, &TunnelOptions{}, &PortTunnelDescription{
+		LocalPort:  localPort,
+		TargetPort: localPort,
+		Visibility: api.TunnelVisiblity_host,
+		Transport:  api.TransportProtocol_transport_udp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.EstablishTunnel(ctx, "test", localPort, localPort, api.TransportProtocol_transport_tcp); err == nil {
+		t.Error("established a TCP tunnel for a UDP port")
+	}
+
+	tunnel, err := service.EstablishTunnel(ctx, "test", localPort, localPort, api.TransportProtocol_transport_udp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+
+	// frames can be split across writes
+	var frames bytes.Buffer
+	_ = api.WriteTunnelDatagram(&frames, []byte("hello"))
+	_ = api.WriteTunnelDatagram(&frames, []byte("world"))
+	for _, chunk := range [][]byte{frames.Bytes()[:3], frames.Bytes()[3:9], frames.Bytes()[9:]} {
+		if _, err := tunnel.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := make([]byte, api.MaxTunnelDatagramSize)
+	var act []string
+	for i := 0; i < 2; i++ {
+		n, err := api.ReadTunnelDatagram(tunnel, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		act = append(act, string(buf[:n]))
+	}
+	if diff := cmp.Diff([]string{"hello!", "world!"}, act); diff != "" {
+		t.Errorf("unexpected datagrams (-want +got):\n%s", diff)
+	}
+
+	closed, err := service.CloseTunnel(ctx, api.TransportProtocol_transport_udp, localPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]uint32{localPort}, closed); diff != "" {
+		t.Errorf("unexpected closed tunnels (-want +got):\n%s", diff)
+	}
+}
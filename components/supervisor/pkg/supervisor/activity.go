@@ -0,0 +1,191 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/ports"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+// ActivityKind classifies what happens in a workspace
+type ActivityKind string
+
+const (
+	// ActivityTerminalInput is a user typing into a terminal
+	ActivityTerminalInput ActivityKind = "terminal-input"
+	// ActivityTerminalOutput is a terminal producing output, e.g. a build running in it
+	ActivityTerminalOutput ActivityKind = "terminal-output"
+	// ActivityTask is a task running a command in the foreground of its terminal
+	ActivityTask ActivityKind = "task"
+	// ActivityPortTraffic is a connection from outside the workspace to a served port
+	ActivityPortTraffic ActivityKind = "port-traffic"
+	// ActivitySSH is an SSH session
+	ActivitySSH ActivityKind = "ssh"
+)
+
+var allActivityKinds = []ActivityKind{ActivityTerminalInput, ActivityTerminalOutput, ActivityTask, ActivityPortTraffic, ActivitySSH}
+
+// defaultTimeoutExtendingActivity are the kinds of activity which extend the workspace timeout by default: only those
+// which need a user at the keyboard. Terminal output, tasks and port traffic don't, because a watching dev server
+// produces output forever and public ports are reachable by anyone. Workspaces can opt into them.
+var defaultTimeoutExtendingActivity = []ActivityKind{ActivityTerminalInput, ActivitySSH}
+
+func (k ActivityKind) valid() bool {
+	for _, kind := range allActivityKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+const defaultActivityHeartbeatInterval = 30 * time.Second
+
+// activityTracker observes the activity in the workspace and sends heartbeats for the
+// kinds of activity which extend the workspace timeout, just like an IDE in use does.
+type activityTracker struct {
+	// Extending are the kinds of activity which extend the workspace timeout
+	Extending map[ActivityKind]bool
+	// Heartbeat marks the workspace active. No heartbeats are sent if it's nil.
+	Heartbeat func(ctx context.Context) error
+	// Interval is the time between two heartbeats at most
+	Interval time.Duration
+
+	// SSHPort is the port of the SSH server, connections to it are SSH sessions
+	SSHPort uint32
+	// Ports provides the served ports
+	Ports func() []*api.PortsStatus
+	// Tasks provides the status of the tasks
+	Tasks func() []*api.TaskStatus
+	// Terminals hosts the terminals of the tasks
+	Terminals *terminal.Mux
+
+	connections func() ([]ports.Connection, error)
+
+	mu            sync.Mutex
+	lastSeen      map[ActivityKind]time.Time
+	lastHeartbeat time.Time
+}
+
+func newActivityTracker(extending map[ActivityKind]bool) *activityTracker {
+	return &activityTracker{
+		Extending:   extending,
+		Interval:    defaultActivityHeartbeatInterval,
+		connections: ports.EstablishedConnections,
+		lastSeen:    make(map[ActivityKind]time.Time),
+	}
+}
+
+// Observe records activity of a kind
+func (a *activityTracker) Observe(kind ActivityKind) {
+	a.mu.Lock()
+	a.lastSeen[kind] = time.Now()
+	a.mu.Unlock()
+}
+
+// TerminalInput implements terminal.ActivityListener
+func (a *activityTracker) TerminalInput(alias string) {
+	a.Observe(ActivityTerminalInput)
+}
+
+// TerminalOutput implements terminal.ActivityListener
+func (a *activityTracker) TerminalOutput(alias string) {
+	a.Observe(ActivityTerminalOutput)
+}
+
+// Run observes tasks and connections, and sends heartbeats until the context is canceled
+func (a *activityTracker) Run(ctx context.Context) {
+	tick := time.NewTicker(a.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+
+		a.poll()
+		kinds := a.activeSince(a.lastHeartbeat)
+		if len(kinds) == 0 || a.Heartbeat == nil {
+			continue
+		}
+
+		err := a.Heartbeat(ctx)
+		if err != nil {
+			log.WithError(err).Warn("cannot send heartbeat")
+			continue
+		}
+		log.WithField("activity", kinds).Debug("sent heartbeat")
+		a.mu.Lock()
+		a.lastHeartbeat = time.Now()
+		a.mu.Unlock()
+	}
+}
+
+// poll observes the activity which cannot be recorded as it happens
+func (a *activityTracker) poll() {
+	if a.Tasks != nil && a.Terminals != nil {
+		for _, task := range a.Tasks() {
+			if task.State != api.TaskState_running || task.Terminal == "" {
+				continue
+			}
+			term, ok := a.Terminals.Get(task.Terminal)
+			if ok && term.Busy() {
+				a.Observe(ActivityTask)
+				break
+			}
+		}
+	}
+
+	conns, err := a.connections()
+	if err != nil {
+		log.WithError(err).Debug("cannot observe connections")
+		return
+	}
+	served := make(map[uint32]struct{})
+	if a.Ports != nil {
+		for _, p := range a.Ports() {
+			if !p.Served {
+				continue
+			}
+			served[p.LocalPort] = struct{}{}
+			served[p.GlobalPort] = struct{}{}
+		}
+	}
+	for _, conn := range conns {
+		if conn.FromLocalhost {
+			continue
+		}
+		if a.SSHPort != 0 && conn.LocalPort == a.SSHPort {
+			a.Observe(ActivitySSH)
+			continue
+		}
+		if _, ok := served[conn.LocalPort]; ok {
+			a.Observe(ActivityPortTraffic)
+		}
+	}
+}
+
+// activeSince returns the kinds of timeout extending activity observed since t
+func (a *activityTracker) activeSince(t time.Time) []ActivityKind {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var res []ActivityKind
+	for _, kind := range allActivityKinds {
+		seen, ok := a.lastSeen[kind]
+		if ok && a.Extending[kind] && seen.After(t) {
+			res = append(res, kind)
+		}
+	}
+	return res
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/ports"
+)
+
+func TestActivityTrackerPoll(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Connections []ports.Connection
+		Expectation []ActivityKind
+	}{
+		{
+			Name: "no connections",
+		},
+		{
+			Name: "ssh session",
+			Connections: []ports.Connection{
+				{LocalPort: 23001, RemotePort: 40000},
+			},
+			Expectation: []ActivityKind{ActivitySSH},
+		},
+		{
+			Name: "port traffic",
+			Connections: []ports.Connection{
+				{LocalPort: 3000, RemotePort: 40000},
+				{LocalPort: 60000, RemotePort: 8080},
+			},
+			Expectation: []ActivityKind{ActivityPortTraffic},
+		},
+		{
+			Name: "port traffic to proxy",
+			Connections: []ports.Connection{
+				{LocalPort: 58080, RemotePort: 40000},
+			},
+			Expectation: []ActivityKind{ActivityPortTraffic},
+		},
+		{
+			Name: "connections from within the workspace",
+			Connections: []ports.Connection{
+				{LocalPort: 23001, RemotePort: 40000, FromLocalhost: true},
+				{LocalPort: 3000, RemotePort: 40001, FromLocalhost: true},
+			},
+		},
+		{
+			Name: "unserved ports",
+			Connections: []ports.Connection{
+				{LocalPort: 5000, RemotePort: 40000},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			tracker := newActivityTracker(map[ActivityKind]bool{ActivitySSH: true, ActivityPortTraffic: true})
+			tracker.SSHPort = 23001
+			tracker.Ports = func() []*api.PortsStatus {
+				return []*api.PortsStatus{
+					{LocalPort: 3000, GlobalPort: 3000, Served: true},
+					{LocalPort: 8080, GlobalPort: 58080, Served: true},
+					{LocalPort: 5000, GlobalPort: 5000},
+				}
+			}
+			tracker.connections = func() ([]ports.Connection, error) {
+				return test.Connections, nil
+			}
+
+			tracker.poll()
+			act := tracker.activeSince(time.Time{})
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected activity (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestActivityTrackerHeartbeat(t *testing.T) {
+	var heartbeats int32
+	tracker := newActivityTracker(map[ActivityKind]bool{ActivityTerminalInput: true})
+	tracker.Interval = 10 * time.Millisecond
+	tracker.connections = func() ([]ports.Connection, error) { return nil, nil }
+	tracker.Heartbeat = func(ctx context.Context) error {
+		atomic.AddInt32(&heartbeats, 1)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Run(ctx)
+
+	// activity which doesn't extend the timeout sends no heartbeat
+	tracker.TerminalOutput("term-1")
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&heartbeats); n != 0 {
+		t.Fatalf("expected no heartbeats, got %d", n)
+	}
+
+	tracker.TerminalInput("term-1")
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&heartbeats); n != 1 {
+		t.Fatalf("expected a single heartbeat per activity, got %d", n)
+	}
+}
+
+func TestTimeoutExtendingActivity(t *testing.T) {
+	type Expectation struct {
+		Kinds map[ActivityKind]bool
+		Err   bool
+	}
+	tests := []struct {
+		Input       string
+		Expectation Expectation
+	}{
+		{Input: "", Expectation: Expectation{Kinds: map[ActivityKind]bool{ActivityTerminalInput: true, ActivitySSH: true}}},
+		{Input: "terminal-input,terminal-output,ssh", Expectation: Expectation{Kinds: map[ActivityKind]bool{ActivityTerminalInput: true, ActivityTerminalOutput: true, ActivitySSH: true}}},
+		{Input: "task, port-traffic", Expectation: Expectation{Kinds: map[ActivityKind]bool{ActivityTask: true, ActivityPortTraffic: true}}},
+		{Input: "ssh,", Expectation: Expectation{Kinds: map[ActivityKind]bool{ActivitySSH: true}}},
+		{Input: "ssh,typing", Expectation: Expectation{Err: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			var act Expectation
+			kinds, err := WorkspaceConfig{ActivityKinds: test.Input}.TimeoutExtendingActivity()
+			act.Kinds = kinds
+			act.Err = err != nil
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	env "github.com/Netflix/go-env"
@@ -36,6 +37,9 @@ type Config struct {
 	StaticConfig
 	IDEConfig
 	WorkspaceConfig
+
+	// IDEBackends are the IDE backends the workspace runs next to the IDE, e.g. desktop IDE backends
+	IDEBackends []IDEConfig
 }
 
 // Validate validates the configuration
@@ -50,16 +54,38 @@ func (c Config) Validate() error {
 		return fmt.Errorf("Workspace config is invalid: %w", err)
 	}
 
+	names := map[string]struct{}{c.IDEConfig.name(): {}}
+	ports := map[int]struct{}{c.IDEPort: {}, c.APIEndpointPort: {}, c.SSHPort: {}}
+	for _, backend := range c.IDEBackends {
+		if err := backend.Validate(); err != nil {
+			return fmt.Errorf("IDE backend config %s is invalid: %w", backend.Name, err)
+		}
+		if backend.Name == "" {
+			return fmt.Errorf("IDE backend config is invalid: name is required")
+		}
+		if _, exists := names[backend.Name]; exists {
+			return fmt.Errorf("IDE backend config %s is invalid: name is not unique", backend.Name)
+		}
+		names[backend.Name] = struct{}{}
+		if !(0 < backend.Port && backend.Port <= math.MaxUint16) {
+			return fmt.Errorf("IDE backend config %s is invalid: port must be between 0 and %d", backend.Name, math.MaxUint16)
+		}
+		if _, exists := ports[backend.Port]; exists {
+			return fmt.Errorf("IDE backend config %s is invalid: port %d is in use already", backend.Name, backend.Port)
+		}
+		ports[backend.Port] = struct{}{}
+	}
+
 	return nil
 }
 
-// LogRateLimit returns the log rate limit for the IDE process in kib/sec.
+// LogRateLimit returns the log rate limit for the process of an IDE in kib/sec.
 // If log rate limiting is disbaled, this function returns 0.
-func (c Config) LogRateLimit() int {
-	if c.WorkspaceLogRateLimit < c.IDELogRateLimit {
+func (c Config) LogRateLimit(ide IDEConfig) int {
+	if c.WorkspaceLogRateLimit < ide.IDELogRateLimit {
 		return c.WorkspaceLogRateLimit
 	}
-	return c.IDELogRateLimit
+	return ide.IDELogRateLimit
 }
 
 // StaticConfig is the supervisor-wide configuration
@@ -67,6 +93,10 @@ type StaticConfig struct {
 	// IDEConfigLocation is a path in the filesystem where to find the IDE configuration
 	IDEConfigLocation string `json:"ideConfigLocation"`
 
+	// IDEBackendsLocation is the directory of the IDE backends the IDE image provides. Each backend has its own
+	// directory, named like the backend, containing its IDE configuration, e.g. /ide/backends/intellij/supervisor-ide-config.json.
+	IDEBackendsLocation string `json:"ideBackendsLocation,omitempty"`
+
 	// FrontendLocation is a path in the filesystem where to find supervisor's frontend assets
 	FrontendLocation string `json:"frontendLocation"`
 
@@ -75,6 +105,33 @@ type StaticConfig struct {
 
 	// SSHPort is the port we run the SSH server on
 	SSHPort int `json:"sshPort"`
+
+	// TerminalRecordingLocation is the directory where terminal sessions are recorded to.
+	// Recording terminal sessions is disabled if this is empty. Only terminals which ask for it, and prebuild tasks,
+	// are recorded. Within /workspace recordings are part of the backup and can be replayed after a restart or
+	// in workspaces started from a prebuild, hence their size and number are limited.
+	TerminalRecordingLocation string `json:"terminalRecordingLocation,omitempty"`
+
+	// TerminalRecordingMaxSize is the size in bytes at which a terminal recording is rotated.
+	// Defaults to terminal.DefaultRecordingMaxSize if zero.
+	TerminalRecordingMaxSize int64 `json:"terminalRecordingMaxSize,omitempty"`
+
+	// TerminalRecordingRetention is the number of recordings kept, older ones are removed.
+	// Defaults to terminal.DefaultRecordingRetention if zero.
+	TerminalRecordingRetention int `json:"terminalRecordingRetention,omitempty"`
+
+	// TokenCacheLocation is the directory where the token cache is persisted to, encrypted with a key derived
+	// from THEIA_SUPERVISOR_TOKENS.
+	// If set, cached tokens survive supervisor restarts. Otherwise they're kept in memory only.
+	TokenCacheLocation string `json:"tokenCacheLocation,omitempty"`
+
+	// SecretFileLocation is the directory the file secret provider reads secrets from, e.g. secret://file/db-password.
+	// The file secret provider is disabled if this is empty.
+	SecretFileLocation string `json:"secretFileLocation,omitempty"`
+
+	// NotificationStoreLocation is the file where the notification history is persisted to.
+	// If set, notifications survive supervisor restarts. Otherwise they're kept in memory only.
+	NotificationStoreLocation string `json:"notificationStoreLocation,omitempty"`
 }
 
 // Validate validates this configuration
@@ -104,33 +161,111 @@ const (
 
 	// ReadinessHTTPProbe returns ready once a single HTTP request against the IDE was successful
 	ReadinessHTTPProbe ReadinessProbeType = "http"
+
+	// ReadinessTCPProbe returns ready once a TCP connection to the IDE could be established
+	ReadinessTCPProbe ReadinessProbeType = "tcp"
+
+	// ReadinessExecProbe returns ready once a command exited with 0
+	ReadinessExecProbe ReadinessProbeType = "exec"
 )
 
+// IDEProbe configures how supervisor checks an IDE
+type IDEProbe struct {
+	// Type determines the type of probe we'll use.
+	// Defaults to process.
+	Type ReadinessProbeType `json:"type"`
+
+	// HTTPProbe configures the HTTP probe.
+	HTTPProbe struct {
+		// Path is the path to make requests to. Defaults to "/"
+		Path string `json:"path"`
+	} `json:"http"`
+
+	// TCPProbe configures the TCP probe.
+	TCPProbe struct {
+		// Port is the port to connect to. Defaults to the port the IDE listens on.
+		Port int `json:"port"`
+	} `json:"tcp"`
+
+	// ExecProbe configures the exec probe.
+	ExecProbe struct {
+		// Command is the command to run, followed by its arguments. It runs as gitpod user in the workspace root.
+		Command []string `json:"command"`
+	} `json:"exec"`
+
+	// TimeoutSeconds is how long a single check may take. Defaults to 1 second.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// PeriodSeconds is the time between two checks. Defaults to half a second for
+	// readiness probes and 10 seconds for liveness probes.
+	PeriodSeconds int `json:"periodSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed checks after which the IDE is restarted.
+	// Readiness probes never give up by default, liveness probes give up after 3 failed checks.
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+}
+
+// Validate validates this configuration
+func (p IDEProbe) Validate() error {
+	switch p.Type {
+	case ReadinessProcessProbe, ReadinessHTTPProbe:
+	case ReadinessTCPProbe:
+		if !(0 <= p.TCPProbe.Port && p.TCPProbe.Port <= math.MaxUint16) {
+			return fmt.Errorf("tcp.port must be between 0 and %d", math.MaxUint16)
+		}
+	case ReadinessExecProbe:
+		if len(p.ExecProbe.Command) == 0 {
+			return fmt.Errorf("exec.command is required")
+		}
+	default:
+		return fmt.Errorf("unknown probe type %s", p.Type)
+	}
+	if p.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds must be >= 0")
+	}
+	if p.PeriodSeconds < 0 {
+		return fmt.Errorf("periodSeconds must be >= 0")
+	}
+	if p.FailureThreshold < 0 {
+		return fmt.Errorf("failureThreshold must be >= 0")
+	}
+	return nil
+}
+
+// defaultIDEName names the IDE in the IDE status unless its configuration provides a name
+const defaultIDEName = "ide"
+
 // IDEConfig is the IDE specific configuration
 type IDEConfig struct {
+	// Name identifies the IDE in the IDE status. It is required for IDE backends.
+	Name string `json:"name,omitempty"`
+
+	// Port is the port an IDE backend listens on. The IDE itself listens on the port
+	// Gitpod determines (GITPOD_THEIA_PORT) instead.
+	Port int `json:"port,omitempty"`
+
 	// Entrypoint is the command that gets executed by supervisor to start
 	// the IDE process. If this command exits, supervisor will start it again.
 	// If this command exits right after it was started with a non-zero exit
 	// code the workspace is stopped.
 	Entrypoint string `json:"entrypoint"`
 
+	// EntrypointArgs are the arguments the entrypoint is started with. {WORKSPACEROOT} and {IDEPORT} are replaced
+	// with the workspace root and the port the IDE is to listen on.
+	// Defaults to {WORKSPACEROOT} --port {IDEPORT} --hostname 0.0.0.0.
+	EntrypointArgs []string `json:"entrypointArgs,omitempty"`
+
 	// LogRateLimit can be used to limit the log output of the IDE process.
 	// Any output that exceeds this limit is silently dropped.
 	// Expressed in kb/sec. Can be overriden by the workspace config (smallest value wins).
 	IDELogRateLimit int `json:"logRateLimit"`
 
 	// ReadinessProbe configures the probe used to serve the IDE status
-	ReadinessProbe struct {
-		// Type determines the type of readiness probe we'll use.
-		// Defaults to process.
-		Type ReadinessProbeType `json:"type"`
-
-		// HTTPProbe configures the HTTP readiness probe.
-		HTTPProbe struct {
-			// Path is the path to make requests to. Defaults to "/"
-			Path string `json:"path"`
-		} `json:"http"`
-	} `json:"readinessProbe"`
+	ReadinessProbe IDEProbe `json:"readinessProbe"`
+
+	// LivenessProbe configures the probe used to restart the IDE once it stopped responding.
+	// It runs once the IDE is ready. The process type disables the liveness probe.
+	LivenessProbe IDEProbe `json:"livenessProbe"`
 }
 
 // Validate validates this configuration
@@ -148,9 +283,23 @@ func (c IDEConfig) Validate() error {
 		return fmt.Errorf("logRateLimit must be >= 0")
 	}
 
+	if err := c.ReadinessProbe.Validate(); err != nil {
+		return fmt.Errorf("readinessProbe is invalid: %w", err)
+	}
+	if err := c.LivenessProbe.Validate(); err != nil {
+		return fmt.Errorf("livenessProbe is invalid: %w", err)
+	}
+
 	return nil
 }
 
+func (c IDEConfig) name() string {
+	if c.Name == "" {
+		return defaultIDEName
+	}
+	return c.Name
+}
+
 // WorkspaceConfig is the workspace specific configuration. This config is drawn exclusively
 // from environment variables.
 type WorkspaceConfig struct {
@@ -188,6 +337,9 @@ type WorkspaceConfig struct {
 	// Tokens is a JSON encoded list of WorkspaceGitpodToken
 	Tokens string `env:"THEIA_SUPERVISOR_TOKENS"`
 
+	// OwnerToken grants the owner access to the workspace, e.g. as password of the SSH server
+	OwnerToken string `env:"THEIA_SUPERVISOR_OWNER_TOKEN"`
+
 	// WorkspaceID is the ID of the workspace
 	WorkspaceID string `env:"GITPOD_WORKSPACE_ID"`
 
@@ -203,6 +355,10 @@ type WorkspaceConfig struct {
 	// GitpodHeadless controls whether the workspace is running headless
 	GitpodHeadless string `env:"GITPOD_HEADLESS"`
 
+	// IDEBackendNames is a comma separated list of the IDE backends the workspace runs next to the IDE,
+	// as chosen when the workspace was started. Their configuration is found in IDEBackendsLocation.
+	IDEBackendNames string `env:"SUPERVISOR_IDE_BACKENDS"`
+
 	// DebugEnabled controls whether the supervisor debugging facilities (pprof, grpc tracing) shoudl be enabled
 	DebugEnable bool `env:"SUPERVISOR_DEBUG_ENABLE"`
 
@@ -211,6 +367,21 @@ type WorkspaceConfig struct {
 
 	// WorkspaceClusterHost is a host under which this workspace is served, e.g. ws-eu11.gitpod.io
 	WorkspaceClusterHost string `env:"GITPOD_WORKSPACE_CLUSTER_HOST"`
+
+	// VaultAddr is the address of the Vault server secret://vault/ references are resolved with
+	VaultAddr string `env:"VAULT_ADDR"`
+
+	// VaultToken authenticates with the Vault server unless the token service provides a token of kind vault
+	VaultToken string `env:"VAULT_TOKEN"`
+
+	// VaultSecretPaths is a comma separated list of patterns of the Vault paths secret://vault/ references may
+	// read, e.g. secret/data/gitpod-io/*. Being a user environment variable, it can be scoped to repositories.
+	// Vault references are not resolved if this is empty.
+	VaultSecretPaths string `env:"GITPOD_VAULT_SECRET_PATHS"`
+
+	// ActivityKinds is a comma separated list of the kinds of activity which extend the workspace timeout,
+	// e.g. terminal-input,terminal-output,ssh. Defaults to terminal input and SSH sessions.
+	ActivityKinds string `env:"SUPERVISOR_ACTIVITY_KINDS"`
 }
 
 // WorkspaceGitpodToken is a list of tokens that should be added to supervisor's token service
@@ -229,6 +400,27 @@ type TaskConfig struct {
 	Env      *map[string]string `json:"env,omitempty"`
 	OpenIn   *string            `json:"openIn,omitempty"`
 	OpenMode *string            `json:"openMode,omitempty"`
+
+	DependsOn *[]string            `json:"dependsOn,omitempty"`
+	Readiness *TaskReadinessConfig `json:"readiness,omitempty"`
+
+	RestartPolicy *string                `json:"restartPolicy,omitempty"`
+	HealthCheck   *TaskHealthCheckConfig `json:"healthCheck,omitempty"`
+}
+
+// TaskReadinessConfig defines when a task is considered ready
+type TaskReadinessConfig struct {
+	Port    *int    `json:"port,omitempty"`
+	File    *string `json:"file,omitempty"`
+	Command *string `json:"command,omitempty"`
+}
+
+// TaskHealthCheckConfig defines how to check if a running task is healthy
+type TaskHealthCheckConfig struct {
+	Port             *int    `json:"port,omitempty"`
+	Command          *string `json:"command,omitempty"`
+	Interval         *int    `json:"interval,omitempty"`
+	FailureThreshold *int    `json:"failureThreshold,omitempty"`
 }
 
 // Validate validates this configuration
@@ -253,9 +445,50 @@ func (c WorkspaceConfig) Validate() error {
 		return err
 	}
 
+	if _, err := c.TimeoutExtendingActivity(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// TimeoutExtendingActivity parses the kinds of activity which extend the workspace timeout from SUPERVISOR_ACTIVITY_KINDS
+func (c WorkspaceConfig) TimeoutExtendingActivity() (map[ActivityKind]bool, error) {
+	if strings.TrimSpace(c.ActivityKinds) == "" {
+		res := make(map[ActivityKind]bool, len(defaultTimeoutExtendingActivity))
+		for _, kind := range defaultTimeoutExtendingActivity {
+			res[kind] = true
+		}
+		return res, nil
+	}
+
+	res := make(map[ActivityKind]bool)
+	for _, kind := range strings.Split(c.ActivityKinds, ",") {
+		kind := ActivityKind(strings.TrimSpace(kind))
+		if kind == "" {
+			continue
+		}
+		if !kind.valid() {
+			return nil, fmt.Errorf("SUPERVISOR_ACTIVITY_KINDS: unknown activity kind %s", kind)
+		}
+		res[kind] = true
+	}
+	return res, nil
+}
+
+// VaultPaths parses the patterns of the Vault paths secret references may read from GITPOD_VAULT_SECRET_PATHS
+func (c WorkspaceConfig) VaultPaths() []string {
+	var res []string
+	for _, p := range strings.Split(c.VaultSecretPaths, ",") {
+		p = strings.Trim(strings.TrimSpace(p), "/")
+		if p == "" {
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
 // GetTokens parses tokens from GITPOD_TOKENS and possibly downloads OTS.
 func (c WorkspaceConfig) GetTokens(downloadOTS bool) ([]WorkspaceGitpodToken, error) {
 	if c.Tokens == "" {
@@ -359,10 +592,16 @@ func GetConfig() (*Config, error) {
 		return nil, err
 	}
 
+	backends, err := loadIDEBackendConfigs(static.IDEBackendsLocation, workspace.IDEBackendNames)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		StaticConfig:    *static,
 		IDEConfig:       *ide,
 		WorkspaceConfig: *workspace,
+		IDEBackends:     backends,
 	}, nil
 }
 
@@ -407,6 +646,34 @@ func loadIDEConfigFromFile(fn string) (*IDEConfig, error) {
 	return &res, nil
 }
 
+// loadIDEBackendConfigs loads the IDE configuration of the comma separated IDE backends in names from location.
+// A backend is named like its directory unless its configuration provides a name.
+func loadIDEBackendConfigs(location, names string) ([]IDEConfig, error) {
+	var res []IDEConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if location == "" {
+			return nil, xerrors.Errorf("cannot load IDE backend %s: the IDE does not provide backends", name)
+		}
+		if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return nil, xerrors.Errorf("invalid IDE backend name %q", name)
+		}
+
+		backend, err := loadIDEConfigFromFile(filepath.Join(location, name, "supervisor-ide-config.json"))
+		if err != nil {
+			return nil, err
+		}
+		if backend.Name == "" {
+			backend.Name = name
+		}
+		res = append(res, *backend)
+	}
+	return res, nil
+}
+
 // loadWorkspaceConfigFromEnv loads the workspace configuration from environment variables.
 func loadWorkspaceConfigFromEnv() (*WorkspaceConfig, error) {
 	var res WorkspaceConfig
@@ -0,0 +1,91 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+// configValidationReporter notifies the user about problems in .gitpod.yml, which would otherwise
+// go unnoticed because invalid parts of the config are silently ignored.
+type configValidationReporter struct {
+	Location      string
+	Notifications *NotificationService
+
+	// reported is the message of the last notification, which isn't repeated until the problems change
+	reported string
+}
+
+// Run validates .gitpod.yml whenever the config changes. This function does not return
+// until the context is canceled.
+func (r *configValidationReporter) Run(ctx context.Context, wg *sync.WaitGroup, cfgobs gitpod.ConfigInterface) {
+	defer wg.Done()
+
+	cfgs, errs := cfgobs.Observe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-cfgs:
+			if !ok {
+				cfgs = nil
+				continue
+			}
+			r.validate(ctx)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			log.WithError(err).Warn("cannot observe .gitpod.yml for validation")
+		}
+	}
+}
+
+func (r *configValidationReporter) validate(ctx context.Context) {
+	content, err := os.ReadFile(r.Location)
+	if os.IsNotExist(err) {
+		r.reported = ""
+		return
+	}
+	if err != nil {
+		log.WithError(err).WithField("location", r.Location).Warn("cannot read .gitpod.yml for validation")
+		return
+	}
+
+	problems := gitpod.ValidateConfig(content)
+	if len(problems) == 0 {
+		r.reported = ""
+		return
+	}
+	message := fmt.Sprintf("Invalid .gitpod.yml, %s", problems[0])
+	switch {
+	case len(problems) == 2:
+		message += " (and 1 more problem, run 'gp validate' for details)"
+	case len(problems) > 2:
+		message += fmt.Sprintf(" (and %d more problems, run 'gp validate' for details)", len(problems)-1)
+	}
+	if message == r.reported {
+		return
+	}
+
+	log.WithField("problems", problems).Info(".gitpod.yml is invalid")
+	_, err = r.Notifications.Notify(ctx, &api.NotifyRequest{
+		Level:   api.NotifyRequest_WARNING,
+		Message: message,
+	})
+	if err != nil {
+		log.WithError(err).Warn("cannot notify about an invalid .gitpod.yml")
+		return
+	}
+	r.reported = message
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigValidationReporter(t *testing.T) {
+	ctx := context.Background()
+	location := filepath.Join(t.TempDir(), ".gitpod.yml")
+	notifications := NewNotificationService()
+	reporter := &configValidationReporter{Location: location, Notifications: notifications}
+
+	messages := func() []string {
+		var res []string
+		for _, n := range notifications.notifications {
+			res = append(res, n.Request.Message)
+		}
+		return res
+	}
+	update := func(content string) {
+		t.Helper()
+		err := os.WriteFile(location, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reporter.validate(ctx)
+	}
+
+	// a missing config is fine
+	reporter.validate(ctx)
+	update("tasks:\n  - command: make\n")
+	update("tasks:\n  - comand: make\n")
+	// unchanged problems are not reported again
+	update("tasks:\n  - comand: make\n")
+	update("tasks:\n  - comand: make\n    openMode: split\n")
+	update("tasks:\n  - command: make\n")
+	update("tasks:\n  - comand: make\n")
+
+	invalidKey := `Invalid .gitpod.yml, line 2, column 5: tasks[0].comand: unknown key "comand", did you mean "command"?`
+	expected := []string{
+		invalidKey,
+		invalidKey + " (and 1 more problem, run 'gp validate' for details)",
+		invalidKey,
+	}
+	if diff := cmp.Diff(expected, messages()); diff != "" {
+		t.Errorf("unexpected notifications (-want +got):\n%s", diff)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
 	"github.com/gitpod-io/gitpod/supervisor/api"
@@ -78,14 +79,44 @@ func (p *GitTokenProvider) GetToken(ctx context.Context, req *api.GetTokenReques
 		}
 		return nil, nil
 	}
-	tkn = &Token{
+	return asGitToken(req.Host, token, scopes, api.TokenReuse_REUSE_NEVER), nil
+}
+
+// RefreshToken asks the Gitpod server for a token which expires later than tkn.
+// The server refreshes tokens which are about to expire with the Git hosting service.
+func (p *GitTokenProvider) RefreshToken(ctx context.Context, kind string, tkn *Token) (*Token, error) {
+	if p.gitpodAPI == nil {
+		return nil, nil
+	}
+	token, err := p.gitpodAPI.GetToken(ctx, &gitpod.GetTokenSearchOptions{
+		Host: tkn.Host,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.Value == "" {
+		return nil, nil
+	}
+	return asGitToken(tkn.Host, token, mapScopes(token.Scopes), tkn.Reuse), nil
+}
+
+// asGitToken converts a token from the Gitpod server. Whether the token is reused is up to the caller,
+// an expiry date only tells until when it may be.
+func asGitToken(host string, token *gitpod.Token, scopes map[string]struct{}, reuse api.TokenReuse) *Token {
+	tkn := &Token{
 		User:  token.Username,
 		Token: token.Value,
-		Host:  req.Host,
+		Host:  host,
 		Scope: scopes,
-		Reuse: api.TokenReuse_REUSE_NEVER,
+		Reuse: reuse,
+	}
+	if token.ExpiryDate != "" {
+		expiry, err := time.Parse(time.RFC3339, token.ExpiryDate)
+		if err == nil {
+			tkn.ExpiryDate = &expiry
+		}
 	}
-	return tkn, nil
+	return tkn
 }
 
 func getMissingScopes(required []string, provided map[string]struct{}) []string {
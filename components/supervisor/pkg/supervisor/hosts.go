@@ -0,0 +1,255 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	daemonapi "github.com/gitpod-io/gitpod/ws-daemon/api"
+)
+
+const (
+	// iwsProxySocket is where workspacekit offers the in-workspace service to supervisor
+	iwsProxySocket = "/tmp/workspacekit-iws/iws.socket"
+
+	hostsRetryInterval = 5 * time.Second
+	hostsUpdateTimeout = 10 * time.Second
+)
+
+// HostsSetter replaces the entries ws-daemon maintains in the workspace's /etc/hosts file
+type HostsSetter interface {
+	SetHosts(ctx context.Context, hosts []*daemonapi.HostEntry) error
+}
+
+// iwsHostsSetter sets the hosts through the in-workspace service proxy of workspacekit
+type iwsHostsSetter struct {
+	Socket string
+}
+
+func (s *iwsHostsSetter) SetHosts(ctx context.Context, hosts []*daemonapi.HostEntry) error {
+	conn, err := grpc.DialContext(ctx, "unix://"+s.Socket, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = daemonapi.NewInWorkspaceServiceClient(conn).SetHosts(ctx, &daemonapi.SetHostsRequest{Hosts: hosts})
+	return err
+}
+
+// NewHostsService creates a new hosts service
+func NewHostsService(setter HostsSetter) *HostsService {
+	return &HostsService{
+		setter: setter,
+		config: make(map[string]string),
+		added:  make(map[string]string),
+	}
+}
+
+// HostsService maintains the additional entries of the workspace's /etc/hosts file.
+// Entries come from .gitpod.yml and the hosts API, where the latter take precedence.
+type HostsService struct {
+	setter HostsSetter
+
+	mu      sync.Mutex
+	config  map[string]string
+	added   map[string]string
+	applied bool
+
+	api.UnimplementedHostsServiceServer
+}
+
+// RegisterGRPC registers a gRPC service
+func (s *HostsService) RegisterGRPC(srv *grpc.Server) {
+	api.RegisterHostsServiceServer(srv, s)
+}
+
+// RegisterREST registers a REST service
+func (s *HostsService) RegisterREST(mux *runtime.ServeMux, grpcEndpoint string) error {
+	return api.RegisterHostsServiceHandlerFromEndpoint(context.Background(), mux, grpcEndpoint, []grpc.DialOption{grpc.WithInsecure()})
+}
+
+// Run applies the hosts of .gitpod.yml whenever the config changes. This function does not return
+// until the context is canceled.
+func (s *HostsService) Run(ctx context.Context, wg *sync.WaitGroup, cfgobs gitpod.ConfigInterface) {
+	defer wg.Done()
+
+	retry := time.NewTicker(hostsRetryInterval)
+	defer retry.Stop()
+
+	cfgs, errs := cfgobs.Observe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfg, ok := <-cfgs:
+			if !ok {
+				cfgs = nil
+				continue
+			}
+			s.updateConfig(ctx, cfg)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			log.WithError(err).Warn("cannot observe .gitpod.yml for hosts changes")
+		case <-retry.C:
+			s.retry(ctx)
+		}
+	}
+}
+
+func (s *HostsService) updateConfig(ctx context.Context, cfg *gitpod.GitpodConfig) {
+	hosts := make(map[string]string)
+	if cfg != nil {
+		for _, h := range cfg.Hosts {
+			if h == nil || h.Name == "" || net.ParseIP(h.Ip) == nil {
+				log.WithField("host", h).Warn("ignoring invalid hosts entry in .gitpod.yml")
+				continue
+			}
+			hosts[h.Name] = h.Ip
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.applied && reflect.DeepEqual(hosts, s.config) {
+		return
+	}
+	err := s.set(ctx, hosts, s.added)
+	if err != nil {
+		// we keep the config nonetheless and retry later
+		s.config = hosts
+		s.applied = false
+		log.WithError(err).Warn("cannot apply hosts from .gitpod.yml - will retry")
+	}
+}
+
+func (s *HostsService) retry(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.applied {
+		return
+	}
+	err := s.set(ctx, s.config, s.added)
+	if err != nil {
+		log.WithError(err).Debug("cannot apply hosts - will retry")
+	}
+}
+
+// set applies the hosts and makes them the current ones if successful. Callers must hold mu.
+func (s *HostsService) set(ctx context.Context, config, added map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, hostsUpdateTimeout)
+	defer cancel()
+
+	var hosts []*daemonapi.HostEntry
+	for _, e := range hostEntries(config, added) {
+		hosts = append(hosts, &daemonapi.HostEntry{Name: e.Name, Addr: e.Addr})
+	}
+	err := s.setter.SetHosts(ctx, hosts)
+	if err != nil {
+		return err
+	}
+
+	s.config = config
+	s.added = added
+	s.applied = true
+	return nil
+}
+
+// hostEntries merges the hosts from .gitpod.yml and the API, sorted by name
+func hostEntries(config, added map[string]string) []*api.HostEntry {
+	res := make([]*api.HostEntry, 0, len(config)+len(added))
+	for name, addr := range config {
+		if _, overridden := added[name]; overridden {
+			continue
+		}
+		res = append(res, &api.HostEntry{Name: name, Addr: addr, Source: api.HostSource_from_config})
+	}
+	for name, addr := range added {
+		res = append(res, &api.HostEntry{Name: name, Addr: addr, Source: api.HostSource_from_api})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// List returns all additional hosts entries
+func (s *HostsService) List(ctx context.Context, req *api.ListHostsRequest) (*api.ListHostsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &api.ListHostsResponse{Hosts: hostEntries(s.config, s.added)}, nil
+}
+
+// Add maps a hostname to an IP address
+func (s *HostsService) Add(ctx context.Context, req *api.AddHostRequest) (*api.AddHostResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if net.ParseIP(req.Addr) == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%q is not a valid IP address", req.Addr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := make(map[string]string, len(s.added)+1)
+	for name, addr := range s.added {
+		added[name] = addr
+	}
+	added[req.Name] = req.Addr
+
+	err := s.set(ctx, s.config, added)
+	if err != nil {
+		return nil, hostsUpdateError(err)
+	}
+	return &api.AddHostResponse{}, nil
+}
+
+// Remove removes a hostname previously added through Add
+func (s *HostsService) Remove(ctx context.Context, req *api.RemoveHostRequest) (*api.RemoveHostResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.added[req.Name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "host %s was not added through the hosts API", req.Name)
+	}
+	added := make(map[string]string, len(s.added))
+	for name, addr := range s.added {
+		if name == req.Name {
+			continue
+		}
+		added[name] = addr
+	}
+
+	err := s.set(ctx, s.config, added)
+	if err != nil {
+		return nil, hostsUpdateError(err)
+	}
+	return &api.RemoveHostResponse{}, nil
+}
+
+func hostsUpdateError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Unavailable, "cannot update hosts: %v", err)
+}
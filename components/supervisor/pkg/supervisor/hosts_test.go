@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	daemonapi "github.com/gitpod-io/gitpod/ws-daemon/api"
+)
+
+type testHostsSetter struct {
+	Err   error
+	Calls [][]*daemonapi.HostEntry
+}
+
+func (s *testHostsSetter) SetHosts(ctx context.Context, hosts []*daemonapi.HostEntry) error {
+	s.Calls = append(s.Calls, hosts)
+	return s.Err
+}
+
+func TestHostsService(t *testing.T) {
+	ctx := context.Background()
+	setter := &testHostsSetter{}
+	srv := NewHostsService(setter)
+
+	srv.updateConfig(ctx, &gitpod.GitpodConfig{
+		Hosts: []*gitpod.HostsItems{
+			{Name: "db.local", Ip: "10.0.0.1"},
+			{Name: "cache.local", Ip: "10.0.0.2"},
+			{Name: "broken.local", Ip: "not-an-ip"},
+		},
+	})
+
+	_, err := srv.Add(ctx, &api.AddHostRequest{Name: "db.local", Addr: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = srv.Add(ctx, &api.AddHostRequest{Name: "foo.local", Addr: "invalid"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for invalid address, got %v", err)
+	}
+	_, err = srv.Remove(ctx, &api.RemoveHostRequest{Name: "cache.local"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound when removing a host from .gitpod.yml, got %v", err)
+	}
+
+	setter.Err = xerrors.Errorf("workspacekit not ready")
+	_, err = srv.Add(ctx, &api.AddHostRequest{Name: "foo.local", Addr: "10.0.0.3"})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable when hosts cannot be set, got %v", err)
+	}
+
+	resp, err := srv.List(ctx, &api.ListHostsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectation := []*api.HostEntry{
+		{Name: "cache.local", Addr: "10.0.0.2", Source: api.HostSource_from_config},
+		{Name: "db.local", Addr: "127.0.0.1", Source: api.HostSource_from_api},
+	}
+	if diff := cmp.Diff(expectation, resp.Hosts, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected hosts (-want +got):\n%s", diff)
+	}
+
+	expectedCalls := [][]*daemonapi.HostEntry{
+		{{Name: "cache.local", Addr: "10.0.0.2"}, {Name: "db.local", Addr: "10.0.0.1"}},
+		{{Name: "cache.local", Addr: "10.0.0.2"}, {Name: "db.local", Addr: "127.0.0.1"}},
+		{{Name: "cache.local", Addr: "10.0.0.2"}, {Name: "db.local", Addr: "127.0.0.1"}, {Name: "foo.local", Addr: "10.0.0.3"}},
+	}
+	if diff := cmp.Diff(expectedCalls, setter.Calls, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected SetHosts calls (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+)
+
+const (
+	defaultIDEProbeTimeout             = 1 * time.Second
+	defaultIDEReadinessPeriod          = 500 * time.Millisecond
+	defaultIDELivenessPeriod           = 10 * time.Second
+	defaultIDELivenessFailureThreshold = 3
+)
+
+// ideCheck runs a single check of an IDE probe
+type ideCheck func(ctx context.Context) error
+
+// newIDECheck produces the check of a probe. It returns nil for process probes,
+// which have nothing to check once the IDE process has been started.
+func newIDECheck(cfg *Config, ide *ideBackend, probe IDEProbe) ideCheck {
+	switch probe.Type {
+	case ReadinessHTTPProbe:
+		var (
+			url    = fmt.Sprintf("http://localhost:%d/%s", ide.Port, strings.TrimPrefix(probe.HTTPProbe.Path, "/"))
+			client = http.Client{}
+		)
+		return func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return xerrors.Errorf("probe came back with non-200 status code: %d", resp.StatusCode)
+			}
+			return nil
+		}
+
+	case ReadinessTCPProbe:
+		port := probe.TCPProbe.Port
+		if port == 0 {
+			port = ide.Port
+		}
+		addr := net.JoinHostPort("localhost", strconv.Itoa(port))
+		return func(ctx context.Context) error {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			conn.Close()
+			return nil
+		}
+
+	case ReadinessExecProbe:
+		var (
+			command = probe.ExecProbe.Command
+			env     = buildChildProcEnv(cfg, nil)
+		)
+		return func(ctx context.Context) error {
+			cmd := runAsGitpodUser(exec.CommandContext(ctx, command[0], command[1:]...))
+			cmd.SysProcAttr.Setpgid = true
+			cmd.Dir = cfg.WorkspaceRoot
+			cmd.Env = env
+			var out bytes.Buffer
+			cmd.Stdout = &out
+			cmd.Stderr = &out
+			err := cmd.Start()
+			if err != nil {
+				return err
+			}
+
+			// the context kills the command only, children which keep its output open would outlive the timeout
+			done := make(chan struct{})
+			go func(pid int) {
+				select {
+				case <-ctx.Done():
+					_ = syscall.Kill(-pid, syscall.SIGKILL)
+				case <-done:
+				}
+			}(cmd.Process.Pid)
+			err = cmd.Wait()
+			close(done)
+			if err != nil {
+				return xerrors.Errorf("%w: %s", err, strings.TrimSpace(out.String()))
+			}
+			return nil
+		}
+
+	default:
+		return nil
+	}
+}
+
+// run runs the check with the timeout of the probe
+func (check ideCheck) run(probe IDEProbe) error {
+	timeout := defaultIDEProbeTimeout
+	if probe.TimeoutSeconds > 0 {
+		timeout = time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return check(ctx)
+}
+
+func probePeriod(probe IDEProbe, def time.Duration) time.Duration {
+	if probe.PeriodSeconds > 0 {
+		return time.Duration(probe.PeriodSeconds) * time.Second
+	}
+	return def
+}
+
+// runIDEReadinessProbe waits until the IDE is ready. It returns false if the IDE stopped before it became ready,
+// or if the readiness probe failed more often than its failure threshold allows.
+func runIDEReadinessProbe(cfg *Config, ide *ideBackend, stopped <-chan struct{}) bool {
+	var (
+		ideLog = log.WithField("ide", ide.Config.name())
+		probe  = ide.Config.ReadinessProbe
+		check  = newIDECheck(cfg, ide, probe)
+	)
+	if check == nil {
+		ideLog.Info("IDE is ready")
+		return true
+	}
+
+	tick := time.NewTicker(probePeriod(probe, defaultIDEReadinessPeriod))
+	defer tick.Stop()
+
+	var (
+		t0       = time.Now()
+		failures int
+	)
+	for {
+		select {
+		case <-tick.C:
+		case <-stopped:
+			return false
+		}
+
+		err := check.run(probe)
+		if err == nil {
+			ideLog.Infof("IDE readiness took %.3f seconds", time.Since(t0).Seconds())
+			ideLog.Info("IDE is ready")
+			return true
+		}
+
+		failures++
+		ideLog.WithError(err).WithField("failures", failures).Debug("IDE readiness probe failed")
+		if probe.FailureThreshold > 0 && failures >= probe.FailureThreshold {
+			ideLog.WithError(err).WithField("failures", failures).Error("IDE did not become ready")
+			return false
+		}
+	}
+}
+
+// watchIDELiveness runs the liveness probe of a ready IDE until it stops, and calls restart
+// once the probe failed as often as its failure threshold allows.
+func watchIDELiveness(cfg *Config, ide *ideBackend, stopped <-chan struct{}, restart func()) {
+	var (
+		ideLog = log.WithField("ide", ide.Config.name())
+		probe  = ide.Config.LivenessProbe
+		check  = newIDECheck(cfg, ide, probe)
+	)
+	if check == nil {
+		return
+	}
+	threshold := defaultIDELivenessFailureThreshold
+	if probe.FailureThreshold > 0 {
+		threshold = probe.FailureThreshold
+	}
+
+	tick := time.NewTicker(probePeriod(probe, defaultIDELivenessPeriod))
+	defer tick.Stop()
+
+	var failures int
+	for {
+		select {
+		case <-tick.C:
+		case <-stopped:
+			return
+		}
+
+		err := check.run(probe)
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		ideLog.WithError(err).WithField("failures", failures).Debug("IDE liveness probe failed")
+		if failures >= threshold {
+			ideLog.WithError(err).WithField("failures", failures).Warn("IDE is not responding - restarting it")
+			restart()
+			return
+		}
+	}
+}
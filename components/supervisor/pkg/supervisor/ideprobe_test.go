@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIDECheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	newProbe := func(tpe ReadinessProbeType, mod func(p *IDEProbe)) IDEProbe {
+		p := IDEProbe{Type: tpe}
+		if mod != nil {
+			mod(&p)
+		}
+		return p
+	}
+	tests := []struct {
+		Name    string
+		Port    int
+		Probe   IDEProbe
+		NoCheck bool
+		OK      bool
+	}{
+		{Name: "process", Port: port, Probe: newProbe(ReadinessProcessProbe, nil), NoCheck: true},
+		{Name: "http ok", Port: port, Probe: newProbe(ReadinessHTTPProbe, func(p *IDEProbe) { p.HTTPProbe.Path = "/healthz" }), OK: true},
+		{Name: "http unavailable", Port: port, Probe: newProbe(ReadinessHTTPProbe, nil)},
+		{Name: "tcp ok", Port: port, Probe: newProbe(ReadinessTCPProbe, nil), OK: true},
+		{Name: "tcp other port", Port: closedPort, Probe: newProbe(ReadinessTCPProbe, func(p *IDEProbe) { p.TCPProbe.Port = port }), OK: true},
+		{Name: "tcp closed", Port: closedPort, Probe: newProbe(ReadinessTCPProbe, nil)},
+		{Name: "exec ok", Probe: newProbe(ReadinessExecProbe, func(p *IDEProbe) { p.ExecProbe.Command = []string{"true"} }), OK: true},
+		{Name: "exec failed", Probe: newProbe(ReadinessExecProbe, func(p *IDEProbe) { p.ExecProbe.Command = []string{"false"} })},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			check := newIDECheck(&Config{}, &ideBackend{Port: test.Port}, test.Probe)
+			if test.NoCheck {
+				if check != nil {
+					t.Error("expected no check")
+				}
+				return
+			}
+			err := check.run(test.Probe)
+			if ok := err == nil; ok != test.OK {
+				t.Errorf("unexpected result: want ok=%v, got %v", test.OK, err)
+			}
+		})
+	}
+}
+
+func TestIDEExecCheckTimeout(t *testing.T) {
+	// the background sleep keeps the output of the command open after the command itself was killed
+	probe := IDEProbe{Type: ReadinessExecProbe, TimeoutSeconds: 1}
+	probe.ExecProbe.Command = []string{"sh", "-c", "sleep 30 & sleep 30"}
+	check := newIDECheck(&Config{}, &ideBackend{}, probe)
+
+	t0 := time.Now()
+	err := check.run(probe)
+	if err == nil {
+		t.Error("expected the probe to time out")
+	}
+	if dt := time.Since(t0); dt > 5*time.Second {
+		t.Errorf("probe took %s despite its timeout", dt)
+	}
+}
+
+func TestIDEProbes(t *testing.T) {
+	closed, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	ide := &ideBackend{Port: port}
+	ide.Config.ReadinessProbe = IDEProbe{Type: ReadinessTCPProbe, FailureThreshold: 2}
+	ide.Config.LivenessProbe = IDEProbe{Type: ReadinessTCPProbe, PeriodSeconds: 1, FailureThreshold: 1}
+
+	done := make(chan bool)
+	go func() { done <- runIDEReadinessProbe(&Config{}, ide, make(chan struct{})) }()
+	select {
+	case ready := <-done:
+		if ready {
+			t.Error("IDE became ready although the readiness probe failed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("readiness probe did not give up")
+	}
+
+	restarted := make(chan struct{})
+	go watchIDELiveness(&Config{}, ide, make(chan struct{}), func() { close(restarted) })
+	select {
+	case <-restarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("liveness probe did not restart the IDE")
+	}
+}
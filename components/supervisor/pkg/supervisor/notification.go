@@ -6,12 +6,19 @@ package supervisor
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"golang.org/x/xerrors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/gitpod-io/gitpod/common-go/log"
 	"github.com/gitpod-io/gitpod/supervisor/api"
@@ -20,6 +27,10 @@ import (
 const (
 	NotifierMaxPendingNotifications   = 120
 	SubscriberMaxPendingNotifications = 100
+
+	// MaxStoredNotifications is the size of the notification history. Once it is full, the oldest
+	// notifications which do not wait for a response are dropped.
+	MaxStoredNotifications = 200
 )
 
 // NewNotificationService creates a new notification service
@@ -38,11 +49,31 @@ type NotificationService struct {
 	nextNotificationID   uint64
 	pendingNotifications map[uint64]*pendingNotification
 
+	// notifications is the notification history, oldest first
+	notifications []*storedNotification
+	// storeLocation is the file the history is persisted to. The history is kept in memory only if empty.
+	storeLocation string
+
 	api.UnimplementedNotificationServiceServer
 }
 
+type storedNotification struct {
+	*api.Notification
+	// delivered is true once the notification was sent to a subscriber
+	delivered bool
+}
+
+func (n *storedNotification) subscribeResponse() *api.SubscribeResponse {
+	return &api.SubscribeResponse{
+		RequestId: n.RequestId,
+		Request:   n.Request,
+		Timestamp: n.Timestamp,
+	}
+}
+
+// pendingNotification is a notification which waits for a response
 type pendingNotification struct {
-	message         *api.SubscribeResponse
+	notification    *storedNotification
 	responseChannel chan *api.NotifyResponse
 	once            sync.Once
 	closed          bool
@@ -83,11 +114,21 @@ func (srv *NotificationService) RegisterREST(mux *runtime.ServeMux, grpcEndpoint
 
 // Notify sends a notification to the user
 func (srv *NotificationService) Notify(ctx context.Context, req *api.NotifyRequest) (*api.NotifyResponse, error) {
-	if len(srv.pendingNotifications) >= NotifierMaxPendingNotifications {
-		return nil, status.Error(codes.ResourceExhausted, "Max number of pending notifications exceeded")
+	pending, err := srv.notifySubscribers(req)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		// nobody needs to respond to the notification
+		return &api.NotifyResponse{}, nil
 	}
 
-	pending := srv.notifySubscribers(req)
+	var timeout <-chan time.Time
+	if req.TimeoutSeconds > 0 {
+		timer := time.NewTimer(time.Duration(req.TimeoutSeconds) * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
 	select {
 	case resp, ok := <-pending.responseChannel:
 		if !ok {
@@ -96,35 +137,60 @@ func (srv *NotificationService) Notify(ctx context.Context, req *api.NotifyReque
 		}
 		log.WithField("NotifyResponse", resp).Info("sending notify response")
 		return resp, nil
+	case <-timeout:
+		log.Info("notify timed out")
+		srv.abandon(pending, api.Notification_TIMED_OUT)
+		return nil, status.Error(codes.DeadlineExceeded, "nobody responded to the notification in time")
 	case <-ctx.Done():
 		log.Info("notify cancelled")
-		srv.mutex.Lock()
-		defer srv.mutex.Unlock()
-		// make sure the notification has not been responded in between these selectors
-		_, ok := srv.pendingNotifications[pending.message.RequestId]
-		if ok {
-			delete(srv.pendingNotifications, pending.message.RequestId)
-			pending.close()
-		}
+		srv.abandon(pending, api.Notification_CANCELLED)
 		return nil, ctx.Err()
 	}
 }
 
-func (srv *NotificationService) notifySubscribers(req *api.NotifyRequest) *pendingNotification {
+// abandon stops waiting for a response to a notification
+func (srv *NotificationService) abandon(pending *pendingNotification, state api.Notification_State) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	// make sure the notification has not been responded in between
+	_, ok := srv.pendingNotifications[pending.notification.RequestId]
+	if !ok {
+		return
+	}
+	delete(srv.pendingNotifications, pending.notification.RequestId)
+	pending.close()
+	pending.notification.State = state
+	srv.persistLocked()
+}
+
+// notifySubscribers stores a notification and sends it to all subscribers.
+// It returns nil if the notification does not wait for a response.
+func (srv *NotificationService) notifySubscribers(req *api.NotifyRequest) (*pendingNotification, error) {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
-	var (
-		requestID = srv.nextNotificationID
-		message   = &api.SubscribeResponse{
-			RequestId: requestID,
+
+	needsResponse := len(req.Actions) > 0
+	if needsResponse && len(srv.pendingNotifications) >= NotifierMaxPendingNotifications {
+		return nil, status.Error(codes.ResourceExhausted, "Max number of pending notifications exceeded")
+	}
+
+	notification := &storedNotification{
+		Notification: &api.Notification{
+			RequestId: srv.nextNotificationID,
 			Request:   req,
-		}
-	)
+			Timestamp: timestamppb.Now(),
+		},
+	}
+	if needsResponse {
+		notification.State = api.Notification_PENDING
+	}
 	srv.nextNotificationID++
+
+	message := notification.subscribeResponse()
 	for _, subscription := range srv.subscriptions {
 		select {
 		case subscription.channel <- message:
-			// all good
+			notification.delivered = true
 		default:
 			// subscriber doesn't consume messages fast enough
 			log.WithField("subscription", req).Info("Cancelling unresponsive subscriber")
@@ -132,18 +198,38 @@ func (srv *NotificationService) notifySubscribers(req *api.NotifyRequest) *pendi
 			subscription.close()
 		}
 	}
-	var channel = make(chan *api.NotifyResponse, 1)
+	srv.storeLocked(notification)
+	srv.persistLocked()
+
+	if !needsResponse {
+		return nil, nil
+	}
 	pending := &pendingNotification{
-		message:         message,
-		responseChannel: channel,
+		notification:    notification,
+		responseChannel: make(chan *api.NotifyResponse, 1),
 	}
-	srv.pendingNotifications[requestID] = pending
-	if len(req.Actions) == 0 {
-		// produce an immediate response
-		channel <- &api.NotifyResponse{}
-		pending.close()
+	srv.pendingNotifications[notification.RequestId] = pending
+	return pending, nil
+}
+
+// storeLocked adds a notification to the history and drops the oldest ones which do not wait
+// for a response if the history is full. Callers are expected to hold mutex.
+func (srv *NotificationService) storeLocked(notification *storedNotification) {
+	srv.notifications = append(srv.notifications, notification)
+	for len(srv.notifications) > MaxStoredNotifications {
+		dropped := false
+		for i, n := range srv.notifications {
+			if n.State == api.Notification_PENDING {
+				continue
+			}
+			srv.notifications = append(srv.notifications[:i], srv.notifications[i+1:]...)
+			dropped = true
+			break
+		}
+		if !dropped {
+			return
+		}
 	}
-	return pending
 }
 
 // Subscribe subscribes to notifications that are sent to the supervisor
@@ -172,18 +258,24 @@ func (srv *NotificationService) Subscribe(req *api.SubscribeRequest, resp api.No
 func (srv *NotificationService) subscribeLocked(req *api.SubscribeRequest, resp api.NotificationService_SubscribeServer) *subscription {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
+
+	var replay []*storedNotification
+	for _, n := range srv.notifications {
+		if req.History || !n.delivered || n.State == api.Notification_PENDING {
+			replay = append(replay, n)
+		}
+	}
+
 	// account for some back pressure
-	capacity := len(srv.pendingNotifications)
+	capacity := len(replay)
 	if SubscriberMaxPendingNotifications > capacity {
 		capacity = SubscriberMaxPendingNotifications
 	}
 	channel := make(chan *api.SubscribeResponse, capacity)
-	log.WithField("pending", len(srv.pendingNotifications)).Info("sending pending notifications")
-	for id, pending := range srv.pendingNotifications {
-		channel <- pending.message
-		if len(pending.message.Request.Actions) == 0 {
-			delete(srv.pendingNotifications, id)
-		}
+	log.WithField("pending", len(replay)).Info("sending pending notifications")
+	for _, n := range replay {
+		channel <- n.subscribeResponse()
+		n.delivered = true
 	}
 	id := srv.nextSubscriptionID
 	srv.nextSubscriptionID++
@@ -217,13 +309,16 @@ func (srv *NotificationService) Respond(ctx context.Context, req *api.RespondReq
 	if !ok {
 		log.WithFields(map[string]interface{}{
 			"RequestId": req.RequestId,
-			"Action":    req.Response.Action,
+			"Action":    req.Response.GetAction(),
 		}).Info("Invalid or late response to notification")
 		return nil, status.Errorf(codes.DeadlineExceeded, "Invalid or late response to notification")
 	}
-	if !isActionAllowed(req.Response.Action, pending.message.Request) {
+	if req.Response == nil {
+		req.Response = &api.NotifyResponse{}
+	}
+	if !isActionAllowed(req.Response.Action, pending.notification.Request) {
 		log.WithFields(map[string]interface{}{
-			"Notification": pending.message,
+			"Notification": pending.notification.Notification,
 			"Action":       req.Response.Action,
 		}).Error("Invalid user action on notification")
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid user action on notification")
@@ -232,10 +327,109 @@ func (srv *NotificationService) Respond(ctx context.Context, req *api.RespondReq
 		pending.responseChannel <- req.Response
 		pending.close()
 	}
-	delete(srv.pendingNotifications, pending.message.RequestId)
+	delete(srv.pendingNotifications, req.RequestId)
+	pending.notification.State = api.Notification_RESPONDED
+	pending.notification.Response = req.Response
+	pending.notification.Read = true
+	srv.persistLocked()
 	return &api.RespondResponse{}, nil
 }
 
+// ListNotifications lists the notifications of the history
+func (srv *NotificationService) ListNotifications(ctx context.Context, req *api.ListNotificationsRequest) (*api.ListNotificationsResponse, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	res := &api.ListNotificationsResponse{}
+	for _, n := range srv.notifications {
+		if req.UnreadOnly && n.Read {
+			continue
+		}
+		res.Notifications = append(res.Notifications, proto.Clone(n.Notification).(*api.Notification))
+	}
+	return res, nil
+}
+
+// MarkRead marks notifications of the history as read
+func (srv *NotificationService) MarkRead(ctx context.Context, req *api.MarkReadRequest) (*api.MarkReadResponse, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ids := make(map[uint64]struct{}, len(req.RequestIds))
+	for _, id := range req.RequestIds {
+		ids[id] = struct{}{}
+	}
+	for _, n := range srv.notifications {
+		if _, ok := ids[n.RequestId]; ok || len(ids) == 0 {
+			n.Read = true
+		}
+	}
+	srv.persistLocked()
+	return &api.MarkReadResponse{}, nil
+}
+
+// PersistTo restores the notification history a previous supervisor persisted to fn, and keeps persisting it there from now on.
+// Notifications which waited for a response are cancelled, because their notifier is gone.
+func (srv *NotificationService) PersistTo(fn string) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	content, err := os.ReadFile(fn)
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("cannot read notification history: %w", err)
+	}
+	if err == nil {
+		var history api.ListNotificationsResponse
+		err = protojson.Unmarshal(content, &history)
+		if err != nil {
+			log.WithError(err).Warn("cannot restore notification history - starting with an empty one")
+		}
+		restored := make([]*storedNotification, 0, len(history.Notifications)+len(srv.notifications))
+		for _, n := range history.Notifications {
+			if n.State == api.Notification_PENDING {
+				n.State = api.Notification_CANCELLED
+			}
+			if n.RequestId >= srv.nextNotificationID {
+				srv.nextNotificationID = n.RequestId + 1
+			}
+			restored = append(restored, &storedNotification{Notification: n, delivered: n.Read})
+		}
+		srv.notifications = append(restored, srv.notifications...)
+	}
+
+	err = os.MkdirAll(filepath.Dir(fn), 0755)
+	if err != nil {
+		return xerrors.Errorf("cannot create notification history location: %w", err)
+	}
+	srv.storeLocation = fn
+	srv.persistLocked()
+	return nil
+}
+
+// persistLocked writes the notification history to disk if it's persisted.
+// Callers are expected to hold mutex.
+func (srv *NotificationService) persistLocked() {
+	if srv.storeLocation == "" {
+		return
+	}
+
+	history := &api.ListNotificationsResponse{Notifications: make([]*api.Notification, 0, len(srv.notifications))}
+	for _, n := range srv.notifications {
+		history.Notifications = append(history.Notifications, n.Notification)
+	}
+	content, err := protojson.Marshal(history)
+	if err == nil {
+		tmp := srv.storeLocation + ".tmp"
+		err = os.WriteFile(tmp, content, 0644)
+		if err == nil {
+			err = os.Rename(tmp, srv.storeLocation)
+		}
+	}
+	if err != nil {
+		log.WithError(err).Warn("cannot persist notification history")
+	}
+}
+
 func isActionAllowed(action string, req *api.NotifyRequest) bool {
 	if action == "" {
 		// user cancelled, which is always allowed
@@ -7,12 +7,18 @@ package supervisor
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
-	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
 )
 
 type TestNotificationService_SubscribeServer struct {
@@ -241,15 +247,6 @@ func Test(t *testing.T) {
 					t.Errorf("error on notification %s", err)
 				}
 			}
-
-			// send message to stress the notifier
-			_, err := notificationService.Notify(context.Background(), &api.NotifyRequest{
-				Level:   api.NotifyRequest_INFO,
-				Message: fmt.Sprintf("Notification %d", NotifierMaxPendingNotifications+1),
-			})
-			if err == nil {
-				t.Errorf("Expected error on notifier backpressure")
-			}
 		}()
 
 		err = notificationService.Subscribe(&api.SubscribeRequest{}, subscriber)
@@ -258,4 +255,130 @@ func Test(t *testing.T) {
 		}
 		wg.Wait()
 	})
+	t.Run("Notifier backpressure", func(t *testing.T) {
+		notificationService := NewNotificationService()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// fill up the notifications which wait for a response
+		for i := 0; i < NotifierMaxPendingNotifications; i++ {
+			go func(i int) {
+				_, _ = notificationService.Notify(ctx, &api.NotifyRequest{
+					Level:   api.NotifyRequest_INFO,
+					Message: fmt.Sprintf("Notification %d", i),
+					Actions: []string{"ok"},
+				})
+			}(i)
+		}
+		for {
+			notificationService.mutex.Lock()
+			pending := len(notificationService.pendingNotifications)
+			notificationService.mutex.Unlock()
+			if pending == NotifierMaxPendingNotifications {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		_, err := notificationService.Notify(ctx, &api.NotifyRequest{
+			Level:   api.NotifyRequest_INFO,
+			Message: "One too many",
+			Actions: []string{"ok"},
+		})
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Expected error on notifier backpressure, got %v", err)
+		}
+
+		// notifications without actions are still accepted
+		_, err = notificationService.Notify(ctx, &api.NotifyRequest{
+			Level:   api.NotifyRequest_INFO,
+			Message: "Informational",
+		})
+		if err != nil {
+			t.Errorf("error on notification %s", err)
+		}
+	})
+}
+
+func TestNotificationHistory(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "notifications.json")
+	notificationService := NewNotificationService()
+	err := notificationService.PersistTo(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < MaxStoredNotifications+10; i++ {
+		_, err := notificationService.Notify(context.Background(), &api.NotifyRequest{
+			Level:   api.NotifyRequest_INFO,
+			Message: fmt.Sprintf("Notification %d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = notificationService.Notify(context.Background(), &api.NotifyRequest{
+		Level:          api.NotifyRequest_WARNING,
+		Message:        "Nobody is going to answer",
+		Actions:        []string{"ok"},
+		TimeoutSeconds: 1,
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected the notification to time out, got %v", err)
+	}
+
+	list, err := notificationService.ListNotifications(context.Background(), &api.ListNotificationsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(list.Notifications); n != MaxStoredNotifications {
+		t.Fatalf("expected %d notifications in the history, got %d", MaxStoredNotifications, n)
+	}
+	if first := list.Notifications[0].Request.Message; first != "Notification 11" {
+		t.Errorf("expected the oldest notifications to be dropped, first one is %q", first)
+	}
+	last := list.Notifications[len(list.Notifications)-1]
+	if last.State != api.Notification_TIMED_OUT {
+		t.Errorf("expected the last notification to be timed out, got %v", last.State)
+	}
+
+	_, err = notificationService.MarkRead(context.Background(), &api.MarkReadRequest{RequestIds: []uint64{last.RequestId}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unread, err := notificationService.ListNotifications(context.Background(), &api.ListNotificationsRequest{UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(unread.Notifications); n != MaxStoredNotifications-1 {
+		t.Errorf("expected %d unread notifications, got %d", MaxStoredNotifications-1, n)
+	}
+
+	// a new supervisor restores the history and replays it on request
+	restored := NewNotificationService()
+	err = restored.PersistTo(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredList, err := restored.ListNotifications(context.Background(), &api.ListNotificationsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(list.Notifications[:len(list.Notifications)-1], restoredList.Notifications[:len(restoredList.Notifications)-1], protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected restored history (-want +got):\n%s", diff)
+	}
+
+	subscriber := NewSubscribeServer()
+	subscriber.resps = make(chan *api.SubscribeResponse, MaxStoredNotifications)
+	defer subscriber.cancel()
+	go func() {
+		_ = restored.Subscribe(&api.SubscribeRequest{History: true}, subscriber)
+	}()
+	for i := 0; i < MaxStoredNotifications; i++ {
+		select {
+		case <-subscriber.resps:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("history was not replayed, got %d notifications", i)
+		}
+	}
 }
@@ -0,0 +1,447 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+const (
+	defaultCgroupBasePath          = "/sys/fs/cgroup"
+	defaultResourcesSampleInterval = 5 * time.Second
+
+	// resourceWarningThreshold and resourceDangerThreshold are the fractions of a limit
+	// at which the usage of a resource is considered close to the limit
+	resourceWarningThreshold = 0.8
+	resourceDangerThreshold  = 0.95
+
+	// resourceNotificationHysteresis is how far below a threshold the usage of a resource must drop before
+	// the user is notified about reaching the threshold again. It keeps usage that hovers around a threshold
+	// from notifying the user over and over.
+	resourceNotificationHysteresis = 0.1
+
+	// cgroup v1 reports an unlimited memory limit as the largest page aligned int64
+	cgroupV1UnlimitedMemory = 1 << 62
+)
+
+type resourcesSubscription struct {
+	updates chan *api.ResourcesStatusResponse
+	Close   func() error
+}
+
+func (sub *resourcesSubscription) Updates() <-chan *api.ResourcesStatusResponse {
+	return sub.updates
+}
+
+// resourcesMonitor samples the CPU, memory and disk usage of the workspace and
+// notifies the user once a resource gets close to its limit.
+type resourcesMonitor struct {
+	// CgroupBasePath is where the cgroup filesystem of the workspace is mounted
+	CgroupBasePath string
+	// DiskPath is a path on the filesystem the workspace content lives on
+	DiskPath string
+	// Interval is the time between two samples
+	Interval time.Duration
+	// Notifications warns the user about resources close to their limit. No warnings are sent if it's nil.
+	Notifications *NotificationService
+
+	mu            sync.Mutex
+	status        *api.ResourcesStatusResponse
+	subscriptions map[*resourcesSubscription]struct{}
+	ready         chan struct{}
+	lastCPU       *cpuSample
+	notified      map[string]api.ResourceStatusSeverity
+}
+
+type cpuSample struct {
+	// usage is the accumulated CPU time in nanoseconds
+	usage uint64
+	time  time.Time
+}
+
+func newResourcesMonitor(cfg *Config, notifications *NotificationService) *resourcesMonitor {
+	return &resourcesMonitor{
+		CgroupBasePath: defaultCgroupBasePath,
+		DiskPath:       cfg.WorkspaceRoot,
+		Interval:       defaultResourcesSampleInterval,
+		Notifications:  notifications,
+		subscriptions:  make(map[*resourcesSubscription]struct{}),
+		ready:          make(chan struct{}),
+		notified:       make(map[string]api.ResourceStatusSeverity),
+	}
+}
+
+// Run samples the resource usage until the context is canceled
+func (m *resourcesMonitor) Run(ctx context.Context) {
+	tick := time.NewTicker(m.Interval)
+	defer tick.Stop()
+
+	for {
+		m.sample()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// Status returns the latest sample, once one was taken
+func (m *resourcesMonitor) Status(ctx context.Context) (*api.ResourcesStatusResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-m.ready:
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return proto.Clone(m.status).(*api.ResourcesStatusResponse), nil
+}
+
+// Subscribe returns a subscription to all samples, starting with the latest one if there is any
+func (m *resourcesMonitor) Subscribe() *resourcesSubscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.subscriptions) > maxSubscriptions {
+		return nil
+	}
+
+	sub := &resourcesSubscription{updates: make(chan *api.ResourcesStatusResponse, 5)}
+	var once sync.Once
+	sub.Close = func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		once.Do(func() {
+			close(sub.updates)
+			delete(m.subscriptions, sub)
+		})
+		return nil
+	}
+	m.subscriptions[sub] = struct{}{}
+
+	if m.status != nil {
+		sub.updates <- proto.Clone(m.status).(*api.ResourcesStatusResponse)
+	}
+	return sub
+}
+
+func (m *resourcesMonitor) sample() {
+	var (
+		status = &api.ResourcesStatusResponse{}
+		now    = time.Now()
+		err    error
+	)
+	status.Cpu, err = m.cpu(now)
+	if err != nil {
+		log.WithError(err).Debug("cannot sample CPU usage")
+	}
+	status.Memory, err = m.memory()
+	if err != nil {
+		log.WithError(err).Debug("cannot sample memory usage")
+	}
+	status.Disk, err = m.disk()
+	if err != nil {
+		log.WithError(err).Debug("cannot sample disk usage")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.status = status
+	select {
+	case <-m.ready:
+	default:
+		close(m.ready)
+	}
+	for sub := range m.subscriptions {
+		select {
+		case sub.updates <- proto.Clone(status).(*api.ResourcesStatusResponse):
+		default:
+			// slow subscribers miss samples rather than blocking the monitor
+		}
+	}
+
+	m.warnLocked("CPU", status.Cpu, formatMillicores)
+	m.warnLocked("memory", status.Memory, formatBytes)
+	m.warnLocked("disk", status.Disk, formatBytes)
+}
+
+// warnLocked notifies the user once the severity of a resource increases. The user is notified about a severity
+// at most once, until the usage dropped below its threshold by resourceNotificationHysteresis.
+func (m *resourcesMonitor) warnLocked(name string, res *api.ResourceStatus, format func(int64) string) {
+	if res == nil || res.Limit <= 0 {
+		return
+	}
+	notified := m.notified[name]
+	ratio := float64(res.Used) / float64(res.Limit)
+	for notified > api.ResourceStatusSeverity_normal && ratio < severityThreshold(notified)-resourceNotificationHysteresis {
+		notified--
+	}
+	if res.Severity <= notified {
+		m.notified[name] = notified
+		return
+	}
+	m.notified[name] = res.Severity
+	if m.Notifications == nil {
+		return
+	}
+
+	level := api.NotifyRequest_WARNING
+	if res.Severity == api.ResourceStatusSeverity_danger {
+		level = api.NotifyRequest_ERROR
+	}
+	message := fmt.Sprintf("Your workspace uses %d%% of its %s limit (%s of %s).", res.Used*100/res.Limit, name, format(res.Used), format(res.Limit))
+	go func() {
+		_, err := m.Notifications.Notify(context.Background(), &api.NotifyRequest{
+			Level:   level,
+			Message: message,
+		})
+		if err != nil {
+			log.WithError(err).WithField("resource", name).Warn("cannot notify about resource usage")
+		}
+	}()
+}
+
+// severityThreshold returns the fraction of a limit at which the usage of a resource reaches severity
+func severityThreshold(severity api.ResourceStatusSeverity) float64 {
+	switch severity {
+	case api.ResourceStatusSeverity_danger:
+		return resourceDangerThreshold
+	case api.ResourceStatusSeverity_warning:
+		return resourceWarningThreshold
+	default:
+		return 0
+	}
+}
+
+func resourceStatus(used, limit int64) *api.ResourceStatus {
+	res := &api.ResourceStatus{Used: used, Limit: limit}
+	if limit <= 0 {
+		return res
+	}
+	switch ratio := float64(used) / float64(limit); {
+	case ratio >= resourceDangerThreshold:
+		res.Severity = api.ResourceStatusSeverity_danger
+	case ratio >= resourceWarningThreshold:
+		res.Severity = api.ResourceStatusSeverity_warning
+	}
+	return res
+}
+
+func (m *resourcesMonitor) isCgroupV2() bool {
+	_, err := os.Stat(filepath.Join(m.CgroupBasePath, "cgroup.controllers"))
+	return err == nil
+}
+
+// cpu samples the CPU usage in millicores, averaged since the previous sample
+func (m *resourcesMonitor) cpu(now time.Time) (*api.ResourceStatus, error) {
+	var (
+		usage uint64
+		limit int64
+		err   error
+	)
+	if m.isCgroupV2() {
+		var stat map[string]uint64
+		stat, err = readCgroupStat(filepath.Join(m.CgroupBasePath, "cpu.stat"))
+		if err != nil {
+			return nil, err
+		}
+		usage = stat["usage_usec"] * uint64(time.Microsecond)
+
+		var content []byte
+		content, err = os.ReadFile(filepath.Join(m.CgroupBasePath, "cpu.max"))
+		if err != nil {
+			return nil, err
+		}
+		segs := strings.Fields(string(content))
+		if len(segs) == 2 && segs[0] != "max" {
+			var quota, period int64
+			quota, err = strconv.ParseInt(segs[0], 10, 64)
+			if err != nil {
+				return nil, xerrors.Errorf("cannot parse cpu.max: %w", err)
+			}
+			period, err = strconv.ParseInt(segs[1], 10, 64)
+			if err != nil {
+				return nil, xerrors.Errorf("cannot parse cpu.max: %w", err)
+			}
+			if period > 0 {
+				limit = quota * 1000 / period
+			}
+		}
+	} else {
+		var v int64
+		v, err = readCgroupInt(filepath.Join(m.CgroupBasePath, "cpuacct", "cpuacct.usage"))
+		if err != nil {
+			return nil, err
+		}
+		usage = uint64(v)
+
+		var quota, period int64
+		quota, err = readCgroupInt(filepath.Join(m.CgroupBasePath, "cpu", "cpu.cfs_quota_us"))
+		if err != nil {
+			return nil, err
+		}
+		period, err = readCgroupInt(filepath.Join(m.CgroupBasePath, "cpu", "cpu.cfs_period_us"))
+		if err != nil {
+			return nil, err
+		}
+		if quota > 0 && period > 0 {
+			limit = quota * 1000 / period
+		}
+	}
+
+	var used int64
+	last := m.lastCPU
+	m.lastCPU = &cpuSample{usage: usage, time: now}
+	if last != nil && usage >= last.usage && now.After(last.time) {
+		used = int64(float64(usage-last.usage) / float64(now.Sub(last.time)) * 1000)
+	}
+	return resourceStatus(used, limit), nil
+}
+
+// memory samples the memory usage in bytes. Like the kubelet it does not count inactive
+// file pages, which the kernel reclaims before hitting the limit.
+func (m *resourcesMonitor) memory() (*api.ResourceStatus, error) {
+	var (
+		usage, limit int64
+		inactive     uint64
+	)
+	if m.isCgroupV2() {
+		var err error
+		usage, err = readCgroupInt(filepath.Join(m.CgroupBasePath, "memory.current"))
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(filepath.Join(m.CgroupBasePath, "memory.max"))
+		if err != nil {
+			return nil, err
+		}
+		if v := strings.TrimSpace(string(content)); v != "max" {
+			limit, err = strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, xerrors.Errorf("cannot parse memory.max: %w", err)
+			}
+		}
+		stat, err := readCgroupStat(filepath.Join(m.CgroupBasePath, "memory.stat"))
+		if err != nil {
+			return nil, err
+		}
+		inactive = stat["inactive_file"]
+	} else {
+		var err error
+		usage, err = readCgroupInt(filepath.Join(m.CgroupBasePath, "memory", "memory.usage_in_bytes"))
+		if err != nil {
+			return nil, err
+		}
+		limit, err = readCgroupInt(filepath.Join(m.CgroupBasePath, "memory", "memory.limit_in_bytes"))
+		if err != nil {
+			return nil, err
+		}
+		if limit >= cgroupV1UnlimitedMemory {
+			limit = 0
+		}
+		stat, err := readCgroupStat(filepath.Join(m.CgroupBasePath, "memory", "memory.stat"))
+		if err != nil {
+			return nil, err
+		}
+		inactive = stat["total_inactive_file"]
+	}
+
+	if int64(inactive) < usage {
+		usage -= int64(inactive)
+	} else {
+		usage = 0
+	}
+	return resourceStatus(usage, limit), nil
+}
+
+// disk samples the usage of the filesystem the workspace content lives on
+func (m *resourcesMonitor) disk() (*api.ResourceStatus, error) {
+	if m.DiskPath == "" {
+		return nil, xerrors.Errorf("no disk path configured")
+	}
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(m.DiskPath, &stat)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		size = int64(stat.Blocks) * int64(stat.Bsize)
+		free = int64(stat.Bfree) * int64(stat.Bsize)
+	)
+	return resourceStatus(size-free, size), nil
+}
+
+func readCgroupInt(fn string) (int64, error) {
+	content, err := os.ReadFile(fn)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("cannot parse %s: %w", fn, err)
+	}
+	return res, nil
+}
+
+// readCgroupStat reads a flat keyed cgroup file, e.g. memory.stat
+func readCgroupStat(fn string) (map[string]uint64, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		segs := strings.Fields(scanner.Text())
+		if len(segs) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(segs[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		res[segs[0]] = v
+	}
+	return res, scanner.Err()
+}
+
+func formatMillicores(v int64) string {
+	return fmt.Sprintf("%.2f cores", float64(v)/1000)
+}
+
+func formatBytes(v int64) string {
+	const unit = 1024
+	if v < unit {
+		return fmt.Sprintf("%d B", v)
+	}
+	div, exp := int64(unit), 0
+	for n := v / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(v)/float64(div), "KMGTPE"[exp])
+}
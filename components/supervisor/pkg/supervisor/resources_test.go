@@ -0,0 +1,236 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+func TestResourcesMonitor(t *testing.T) {
+	type Sample struct {
+		Files map[string]string
+		Delay time.Duration
+	}
+	tests := []struct {
+		Name        string
+		Samples     []Sample
+		Expectation *api.ResourcesStatusResponse
+	}{
+		{
+			Name: "cgroup v2",
+			Samples: []Sample{
+				{Files: map[string]string{
+					"cgroup.controllers": "cpu memory",
+					"cpu.stat":           "usage_usec 1000000\nuser_usec 800000\n",
+					"cpu.max":            "200000 100000\n",
+					"memory.current":     "1000\n",
+					"memory.max":         "1000\n",
+					"memory.stat":        "anon 700\ninactive_file 150\n",
+				}},
+				{Files: map[string]string{"cpu.stat": "usage_usec 2500000\n"}, Delay: time.Second},
+			},
+			Expectation: &api.ResourcesStatusResponse{
+				Cpu:    &api.ResourceStatus{Used: 1500, Limit: 2000, Severity: api.ResourceStatusSeverity_normal},
+				Memory: &api.ResourceStatus{Used: 850, Limit: 1000, Severity: api.ResourceStatusSeverity_warning},
+			},
+		},
+		{
+			Name: "cgroup v2 unlimited",
+			Samples: []Sample{
+				{Files: map[string]string{
+					"cgroup.controllers": "cpu memory",
+					"cpu.stat":           "usage_usec 1000000\n",
+					"cpu.max":            "max 100000\n",
+					"memory.current":     "1000\n",
+					"memory.max":         "max\n",
+					"memory.stat":        "inactive_file 2000\n",
+				}},
+			},
+			Expectation: &api.ResourcesStatusResponse{
+				Cpu:    &api.ResourceStatus{},
+				Memory: &api.ResourceStatus{},
+			},
+		},
+		{
+			Name: "cgroup v1",
+			Samples: []Sample{
+				{Files: map[string]string{
+					"cpuacct/cpuacct.usage":        "1000000000\n",
+					"cpu/cpu.cfs_quota_us":         "400000\n",
+					"cpu/cpu.cfs_period_us":        "100000\n",
+					"memory/memory.usage_in_bytes": "1000\n",
+					"memory/memory.limit_in_bytes": "1000\n",
+					"memory/memory.stat":           "cache 100\ntotal_inactive_file 20\n",
+				}},
+				{Files: map[string]string{"cpuacct/cpuacct.usage": "4900000000\n"}, Delay: time.Second},
+			},
+			Expectation: &api.ResourcesStatusResponse{
+				Cpu:    &api.ResourceStatus{Used: 3900, Limit: 4000, Severity: api.ResourceStatusSeverity_danger},
+				Memory: &api.ResourceStatus{Used: 980, Limit: 1000, Severity: api.ResourceStatusSeverity_danger},
+			},
+		},
+		{
+			Name: "cgroup v1 unlimited",
+			Samples: []Sample{
+				{Files: map[string]string{
+					"cpuacct/cpuacct.usage":        "1000000000\n",
+					"cpu/cpu.cfs_quota_us":         "-1\n",
+					"cpu/cpu.cfs_period_us":        "100000\n",
+					"memory/memory.usage_in_bytes": "1000\n",
+					"memory/memory.limit_in_bytes": "9223372036854771712\n",
+					"memory/memory.stat":           "total_inactive_file 0\n",
+				}},
+			},
+			Expectation: &api.ResourcesStatusResponse{
+				Cpu:    &api.ResourceStatus{},
+				Memory: &api.ResourceStatus{Used: 1000},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			base := t.TempDir()
+			monitor := newResourcesMonitor(&Config{}, nil)
+			monitor.CgroupBasePath = base
+
+			now := time.Now()
+			for _, sample := range test.Samples {
+				for fn, content := range sample.Files {
+					fn = filepath.Join(base, fn)
+					err := os.MkdirAll(filepath.Dir(fn), 0755)
+					if err != nil {
+						t.Fatal(err)
+					}
+					err = os.WriteFile(fn, []byte(content), 0644)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				now = now.Add(sample.Delay)
+
+				// the CPU usage depends on the time between samples, hence we don't use monitor.sample
+				var (
+					act = &api.ResourcesStatusResponse{}
+					err error
+				)
+				act.Cpu, err = monitor.cpu(now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				act.Memory, err = monitor.memory()
+				if err != nil {
+					t.Fatal(err)
+				}
+				monitor.status = act
+			}
+
+			if diff := cmp.Diff(test.Expectation, monitor.status, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected status (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResourcesMonitorNotifications(t *testing.T) {
+	base := t.TempDir()
+	files := map[string]string{
+		"cgroup.controllers": "cpu memory",
+		"cpu.stat":           "usage_usec 0\n",
+		"cpu.max":            "max 100000\n",
+		"memory.current":     "500\n",
+		"memory.max":         "1000\n",
+		"memory.stat":        "inactive_file 0\n",
+	}
+	writeFiles := func() {
+		for fn, content := range files {
+			err := os.WriteFile(filepath.Join(base, fn), []byte(content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeFiles()
+
+	notifications := NewNotificationService()
+	monitor := newResourcesMonitor(&Config{WorkspaceConfig: WorkspaceConfig{WorkspaceRoot: base}}, notifications)
+	monitor.CgroupBasePath = base
+
+	sub := monitor.Subscribe()
+	defer sub.Close()
+
+	var severities []api.ResourceStatusSeverity
+	// usage which hovers around a threshold does not notify again until it dropped clearly below the threshold
+	for _, current := range []string{"500", "850", "900", "990", "930", "990", "500", "850", "750", "850"} {
+		files["memory.current"] = current + "\n"
+		writeFiles()
+		monitor.sample()
+
+		update := <-sub.Updates()
+		severities = append(severities, update.Memory.Severity)
+		if update.Disk == nil || update.Disk.Limit == 0 {
+			t.Errorf("disk usage was not sampled: %v", update.Disk)
+		}
+	}
+	if diff := cmp.Diff([]api.ResourceStatusSeverity{
+		api.ResourceStatusSeverity_normal,
+		api.ResourceStatusSeverity_warning,
+		api.ResourceStatusSeverity_warning,
+		api.ResourceStatusSeverity_danger,
+		api.ResourceStatusSeverity_warning,
+		api.ResourceStatusSeverity_danger,
+		api.ResourceStatusSeverity_normal,
+		api.ResourceStatusSeverity_warning,
+		api.ResourceStatusSeverity_normal,
+		api.ResourceStatusSeverity_warning,
+	}, severities); diff != "" {
+		t.Errorf("unexpected severities (-want +got):\n%s", diff)
+	}
+
+	// notifications are sent asynchronously
+	var list *api.ListNotificationsResponse
+	for i := 0; i < 100; i++ {
+		var err error
+		list, err = notifications.ListNotifications(context.Background(), &api.ListNotificationsRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Notifications) >= 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// give superfluous notifications the chance to show up
+	time.Sleep(100 * time.Millisecond)
+	list, err := notifications.ListNotifications(context.Background(), &api.ListNotificationsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var levels []api.NotifyRequest_Level
+	for _, n := range list.Notifications {
+		levels = append(levels, n.Request.Level)
+	}
+	sortLevels := cmpopts.SortSlices(func(a, b api.NotifyRequest_Level) bool { return a < b })
+	if diff := cmp.Diff([]api.NotifyRequest_Level{api.NotifyRequest_WARNING, api.NotifyRequest_ERROR, api.NotifyRequest_WARNING}, levels, sortLevels); diff != "" {
+		t.Errorf("unexpected notifications (-want +got):\n%s", diff)
+	}
+
+	status, err := monitor.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Memory.Used != 850 {
+		t.Errorf("unexpected memory usage in status: %d", status.Memory.Used)
+	}
+}
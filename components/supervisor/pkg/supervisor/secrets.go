@@ -0,0 +1,335 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+const (
+	// secretReferencePrefix marks environment variable values which refer to a secret rather than being the value
+	secretReferencePrefix = "secret://"
+
+	// secretProviderFile and secretProviderVault name the built-in secret providers
+	secretProviderFile  = "file"
+	secretProviderVault = "vault"
+
+	secretResolveTimeout = 30 * time.Second
+)
+
+// secretReference refers to a secret kept in an external store, e.g. secret://vault/secret/data/db#password
+// or secret://file/db-password. The provider is named by the host, the path identifies the secret within the provider.
+type secretReference struct {
+	Provider string
+	Path     string
+}
+
+func (r secretReference) String() string {
+	return secretReferencePrefix + r.Provider + "/" + r.Path
+}
+
+// parseSecretReference parses a secret reference. It returns nil if value is not a secret reference.
+func parseSecretReference(value string) (*secretReference, error) {
+	if !strings.HasPrefix(value, secretReferencePrefix) {
+		return nil, nil
+	}
+	segs := strings.SplitN(strings.TrimPrefix(value, secretReferencePrefix), "/", 2)
+	if len(segs) != 2 || segs[0] == "" || strings.Trim(segs[1], "/") == "" {
+		return nil, xerrors.Errorf("invalid secret reference %s: expected secret://<provider>/<path>", value)
+	}
+	return &secretReference{Provider: segs[0], Path: strings.Trim(segs[1], "/")}, nil
+}
+
+type secretProvider interface {
+	GetSecret(ctx context.Context, path string) (string, error)
+}
+
+// fileSecretProvider reads secrets from files in a directory, e.g. a mounted Kubernetes secret.
+// A single trailing newline is not part of the secret.
+type fileSecretProvider struct {
+	Location string
+}
+
+// GetSecret reads the secret from the file at path, relative to the provider location
+func (p *fileSecretProvider) GetSecret(ctx context.Context, path string) (string, error) {
+	// cleaning the path as absolute one prevents it from leaving the location
+	fn := filepath.Join(p.Location, filepath.Clean("/"+path))
+	content, err := os.ReadFile(fn)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r"), nil
+}
+
+// vaultSecretProvider reads secrets from HashiCorp Vault. The path is the API path of the secret,
+// e.g. secret/data/db for the db secret in the KV version 2 engine mounted at secret/, followed
+// by #<key> to pick a key of the secret. The key can be omitted if the secret has a single key only.
+//
+// References can come from a repository's .gitpod.yml, yet are resolved with the user's Vault token.
+// Hence only paths the user allowed are read, see vaultPathAllowed.
+type vaultSecretProvider struct {
+	Addr   string
+	Token  func(ctx context.Context) (string, error)
+	Client *http.Client
+
+	// Paths are the patterns of the secret paths which may be read. No secret is read if empty.
+	Paths []string
+}
+
+// GetSecret reads the secret from Vault
+func (p *vaultSecretProvider) GetSecret(ctx context.Context, path string) (string, error) {
+	if p.Addr == "" {
+		return "", xerrors.Errorf("Vault is not configured: VAULT_ADDR is not set")
+	}
+
+	var key string
+	if i := strings.LastIndex(path, "#"); i >= 0 {
+		path, key = path[:i], path[i+1:]
+	}
+	if !vaultPathAllowed(p.Paths, path) {
+		return "", xerrors.Errorf("reading %s from Vault is not allowed: add it to GITPOD_VAULT_SECRET_PATHS", path)
+	}
+
+	token, err := p.Token(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("cannot get Vault token: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.Addr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []string               `json:"errors"`
+	}
+	err = json.Unmarshal(body, &secret)
+	if err != nil {
+		return "", xerrors.Errorf("cannot parse Vault response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("cannot read %s from Vault (%s): %s", path, resp.Status, strings.Join(secret.Errors, ", "))
+	}
+
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			// KV version 2 wraps the secret data
+			data = inner
+		}
+	}
+	if key == "" {
+		if len(data) != 1 {
+			return "", xerrors.Errorf("secret %s has %d keys: use %s#<key> to pick one", path, len(data), path)
+		}
+		for k := range data {
+			key = k
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", xerrors.Errorf("secret %s has no key %s", path, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	res, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+// vaultPathAllowed returns true if the secret path matches one of the patterns. Like the repository patterns
+// of user environment variables, a * matches a single path segment. A pattern also matches all paths below it,
+// e.g. secret/data/gitpod-io/* allows secret/data/gitpod-io/gitpod/db, but not secret/data/other/db.
+func vaultPathAllowed(patterns []string, p string) bool {
+	segs := strings.Split(p, "/")
+	for _, seg := range segs {
+		if seg == "" || seg == "." || seg == ".." {
+			return false
+		}
+	}
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		n := strings.Count(pattern, "/") + 1
+		if len(segs) < n {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.Join(segs[:n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// secretResolver resolves secret references in environment variables. Resolved secrets are
+// redacted from terminal output. Secrets can also be requested through the token service
+// (kind secret, host the provider and scope the path), but only those the workspace refers to.
+//
+// Secrets are not written to a tmpfs for the workspace to read. All processes of the workspace run as the
+// same user, so file permissions could not scope access any further than the token service does, while the
+// files would keep the secrets around after they were used.
+type secretResolver struct {
+	providers map[string]secretProvider
+	redactor  *terminal.Redactor
+
+	mu       sync.RWMutex
+	declared map[secretReference]struct{}
+}
+
+func newSecretResolver(providers map[string]secretProvider, redactor *terminal.Redactor) *secretResolver {
+	return &secretResolver{
+		providers: providers,
+		redactor:  redactor,
+		declared:  make(map[secretReference]struct{}),
+	}
+}
+
+// newSecretProviders produces the built-in secret providers. The Vault token is taken from the
+// token service (kind vault, host the Vault host) and falls back to VAULT_TOKEN.
+func newSecretProviders(cfg *Config, tokenService *InMemoryTokenService) map[string]secretProvider {
+	res := map[string]secretProvider{
+		secretProviderVault: &vaultSecretProvider{
+			Addr: cfg.VaultAddr,
+			Token: func(ctx context.Context) (string, error) {
+				var host string
+				if u, err := url.Parse(cfg.VaultAddr); err == nil {
+					host = u.Host
+				}
+				resp, err := tokenService.GetToken(ctx, &api.GetTokenRequest{Kind: KindVault, Host: host})
+				if err == nil {
+					return resp.Token, nil
+				}
+				if cfg.VaultToken != "" {
+					return cfg.VaultToken, nil
+				}
+				return "", xerrors.Errorf("no Vault token for %s available: set VAULT_TOKEN or provide one through the token service", host)
+			},
+			Client: &http.Client{Timeout: secretResolveTimeout},
+			Paths:  cfg.VaultPaths(),
+		},
+	}
+	if cfg.SecretFileLocation != "" {
+		res[secretProviderFile] = &fileSecretProvider{Location: cfg.SecretFileLocation}
+	}
+	return res
+}
+
+// Declare allows the secrets env refers to to be requested through the token service
+func (r *secretResolver) Declare(env map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, value := range env {
+		ref, err := parseSecretReference(value)
+		if err != nil {
+			return xerrors.Errorf("%s: %w", name, err)
+		}
+		if ref != nil {
+			r.declared[*ref] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// ResolveEnv returns env with all secret references replaced by the secrets they refer to
+func (r *secretResolver) ResolveEnv(ctx context.Context, env map[string]string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, secretResolveTimeout)
+	defer cancel()
+
+	res := make(map[string]string, len(env))
+	for name, value := range env {
+		ref, err := parseSecretReference(value)
+		if err != nil {
+			return nil, xerrors.Errorf("%s: %w", name, err)
+		}
+		if ref == nil {
+			res[name] = value
+			continue
+		}
+		secret, err := r.resolve(ctx, *ref)
+		if err != nil {
+			return nil, xerrors.Errorf("cannot resolve %s: %w", name, err)
+		}
+		res[name] = secret
+	}
+	return res, nil
+}
+
+func (r *secretResolver) resolve(ctx context.Context, ref secretReference) (string, error) {
+	provider, ok := r.providers[ref.Provider]
+	if !ok {
+		return "", xerrors.Errorf("unknown secret provider %s in %s", ref.Provider, ref)
+	}
+	secret, err := provider.GetSecret(ctx, ref.Path)
+	if err != nil {
+		return "", xerrors.Errorf("cannot get %s: %w", ref, err)
+	}
+	if r.redactor != nil {
+		r.redactor.Add(secret)
+	}
+	return secret, nil
+}
+
+// GetToken provides the secret the request refers to, if the workspace refers to it too
+func (r *secretResolver) GetToken(ctx context.Context, req *api.GetTokenRequest) (*Token, error) {
+	if len(req.Scope) != 1 {
+		return nil, xerrors.Errorf("secrets must be requested with their path as the only scope")
+	}
+	ref := secretReference{Provider: req.Host, Path: strings.Trim(req.Scope[0], "/")}
+
+	r.mu.RLock()
+	_, declared := r.declared[ref]
+	r.mu.RUnlock()
+	if !declared {
+		return nil, xerrors.Errorf("%s is not referred to by the workspace", ref)
+	}
+
+	secret, err := r.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &Token{
+		Host:  req.Host,
+		Token: secret,
+		Scope: mapScopes(req.Scope),
+		// secrets are never cached, so that they're not persisted and always current
+		Reuse: api.TokenReuse_REUSE_NEVER,
+	}, nil
+}
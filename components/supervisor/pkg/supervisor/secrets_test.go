@@ -0,0 +1,227 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+func TestParseSecretReference(t *testing.T) {
+	type Expectation struct {
+		Ref *secretReference
+		Err bool
+	}
+	tests := []struct {
+		Input       string
+		Expectation Expectation
+	}{
+		{Input: "plain value"},
+		{Input: "https://example.com"},
+		{Input: "secret://vault/secret/data/db#password", Expectation: Expectation{Ref: &secretReference{Provider: "vault", Path: "secret/data/db#password"}}},
+		{Input: "secret://file/db-password/", Expectation: Expectation{Ref: &secretReference{Provider: "file", Path: "db-password"}}},
+		{Input: "secret://vault", Expectation: Expectation{Err: true}},
+		{Input: "secret:///db-password", Expectation: Expectation{Err: true}},
+		{Input: "secret://file/", Expectation: Expectation{Err: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			var act Expectation
+			ref, err := parseSecretReference(test.Input)
+			act.Ref = ref
+			act.Err = err != nil
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "db-password"), []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(filepath.Dir(dir), "outside")
+	err = os.WriteFile(outside, []byte("outside"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(outside)
+
+	provider := &fileSecretProvider{Location: dir}
+	secret, err := provider.GetSecret(context.Background(), "db-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "s3cr3t" {
+		t.Errorf("unexpected secret: %q", secret)
+	}
+
+	_, err = provider.GetSecret(context.Background(), "../outside")
+	if err == nil {
+		t.Error("secret outside of the location was read")
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			_, _ = w.Write([]byte(`{"data":{"data":{"user":"gitpod","password":"s3cr3t"},"metadata":{"version":1}}}`))
+		case "/v1/kv/api-key":
+			_, _ = w.Write([]byte(`{"data":{"key":"api-s3cr3t"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	type Expectation struct {
+		Secret string
+		Err    bool
+	}
+	tests := []struct {
+		Name        string
+		Path        string
+		Token       string
+		Expectation Expectation
+	}{
+		{Name: "kv v2 with key", Path: "secret/data/db#password", Token: "vault-token", Expectation: Expectation{Secret: "s3cr3t"}},
+		{Name: "kv v2 without key", Path: "secret/data/db", Token: "vault-token", Expectation: Expectation{Err: true}},
+		{Name: "kv v1 single key", Path: "kv/api-key", Token: "vault-token", Expectation: Expectation{Secret: "api-s3cr3t"}},
+		{Name: "unknown key", Path: "kv/api-key#other", Token: "vault-token", Expectation: Expectation{Err: true}},
+		{Name: "not found", Path: "kv/unknown", Token: "vault-token", Expectation: Expectation{Err: true}},
+		{Name: "permission denied", Path: "kv/api-key", Token: "wrong-token", Expectation: Expectation{Err: true}},
+		{Name: "path not allowed", Path: "secret/data/other#password", Token: "vault-token", Expectation: Expectation{Err: true}},
+		{Name: "path leaving allowed one", Path: "kv/../secret/data/other", Token: "vault-token", Expectation: Expectation{Err: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			provider := &vaultSecretProvider{
+				Addr:  srv.URL,
+				Token: func(ctx context.Context) (string, error) { return test.Token, nil },
+				Paths: []string{"secret/data/db", "kv"},
+			}
+			var act Expectation
+			secret, err := provider.GetSecret(context.Background(), test.Path)
+			act.Secret = secret
+			act.Err = err != nil
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVaultPathAllowed(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Patterns    []string
+		Path        string
+		Expectation bool
+	}{
+		{Desc: "no patterns", Path: "secret/data/db"},
+		{Desc: "exact path", Patterns: []string{"secret/data/db"}, Path: "secret/data/db", Expectation: true},
+		{Desc: "below pattern", Patterns: []string{"secret/data/gitpod-io/gitpod"}, Path: "secret/data/gitpod-io/gitpod/db", Expectation: true},
+		{Desc: "wildcard segment", Patterns: []string{"secret/data/gitpod-io/*"}, Path: "secret/data/gitpod-io/website/db", Expectation: true},
+		{Desc: "wildcard does not cross segments", Patterns: []string{"secret/data/*/gitpod"}, Path: "secret/data/gitpod-io/website/gitpod"},
+		{Desc: "other path", Patterns: []string{"secret/data/gitpod-io/*"}, Path: "secret/data/other/db"},
+		{Desc: "shorter than pattern", Patterns: []string{"secret/data/gitpod-io/*"}, Path: "secret/data"},
+		{Desc: "prefix of segment", Patterns: []string{"secret/data/db"}, Path: "secret/data/dbs"},
+		{Desc: "parent segment", Patterns: []string{"secret/data/gitpod-io/*"}, Path: "secret/data/gitpod-io/../other/db"},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			act := vaultPathAllowed(test.Patterns, test.Path)
+			if act != test.Expectation {
+				t.Errorf("vaultPathAllowed(%v, %q) = %v, expected %v", test.Patterns, test.Path, act, test.Expectation)
+			}
+		})
+	}
+}
+
+type secretProviderFunc func(ctx context.Context, path string) (string, error)
+
+func (f secretProviderFunc) GetSecret(ctx context.Context, path string) (string, error) {
+	return f(ctx, path)
+}
+
+func TestSecretResolver(t *testing.T) {
+	var (
+		redactor terminal.Redactor
+		secrets  = map[string]string{"db-password": "s3cr3t", "api-key": "api-s3cr3t"}
+	)
+	resolver := newSecretResolver(map[string]secretProvider{
+		"test": secretProviderFunc(func(ctx context.Context, path string) (string, error) {
+			secret, ok := secrets[path]
+			if !ok {
+				return "", os.ErrNotExist
+			}
+			return secret, nil
+		}),
+	}, &redactor)
+
+	env := map[string]string{
+		"DB_USER":     "gitpod",
+		"DB_PASSWORD": "secret://test/db-password",
+	}
+	err := resolver.Declare(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := resolver.ResolveEnv(context.Background(), env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"DB_USER": "gitpod", "DB_PASSWORD": "s3cr3t"}, resolved); diff != "" {
+		t.Errorf("unexpected environment (-want +got):\n%s", diff)
+	}
+	if act := string(redactor.Redact([]byte("password: s3cr3t"))); act != "password: ********" {
+		t.Errorf("resolved secret is not redacted: %q", act)
+	}
+
+	_, err = resolver.ResolveEnv(context.Background(), map[string]string{"UNKNOWN": "secret://test/unknown"})
+	if err == nil {
+		t.Error("unknown secret was resolved")
+	}
+	_, err = resolver.ResolveEnv(context.Background(), map[string]string{"UNKNOWN": "secret://other/db-password"})
+	if err == nil {
+		t.Error("secret of unknown provider was resolved")
+	}
+
+	tokenService := NewInMemoryTokenService()
+	tokenService.provider[KindSecret] = []tokenProvider{resolver}
+	resp, err := tokenService.GetToken(context.Background(), &api.GetTokenRequest{Kind: KindSecret, Host: "test", Scope: []string{"db-password"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Token != "s3cr3t" {
+		t.Errorf("unexpected secret from token service: %q", resp.Token)
+	}
+	if n := len(tokenService.token[KindSecret]); n != 0 {
+		t.Errorf("secrets must not be cached, got %d", n)
+	}
+	_, err = tokenService.GetToken(context.Background(), &api.GetTokenRequest{Kind: KindSecret, Host: "test", Scope: []string{"api-key"}})
+	if err == nil {
+		t.Error("token service provided a secret the workspace does not refer to")
+	}
+}
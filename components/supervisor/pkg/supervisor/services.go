@@ -80,7 +80,9 @@ type statusService struct {
 	ContentState ContentState
 	Ports        *ports.Manager
 	Tasks        *tasksManager
-	ideReady     *ideReadyState
+	// ideBackends are the IDE backends of the workspace, the primary one first
+	ideBackends []*ideBackend
+	Resources   *resourcesMonitor
 
 	api.UnimplementedStatusServiceServer
 }
@@ -98,17 +100,36 @@ func (s *statusService) SupervisorStatus(context.Context, *api.SupervisorStatusR
 }
 
 func (s *statusService) IDEStatus(ctx context.Context, req *api.IDEStatusRequest) (*api.IDEStatusResponse, error) {
+	var ide *ideBackend
+	for _, backend := range s.ideBackends {
+		if (req.Backend == "" && backend.Primary) || (req.Backend != "" && req.Backend == backend.Config.name()) {
+			ide = backend
+			break
+		}
+	}
+	if ide == nil {
+		return nil, status.Errorf(codes.NotFound, "IDE backend %s not found", req.Backend)
+	}
+
 	if req.Wait {
 		select {
-		case <-s.ideReady.Wait():
-			return &api.IDEStatusResponse{Ok: true}, nil
+		case <-ide.ready.Wait():
+			return &api.IDEStatusResponse{Ok: true, Backends: s.ideBackendsStatus()}, nil
 		case <-ctx.Done():
 			return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
 		}
 	}
 
-	ok := s.ideReady.Get()
-	return &api.IDEStatusResponse{Ok: ok}, nil
+	ok := ide.ready.Get()
+	return &api.IDEStatusResponse{Ok: ok, Backends: s.ideBackendsStatus()}, nil
+}
+
+func (s *statusService) ideBackendsStatus() []*api.IDEBackendStatus {
+	res := make([]*api.IDEBackendStatus, 0, len(s.ideBackends))
+	for _, backend := range s.ideBackends {
+		res = append(res, backend.Status())
+	}
+	return res
 }
 
 // ContentStatus provides feedback regarding the workspace content readiness
@@ -153,7 +174,7 @@ func (s *statusService) BackupStatus(ctx context.Context, req *api.BackupStatusR
 func (s *statusService) PortsStatus(req *api.PortsStatusRequest, srv api.StatusService_PortsStatusServer) error {
 	if !req.Observe {
 		return srv.Send(&api.PortsStatusResponse{
-			Ports: s.Ports.Status(),
+			Ports: filterPortsStatus(s.Ports.Status(), req.IncludeUdp),
 		})
 	}
 
@@ -175,7 +196,7 @@ func (s *statusService) PortsStatus(req *api.PortsStatusRequest, srv api.StatusS
 				return nil
 			}
 			err := srv.Send(&api.PortsStatusResponse{
-				Ports: update,
+				Ports: filterPortsStatus(update, req.IncludeUdp),
 			})
 			if err != nil {
 				return err
@@ -184,6 +205,21 @@ func (s *statusService) PortsStatus(req *api.PortsStatusRequest, srv api.StatusS
 	}
 }
 
+// filterPortsStatus removes UDP ports unless the client asked for them
+func filterPortsStatus(ports []*api.PortsStatus, includeUDP bool) []*api.PortsStatus {
+	if includeUDP {
+		return ports
+	}
+	res := make([]*api.PortsStatus, 0, len(ports))
+	for _, p := range ports {
+		if p.TransportProtocol == api.TransportProtocol_transport_udp {
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
 func (s *statusService) TasksStatus(req *api.TasksStatusRequest, srv api.StatusService_TasksStatusServer) error {
 	select {
 	case <-srv.Context().Done():
@@ -219,6 +255,53 @@ func (s *statusService) TasksStatus(req *api.TasksStatusRequest, srv api.StatusS
 	}
 }
 
+func (s *statusService) StopTask(ctx context.Context, req *api.StopTaskRequest) (*api.StopTaskResponse, error) {
+	err := s.Tasks.Stop(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &api.StopTaskResponse{}, nil
+}
+
+func (s *statusService) RestartTask(ctx context.Context, req *api.RestartTaskRequest) (*api.RestartTaskResponse, error) {
+	err := s.Tasks.Restart(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &api.RestartTaskResponse{}, nil
+}
+
+func (s *statusService) ResourcesStatus(req *api.ResourcesStatusRequest, srv api.StatusService_ResourcesStatusServer) error {
+	if !req.Observe {
+		resp, err := s.Resources.Status(srv.Context())
+		if err != nil {
+			return status.Error(codes.Canceled, err.Error())
+		}
+		return srv.Send(resp)
+	}
+
+	sub := s.Resources.Subscribe()
+	if sub == nil {
+		return status.Error(codes.ResourceExhausted, "too many subscriptions")
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case update := <-sub.Updates():
+			if update == nil {
+				return nil
+			}
+			err := srv.Send(update)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
 // RegistrableTokenService can register the token service
 type RegistrableTokenService struct {
 	Service api.TokenServiceServer
@@ -239,8 +322,10 @@ func (s RegistrableTokenService) RegisterREST(mux *runtime.ServeMux, grpcEndpoin
 // NewInMemoryTokenService produces a new InMemoryTokenService
 func NewInMemoryTokenService() *InMemoryTokenService {
 	return &InMemoryTokenService{
-		token:    make(map[string][]*Token),
-		provider: make(map[string][]tokenProvider),
+		token:         make(map[string][]*Token),
+		provider:      make(map[string][]tokenProvider),
+		refreshFailed: make(map[*Token]struct{}),
+		subscriptions: make(map[*tokenEventSubscription]struct{}),
 	}
 }
 
@@ -296,12 +381,17 @@ type tokenProvider interface {
 	GetToken(ctx context.Context, req *api.GetTokenRequest) (tkn *Token, err error)
 }
 
-// InMemoryTokenService provides an in-memory caching token service
+// InMemoryTokenService provides an in-memory caching token service.
+// The cache can be persisted to disk using PersistTo, so that it survives supervisor restarts.
 type InMemoryTokenService struct {
 	token    map[string][]*Token
 	provider map[string][]tokenProvider
 	mu       sync.RWMutex
 
+	store         *tokenStore
+	refreshFailed map[*Token]struct{}
+	subscriptions map[*tokenEventSubscription]struct{}
+
 	api.UnimplementedTokenServiceServer
 }
 
@@ -343,6 +433,21 @@ func (s *InMemoryTokenService) GetToken(ctx context.Context, req *api.GetTokenRe
 	return nil, status.Error(codes.NotFound, "no token available")
 }
 
+// validTokens returns the cached tokens of a kind which have not expired yet
+func (s *InMemoryTokenService) validTokens(kind string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []string
+	for _, tkn := range s.token[kind] {
+		if tkn.ExpiryDate != nil && time.Now().After(*tkn.ExpiryDate) {
+			continue
+		}
+		res = append(res, tkn.Token)
+	}
+	return res
+}
+
 func asGetTokenResponse(tkn *Token) *api.GetTokenResponse {
 	resp := &api.GetTokenResponse{Token: tkn.Token, User: tkn.User}
 	for scope := range tkn.Scope {
@@ -372,8 +477,19 @@ func (s *InMemoryTokenService) cacheToken(kind string, tkn *Token) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.token[kind] = append(s.token[kind], tkn)
+	token := s.token[kind]
+	for i, t := range token {
+		if t.Token == tkn.Token && t.Host == tkn.Host {
+			// the same token was cached before, e.g. restored from disk
+			token = append(token[:i], token[i+1:]...)
+			delete(s.refreshFailed, t)
+			break
+		}
+	}
+	s.token[kind] = append(token, tkn)
 	log.WithField("kind", kind).WithField("host", tkn.Host).WithField("scopes", tkn.Scope).WithField("reuse", tkn.Reuse.String()).Info("registered new token")
+	s.persist()
+	s.publishTokenEvent(api.TokenEventsResponse_CACHED, kind, tkn, "")
 }
 
 func convertReceivedToken(req *api.SetTokenRequest) (tkn *Token, err error) {
@@ -431,7 +547,12 @@ func (s *InMemoryTokenService) ClearToken(ctx context.Context, req *api.ClearTok
 		s.mu.Lock()
 		defer s.mu.Unlock()
 
+		for _, t := range s.token[req.Kind] {
+			delete(s.refreshFailed, t)
+			s.publishTokenEvent(api.TokenEventsResponse_CLEARED, req.Kind, t, "")
+		}
 		s.token[req.Kind] = nil
+		s.persist()
 
 		log.WithField("kind", req.Kind).Info("cleared all cached tokens")
 		return &api.ClearTokenResponse{}, nil
@@ -449,6 +570,8 @@ func (s *InMemoryTokenService) ClearToken(ctx context.Context, req *api.ClearTok
 
 			found = true
 			token = append(token[:i], token[i+1:]...)
+			delete(s.refreshFailed, t)
+			s.publishTokenEvent(api.TokenEventsResponse_CLEARED, req.Kind, t, "")
 			log.WithField("kind", req.Kind).WithField("host", t.Host).WithField("scopes", t.Scope).Info("cleared token")
 			break
 		}
@@ -456,6 +579,7 @@ func (s *InMemoryTokenService) ClearToken(ctx context.Context, req *api.ClearTok
 		if !found {
 			return nil, status.Error(codes.NotFound, "token not found")
 		}
+		s.persist()
 
 		return &api.ClearTokenResponse{}, nil
 	}
@@ -651,6 +775,12 @@ func (c *ControlService) ExposePort(ctx context.Context, req *api.ExposePortRequ
 	return &api.ExposePortResponse{}, err
 }
 
+// SetPortVisibility changes the visibility of an exposed port
+func (c *ControlService) SetPortVisibility(ctx context.Context, req *api.SetPortVisibilityRequest) (*api.SetPortVisibilityResponse, error) {
+	err := c.portsManager.SetVisibility(ctx, req.Port, req.Visibility)
+	return &api.SetPortVisibilityResponse{}, err
+}
+
 // ContentState signals the workspace content state
 type ContentState interface {
 	MarkContentReady(src csapi.WorkspaceInitSource)
@@ -717,6 +847,7 @@ func (s *portService) Tunnel(ctx context.Context, req *api.TunnelPortRequest) (*
 		LocalPort:  req.Port,
 		TargetPort: req.TargetPort,
 		Visibility: req.Visibility,
+		Transport:  req.TransportProtocol,
 	})
 	if err != nil {
 		return nil, err
@@ -726,7 +857,7 @@ func (s *portService) Tunnel(ctx context.Context, req *api.TunnelPortRequest) (*
 
 // CloseTunnel closes the tunnel.
 func (s *portService) CloseTunnel(ctx context.Context, req *api.CloseTunnelRequest) (*api.CloseTunnelResponse, error) {
-	err := s.portsManager.CloseTunnel(ctx, req.Port)
+	err := s.portsManager.CloseTunnel(ctx, req.Port, req.TransportProtocol)
 	if err != nil {
 		return nil, err
 	}
@@ -744,7 +875,7 @@ func (s *portService) EstablishTunnel(stream api.PortService_EstablishTunnelServ
 		return status.Error(codes.FailedPrecondition, "first request should be a desc")
 	}
 
-	tunnel, err := s.portsManager.EstablishTunnel(stream.Context(), desc.ClientId, desc.Port, desc.TargetPort)
+	tunnel, err := s.portsManager.EstablishTunnel(stream.Context(), desc.ClientId, desc.Port, desc.TargetPort, desc.TransportProtocol)
 	if err != nil {
 		return status.Errorf(codes.Internal, "failed establish the tunnel: %v", err)
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/gitpod-io/gitpod/supervisor/api"
@@ -384,3 +385,66 @@ type tokenProviderFunc func(ctx context.Context, req *api.GetTokenRequest) (tkn
 func (f tokenProviderFunc) GetToken(ctx context.Context, req *api.GetTokenRequest) (tkn *Token, err error) {
 	return f(ctx, req)
 }
+
+func TestIDEStatus(t *testing.T) {
+	cfg := &Config{
+		IDEConfig: IDEConfig{Entrypoint: "/ide/startup.sh"},
+		IDEBackends: []IDEConfig{
+			{Name: "intellij", Entrypoint: "/ide-desktop/startup.sh", Port: 63342},
+		},
+	}
+	cfg.IDEPort = 23000
+	backends := newIDEBackends(cfg)
+	backends[1].ready.Set(true)
+	backends[1].restarted()
+	srv := &statusService{ideBackends: backends}
+
+	type Expectation struct {
+		Resp *api.IDEStatusResponse
+		Code codes.Code
+	}
+	allBackends := []*api.IDEBackendStatus{
+		{Name: defaultIDEName, Port: 23000, Primary: true},
+		{Name: "intellij", Ok: true, Port: 63342, RestartCount: 1},
+	}
+	tests := []struct {
+		Name        string
+		Req         *api.IDEStatusRequest
+		Expectation Expectation
+	}{
+		{
+			Name:        "primary",
+			Req:         &api.IDEStatusRequest{},
+			Expectation: Expectation{Resp: &api.IDEStatusResponse{Ok: false, Backends: allBackends}},
+		},
+		{
+			Name:        "backend",
+			Req:         &api.IDEStatusRequest{Backend: "intellij"},
+			Expectation: Expectation{Resp: &api.IDEStatusResponse{Ok: true, Backends: allBackends}},
+		},
+		{
+			Name:        "wait for backend",
+			Req:         &api.IDEStatusRequest{Backend: "intellij", Wait: true},
+			Expectation: Expectation{Resp: &api.IDEStatusResponse{Ok: true, Backends: allBackends}},
+		},
+		{
+			Name:        "unknown backend",
+			Req:         &api.IDEStatusRequest{Backend: "vscode"},
+			Expectation: Expectation{Code: codes.NotFound},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var act Expectation
+			resp, err := srv.IDEStatus(ctx, test.Req)
+			act.Resp = resp
+			act.Code = status.Code(err)
+			if diff := cmp.Diff(test.Expectation, act, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,700 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/ports"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+const (
+	// sshAuthorizedKeysLocation lists public keys which may log in, in addition to the ones registered with the token service
+	sshAuthorizedKeysLocation = "/home/" + gitpodUserName + "/.ssh/authorized_keys"
+
+	// sshTerminalAnnotation marks terminals of SSH sessions, the value is the remote address of the session
+	sshTerminalAnnotation = "ssh"
+
+	sshTerminalGracePeriod = 5 * time.Second
+
+	// sshOwnerTokenHost is the host owner tokens of the workspace itself are registered for
+	sshOwnerTokenHost = "workspace"
+
+	// maxSSHAcceptDelay caps the delay before accepting connections again after an error
+	maxSSHAcceptDelay = 1 * time.Second
+)
+
+// sshServer is the SSH server of the workspace. Users authenticate with an owner token as password,
+// or with a public key registered with the token service or listed in the authorized keys file.
+// Owner tokens and keys are registered with SetToken (kind owner or ssh-key) and must be reusable to be kept.
+// Interactive sessions run in terminals of the terminal mux, hence they show up in the terminal list.
+// Local port forwarding uses the tunnels of the ports manager.
+type sshServer struct {
+	Tokens    *InMemoryTokenService
+	Terminals *terminal.MuxTerminalService
+	Ports     *ports.Manager
+
+	// AuthorizedKeysLocation is an authorized_keys file, it's ignored if empty or missing
+	AuthorizedKeysLocation string
+
+	config *ssh.ServerConfig
+}
+
+func newSSHServer(tokens *InMemoryTokenService, terminals *terminal.MuxTerminalService, portsManager *ports.Manager) (*sshServer, error) {
+	hostKey, err := generateHostKey()
+	if err != nil {
+		return nil, xerrors.Errorf("cannot generate host key: %w", err)
+	}
+
+	srv := &sshServer{
+		Tokens:                 tokens,
+		Terminals:              terminals,
+		Ports:                  portsManager,
+		AuthorizedKeysLocation: sshAuthorizedKeysLocation,
+	}
+	srv.config = &ssh.ServerConfig{
+		PasswordCallback:  srv.authenticatePassword,
+		PublicKeyCallback: srv.authenticatePublicKey,
+	}
+	srv.config.AddHostKey(hostKey)
+	return srv, nil
+}
+
+// registerOwnerToken registers the owner token the workspace was started with, s.t. its owner can log in with it
+func registerOwnerToken(tokens *InMemoryTokenService, token string) error {
+	_, err := tokens.SetToken(context.Background(), &api.SetTokenRequest{
+		Kind:  KindOwner,
+		Host:  sshOwnerTokenHost,
+		Token: token,
+		Reuse: api.TokenReuse_REUSE_WHEN_POSSIBLE,
+	})
+	return err
+}
+
+func (s *sshServer) authenticatePassword(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	for _, tkn := range s.Tokens.validTokens(KindOwner) {
+		if subtle.ConstantTimeCompare([]byte(tkn), password) == 1 {
+			return &ssh.Permissions{}, nil
+		}
+	}
+	return nil, xerrors.Errorf("invalid owner token for %s", conn.User())
+}
+
+func (s *sshServer) authenticatePublicKey(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	authorized := s.Tokens.validTokens(KindSSHKey)
+	if s.AuthorizedKeysLocation != "" {
+		content, err := os.ReadFile(s.AuthorizedKeysLocation)
+		if err != nil && !os.IsNotExist(err) {
+			log.WithError(err).Warn("cannot read authorized SSH keys")
+		}
+		if len(content) > 0 {
+			authorized = append(authorized, string(content))
+		}
+	}
+
+	marshaled := key.Marshal()
+	for _, keys := range authorized {
+		rest := []byte(keys)
+		for len(rest) > 0 {
+			var (
+				authorizedKey ssh.PublicKey
+				err           error
+			)
+			authorizedKey, _, _, rest, err = ssh.ParseAuthorizedKey(rest)
+			if err != nil {
+				break
+			}
+			if bytes.Equal(authorizedKey.Marshal(), marshaled) {
+				return &ssh.Permissions{
+					Extensions: map[string]string{"pubkey-fp": ssh.FingerprintSHA256(key)},
+				}, nil
+			}
+		}
+	}
+	return nil, xerrors.Errorf("unknown public key for %s", conn.User())
+}
+
+// ListenAndServe serves SSH connections on addr until the context is canceled
+func (s *sshServer) ListenAndServe(ctx context.Context, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var delay time.Duration
+	for {
+		conn, err := l.Accept()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		if err != nil {
+			// e.g. running out of file descriptors - back off and retry as long as the listener is open
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else {
+				delay *= 2
+			}
+			if delay > maxSSHAcceptDelay {
+				delay = maxSSHAcceptDelay
+			}
+			log.WithError(err).WithField("delay", delay.String()).Warn("ssh: cannot accept connection - retrying")
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+		go s.handleConn(ctx, conn)
+	}
+}
+
+// sshConnection is an authenticated SSH connection
+type sshConnection struct {
+	server *sshServer
+	conn   *ssh.ServerConn
+	// clientID identifies the connection as client of port tunnels
+	clientID string
+
+	mu sync.Mutex
+	// forwards are the listeners of remote port forwarding by their address
+	forwards map[string]net.Listener
+	// tunnels are the ports tunneled for local port forwarding, which were not tunneled before
+	tunnels map[uint32]struct{}
+}
+
+func (s *sshServer) handleConn(ctx context.Context, nConn net.Conn) {
+	conn, chans, reqs, err := ssh.NewServerConn(nConn, s.config)
+	if err != nil {
+		log.WithError(err).WithField("remote", nConn.RemoteAddr().String()).Debug("ssh: handshake failed")
+		nConn.Close()
+		return
+	}
+	sshLog := log.WithField("remote", conn.RemoteAddr().String()).WithField("user", conn.User())
+	sshLog.Info("ssh: new connection")
+	defer sshLog.Info("ssh: connection closed")
+
+	c := &sshConnection{
+		server:   s,
+		conn:     conn,
+		clientID: "ssh-" + hex.EncodeToString(conn.SessionID()[:8]),
+		forwards: make(map[string]net.Listener),
+		tunnels:  make(map[uint32]struct{}),
+	}
+	defer c.cleanup(ctx)
+
+	go c.handleGlobalRequests(ctx, reqs)
+	for newCh := range chans {
+		switch newCh.ChannelType() {
+		case "session":
+			go c.handleSession(ctx, newCh)
+		case "direct-tcpip":
+			go c.handleDirectTCPIP(ctx, newCh)
+		default:
+			_ = newCh.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+func (c *sshConnection) cleanup(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, l := range c.forwards {
+		l.Close()
+		delete(c.forwards, addr)
+	}
+	for port := range c.tunnels {
+		err := c.server.Ports.CloseTunnel(ctx, port, api.TransportProtocol_transport_tcp)
+		if err != nil {
+			log.WithError(err).WithField("port", port).Warn("ssh: cannot close tunnel")
+		}
+		delete(c.tunnels, port)
+	}
+}
+
+type sshForwardRequest struct {
+	BindAddr string
+	BindPort uint32
+}
+
+type sshForwardedChannel struct {
+	Addr       string
+	Port       uint32
+	OriginAddr string
+	OriginPort uint32
+}
+
+// handleGlobalRequests handles remote port forwarding, i.e. ssh -R
+func (c *sshConnection) handleGlobalRequests(ctx context.Context, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		switch req.Type {
+		case "tcpip-forward":
+			var fwd sshForwardRequest
+			err := ssh.Unmarshal(req.Payload, &fwd)
+			if err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			port, err := c.forward(fwd)
+			if err != nil {
+				log.WithError(err).WithField("addr", fwd.BindAddr).WithField("port", fwd.BindPort).Warn("ssh: cannot forward remote port")
+				_ = req.Reply(false, nil)
+				continue
+			}
+			var resp []byte
+			if fwd.BindPort == 0 {
+				resp = ssh.Marshal(struct{ Port uint32 }{port})
+			}
+			_ = req.Reply(true, resp)
+
+		case "cancel-tcpip-forward":
+			var fwd sshForwardRequest
+			err := ssh.Unmarshal(req.Payload, &fwd)
+			if err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			addr := net.JoinHostPort(fwd.BindAddr, strconv.FormatUint(uint64(fwd.BindPort), 10))
+			c.mu.Lock()
+			l, ok := c.forwards[addr]
+			delete(c.forwards, addr)
+			c.mu.Unlock()
+			if ok {
+				l.Close()
+			}
+			_ = req.Reply(ok, nil)
+
+		default:
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+// forward listens on a port in the workspace and forwards its connections to the client.
+// The ports manager picks up the listener as a served port.
+func (c *sshConnection) forward(fwd sshForwardRequest) (port uint32, err error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(fwd.BindAddr, strconv.FormatUint(uint64(fwd.BindPort), 10)))
+	if err != nil {
+		return 0, err
+	}
+	port = uint32(l.Addr().(*net.TCPAddr).Port)
+
+	c.mu.Lock()
+	c.forwards[net.JoinHostPort(fwd.BindAddr, strconv.FormatUint(uint64(fwd.BindPort), 10))] = l
+	c.mu.Unlock()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				origin := conn.RemoteAddr().(*net.TCPAddr)
+				ch, reqs, err := c.conn.OpenChannel("forwarded-tcpip", ssh.Marshal(&sshForwardedChannel{
+					Addr:       fwd.BindAddr,
+					Port:       port,
+					OriginAddr: origin.IP.String(),
+					OriginPort: uint32(origin.Port),
+				}))
+				if err != nil {
+					log.WithError(err).WithField("port", port).Debug("ssh: client rejected forwarded connection")
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				pipe(ch, conn)
+			}()
+		}
+	}()
+	return port, nil
+}
+
+type sshDirectTCPIP struct {
+	Host       string
+	Port       uint32
+	OriginAddr string
+	OriginPort uint32
+}
+
+// handleDirectTCPIP handles local port forwarding, i.e. ssh -L. Only ports of the workspace can be forwarded,
+// they're tunneled through the ports manager, so that the forwarding shows up in the port status.
+func (c *sshConnection) handleDirectTCPIP(ctx context.Context, newCh ssh.NewChannel) {
+	var req sshDirectTCPIP
+	err := ssh.Unmarshal(newCh.ExtraData(), &req)
+	if err != nil {
+		_ = newCh.Reject(ssh.ConnectionFailed, "invalid direct-tcpip request")
+		return
+	}
+	if req.Host != "localhost" && !net.ParseIP(req.Host).IsLoopback() {
+		_ = newCh.Reject(ssh.Prohibited, "only ports of the workspace can be forwarded")
+		return
+	}
+
+	err = c.ensureTunnel(ctx, req.Port)
+	if err != nil {
+		_ = newCh.Reject(ssh.Prohibited, err.Error())
+		return
+	}
+	conn, err := c.server.Ports.EstablishTunnel(ctx, c.clientID, req.Port, req.Port, api.TransportProtocol_transport_tcp)
+	if err != nil {
+		_ = newCh.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	pipe(ch, conn)
+}
+
+func (c *sshConnection) ensureTunnel(ctx context.Context, port uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.tunnels[port]; ok {
+		return nil
+	}
+	for _, p := range c.server.Ports.Status() {
+		if p.LocalPort == port && p.Tunneled != nil && p.TransportProtocol == api.TransportProtocol_transport_tcp {
+			return nil
+		}
+	}
+	err := c.server.Ports.Tunnel(ctx, &ports.PortTunnelDescription{
+		LocalPort:  port,
+		TargetPort: port,
+		Visibility: api.TunnelVisiblity_host,
+		Transport:  api.TransportProtocol_transport_tcp,
+	})
+	if err != nil {
+		return err
+	}
+	c.tunnels[port] = struct{}{}
+	return nil
+}
+
+// pipe copies between a and b until either of them is closed
+func pipe(a io.ReadWriteCloser, b io.ReadWriteCloser) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+	a.Close()
+	b.Close()
+}
+
+type sshPtyRequest struct {
+	Term   string
+	Cols   uint32
+	Rows   uint32
+	Width  uint32
+	Height uint32
+	Modes  string
+}
+
+type sshWindowChange struct {
+	Cols   uint32
+	Rows   uint32
+	Width  uint32
+	Height uint32
+}
+
+type sshEnvRequest struct {
+	Name  string
+	Value string
+}
+
+type sshExecRequest struct {
+	Command string
+}
+
+// sshSession is a session channel of an SSH connection
+type sshSession struct {
+	conn *sshConnection
+	ch   ssh.Channel
+
+	env     map[string]string
+	pty     *sshPtyRequest
+	started bool
+
+	mu    sync.Mutex
+	alias string
+	// cmd is the process of a session without PTY, cmdDone is closed once it exited
+	cmd     *exec.Cmd
+	cmdDone chan struct{}
+}
+
+func (c *sshConnection) handleSession(ctx context.Context, newCh ssh.NewChannel) {
+	ch, reqs, err := newCh.Accept()
+	if err != nil {
+		return
+	}
+	sess := &sshSession{
+		conn: c,
+		ch:   ch,
+		env:  make(map[string]string),
+	}
+	defer sess.close()
+
+	for req := range reqs {
+		var ok bool
+		switch req.Type {
+		case "pty-req":
+			var p sshPtyRequest
+			ok = ssh.Unmarshal(req.Payload, &p) == nil && !sess.started
+			if ok {
+				sess.pty = &p
+			}
+		case "env":
+			var e sshEnvRequest
+			ok = ssh.Unmarshal(req.Payload, &e) == nil
+			if ok {
+				sess.env[e.Name] = e.Value
+			}
+		case "window-change":
+			var w sshWindowChange
+			ok = ssh.Unmarshal(req.Payload, &w) == nil
+			if ok {
+				sess.resize(w)
+			}
+		case "shell", "exec":
+			var command string
+			if req.Type == "exec" {
+				var e sshExecRequest
+				if ssh.Unmarshal(req.Payload, &e) != nil {
+					break
+				}
+				command = e.Command
+			}
+			if sess.started {
+				break
+			}
+			err := sess.start(ctx, command)
+			if err != nil {
+				log.WithError(err).Warn("ssh: cannot start session")
+				break
+			}
+			sess.started = true
+			ok = true
+		}
+		if req.WantReply {
+			_ = req.Reply(ok, nil)
+		}
+	}
+}
+
+// start runs the shell, or command if not empty. Sessions with a PTY run in a terminal of the terminal mux.
+func (sess *sshSession) start(ctx context.Context, command string) error {
+	var shellArgs []string
+	if command != "" {
+		shellArgs = []string{"-c", command}
+	}
+	if sess.pty != nil {
+		return sess.startTerminal(ctx, shellArgs)
+	}
+	return sess.startCommand(shellArgs)
+}
+
+func (sess *sshSession) startTerminal(ctx context.Context, shellArgs []string) error {
+	terminals := sess.conn.server.Terminals
+	env := make(map[string]string, len(sess.env)+1)
+	for k, v := range sess.env {
+		env[k] = v
+	}
+	if sess.pty.Term != "" {
+		env["TERM"] = sess.pty.Term
+	}
+	resp, err := terminals.OpenWithOptions(ctx, &api.OpenTerminalRequest{
+		ShellArgs: shellArgs,
+		Env:       env,
+		Size: &api.TerminalSize{
+			Rows:     sess.pty.Rows,
+			Cols:     sess.pty.Cols,
+			WidthPx:  sess.pty.Width,
+			HeightPx: sess.pty.Height,
+		},
+	}, terminal.TermOptions{
+		Annotations: map[string]string{sshTerminalAnnotation: sess.conn.conn.RemoteAddr().String()},
+		Title:       "ssh",
+	})
+	if err != nil {
+		return err
+	}
+	alias := resp.Terminal.Alias
+	term, ok := terminals.Mux.Get(alias)
+	if !ok {
+		return xerrors.Errorf("cannot find terminal %s", alias)
+	}
+	sess.mu.Lock()
+	sess.alias = alias
+	sess.mu.Unlock()
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := sess.ch.Read(buf)
+			if n > 0 {
+				_, werr := terminals.Write(ctx, &api.WriteTerminalRequest{Alias: alias, Stdin: buf[:n]})
+				if werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				// the client closed its input
+				return
+			}
+		}
+	}()
+	go func() {
+		stdout := term.Stdout.Listen()
+		defer stdout.Close()
+		_, _ = io.Copy(sess.ch, stdout)
+
+		exitCode, _ := term.Wait()
+		sess.exit(exitCode)
+	}()
+	return nil
+}
+
+func (sess *sshSession) startCommand(shellArgs []string) error {
+	terminals := sess.conn.server.Terminals
+	cmd := exec.Command(terminals.DefaultShell, shellArgs...)
+	if terminals.DefaultCreds != nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Credential: terminals.DefaultCreds}
+	}
+	if terminals.DefaultWorkdirProvider != nil {
+		cmd.Dir = terminals.DefaultWorkdirProvider()
+	}
+	if cmd.Dir == "" {
+		cmd.Dir = terminals.DefaultWorkdir
+	}
+	cmd.Env = append([]string{}, terminals.Env...)
+	for k, v := range sess.env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	cmd.Stdout = sess.ch
+	cmd.Stderr = sess.ch.Stderr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	sess.mu.Lock()
+	sess.cmd, sess.cmdDone = cmd, done
+	sess.mu.Unlock()
+
+	go func() {
+		_, _ = io.Copy(stdin, sess.ch)
+		stdin.Close()
+	}()
+	go func() {
+		err := cmd.Wait()
+		close(done)
+		exitCode := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			exitCode = 1
+		}
+		sess.exit(exitCode)
+	}()
+	return nil
+}
+
+func (sess *sshSession) resize(w sshWindowChange) {
+	sess.mu.Lock()
+	alias := sess.alias
+	sess.mu.Unlock()
+	if alias == "" {
+		if sess.pty != nil {
+			sess.pty.Cols, sess.pty.Rows, sess.pty.Width, sess.pty.Height = w.Cols, w.Rows, w.Width, w.Height
+		}
+		return
+	}
+	term, ok := sess.conn.server.Terminals.Mux.Get(alias)
+	if !ok {
+		return
+	}
+	err := term.Resize(&pty.Winsize{Cols: uint16(w.Cols), Rows: uint16(w.Rows), X: uint16(w.Width), Y: uint16(w.Height)})
+	if err != nil {
+		log.WithError(err).WithField("alias", alias).Debug("ssh: cannot resize terminal")
+	}
+}
+
+// exit reports the exit code of the session's process to the client and closes the session
+func (sess *sshSession) exit(exitCode int) {
+	if exitCode < 0 {
+		// the process was terminated by a signal
+		exitCode = 255
+	}
+	_, _ = sess.ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(exitCode)}))
+	sess.ch.Close()
+}
+
+// close closes the session channel and ends the process of the session, if the client went away while it was still running
+func (sess *sshSession) close() {
+	sess.ch.Close()
+
+	sess.mu.Lock()
+	alias, cmd, cmdDone := sess.alias, sess.cmd, sess.cmdDone
+	sess.mu.Unlock()
+	if cmd != nil {
+		select {
+		case <-cmdDone:
+		default:
+			_ = cmd.Process.Kill()
+		}
+		return
+	}
+	if alias == "" {
+		return
+	}
+	err := sess.conn.server.Terminals.Mux.CloseTerminal(alias, sshTerminalGracePeriod)
+	if err != nil && err != terminal.ErrNotFound {
+		log.WithError(err).WithField("alias", alias).Debug("ssh: cannot close terminal")
+	}
+}
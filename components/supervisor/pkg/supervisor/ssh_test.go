@@ -0,0 +1,205 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+func newTestSSHKey(t *testing.T) ssh.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestSSHServerAuthentication(t *testing.T) {
+	var (
+		registeredKey = newTestSSHKey(t)
+		fileKey       = newTestSSHKey(t)
+		unknownKey    = newTestSSHKey(t)
+	)
+	authorizedKeys := filepath.Join(t.TempDir(), "authorized_keys")
+	err := os.WriteFile(authorizedKeys, append([]byte("# comment\n"), ssh.MarshalAuthorizedKey(fileKey.PublicKey())...), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := NewInMemoryTokenService()
+	err = registerOwnerToken(tokens, "owner-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []*api.SetTokenRequest{
+		{Kind: KindOwner, Host: "workspace", Token: "expiring-owner-token", ExpiryDate: timestamppb.New(time.Now().Add(50 * time.Millisecond)), Reuse: api.TokenReuse_REUSE_WHEN_POSSIBLE},
+		{Kind: KindSSHKey, Host: "workspace", Token: string(ssh.MarshalAuthorizedKey(registeredKey.PublicKey())), Reuse: api.TokenReuse_REUSE_WHEN_POSSIBLE},
+	} {
+		_, err := tokens.SetToken(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	srv, err := newSSHServer(tokens, terminal.NewMuxTerminalService(terminal.NewMux()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.AuthorizedKeysLocation = authorizedKeys
+
+	tests := []struct {
+		Name     string
+		Password string
+		Key      ssh.Signer
+		Success  bool
+	}{
+		{Name: "owner token", Password: "owner-token", Success: true},
+		{Name: "expired owner token", Password: "expiring-owner-token"},
+		{Name: "wrong password", Password: "foobar"},
+		{Name: "registered key", Key: registeredKey, Success: true},
+		{Name: "authorized keys file", Key: fileKey, Success: true},
+		{Name: "unknown key", Key: unknownKey},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var err error
+			if test.Key != nil {
+				_, err = srv.authenticatePublicKey(sshConnMetadata{}, test.Key.PublicKey())
+			} else {
+				_, err = srv.authenticatePassword(sshConnMetadata{}, []byte(test.Password))
+			}
+			if success := err == nil; success != test.Success {
+				t.Errorf("expected success to be %v, got error %v", test.Success, err)
+			}
+		})
+	}
+}
+
+// sshConnMetadata is just enough of ssh.ConnMetadata for the authentication callbacks
+type sshConnMetadata struct {
+	ssh.ConnMetadata
+}
+
+func (sshConnMetadata) User() string { return "gitpod" }
+
+func TestSSHServerSessions(t *testing.T) {
+	tokens := NewInMemoryTokenService()
+	err := registerOwnerToken(tokens, "owner-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	terminals := terminal.NewMuxTerminalService(terminal.NewMux())
+	terminals.DefaultShell = "/bin/sh"
+	terminals.DefaultWorkdir = t.TempDir()
+	srv, err := newSSHServer(tokens, terminals, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = srv.ListenAndServe(ctx, l.Addr().String())
+	}()
+
+	var client *ssh.Client
+	for i := 0; i < 50; i++ {
+		client, err = ssh.Dial("tcp", l.Addr().String(), &ssh.ClientConfig{
+			User:            "gitpod",
+			Auth:            []ssh.AuthMethod{ssh.Password("owner-token")},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		})
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	sess, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sess.Setenv("GREETING", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := sess.Output("echo $GREETING from $(pwd)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := strings.TrimSpace(string(out)), "hello from "+terminals.DefaultWorkdir; act != exp {
+		t.Errorf("unexpected output: %q, expected %q", act, exp)
+	}
+
+	sess, err = client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sess.Run("exit 3")
+	if exitErr, ok := err.(*ssh.ExitError); !ok || exitErr.ExitStatus() != 3 {
+		t.Errorf("expected exit status 3, got %v", err)
+	}
+
+	// sessions with a PTY run in terminals
+	sess, err = client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sess.RequestPty("xterm", 24, 80, ssh.TerminalModes{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sess.Shell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := terminals.List(context.Background(), &api.ListTerminalsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Terminals) != 1 || list.Terminals[0].Annotations[sshTerminalAnnotation] == "" {
+		t.Fatalf("expected the session to be listed as SSH terminal, got %v", list.Terminals)
+	}
+	_, err = stdin.Write([]byte("exit 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sess.Wait()
+	if exitErr, ok := err.(*ssh.ExitError); !ok || exitErr.ExitStatus() != 4 {
+		t.Errorf("expected exit status 4, got %v", err)
+	}
+}
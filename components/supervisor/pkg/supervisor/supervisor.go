@@ -101,6 +101,18 @@ const (
 
 	// KindGit marks any kind of Git access token
 	KindGit = "git"
+
+	// KindVault marks tokens that provide access to a HashiCorp Vault server
+	KindVault = "vault"
+
+	// KindSecret marks secrets the workspace refers to with secret references, see secretReference
+	KindSecret = "secret"
+
+	// KindOwner marks owner tokens, which grant access to the workspace, e.g. through the SSH server
+	KindOwner = "owner"
+
+	// KindSSHKey marks public keys in authorized_keys format which may log in through the SSH server
+	KindSSHKey = "ssh-key"
 )
 
 type ShutdownReason int16
@@ -139,6 +151,13 @@ func Run(options ...RunOption) {
 	configureGit(cfg)
 
 	tokenService := NewInMemoryTokenService()
+	if cfg.TokenCacheLocation != "" {
+		// the tokens the workspace was started with stay the same across supervisor restarts
+		err = tokenService.PersistTo(cfg.TokenCacheLocation, cfg.Tokens)
+		if err != nil {
+			log.WithError(err).Warn("cannot persist token cache")
+		}
+	}
 	tkns, err := cfg.GetTokens(true)
 	if err != nil {
 		log.WithError(err).Warn("cannot prepare tokens")
@@ -149,6 +168,12 @@ func Run(options ...RunOption) {
 			log.WithError(err).Warn("cannot prepare tokens")
 		}
 	}
+	if cfg.OwnerToken != "" {
+		err = registerOwnerToken(tokenService, cfg.OwnerToken)
+		if err != nil {
+			log.WithError(err).Warn("cannot register owner token")
+		}
+	}
 
 	tunneledPortsService := ports.NewTunneledPortsService(cfg.DebugEnable)
 	_, err = tunneledPortsService.Tunnel(context.Background(), &ports.TunnelOptions{
@@ -169,28 +194,57 @@ func Run(options ...RunOption) {
 	ctx, cancel := context.WithCancel(context.Background())
 	var (
 		shutdown            = make(chan ShutdownReason, 1)
-		ideReady            = &ideReadyState{cond: sync.NewCond(&sync.Mutex{})}
+		ideBackends         = newIDEBackends(cfg)
 		cstate              = NewInMemoryContentState(cfg.RepoRoot)
 		gitpodService       = createGitpodService(cfg, tokenService)
 		gitpodConfigService = gitpod.NewConfigService(cfg.RepoRoot+"/.gitpod.yml", cstate.ContentReady(), log.Log)
 		portMgmt            = ports.NewManager(
 			createExposedPortsImpl(cfg, gitpodService),
-			&ports.PollingServedPortsObserver{
-				RefreshInterval: 2 * time.Second,
+			&ports.NetlinkServedPortsObserver{
+				RefreshInterval:    500 * time.Millisecond,
+				MaxRefreshInterval: 2 * time.Second,
+				Fallback: &ports.PollingServedPortsObserver{
+					RefreshInterval: 2 * time.Second,
+				},
 			},
 			ports.NewConfigService(cfg.WorkspaceID, gitpodConfigService, gitpodService),
 			tunneledPortsService,
-			uint32(cfg.IDEPort),
-			uint32(cfg.APIEndpointPort),
-			uint32(cfg.SSHPort),
+			internalPorts(cfg)...,
 		)
 		termMux             = terminal.NewMux()
 		termMuxSrv          = terminal.NewMuxTerminalService(termMux)
 		taskManager         = newTasksManager(cfg, termMuxSrv, cstate, &loggingHeadlessTaskProgressReporter{})
 		analytics           = analytics.NewFromEnvironment()
 		notificationService = NewNotificationService()
+		hostsService        = NewHostsService(&iwsHostsSetter{Socket: iwsProxySocket})
+		resources           = newResourcesMonitor(cfg, notificationService)
 	)
+	if cfg.NotificationStoreLocation != "" {
+		err = notificationService.PersistTo(cfg.NotificationStoreLocation)
+		if err != nil {
+			log.WithError(err).Warn("cannot persist notifications")
+		}
+	}
 	tokenService.provider[KindGit] = []tokenProvider{NewGitTokenProvider(gitpodService, cfg.WorkspaceConfig, notificationService)}
+	termMux.Redactor = &terminal.Redactor{}
+	extendingActivity, err := cfg.TimeoutExtendingActivity()
+	if err != nil {
+		log.WithError(err).Warn("cannot determine which activity extends the workspace timeout")
+	}
+	activity := newActivityTracker(extendingActivity)
+	activity.SSHPort = uint32(cfg.SSHPort)
+	activity.Ports = portMgmt.Status
+	activity.Tasks = taskManager.Status
+	activity.Terminals = termMux
+	if gitpodService != nil {
+		activity.Heartbeat = func(ctx context.Context) error {
+			return gitpodService.SendHeartBeat(ctx, &gitpod.SendHeartBeatOptions{InstanceID: cfg.WorkspaceInstanceID})
+		}
+	}
+	termMux.Activity = activity
+	taskManager.secrets = newSecretResolver(newSecretProviders(cfg, tokenService), termMux.Redactor)
+	tokenService.provider[KindSecret] = []tokenProvider{taskManager.secrets}
+	go tokenService.RefreshTokens(ctx)
 
 	defer analytics.Close()
 	go analyseConfigChanges(ctx, cfg, analytics, gitpodConfigService)
@@ -213,13 +267,23 @@ func Run(options ...RunOption) {
 		Uid: gitpodUID,
 		Gid: gitpodGID,
 	}
+	termMuxSrv.RecordingLocation = cfg.TerminalRecordingLocation
+	termMuxSrv.RecordingMaxSize = cfg.TerminalRecordingMaxSize
+	if termMuxSrv.RecordingMaxSize == 0 {
+		termMuxSrv.RecordingMaxSize = terminal.DefaultRecordingMaxSize
+	}
+	termMuxSrv.RecordingRetention = cfg.TerminalRecordingRetention
+	if termMuxSrv.RecordingRetention == 0 {
+		termMuxSrv.RecordingRetention = terminal.DefaultRecordingRetention
+	}
 
 	apiServices := []RegisterableService{
 		&statusService{
 			ContentState: cstate,
 			Ports:        portMgmt,
 			Tasks:        taskManager,
-			ideReady:     ideReady,
+			ideBackends:  ideBackends,
+			Resources:    resources,
 		},
 		termMuxSrv,
 		RegistrableTokenService{Service: tokenService},
@@ -227,6 +291,7 @@ func Run(options ...RunOption) {
 		&InfoService{cfg: cfg, ContentState: cstate},
 		&ControlService{portsManager: portMgmt},
 		&portService{portsManager: portMgmt},
+		hostsService,
 	}
 	apiServices = append(apiServices, additionalServices...)
 
@@ -241,8 +306,10 @@ func Run(options ...RunOption) {
 	go reaper(terminatingReaper)
 
 	var ideWG sync.WaitGroup
-	ideWG.Add(1)
-	go startAndWatchIDE(ctx, cfg, &ideWG, ideReady)
+	for _, ide := range ideBackends {
+		ideWG.Add(1)
+		go startAndWatchIDE(ctx, cfg, ide, &ideWG)
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -250,12 +317,16 @@ func Run(options ...RunOption) {
 	wg.Add(1)
 	go startAPIEndpoint(ctx, cfg, &wg, apiServices, tunneledPortsService, apiEndpointOpts...)
 	wg.Add(1)
-	go startSSHServer(ctx, cfg, &wg)
+	go startSSHServer(ctx, cfg, &wg, tokenService, termMuxSrv, portMgmt)
 	wg.Add(1)
 	tasksSuccessChan := make(chan bool, 1)
 	go taskManager.Run(ctx, &wg, tasksSuccessChan)
+	go resources.Run(ctx)
+	go activity.Run(ctx)
 	wg.Add(1)
 	go socketActivationForDocker(ctx, &wg, termMux)
+	wg.Add(1)
+	go hostsService.Run(ctx, &wg, gitpodConfigService)
 
 	if cfg.isHeadless() {
 		wg.Add(1)
@@ -263,6 +334,9 @@ func Run(options ...RunOption) {
 	} else {
 		wg.Add(1)
 		go portMgmt.Run(ctx, &wg)
+		wg.Add(1)
+		configValidation := &configValidationReporter{Location: cfg.RepoRoot + "/.gitpod.yml", Notifications: notificationService}
+		go configValidation.Run(ctx, &wg, gitpodConfigService)
 	}
 
 	if cfg.PreventMetadataAccess {
@@ -313,6 +387,7 @@ func createGitpodService(cfg *Config, tknsrv api.TokenServiceServer) *gitpod.API
 			"function:openPort",
 			"function:getOpenPorts",
 			"function:guessGitTokenScopes",
+			"function:sendHeartBeat",
 		},
 	})
 	if err != nil {
@@ -474,12 +549,79 @@ func reaper(terminatingReaper <-chan bool) {
 	}
 }
 
-func startAndWatchIDE(ctx context.Context, cfg *Config, wg *sync.WaitGroup, ideReady *ideReadyState) {
+// internalPorts are the ports supervisor and the IDE backends listen on
+func internalPorts(cfg *Config) []uint32 {
+	res := []uint32{uint32(cfg.IDEPort), uint32(cfg.APIEndpointPort), uint32(cfg.SSHPort)}
+	for _, backend := range cfg.IDEBackends {
+		res = append(res, uint32(backend.Port))
+	}
+	return res
+}
+
+const (
+	// ideBackendRestartDelay is the delay before an IDE backend which stopped is started again.
+	// The delay doubles for every consecutive start of a backend that did not become ready, up to maxIDEBackendRestartDelay.
+	ideBackendRestartDelay    = 1 * time.Second
+	maxIDEBackendRestartDelay = 30 * time.Second
+)
+
+// ideBackend is an IDE process which supervisor starts and restarts when it stops
+type ideBackend struct {
+	Config IDEConfig
+	Port   int
+	// Primary is true for the IDE the workspace is accessed with.
+	// The workspace is stopped if the primary IDE fails to start.
+	Primary bool
+
+	ready *ideReadyState
+
+	mu           sync.Mutex
+	restartCount uint32
+}
+
+func newIDEBackends(cfg *Config) []*ideBackend {
+	res := []*ideBackend{{
+		Config:  cfg.IDEConfig,
+		Port:    cfg.IDEPort,
+		Primary: true,
+		ready:   &ideReadyState{cond: sync.NewCond(&sync.Mutex{})},
+	}}
+	for _, backend := range cfg.IDEBackends {
+		res = append(res, &ideBackend{
+			Config: backend,
+			Port:   backend.Port,
+			ready:  &ideReadyState{cond: sync.NewCond(&sync.Mutex{})},
+		})
+	}
+	return res
+}
+
+// Status produces an API compatible status of the backend
+func (ide *ideBackend) Status() *api.IDEBackendStatus {
+	ide.mu.Lock()
+	defer ide.mu.Unlock()
+	return &api.IDEBackendStatus{
+		Name:         ide.Config.name(),
+		Ok:           ide.ready.Get(),
+		Port:         uint32(ide.Port),
+		Primary:      ide.Primary,
+		RestartCount: ide.restartCount,
+	}
+}
+
+func (ide *ideBackend) restarted() {
+	ide.mu.Lock()
+	ide.restartCount++
+	ide.mu.Unlock()
+}
+
+func startAndWatchIDE(ctx context.Context, cfg *Config, ide *ideBackend, wg *sync.WaitGroup) {
+	ideLog := log.WithField("ide", ide.Config.name())
 	defer wg.Done()
-	defer log.Debug("startAndWatchIDE shutdown")
+	defer ideLog.Debug("startAndWatchIDE shutdown")
 
 	if cfg.isHeadless() {
-		ideReady.Set(true)
+		ide.ready.Set(true)
 		return
 	}
 
@@ -492,8 +634,10 @@ func startAndWatchIDE(ctx context.Context, cfg *Config, wg *sync.WaitGroup, ideR
 	s := statusNeverRan
 
 	var (
-		cmd        *exec.Cmd
-		ideStopped chan struct{}
+		cmd          *exec.Cmd
+		ideStopped   chan struct{}
+		ideFailed    bool
+		restartDelay = ideBackendRestartDelay
 	)
 supervisorLoop:
 	for {
@@ -502,8 +646,8 @@ supervisorLoop:
 		}
 
 		ideStopped = make(chan struct{}, 1)
-		go func() {
-			cmd = prepareIDELaunch(cfg)
+		go func(ideStopped chan struct{}) {
+			cmd = prepareIDELaunch(cfg, ide)
 
 			// prepareIDELaunch sets Pdeathsig, which on on Linux, will kill the
 			// child process when the thread dies, not when the process dies.
@@ -516,33 +660,58 @@ supervisorLoop:
 
 			err := cmd.Start()
 			if err != nil {
-				if s == statusNeverRan {
-					log.WithError(err).Fatal("IDE failed to start")
+				if s == statusNeverRan && ide.Primary {
+					ideLog.WithError(err).Fatal("IDE failed to start")
 				}
+				ideLog.WithError(err).Error("IDE failed to start")
 
+				ideFailed = true
+				close(ideStopped)
 				return
 			}
 			s = statusShouldRun
 
-			go func() {
-				runIDEReadinessProbe(cfg)
-				ideReady.Set(true)
-			}()
+			// probeKilled is closed once a probe gave up on the IDE - that's a reason to restart it, not a failure to start
+			probeKilled := make(chan struct{})
+			go func(pid int) {
+				// the IDE runs in its own process group, which we stop as a whole
+				kill := func() {
+					close(probeKilled)
+					_ = syscall.Kill(-pid, syscall.SIGKILL)
+				}
+				if !runIDEReadinessProbe(cfg, ide, ideStopped) {
+					select {
+					case <-ideStopped:
+					default:
+						kill()
+					}
+					return
+				}
+				ide.ready.Set(true)
+				watchIDELiveness(cfg, ide, ideStopped, kill)
+			}(cmd.Process.Pid)
 
 			err = cmd.Wait()
+			ideWasReady := ide.ready.Get()
+			var killedByProbe bool
+			select {
+			case <-probeKilled:
+				killedByProbe = true
+			default:
+			}
 			if err != nil && !(strings.Contains(err.Error(), "signal: interrupt") || strings.Contains(err.Error(), "wait: no child processes")) {
-				log.WithError(err).Warn("IDE was stopped")
+				ideLog.WithError(err).Warn("IDE was stopped")
 
-				ideWasReady := ideReady.Get()
-				if !ideWasReady {
-					log.WithError(err).Fatal("IDE failed to start")
+				if !ideWasReady && ide.Primary && !killedByProbe {
+					ideLog.WithError(err).Fatal("IDE failed to start")
 					return
 				}
 			}
 
-			ideReady.Set(false)
+			ideFailed = !ideWasReady
+			ide.ready.Set(false)
 			close(ideStopped)
-		}()
+		}(ideStopped)
 
 		select {
 		case <-ideStopped:
@@ -550,7 +719,17 @@ supervisorLoop:
 			if s == statusShouldShutdown {
 				break supervisorLoop
 			}
-			time.Sleep(1 * time.Second)
+			if !ideFailed || ide.Primary {
+				restartDelay = ideBackendRestartDelay
+			}
+			time.Sleep(restartDelay)
+			if ideFailed && restartDelay < maxIDEBackendRestartDelay {
+				restartDelay *= 2
+				if restartDelay > maxIDEBackendRestartDelay {
+					restartDelay = maxIDEBackendRestartDelay
+				}
+			}
+			ide.restarted()
 		case <-ctx.Done():
 			// we've been asked to shut down
 			s = statusShouldShutdown
@@ -561,24 +740,38 @@ supervisorLoop:
 		}
 	}
 
-	log.WithField("budget", timeBudgetIDEShutdown.String()).Info("IDE supervisor loop ended - waiting for IDE to come down")
+	ideLog.WithField("budget", timeBudgetIDEShutdown.String()).Info("IDE supervisor loop ended - waiting for IDE to come down")
 	select {
 	case <-ideStopped:
 		return
 	case <-time.After(timeBudgetIDEShutdown):
-		log.WithField("timeBudgetIDEShutdown", timeBudgetIDEShutdown.String()).Error("IDE did not stop in time - sending SIGKILL")
+		ideLog.WithField("timeBudgetIDEShutdown", timeBudgetIDEShutdown.String()).Error("IDE did not stop in time - sending SIGKILL")
 		cmd.Process.Signal(syscall.SIGKILL)
 	}
 }
 
-func prepareIDELaunch(cfg *Config) *exec.Cmd {
-	var args []string
-	args = append(args, cfg.WorkspaceRoot)
-	args = append(args, "--port", strconv.Itoa(cfg.IDEPort))
-	args = append(args, "--hostname", "0.0.0.0")
-	log.WithField("args", args).WithField("entrypoint", cfg.Entrypoint).Info("launching IDE")
+// defaultIDEEntrypointArgs are the arguments of IDEs which do not configure their own
+var defaultIDEEntrypointArgs = []string{"{WORKSPACEROOT}", "--port", "{IDEPORT}", "--hostname", "0.0.0.0"}
 
-	cmd := exec.Command(cfg.Entrypoint, args...)
+// entrypointArgs produces the arguments the IDE is started with
+func (ide *ideBackend) entrypointArgs(workspaceRoot string) []string {
+	args := ide.Config.EntrypointArgs
+	if len(args) == 0 {
+		args = defaultIDEEntrypointArgs
+	}
+	replacer := strings.NewReplacer("{WORKSPACEROOT}", workspaceRoot, "{IDEPORT}", strconv.Itoa(ide.Port))
+	res := make([]string, 0, len(args))
+	for _, arg := range args {
+		res = append(res, replacer.Replace(arg))
+	}
+	return res
+}
+
+func prepareIDELaunch(cfg *Config, ide *ideBackend) *exec.Cmd {
+	args := ide.entrypointArgs(cfg.WorkspaceRoot)
+	log.WithField("ide", ide.Config.name()).WithField("args", args).WithField("entrypoint", ide.Config.Entrypoint).Info("launching IDE")
+
+	cmd := exec.Command(ide.Config.Entrypoint, args...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		// We need the child process to run in its own process group, s.t. we can suspend and resume
 		// IDE and its children.
@@ -598,7 +791,7 @@ func prepareIDELaunch(cfg *Config) *exec.Cmd {
 	// This would break the JSON parsing of the headless builds.
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	if lrr := cfg.LogRateLimit(); lrr > 0 {
+	if lrr := cfg.LogRateLimit(ide.Config); lrr > 0 {
 		limit := int64(lrr)
 		cmd.Stdout = dropwriter.Writer(cmd.Stdout, dropwriter.NewBucket(limit*1024*3, limit*1024))
 		cmd.Stderr = dropwriter.Writer(cmd.Stderr, dropwriter.NewBucket(limit*1024*3, limit*1024))
@@ -667,42 +860,6 @@ func buildChildProcEnv(cfg *Config, envvars []string) []string {
 	return env
 }
 
-func runIDEReadinessProbe(cfg *Config) {
-	defer log.Info("IDE is ready")
-
-	switch cfg.ReadinessProbe.Type {
-	case ReadinessProcessProbe:
-		return
-
-	case ReadinessHTTPProbe:
-		var (
-			url    = fmt.Sprintf("http://localhost:%d/%s", cfg.IDEPort, strings.TrimPrefix(cfg.ReadinessProbe.HTTPProbe.Path, "/"))
-			client = http.Client{Timeout: 1 * time.Second}
-			tick   = time.NewTicker(500 * time.Millisecond)
-		)
-		defer tick.Stop()
-
-		t0 := time.Now()
-
-		for {
-			<-tick.C
-
-			resp, err := client.Get(url)
-			if err != nil {
-				continue
-			}
-			resp.Body.Close()
-
-			if resp.StatusCode == http.StatusOK {
-				log.WithField("status", resp.StatusCode).Infof("IDE readiness took %.3f seconds", time.Since(t0).Seconds())
-				break
-			}
-
-			log.WithField("status", resp.StatusCode).Info("IDE readiness probe came back with non-200 status code")
-		}
-	}
-}
-
 func isBlacklistedEnvvar(name string) bool {
 	// exclude blacklisted
 	prefixBlacklist := []string{
@@ -853,7 +1010,7 @@ func tunnelOverSSH(ctx context.Context, tunneled *ports.TunneledPortsService, ne
 		return
 	}
 
-	tunnel, err := tunneled.EstablishTunnel(ctx, tunnelReq.ClientId, tunnelReq.Port, tunnelReq.TargetPort)
+	tunnel, err := tunneled.EstablishTunnel(ctx, tunnelReq.ClientId, tunnelReq.Port, tunnelReq.TargetPort, tunnelReq.TransportProtocol)
 	if err != nil {
 		log.WithError(err).Error("tunnel: failed to establish")
 		newCh.Reject(ssh.Prohibited, err.Error())
@@ -898,77 +1055,17 @@ func stopWhenTasksAreDone(ctx context.Context, wg *sync.WaitGroup, shutdown chan
 	shutdown <- ShutdownReasonSuccess
 }
 
-func startSSHServer(ctx context.Context, cfg *Config, wg *sync.WaitGroup) {
+func startSSHServer(ctx context.Context, cfg *Config, wg *sync.WaitGroup, tokens *InMemoryTokenService, terminals *terminal.MuxTerminalService, portsManager *ports.Manager) {
 	defer wg.Done()
 
-	bin, err := os.Executable()
+	srv, err := newSSHServer(tokens, terminals, portsManager)
 	if err != nil {
-		log.WithError(err).Error("cannot find executable path")
-		return
-	}
-	dropbear := filepath.Join(filepath.Dir(bin), "dropbear", "dropbear")
-	if _, err := os.Stat(dropbear); err != nil {
-		log.WithError(err).WithField("path", dropbear).Error("cannot locate dropebar binary")
+		log.WithError(err).Error("cannot create SSH server")
 		return
 	}
-	dropbearkey := filepath.Join(filepath.Dir(bin), "dropbear", "dropbearkey")
-	if _, err := os.Stat(dropbearkey); err != nil {
-		log.WithError(err).WithField("path", dropbearkey).Error("cannot locate dropebarkey")
-		return
-	}
-
-	hostkeyFN, err := ioutil.TempFile("", "hostkey")
-	if err != nil {
-		log.WithError(err).Error("cannot create hostkey file")
-		return
-	}
-	hostkeyFN.Close()
-	os.Remove(hostkeyFN.Name())
-
-	keycmd := exec.Command(dropbearkey, "-t", "rsa", "-f", hostkeyFN.Name())
-	// We need to force HOME because the Gitpod user might not have existed at the start of the container
-	// which makes the container runtime set an invalid HOME value.
-	keycmd.Env = func() []string {
-		env := os.Environ()
-		res := make([]string, 0, len(env))
-		for _, e := range env {
-			if strings.HasPrefix(e, "HOME=") {
-				e = "HOME=/root"
-			}
-			res = append(res, e)
-		}
-		return res
-	}()
-	out, err := keycmd.CombinedOutput()
-	if err != nil {
-		log.WithError(err).WithField("out", string(out)).Error("cannot create hostkey file")
-		return
-	}
-
-	cmd := exec.Command(dropbear, "-F", "-E", "-w", "-s", "-p", fmt.Sprintf(":%d", cfg.SSHPort), "-r", hostkeyFN.Name())
-	cmd.Env = buildChildProcEnv(cfg, nil)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err = cmd.Start()
+	err = srv.ListenAndServe(ctx, fmt.Sprintf(":%d", cfg.SSHPort))
 	if err != nil {
-		log.WithError(err).Error("cannot start SSH server")
-		return
-	}
-
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Wait()
-	}()
-	select {
-	case <-ctx.Done():
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		return
-	case err = <-done:
-		if err != nil {
-			log.WithError(err).Error("SSH server stopped")
-		}
+		log.WithError(err).Error("SSH server stopped")
 	}
 }
 
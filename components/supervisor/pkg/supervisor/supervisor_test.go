@@ -7,6 +7,7 @@ package supervisor
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
@@ -126,3 +127,100 @@ func TestIsBlacklistedEnvvar(t *testing.T) {
 		})
 	}
 }
+
+func TestIDEEntrypointArgs(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Args        []string
+		Expectation []string
+	}{
+		{
+			Name:        "default",
+			Expectation: []string{"/workspace/gitpod", "--port", "63342", "--hostname", "0.0.0.0"},
+		},
+		{
+			Name:        "configured",
+			Args:        []string{"run", "--listen=127.0.0.1:{IDEPORT}", "{WORKSPACEROOT}"},
+			Expectation: []string{"run", "--listen=127.0.0.1:63342", "/workspace/gitpod"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ide := &ideBackend{Config: IDEConfig{EntrypointArgs: test.Args}, Port: 63342}
+			act := ide.entrypointArgs("/workspace/gitpod")
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected entrypointArgs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadIDEBackendConfigs(t *testing.T) {
+	location := t.TempDir()
+	for name, content := range map[string]string{
+		"intellij": `{"entrypoint":"/ide/backends/intellij/startup.sh","port":63342}`,
+		"vscode":   `{"name":"vscode-desktop","entrypoint":"/ide/backends/vscode/startup.sh","port":63343}`,
+	} {
+		err := os.MkdirAll(filepath.Join(location, name), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(location, name, "supervisor-ide-config.json"), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type Expectation struct {
+		Backends []IDEConfig
+		Error    bool
+	}
+	tests := []struct {
+		Name        string
+		Location    string
+		Names       string
+		Expectation Expectation
+	}{
+		{
+			Name:     "none",
+			Location: location,
+		},
+		{
+			Name:     "selected backends",
+			Location: location,
+			Names:    "intellij, vscode",
+			Expectation: Expectation{Backends: []IDEConfig{
+				{Name: "intellij", Entrypoint: "/ide/backends/intellij/startup.sh", Port: 63342},
+				{Name: "vscode-desktop", Entrypoint: "/ide/backends/vscode/startup.sh", Port: 63343},
+			}},
+		},
+		{
+			Name:        "unknown backend",
+			Location:    location,
+			Names:       "intellij,goland",
+			Expectation: Expectation{Error: true},
+		},
+		{
+			Name:        "outside of location",
+			Location:    filepath.Join(location, "intellij"),
+			Names:       "..",
+			Expectation: Expectation{Error: true},
+		},
+		{
+			Name:        "IDE without backends",
+			Names:       "intellij",
+			Expectation: Expectation{Error: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var act Expectation
+			backends, err := loadIDEBackendConfigs(test.Location, test.Names)
+			act.Backends = backends
+			act.Error = err != nil
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected loadIDEBackendConfigs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
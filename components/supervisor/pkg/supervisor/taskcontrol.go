@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+// stopTaskGracePeriod is the time a task has to terminate once it's stopped or restarted
+var stopTaskGracePeriod = 5 * time.Second
+
+// Stop closes the terminal of a task. Stopped tasks are not restarted according to their restart policy.
+func (tm *tasksManager) Stop(id string) error {
+	t, err := tm.controllableTask(id)
+	if err != nil {
+		return err
+	}
+
+	tm.mu.Lock()
+	state, alias := t.State, t.Terminal
+	t.restartRequested = false
+	tm.mu.Unlock()
+
+	switch state {
+	case api.TaskState_running:
+		log.WithField("task", id).Info("stopping task")
+		return tm.closeTaskTerminal(t, alias)
+	case api.TaskState_restarting:
+		select {
+		case t.interrupt <- false:
+		default:
+		}
+		return nil
+	case api.TaskState_closed:
+		return nil
+	default:
+		return status.Errorf(codes.FailedPrecondition, "task %s is %s and cannot be stopped", id, state)
+	}
+}
+
+// Restart runs a task again in a new terminal, right away and regardless of its restart policy.
+// Running tasks are stopped first.
+func (tm *tasksManager) Restart(id string) error {
+	t, err := tm.controllableTask(id)
+	if err != nil {
+		return err
+	}
+
+	tm.mu.Lock()
+	state, alias, exitCode := t.State, t.Terminal, int(t.LastExitCode)
+	switch state {
+	case api.TaskState_running:
+		t.restartRequested = true
+	case api.TaskState_closed:
+		// claim the task so that concurrent restarts don't start it twice
+		t.State = api.TaskState_restarting
+		t.restartRequested = false
+		t.closedChan = make(chan struct{})
+	}
+	tm.mu.Unlock()
+
+	switch state {
+	case api.TaskState_running:
+		log.WithField("task", id).Info("restarting task on request")
+		return tm.closeTaskTerminal(t, alias)
+	case api.TaskState_restarting:
+		select {
+		case t.interrupt <- true:
+		default:
+		}
+		return nil
+	case api.TaskState_closed:
+		t.restartAttempt = 0
+		go tm.restartTask(tm.ctx, t, exitCode, 0)
+		return nil
+	default:
+		return status.Errorf(codes.FailedPrecondition, "task %s is %s and cannot be restarted", id, state)
+	}
+}
+
+// controllableTask returns the task with the given ID if it can be stopped and restarted
+func (tm *tasksManager) controllableTask(id string) (*task, error) {
+	select {
+	case <-tm.ready:
+	default:
+		return nil, status.Error(codes.Unavailable, "tasks are not initialized yet")
+	}
+	if tm.config.isHeadless() {
+		return nil, status.Error(codes.FailedPrecondition, "prebuild tasks cannot be controlled")
+	}
+
+	for _, t := range tm.tasks {
+		if t.Id == id {
+			return t, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "task %s not found", id)
+}
+
+func (tm *tasksManager) closeTaskTerminal(t *task, alias string) error {
+	err := tm.terminalService.Mux.CloseTerminal(alias, stopTaskGracePeriod)
+	if err == terminal.ErrNotFound {
+		// the task has exited in the meantime
+		return nil
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "cannot close the terminal of task %s: %v", t.Id, err)
+	}
+	return nil
+}
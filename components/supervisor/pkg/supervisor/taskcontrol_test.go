@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csapi "github.com/gitpod-io/gitpod/content-service/api"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+func TestTaskControl(t *testing.T) {
+	defer func(gracePeriod time.Duration) { stopTaskGracePeriod = gracePeriod }(stopTaskGracePeriod)
+	stopTaskGracePeriod = 100 * time.Millisecond
+
+	p := func(v string) *string { return &v }
+	tasks, err := json.Marshal([]TaskConfig{
+		{Command: p("sleep 60"), RestartPolicy: p(restartPolicyAlways)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminalService := terminal.NewMuxTerminalService(terminal.NewMux())
+	terminalService.DefaultShell = "/bin/sh"
+	terminalService.DefaultWorkdir = t.TempDir()
+	contentState := NewInMemoryContentState("")
+	contentState.MarkContentReady(csapi.WorkspaceInitFromOther)
+	tm := newTasksManager(&Config{
+		WorkspaceConfig: WorkspaceConfig{GitpodTasks: string(tasks)},
+	}, terminalService, contentState, nil)
+	tm.storeLocation = t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go tm.Run(ctx, &wg, make(chan bool, 1))
+	defer func() {
+		_ = tm.Stop("0")
+		cancel()
+		wg.Wait()
+	}()
+	<-tm.ready
+
+	awaitTask := func(desc string, cond func(s *api.TaskStatus) bool) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			tm.mu.RLock()
+			ok := cond(&tm.tasks[0].TaskStatus)
+			tm.mu.RUnlock()
+			if ok {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("task did not become %s", desc)
+	}
+	running := func(restarts uint32) func(s *api.TaskStatus) bool {
+		return func(s *api.TaskStatus) bool {
+			return s.State == api.TaskState_running && s.RestartCount == restarts
+		}
+	}
+	closed := func(s *api.TaskStatus) bool { return s.State == api.TaskState_closed }
+
+	awaitTask("running", running(0))
+
+	err = tm.Stop("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stopped tasks are not restarted even though their restart policy says so
+	awaitTask("closed", closed)
+	time.Sleep(taskRestartMinBackoff + 100*time.Millisecond)
+	awaitTask("closed", closed)
+
+	err = tm.Restart("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	awaitTask("running after the first restart", running(1))
+
+	err = tm.Restart("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	awaitTask("running after the second restart", running(2))
+
+	err = tm.Stop("1")
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown task, got %v", err)
+	}
+}
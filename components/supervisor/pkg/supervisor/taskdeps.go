@@ -0,0 +1,232 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	gitpod "github.com/gitpod-io/gitpod/gitpod-protocol"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+const (
+	taskReadinessInterval = 1 * time.Second
+	taskProbeTimeout      = 10 * time.Second
+)
+
+// resolveTaskDependencies resolves the task names in dependsOn to task indices.
+// Tasks which cannot be scheduled, because they depend on an unknown task or are part of
+// a dependency cycle, are listed in blocked with the reason why.
+func resolveTaskDependencies(tasks []TaskConfig) (deps [][]int, blocked map[int]string) {
+	names := make(map[string][]int)
+	for i, t := range tasks {
+		if t.Name != nil && *t.Name != "" {
+			names[*t.Name] = append(names[*t.Name], i)
+		}
+	}
+
+	deps = make([][]int, len(tasks))
+	blocked = make(map[int]string)
+	for i, t := range tasks {
+		if t.DependsOn == nil {
+			continue
+		}
+		for _, name := range *t.DependsOn {
+			idx, ok := names[name]
+			if !ok {
+				blocked[i] = fmt.Sprintf("depends on unknown task %q", name)
+				break
+			}
+			deps[i] = append(deps[i], idx...)
+		}
+	}
+
+	// the same cycle detection is used by gp validate, so users learn about cycles before the workspace starts
+	for _, cycle := range gitpod.TaskDependencyCycles(deps) {
+		path := make([]string, len(cycle))
+		for j, c := range cycle {
+			path[j] = taskName(tasks, c)
+		}
+		for _, c := range cycle[:len(cycle)-1] {
+			blocked[c] = "dependency cycle: " + strings.Join(path, " -> ")
+		}
+	}
+	return deps, blocked
+}
+
+func taskName(tasks []TaskConfig, i int) string {
+	if tasks[i].Name != nil && *tasks[i].Name != "" {
+		return *tasks[i].Name
+	}
+	return strconv.Itoa(i)
+}
+
+// awaitDependencies waits until all tasks t depends on are ready. Returns an error if a dependency
+// closes without becoming ready.
+func (tm *tasksManager) awaitDependencies(ctx context.Context, t *task) error {
+	for _, dep := range t.deps {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-dep.readyChan:
+		case <-tm.closed(dep):
+			select {
+			case <-dep.readyChan:
+				continue
+			default:
+			}
+			tm.mu.RLock()
+			blocked := dep.State == api.TaskState_blocked
+			tm.mu.RUnlock()
+			if blocked {
+				return fmt.Errorf("task %s is blocked", dep.Presentation.Name)
+			}
+			return fmt.Errorf("task %s closed before it became ready", dep.Presentation.Name)
+		}
+	}
+	return nil
+}
+
+// watchReadiness probes the readiness condition of a task until it is met or the task closes.
+func (tm *tasksManager) watchReadiness(ctx context.Context, t *task) {
+	probe := tm.readinessProbe(t)
+	if probe == nil {
+		return
+	}
+
+	// readiness is only watched during the first run, a restart must not swap the channel underneath us
+	closed := tm.closed(t)
+	ticker := time.NewTicker(taskReadinessInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			// markReadyOnClose has evaluated the readiness one last time
+			return
+		case <-ticker.C:
+		}
+
+		if probe(ctx) {
+			log.WithField("task", t.Id).Info("task is ready")
+			tm.markReady(t)
+			return
+		}
+	}
+}
+
+// markReadyOnClose evaluates the readiness of a task one last time before the task is marked closed.
+// Tasks which exit successfully count as ready. Otherwise a dependency which becomes ready and exits
+// between two readiness probes, e.g. a task which only runs init commands, would fail its dependents.
+func (tm *tasksManager) markReadyOnClose(ctx context.Context, t *task, success bool) {
+	probe := tm.readinessProbe(t)
+	if probe == nil {
+		return
+	}
+	tm.mu.RLock()
+	ready := t.Ready
+	tm.mu.RUnlock()
+	if ready {
+		return
+	}
+	if !success && !probe(ctx) {
+		return
+	}
+	log.WithField("task", t.Id).Info("task is ready")
+	tm.markReady(t)
+}
+
+func (tm *tasksManager) markReady(t *task) {
+	tm.updateState(func() bool {
+		if t.Ready {
+			return false
+		}
+		t.Ready = true
+		close(t.readyChan)
+		return true
+	})
+}
+
+// readinessProbe returns a function which checks if a task is ready, or nil if nothing waits for the task to become ready
+func (tm *tasksManager) readinessProbe(t *task) func(ctx context.Context) bool {
+	cfg := t.config.Readiness
+	switch {
+	case cfg != nil:
+		return tm.probe(cfg.Port, cfg.File, cfg.Command)
+	case t.readyMarker != "":
+		return tm.probe(nil, &t.readyMarker, nil)
+	default:
+		return nil
+	}
+}
+
+// probe returns a function which checks if a port is served, a file exists or a command exits with 0
+func (tm *tasksManager) probe(port *int, file *string, command *string) func(ctx context.Context) bool {
+	switch {
+	case port != nil:
+		addr := net.JoinHostPort("localhost", strconv.Itoa(*port))
+		return func(ctx context.Context) bool {
+			conn, err := net.DialTimeout("tcp", addr, taskProbeTimeout)
+			if err != nil {
+				return false
+			}
+			conn.Close()
+			return true
+		}
+	case file != nil:
+		fn := *file
+		if !filepath.IsAbs(fn) {
+			fn = filepath.Join(tm.config.RepoRoot, fn)
+		}
+		return func(ctx context.Context) bool {
+			_, err := os.Stat(fn)
+			return err == nil
+		}
+	case command != nil:
+		command := *command
+		return func(ctx context.Context) bool {
+			ctx, cancel := context.WithTimeout(ctx, taskProbeTimeout)
+			defer cancel()
+
+			cmd := runAsGitpodUser(exec.CommandContext(ctx, "/bin/sh", "-c", command))
+			cmd.Dir = tm.config.RepoRoot
+			cmd.Env = tm.terminalService.Env
+			return cmd.Run() == nil
+		}
+	default:
+		return nil
+	}
+}
+
+// withReadyMarker adds a command which marks the task as ready once the commands
+// preceding the main command succeeded.
+func withReadyMarker(task *task, commands []*string, isHeadless bool) []*string {
+	if task.readyMarker == "" {
+		return commands
+	}
+	if isHeadless && strings.TrimSpace(composeCommand(composeCommandOptions{commands: commands, format: "%s"})) == "" {
+		// headless tasks without commands are never started
+		return commands
+	}
+
+	marker := "touch " + task.readyMarker
+	if isHeadless || len(commands) == 0 {
+		return append(commands[:len(commands):len(commands)], &marker)
+	}
+	res := make([]*string, 0, len(commands)+1)
+	res = append(res, commands[:len(commands)-1]...)
+	res = append(res, &marker, commands[len(commands)-1])
+	return res
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+func TestResolveTaskDependencies(t *testing.T) {
+	p := func(v string) *string { return &v }
+	dependsOn := func(v ...string) *[]string { return &v }
+	tests := []struct {
+		Desc            string
+		Tasks           []TaskConfig
+		ExpectedDeps    [][]int
+		ExpectedBlocked map[int]string
+	}{
+		{
+			Desc:            "no dependencies",
+			Tasks:           []TaskConfig{{Name: p("a")}, {}},
+			ExpectedDeps:    [][]int{nil, nil},
+			ExpectedBlocked: map[int]string{},
+		},
+		{
+			Desc: "chain",
+			Tasks: []TaskConfig{
+				{Name: p("db")},
+				{Name: p("backend"), DependsOn: dependsOn("db")},
+				{Name: p("frontend"), DependsOn: dependsOn("backend", "db")},
+			},
+			ExpectedDeps:    [][]int{nil, {0}, {1, 0}},
+			ExpectedBlocked: map[int]string{},
+		},
+		{
+			Desc: "unknown dependency",
+			Tasks: []TaskConfig{
+				{Name: p("a"), DependsOn: dependsOn("b")},
+			},
+			ExpectedDeps:    [][]int{nil},
+			ExpectedBlocked: map[int]string{0: `depends on unknown task "b"`},
+		},
+		{
+			Desc: "self dependency",
+			Tasks: []TaskConfig{
+				{Name: p("a"), DependsOn: dependsOn("a")},
+			},
+			ExpectedDeps:    [][]int{{0}},
+			ExpectedBlocked: map[int]string{0: "dependency cycle: a -> a"},
+		},
+		{
+			Desc: "cycle",
+			Tasks: []TaskConfig{
+				{Name: p("a"), DependsOn: dependsOn("c")},
+				{Name: p("b"), DependsOn: dependsOn("a")},
+				{Name: p("c"), DependsOn: dependsOn("b")},
+				{Name: p("d"), DependsOn: dependsOn("a")},
+			},
+			ExpectedDeps: [][]int{{2}, {0}, {1}, {0}},
+			ExpectedBlocked: map[int]string{
+				0: "dependency cycle: a -> c -> b -> a",
+				1: "dependency cycle: a -> c -> b -> a",
+				2: "dependency cycle: a -> c -> b -> a",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			deps, blocked := resolveTaskDependencies(test.Tasks)
+			if diff := cmp.Diff(test.ExpectedDeps, deps, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected dependencies (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.ExpectedBlocked, blocked); diff != "" {
+				t.Errorf("unexpected blocked tasks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithReadyMarker(t *testing.T) {
+	p := func(v string) *string { return &v }
+	tests := []struct {
+		Desc        string
+		Commands    []*string
+		IsHeadless  bool
+		Expectation []*string
+	}{
+		{
+			Desc:        "before main command",
+			Commands:    []*string{p("before"), p("init"), p("command")},
+			Expectation: []*string{p("before"), p("init"), p("touch /ready-0"), p("command")},
+		},
+		{
+			Desc:        "headless",
+			Commands:    []*string{p("before"), p("init"), p("prebuild")},
+			IsHeadless:  true,
+			Expectation: []*string{p("before"), p("init"), p("prebuild"), p("touch /ready-0")},
+		},
+		{
+			Desc:        "headless without commands",
+			Commands:    []*string{nil, nil, nil},
+			IsHeadless:  true,
+			Expectation: []*string{nil, nil, nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			act := withReadyMarker(&task{readyMarker: "/ready-0"}, test.Commands, test.IsHeadless)
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected commands (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMarkReadyOnClose(t *testing.T) {
+	tests := []struct {
+		Desc         string
+		NoProbe      bool
+		MarkerExists bool
+		Success      bool
+		ExpectReady  bool
+	}{
+		{Desc: "ready before close", MarkerExists: true, ExpectReady: true},
+		{Desc: "successful exit", Success: true, ExpectReady: true},
+		{Desc: "failed exit", ExpectReady: false},
+		{Desc: "no readiness probe", NoProbe: true, Success: true, ExpectReady: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			marker := filepath.Join(t.TempDir(), "ready-0")
+			if test.MarkerExists {
+				err := os.WriteFile(marker, nil, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			dep := &task{
+				TaskStatus: api.TaskStatus{Id: "0", Presentation: &api.TaskPresentation{Name: "dep"}},
+				readyChan:  make(chan struct{}),
+				closedChan: make(chan struct{}),
+			}
+			if !test.NoProbe {
+				dep.readyMarker = marker
+			}
+			tm := &tasksManager{config: &Config{}, tasks: []*task{dep}}
+
+			// the task closes in between two readiness probes
+			tm.markReadyOnClose(context.Background(), dep, test.Success)
+			close(dep.closedChan)
+
+			err := tm.awaitDependencies(context.Background(), &task{deps: []*task{dep}})
+			if ready := err == nil; ready != test.ExpectReady {
+				t.Errorf("dependency ready: %v, expected %v (%v)", ready, test.ExpectReady, err)
+			}
+		})
+	}
+}
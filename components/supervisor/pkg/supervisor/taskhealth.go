@@ -0,0 +1,204 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	csapi "github.com/gitpod-io/gitpod/content-service/api"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+const (
+	restartPolicyNever     = "never"
+	restartPolicyOnFailure = "on-failure"
+	restartPolicyAlways    = "always"
+
+	taskRestartMinBackoff = 1 * time.Second
+	taskRestartMaxBackoff = 5 * time.Minute
+	// taskRestartResetAfter is how long a task has to run until we consider it stable and reset its backoff
+	taskRestartResetAfter = 10 * time.Minute
+
+	defaultHealthCheckInterval         = 10 * time.Second
+	defaultHealthCheckFailureThreshold = 3
+	unhealthyTaskGracePeriod           = 5 * time.Second
+)
+
+// restartPolicy returns the effective restart policy of a task
+func restartPolicy(cfg TaskConfig) string {
+	if cfg.RestartPolicy != nil && *cfg.RestartPolicy != "" {
+		return *cfg.RestartPolicy
+	}
+	if cfg.HealthCheck != nil {
+		return restartPolicyOnFailure
+	}
+	return restartPolicyNever
+}
+
+// shouldRestart decides if a task whose terminal has exited is restarted
+func (tm *tasksManager) shouldRestart(ctx context.Context, t *task, term *terminal.Term, success, unhealthy bool) bool {
+	if tm.config.isHeadless() || ctx.Err() != nil {
+		// prebuild tasks must terminate, and we don't restart anything when shutting down
+		return false
+	}
+
+	switch policy := restartPolicy(t.config); {
+	case policy == restartPolicyNever:
+		return false
+	case term.ClosedExplicitly():
+		// someone closed the task's terminal on purpose, even if the task was unhealthy at that time
+		return false
+	case unhealthy:
+		return true
+	case policy == restartPolicyAlways:
+		return true
+	case policy == restartPolicyOnFailure:
+		return !success
+	default:
+		log.WithField("task", t.Id).WithField("restartPolicy", policy).Warn("unknown restart policy - not restarting task")
+		return false
+	}
+}
+
+// restartBackoff computes the time to wait before the given consecutive restart attempt
+func restartBackoff(attempt int) time.Duration {
+	backoff := taskRestartMinBackoff
+	for i := 0; i < attempt && backoff < taskRestartMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > taskRestartMaxBackoff {
+		backoff = taskRestartMaxBackoff
+	}
+	return backoff
+}
+
+// nextRestartBackoff advances the restart attempts of a task which ran for the given time and returns
+// how long to wait before restarting it
+func nextRestartBackoff(t *task, ran time.Duration) time.Duration {
+	if ran > taskRestartResetAfter {
+		t.restartAttempt = 0
+	}
+	backoff := restartBackoff(t.restartAttempt)
+	t.restartAttempt++
+	return backoff
+}
+
+// restartTask starts a task again once its backoff has passed, or once it's interrupted through Restart.
+// Interrupting it through Stop closes the task instead.
+func (tm *tasksManager) restartTask(ctx context.Context, t *task, exitCode int, backoff time.Duration) {
+	// drop interrupts which arrived while the task was running
+	select {
+	case <-t.interrupt:
+	default:
+	}
+
+	log.WithField("task", t.Id).WithField("exitCode", exitCode).WithField("backoff", backoff.String()).Info("restarting task")
+	tm.updateState(func() bool {
+		t.State = api.TaskState_restarting
+		t.LastExitCode = int32(exitCode)
+		return true
+	})
+
+	select {
+	case <-ctx.Done():
+		tm.closeTask(t, false)
+		return
+	case restart := <-t.interrupt:
+		if !restart {
+			log.WithField("task", t.Id).Info("task was stopped while waiting to be restarted")
+			tm.closeTask(t, exitCode == 0)
+			return
+		}
+	case <-time.After(backoff):
+	}
+
+	// restarts neither run init, nor print the prebuild log again
+	t.command = getCommand(t, false, csapi.WorkspaceInitFromBackup, tm.storeLocation)
+	tm.updateState(func() bool {
+		t.RestartCount++
+		return true
+	})
+	tm.startTask(ctx, t)
+}
+
+// watchHealth runs the health check of a task while its terminal runs. Once the task is unhealthy,
+// watchHealth closes unhealthy and stops the task's process.
+func (tm *tasksManager) watchHealth(ctx context.Context, t *task, term *terminal.Term, exited <-chan struct{}, unhealthy chan<- struct{}) {
+	cfg := t.config.HealthCheck
+	if cfg == nil || tm.config.isHeadless() || restartPolicy(t.config) == restartPolicyNever {
+		return
+	}
+	probe := tm.probe(cfg.Port, nil, cfg.Command)
+	if probe == nil {
+		return
+	}
+	interval := defaultHealthCheckInterval
+	if cfg.Interval != nil && *cfg.Interval > 0 {
+		interval = time.Duration(*cfg.Interval) * time.Second
+	}
+	threshold := defaultHealthCheckFailureThreshold
+	if cfg.FailureThreshold != nil && *cfg.FailureThreshold > 0 {
+		threshold = *cfg.FailureThreshold
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		healthy  bool
+		failures int
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-exited:
+			return
+		case <-ticker.C:
+		}
+
+		if probe(ctx) {
+			healthy = true
+			failures = 0
+			continue
+		}
+		if !healthy {
+			// the task is still starting up
+			continue
+		}
+		failures++
+		if failures < threshold {
+			continue
+		}
+
+		log.WithField("task", t.Id).WithField("failures", failures).Warn("task is unhealthy - stopping its process")
+		close(unhealthy)
+		stopUnhealthyTask(t, term, exited)
+		return
+	}
+}
+
+// stopUnhealthyTask ends the process of a task's terminal with SIGTERM, and with SIGKILL if it's still running
+// after unhealthyTaskGracePeriod. Closing the terminal instead would count as closing the task on purpose.
+func stopUnhealthyTask(t *task, term *terminal.Term, exited <-chan struct{}) {
+	err := term.Command.Process.Signal(syscall.SIGTERM)
+	if err == nil {
+		select {
+		case <-exited:
+			return
+		case <-time.After(unhealthyTaskGracePeriod):
+		}
+	}
+	err = term.Command.Process.Kill()
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		log.WithError(err).WithField("task", t.Id).Warn("cannot stop unhealthy task")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/gitpod-io/gitpod/supervisor/pkg/terminal"
+)
+
+func TestRestartPolicy(t *testing.T) {
+	p := func(v string) *string { return &v }
+	tests := []struct {
+		Desc        string
+		Config      TaskConfig
+		Expectation string
+	}{
+		{Desc: "default", Expectation: restartPolicyNever},
+		{Desc: "explicit", Config: TaskConfig{RestartPolicy: p(restartPolicyAlways)}, Expectation: restartPolicyAlways},
+		{Desc: "health check", Config: TaskConfig{HealthCheck: &TaskHealthCheckConfig{Command: p("true")}}, Expectation: restartPolicyOnFailure},
+		{Desc: "health check with explicit policy", Config: TaskConfig{RestartPolicy: p(restartPolicyNever), HealthCheck: &TaskHealthCheckConfig{Command: p("true")}}, Expectation: restartPolicyNever},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			if act := restartPolicy(test.Config); act != test.Expectation {
+				t.Errorf("unexpected restart policy: want %s, got %s", test.Expectation, act)
+			}
+		})
+	}
+}
+
+func TestRestartBackoff(t *testing.T) {
+	tests := []struct {
+		Attempt     int
+		Expectation time.Duration
+	}{
+		{Attempt: 0, Expectation: 1 * time.Second},
+		{Attempt: 1, Expectation: 2 * time.Second},
+		{Attempt: 5, Expectation: 32 * time.Second},
+		{Attempt: 9, Expectation: taskRestartMaxBackoff},
+		{Attempt: 1000, Expectation: taskRestartMaxBackoff},
+	}
+
+	for _, test := range tests {
+		if act := restartBackoff(test.Attempt); act != test.Expectation {
+			t.Errorf("unexpected backoff for attempt %d: want %s, got %s", test.Attempt, test.Expectation, act)
+		}
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	p := func(v string) *string { return &v }
+	mux := terminal.NewMux()
+	exitedTerminal := func(t *testing.T, closedExplicitly bool) *terminal.Term {
+		alias, err := mux.Start(exec.Command("sleep", "60"), terminal.TermOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		term, ok := mux.Get(alias)
+		if !ok {
+			t.Fatal("terminal not found")
+		}
+		if closedExplicitly {
+			err = mux.CloseTerminal(alias, 0)
+		} else {
+			err = term.Command.Process.Kill()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = term.Wait()
+		return term
+	}
+
+	tests := []struct {
+		Desc             string
+		Policy           string
+		ClosedExplicitly bool
+		Unhealthy        bool
+		Success          bool
+		Expectation      bool
+	}{
+		{Desc: "never", Policy: restartPolicyNever, Unhealthy: true},
+		{Desc: "always", Policy: restartPolicyAlways, Success: true, Expectation: true},
+		{Desc: "on failure succeeded", Policy: restartPolicyOnFailure, Success: true},
+		{Desc: "on failure failed", Policy: restartPolicyOnFailure, Expectation: true},
+		{Desc: "unhealthy", Policy: restartPolicyOnFailure, Success: true, Unhealthy: true, Expectation: true},
+		{Desc: "closed explicitly", Policy: restartPolicyAlways, ClosedExplicitly: true},
+		{Desc: "unhealthy and closed explicitly", Policy: restartPolicyOnFailure, ClosedExplicitly: true, Unhealthy: true},
+	}
+	tm := &tasksManager{config: &Config{}}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			task := &task{config: TaskConfig{RestartPolicy: p(test.Policy)}}
+			term := exitedTerminal(t, test.ClosedExplicitly)
+			if act := tm.shouldRestart(context.Background(), task, term, test.Success, test.Unhealthy); act != test.Expectation {
+				t.Errorf("unexpected result: want %v, got %v", test.Expectation, act)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -67,6 +68,21 @@ type task struct {
 	command     string
 	successChan chan bool
 	title       string
+
+	// deps are the tasks which must be ready before this task starts
+	deps []*task
+	// readyMarker is the file the task creates once its before and init commands succeeded
+	readyMarker string
+	// readyChan is closed once the task is ready
+	readyChan chan struct{}
+	// closedChan is closed once the task is closed or blocked. Restart replaces it, hence it's guarded by tasksManager.mu.
+	closedChan chan struct{}
+	// restartAttempt is the number of consecutive restarts, which determines the restart backoff
+	restartAttempt int
+	// restartRequested is true if the task restarts right away once its terminal has been closed. Guarded by tasksManager.mu.
+	restartRequested bool
+	// interrupt ends the restart backoff of the task: true restarts the task right away, false stops it
+	interrupt chan bool
 }
 
 type headlessTaskProgressReporter interface {
@@ -85,6 +101,12 @@ type tasksManager struct {
 	terminalService *terminal.MuxTerminalService
 	contentState    ContentState
 	reporter        headlessTaskProgressReporter
+
+	// secrets resolves secret references in the task environment. References are passed on as they are if nil.
+	secrets *secretResolver
+
+	// ctx is the context the tasks run in, it's set once Run is called
+	ctx context.Context
 }
 
 func newTasksManager(config *Config, terminalService *terminal.MuxTerminalService, contentState ContentState, reporter headlessTaskProgressReporter) *tasksManager {
@@ -171,6 +193,14 @@ func (tm *tasksManager) init(ctx context.Context) {
 	contentSource, _ := tm.contentState.ContentSource()
 	tm.contentSource = contentSource
 
+	deps, blocked := resolveTaskDependencies(*tasks)
+	hasDependents := make(map[int]bool)
+	for _, d := range deps {
+		for _, i := range d {
+			hasDependents[i] = true
+		}
+	}
+
 	for i, config := range *tasks {
 		id := strconv.Itoa(i)
 		presentation := &api.TaskPresentation{}
@@ -196,80 +226,75 @@ func (tm *tasksManager) init(ctx context.Context) {
 			config:      config,
 			successChan: make(chan bool, 1),
 			title:       title,
+			readyChan:   make(chan struct{}),
+			closedChan:  make(chan struct{}),
+			interrupt:   make(chan bool, 1),
+		}
+		if tm.secrets != nil && config.Env != nil {
+			err := tm.secrets.Declare(*config.Env)
+			if err != nil {
+				log.WithError(err).WithField("task", id).Warn("invalid secret reference in task environment")
+			}
+		}
+		if hasDependents[i] && config.Readiness == nil {
+			task.readyMarker = filepath.Join(tm.storeLocation, "ready-"+id)
+			// the marker might be left over from a previous workspace start or a prebuild
+			_ = os.Remove(task.readyMarker)
 		}
 		task.command = getCommand(task, tm.config.isHeadless(), tm.contentSource, tm.storeLocation)
 		if tm.config.isHeadless() && task.command == "exit" {
+			// there is nothing to run, hence nothing to wait for either
 			task.State = api.TaskState_closed
+			task.Ready = true
 			task.successChan <- true
+			close(task.readyChan)
+			close(task.closedChan)
+		} else if reason, ok := blocked[i]; ok {
+			log.WithField("task", id).WithField("reason", reason).Error("cannot schedule task")
+			task.State = api.TaskState_blocked
+			task.BlockedReason = reason
+			task.successChan <- false
+			close(task.closedChan)
+		} else if len(deps[i]) > 0 {
+			task.State = api.TaskState_waiting
 		}
 		tm.tasks = append(tm.tasks, task)
 	}
+	for i, task := range tm.tasks {
+		for _, d := range deps[i] {
+			task.deps = append(task.deps, tm.tasks[d])
+			task.DependsOn = append(task.DependsOn, tm.tasks[d].Id)
+		}
+	}
 }
 
 func (tm *tasksManager) Run(ctx context.Context, wg *sync.WaitGroup, successChan chan bool) {
 	defer wg.Done()
 	defer log.Debug("tasksManager shutdown")
 
+	tm.ctx = ctx
 	tm.init(ctx)
 
 	for _, t := range tm.tasks {
-		if t.State == api.TaskState_closed {
+		if t.State == api.TaskState_closed || t.State == api.TaskState_blocked {
 			continue
 		}
-		taskLog := log.WithField("command", t.command)
-		taskLog.Info("starting a task terminal...")
-		openRequest := &api.OpenTerminalRequest{}
-		if t.config.Env != nil {
-			openRequest.Env = *t.config.Env
-		}
-		var readTimeout time.Duration
-		if !tm.config.isHeadless() {
-			readTimeout = 5 * time.Second
-		}
-		resp, err := tm.terminalService.OpenWithOptions(ctx, openRequest, terminal.TermOptions{
-			ReadTimeout: readTimeout,
-			Title:       t.title,
-		})
-		if err != nil {
-			taskLog.WithError(err).Error("cannot open new task terminal")
-			t.successChan <- false
-			tm.setTaskState(t, api.TaskState_closed)
+		if len(t.deps) == 0 {
+			tm.startTask(ctx, t)
 			continue
 		}
-
-		taskLog = taskLog.WithField("terminal", resp.Terminal.Alias)
-		term, ok := tm.terminalService.Mux.Get(resp.Terminal.Alias)
-		if !ok {
-			taskLog.Error("cannot find a task terminal")
-			t.successChan <- false
-			tm.setTaskState(t, api.TaskState_closed)
-			continue
-		}
-
-		taskLog = taskLog.WithField("pid", term.Command.Process.Pid)
-		taskLog.Info("task terminal has been started")
-		tm.updateState(func() bool {
-			t.Terminal = resp.Terminal.Alias
-			t.State = api.TaskState_running
-			return true
-		})
-
-		go func(t *task, term *terminal.Term) {
-			state, _ := term.Wait()
-			if state != nil {
-				t.successChan <- state.Success()
-			} else {
-				t.successChan <- false
+		go func(t *task) {
+			err := tm.awaitDependencies(ctx, t)
+			if ctx.Err() != nil {
+				return
 			}
-			taskLog.Info("task terminal has been closed")
-			tm.setTaskState(t, api.TaskState_closed)
-		}(t, term)
-
-		tm.watch(t, term)
-
-		if t.command != "" {
-			term.PTY.Write([]byte(t.command + "\n"))
-		}
+			if err != nil {
+				log.WithError(err).WithField("task", t.Id).Error("cannot start task")
+				tm.blockTask(t, err.Error())
+				return
+			}
+			tm.startTask(ctx, t)
+		}(t)
 	}
 
 	success := true
@@ -291,10 +316,155 @@ func (tm *tasksManager) Run(ctx context.Context, wg *sync.WaitGroup, successChan
 	successChan <- success
 }
 
+// startTask opens the terminal of a task and runs its command
+func (tm *tasksManager) startTask(ctx context.Context, t *task) {
+	taskLog := log.WithField("command", t.command)
+	taskLog.Info("starting a task terminal...")
+	openRequest := &api.OpenTerminalRequest{}
+	if t.config.Env != nil {
+		openRequest.Env = *t.config.Env
+	}
+	if tm.secrets != nil && len(openRequest.Env) > 0 {
+		env, err := tm.secrets.ResolveEnv(ctx, openRequest.Env)
+		if err != nil {
+			taskLog.WithError(err).Error("cannot resolve task secrets")
+			tm.blockTask(t, err.Error())
+			return
+		}
+		openRequest.Env = env
+	}
+	var readTimeout time.Duration
+	if !tm.config.isHeadless() {
+		readTimeout = 5 * time.Second
+	}
+	if tm.config.isHeadless() && tm.terminalService.RecordingLocation != "" {
+		// recordings of prebuild tasks help debugging prebuilds
+		openRequest.Record = true
+	}
+	resp, err := tm.terminalService.OpenWithOptions(ctx, openRequest, terminal.TermOptions{
+		ReadTimeout: readTimeout,
+		Title:       t.title,
+	})
+	if err != nil {
+		taskLog.WithError(err).Error("cannot open new task terminal")
+		tm.closeTask(t, false)
+		return
+	}
+
+	taskLog = taskLog.WithField("terminal", resp.Terminal.Alias)
+	term, ok := tm.terminalService.Mux.Get(resp.Terminal.Alias)
+	if !ok {
+		taskLog.Error("cannot find a task terminal")
+		tm.closeTask(t, false)
+		return
+	}
+
+	taskLog = taskLog.WithField("pid", term.Command.Process.Pid)
+	taskLog.Info("task terminal has been started")
+	tm.updateState(func() bool {
+		t.Terminal = resp.Terminal.Alias
+		t.State = api.TaskState_running
+		return true
+	})
+
+	var (
+		started   = time.Now()
+		exited    = make(chan struct{})
+		unhealthy = make(chan struct{})
+		// the task might be restarted as soon as its terminal exits, which changes both
+		command  = t.command
+		firstRun = t.RestartCount == 0
+	)
+	go func(t *task, term *terminal.Term) {
+		exitCode, _ := term.Wait()
+		close(exited)
+		success := exitCode == 0
+		taskLog.WithField("exitCode", exitCode).Info("task terminal has been closed")
+
+		var wasUnhealthy bool
+		select {
+		case <-unhealthy:
+			wasUnhealthy = true
+		default:
+		}
+		tm.mu.Lock()
+		restartRequested := t.restartRequested
+		t.restartRequested = false
+		tm.mu.Unlock()
+		if restartRequested && ctx.Err() == nil {
+			t.restartAttempt = 0
+			tm.restartTask(ctx, t, exitCode, 0)
+			return
+		}
+		if tm.shouldRestart(ctx, t, term, success, wasUnhealthy) {
+			tm.restartTask(ctx, t, exitCode, nextRestartBackoff(t, time.Since(started)))
+			return
+		}
+
+		t.reportSuccess(success)
+		// dependents learn about the closed task only once its readiness is settled
+		tm.markReadyOnClose(ctx, t, success)
+		tm.updateState(func() bool {
+			t.State = api.TaskState_closed
+			t.LastExitCode = int32(exitCode)
+			// closed under mu, because Restart replaces the channel of closed tasks
+			close(t.closedChan)
+			return true
+		})
+	}(t, term)
+	go tm.watchHealth(ctx, t, term, exited, unhealthy)
+
+	tm.watch(t, term)
+
+	if command != "" {
+		term.PTY.Write([]byte(command + "\n"))
+	}
+
+	if firstRun {
+		go tm.watchReadiness(ctx, t)
+	}
+}
+
+func (tm *tasksManager) blockTask(t *task, reason string) {
+	t.reportSuccess(false)
+	tm.updateState(func() bool {
+		t.State = api.TaskState_blocked
+		t.BlockedReason = reason
+		close(t.closedChan)
+		return true
+	})
+}
+
+// closed returns the channel which is closed once the current run of a task is closed or blocked
+func (tm *tasksManager) closed(t *task) <-chan struct{} {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return t.closedChan
+}
+
+// closeTask marks a task closed which won't run again
+func (tm *tasksManager) closeTask(t *task, success bool) {
+	t.reportSuccess(success)
+	tm.updateState(func() bool {
+		t.State = api.TaskState_closed
+		close(t.closedChan)
+		return true
+	})
+}
+
+// reportSuccess reports the outcome of a task. Only the first outcome counts
+// for the tasks manager, tasks which run again report theirs in vain.
+func (t *task) reportSuccess(success bool) {
+	select {
+	case t.successChan <- success:
+	default:
+	}
+}
+
 func getCommand(task *task, isHeadless bool, contentSource csapi.WorkspaceInitSource, storeLocation string) string {
 	commands := getCommands(task, isHeadless, contentSource, storeLocation)
 	command := composeCommand(composeCommandOptions{
-		commands: commands,
+		commands: withReadyMarker(task, commands, isHeadless),
 		format:   "{\n%s\n}",
 		sep:      " && ",
 	})
@@ -308,6 +478,11 @@ func getCommand(task *task, isHeadless bool, contentSource csapi.WorkspaceInitSo
 		return command + "; exit"
 	}
 
+	if restartPolicy(task.config) != restartPolicyNever && task.config.Command != nil && strings.TrimSpace(*task.config.Command) != "" {
+		// the terminal must end with the command for us to notice when to restart the task
+		command += "; exit"
+	}
+
 	histfileCommand := getHistfileCommand(task, commands, contentSource, storeLocation)
 	if strings.TrimSpace(command) == "" {
 		return histfileCommand
@@ -0,0 +1,405 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+const (
+	// tokenRefreshInterval is the time between two checks for tokens which are about to expire
+	tokenRefreshInterval = 30 * time.Second
+	// tokenRefreshLeeway is the time before its expiry at which we start refreshing a token
+	tokenRefreshLeeway = 5 * time.Minute
+
+	// maxPendingTokenEvents is the number of events a subscriber may fall behind before it's dropped
+	maxPendingTokenEvents = 100
+
+	tokenCacheFile = "tokens.enc"
+	// tokenCacheKeyInfo binds the key derived for the token cache to this purpose
+	tokenCacheKeyInfo = "supervisor token cache"
+)
+
+// tokenRefresher is implemented by token providers which can replace the tokens they provided before they expire
+type tokenRefresher interface {
+	// RefreshToken returns a token which replaces tkn, or nil if there's none which lives longer.
+	RefreshToken(ctx context.Context, kind string, tkn *Token) (*Token, error)
+}
+
+// tokenStore keeps the token cache on disk, encrypted with AES-GCM.
+//
+// The key is derived from a secret the workspace receives in its environment and is never written to disk,
+// so copies of the cache location, e.g. in bug reports, don't leak the tokens. This is no protection
+// against processes in the workspace: they can read the environment of supervisor just as well.
+type tokenStore struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+type storedToken struct {
+	Kind       string         `json:"kind"`
+	User       string         `json:"user,omitempty"`
+	Token      string         `json:"token"`
+	Host       string         `json:"host"`
+	Scope      []string       `json:"scope,omitempty"`
+	ExpiryDate *time.Time     `json:"expiryDate,omitempty"`
+	Reuse      api.TokenReuse `json:"reuse"`
+}
+
+func newTokenStore(dir string, secret string) (*tokenStore, error) {
+	if secret == "" {
+		return nil, xerrors.Errorf("no secret to derive the token cache key from")
+	}
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot create token cache location: %w", err)
+	}
+
+	key := make([]byte, 32)
+	_, err = io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(tokenCacheKeyInfo)), key)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot derive token cache key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid token cache key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenStore{dir: dir, aead: aead}, nil
+}
+
+func (st *tokenStore) save(tokens map[string][]*Token) error {
+	var stored []storedToken
+	for kind, tkns := range tokens {
+		for _, tkn := range tkns {
+			var scopes []string
+			for scope := range tkn.Scope {
+				scopes = append(scopes, scope)
+			}
+			stored = append(stored, storedToken{
+				Kind:       kind,
+				User:       tkn.User,
+				Token:      tkn.Token,
+				Host:       tkn.Host,
+				Scope:      scopes,
+				ExpiryDate: tkn.ExpiryDate,
+				Reuse:      tkn.Reuse,
+			})
+		}
+	}
+	plaintext, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, st.aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+	ciphertext := st.aead.Seal(nonce, nonce, plaintext, nil)
+
+	fn := filepath.Join(st.dir, tokenCacheFile)
+	err = os.WriteFile(fn+".tmp", ciphertext, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(fn+".tmp", fn)
+}
+
+func (st *tokenStore) load() (map[string][]*Token, error) {
+	ciphertext, err := os.ReadFile(filepath.Join(st.dir, tokenCacheFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < st.aead.NonceSize() {
+		return nil, xerrors.Errorf("token cache is truncated")
+	}
+	nonce, ciphertext := ciphertext[:st.aead.NonceSize()], ciphertext[st.aead.NonceSize():]
+	plaintext, err := st.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot decrypt token cache: %w", err)
+	}
+
+	var stored []storedToken
+	err = json.Unmarshal(plaintext, &stored)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot parse token cache: %w", err)
+	}
+	res := make(map[string][]*Token)
+	for _, t := range stored {
+		res[t.Kind] = append(res[t.Kind], &Token{
+			User:       t.User,
+			Token:      t.Token,
+			Host:       t.Host,
+			Scope:      mapScopes(t.Scope),
+			ExpiryDate: t.ExpiryDate,
+			Reuse:      t.Reuse,
+		})
+	}
+	return res, nil
+}
+
+// PersistTo restores the tokens a previous supervisor cached in dir, and keeps the cache there from now on.
+// The cache is encrypted with a key derived from secret, which must be the same after a restart.
+func (s *InMemoryTokenService) PersistTo(dir string, secret string) error {
+	store, err := newTokenStore(dir, secret)
+	if err != nil {
+		return err
+	}
+	tokens, err := store.load()
+	if err != nil {
+		log.WithError(err).Warn("cannot restore token cache - starting with an empty one")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for kind, tkns := range tokens {
+		for _, tkn := range tkns {
+			if tkn.ExpiryDate != nil && !now.Before(*tkn.ExpiryDate) {
+				continue
+			}
+			s.token[kind] = append(s.token[kind], tkn)
+		}
+	}
+	s.store = store
+	s.persist()
+	return nil
+}
+
+// persist writes the token cache to disk if it's persisted.
+// Callers are expected to hold mu.
+func (s *InMemoryTokenService) persist() {
+	if s.store == nil {
+		return
+	}
+	err := s.store.save(s.token)
+	if err != nil {
+		log.WithError(err).Warn("cannot persist token cache")
+	}
+}
+
+// RefreshTokens refreshes cached tokens before they expire, and removes expired ones, until ctx is canceled.
+func (s *InMemoryTokenService) RefreshTokens(ctx context.Context) {
+	t := time.NewTicker(tokenRefreshInterval)
+	defer t.Stop()
+	for {
+		s.refreshTokens(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *InMemoryTokenService) refreshTokens(ctx context.Context, now time.Time) {
+	type expiringToken struct {
+		Kind  string
+		Token *Token
+	}
+	var expiring []expiringToken
+
+	s.mu.Lock()
+	var expired bool
+	for kind, tkns := range s.token {
+		var valid []*Token
+		for _, tkn := range tkns {
+			if tkn.ExpiryDate == nil {
+				valid = append(valid, tkn)
+				continue
+			}
+			if !now.Before(*tkn.ExpiryDate) {
+				expired = true
+				delete(s.refreshFailed, tkn)
+				s.publishTokenEvent(api.TokenEventsResponse_EXPIRED, kind, tkn, "")
+				log.WithField("kind", kind).WithField("host", tkn.Host).WithField("scopes", tkn.Scope).Info("token expired")
+				continue
+			}
+			valid = append(valid, tkn)
+			if tkn.ExpiryDate.Sub(now) < tokenRefreshLeeway {
+				expiring = append(expiring, expiringToken{Kind: kind, Token: tkn})
+			}
+		}
+		s.token[kind] = valid
+	}
+	if expired {
+		s.persist()
+	}
+	s.mu.Unlock()
+
+	for _, e := range expiring {
+		s.refreshToken(ctx, e.Kind, e.Token)
+	}
+}
+
+func (s *InMemoryTokenService) refreshToken(ctx context.Context, kind string, tkn *Token) {
+	s.mu.RLock()
+	prov := s.provider[kind]
+	s.mu.RUnlock()
+
+	var errs []string
+	for _, p := range prov {
+		refresher, ok := p.(tokenRefresher)
+		if !ok {
+			continue
+		}
+		fresh, err := refresher.RefreshToken(ctx, kind, tkn)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if fresh == nil || fresh.ExpiryDate == nil || !fresh.ExpiryDate.After(*tkn.ExpiryDate) {
+			continue
+		}
+		if s.replaceToken(kind, tkn, fresh) {
+			log.WithField("kind", kind).WithField("host", tkn.Host).WithField("scopes", tkn.Scope).Info("refreshed token")
+		}
+		return
+	}
+
+	reason := "no token provider can refresh the token"
+	if len(errs) > 0 {
+		reason = strings.Join(errs, "; ")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, failed := s.refreshFailed[tkn]; failed || !s.isCached(kind, tkn) {
+		return
+	}
+	s.refreshFailed[tkn] = struct{}{}
+	s.publishTokenEvent(api.TokenEventsResponse_REFRESH_FAILED, kind, tkn, reason)
+	log.WithField("kind", kind).WithField("host", tkn.Host).WithField("reason", reason).Warn("cannot refresh token")
+}
+
+// replaceToken replaces a cached token by a fresh one. It returns false if the old token isn't cached anymore.
+func (s *InMemoryTokenService) replaceToken(kind string, old, fresh *Token) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, t := range s.token[kind] {
+		if t != old {
+			continue
+		}
+		s.token[kind][i] = fresh
+		delete(s.refreshFailed, old)
+		s.persist()
+		s.publishTokenEvent(api.TokenEventsResponse_REFRESHED, kind, fresh, "")
+		return true
+	}
+	return false
+}
+
+// isCached returns true if tkn is in the cache.
+// Callers are expected to hold mu.
+func (s *InMemoryTokenService) isCached(kind string, tkn *Token) bool {
+	for _, t := range s.token[kind] {
+		if t == tkn {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenEventSubscription struct {
+	kind   string
+	events chan *api.TokenEventsResponse
+}
+
+// publishTokenEvent sends an event to all subscribers. Subscribers which fall behind are dropped.
+// Callers are expected to hold mu.
+func (s *InMemoryTokenService) publishTokenEvent(tpe api.TokenEventsResponse_Type, kind string, tkn *Token, reason string) {
+	if len(s.subscriptions) == 0 {
+		return
+	}
+
+	evt := &api.TokenEventsResponse{
+		Type:  tpe,
+		Kind:  kind,
+		Host:  tkn.Host,
+		Error: reason,
+	}
+	for scope := range tkn.Scope {
+		evt.Scope = append(evt.Scope, scope)
+	}
+	if tkn.ExpiryDate != nil {
+		evt.ExpiryDate = timestamppb.New(*tkn.ExpiryDate)
+	}
+	for sub := range s.subscriptions {
+		if sub.kind != "" && sub.kind != kind {
+			continue
+		}
+		select {
+		case sub.events <- evt:
+		default:
+			log.Warn("token event subscriber fell behind - dropping it")
+			delete(s.subscriptions, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// TokenEvents streams changes of the cached tokens
+func (s *InMemoryTokenService) TokenEvents(req *api.TokenEventsRequest, srv api.TokenService_TokenEventsServer) error {
+	sub := &tokenEventSubscription{
+		kind:   req.Kind,
+		events: make(chan *api.TokenEventsResponse, maxPendingTokenEvents),
+	}
+	s.mu.Lock()
+	s.subscriptions[sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscriptions[sub]; ok {
+			delete(s.subscriptions, sub)
+			close(sub.events)
+		}
+	}()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case evt, ok := <-sub.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "too many pending token events")
+			}
+			err := srv.Send(evt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
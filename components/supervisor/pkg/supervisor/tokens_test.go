@@ -0,0 +1,209 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+func TestTokenCachePersistence(t *testing.T) {
+	dir := t.TempDir()
+	expiry := time.Now().Add(1 * time.Hour).Truncate(time.Second)
+
+	service := NewInMemoryTokenService()
+	err := service.PersistTo(dir, "workspace-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.SetToken(context.Background(), &api.SetTokenRequest{
+		Kind:       KindGit,
+		Host:       "github.com",
+		Scope:      []string{"repo"},
+		Token:      "secret-token",
+		ExpiryDate: timestamppb.New(expiry),
+		Reuse:      api.TokenReuse_REUSE_WHEN_POSSIBLE,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := os.ReadFile(filepath.Join(dir, tokenCacheFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(cache, []byte("secret-token")) {
+		t.Error("token cache is not encrypted")
+	}
+
+	restored := NewInMemoryTokenService()
+	err = restored.PersistTo(dir, "workspace-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := restored.GetToken(context.Background(), &api.GetTokenRequest{Kind: KindGit, Host: "github.com", Scope: []string{"repo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Token != "secret-token" {
+		t.Errorf("unexpected token: %q", resp.Token)
+	}
+
+	// the environment provides the same token again after a restart
+	_, err = restored.SetToken(context.Background(), &api.SetTokenRequest{
+		Kind:       KindGit,
+		Host:       "github.com",
+		Scope:      []string{"repo"},
+		Token:      "secret-token",
+		ExpiryDate: timestamppb.New(expiry),
+		Reuse:      api.TokenReuse_REUSE_WHEN_POSSIBLE,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(restored.token[KindGit]); n != 1 {
+		t.Errorf("expected a single cached token, got %d", n)
+	}
+
+	// the key is not stored next to the cache
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected only the token cache in %s, got %d files", dir, len(files))
+	}
+
+	// without a secret there is no key to encrypt the cache with
+	err = NewInMemoryTokenService().PersistTo(dir, "")
+	if err == nil {
+		t.Error("expected an error without a secret")
+	}
+
+	// a different secret cannot decrypt the cache
+	fresh := NewInMemoryTokenService()
+	err = fresh.PersistTo(dir, "other-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fresh.token[KindGit]); n != 0 {
+		t.Errorf("expected no cached tokens with a new key, got %d", n)
+	}
+}
+
+type refreshingTokenProvider struct {
+	tokenProviderFunc
+	refresh func(tkn *Token) (*Token, error)
+}
+
+func (p *refreshingTokenProvider) RefreshToken(ctx context.Context, kind string, tkn *Token) (*Token, error) {
+	return p.refresh(tkn)
+}
+
+type tokenEventsRecorder struct {
+	api.TokenService_TokenEventsServer
+	ctx    context.Context
+	events chan *api.TokenEventsResponse
+}
+
+func (r *tokenEventsRecorder) Context() context.Context { return r.ctx }
+func (r *tokenEventsRecorder) Send(evt *api.TokenEventsResponse) error {
+	r.events <- evt
+	return nil
+}
+
+func TestRefreshTokens(t *testing.T) {
+	var (
+		now          = time.Now().Truncate(time.Second)
+		expiringSoon = now.Add(1 * time.Minute)
+		expired      = now.Add(-1 * time.Minute)
+		later        = now.Add(1 * time.Hour)
+	)
+	newToken := func(value string, expiry time.Time) *Token {
+		return &Token{
+			Host:       "gitlab.com",
+			Token:      value,
+			Scope:      mapScopes([]string{"api"}),
+			ExpiryDate: &expiry,
+			Reuse:      api.TokenReuse_REUSE_WHEN_POSSIBLE,
+		}
+	}
+
+	service := NewInMemoryTokenService()
+	service.token[KindGit] = []*Token{newToken("expiring", expiringSoon), newToken("expired", expired)}
+	service.token["other"] = []*Token{newToken("unrefreshable", expiringSoon)}
+	service.provider[KindGit] = []tokenProvider{&refreshingTokenProvider{
+		refresh: func(tkn *Token) (*Token, error) {
+			return newToken("fresh", later), nil
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	recorder := &tokenEventsRecorder{ctx: ctx, events: make(chan *api.TokenEventsResponse, 10)}
+	go func() {
+		_ = service.TokenEvents(&api.TokenEventsRequest{}, recorder)
+	}()
+	for {
+		service.mu.RLock()
+		subscribed := len(service.subscriptions) > 0
+		service.mu.RUnlock()
+		if subscribed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	service.refreshTokens(context.Background(), now)
+	// a failed refresh is reported only once
+	service.refreshTokens(context.Background(), now)
+
+	var cached []string
+	for _, tkns := range service.token {
+		for _, tkn := range tkns {
+			cached = append(cached, tkn.Token)
+		}
+	}
+	sortStrings := cmpopts.SortSlices(func(x, y string) bool { return x < y })
+	if diff := cmp.Diff([]string{"fresh", "unrefreshable"}, cached, sortStrings); diff != "" {
+		t.Errorf("unexpected cached tokens (-want +got):\n%s", diff)
+	}
+
+	expectation := []*api.TokenEventsResponse{
+		{Type: api.TokenEventsResponse_EXPIRED, Kind: KindGit, Host: "gitlab.com", Scope: []string{"api"}, ExpiryDate: timestamppb.New(expired)},
+		{Type: api.TokenEventsResponse_REFRESHED, Kind: KindGit, Host: "gitlab.com", Scope: []string{"api"}, ExpiryDate: timestamppb.New(later)},
+		{Type: api.TokenEventsResponse_REFRESH_FAILED, Kind: "other", Host: "gitlab.com", Scope: []string{"api"}, ExpiryDate: timestamppb.New(expiringSoon), Error: "no token provider can refresh the token"},
+	}
+	var act []*api.TokenEventsResponse
+	for len(act) < len(expectation) {
+		select {
+		case evt := <-recorder.events:
+			act = append(act, evt)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("missing token events, got %v", act)
+		}
+	}
+	select {
+	case evt := <-recorder.events:
+		t.Errorf("unexpected token event: %v", evt)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	sortEvents := cmpopts.SortSlices(func(x, y *api.TokenEventsResponse) bool { return x.Type < y.Type })
+	ignoreUnexported := cmpopts.IgnoreUnexported(api.TokenEventsResponse{}, timestamppb.Timestamp{})
+	if diff := cmp.Diff(expectation, act, sortEvents, ignoreUnexported); diff != "" {
+		t.Errorf("unexpected token events (-want +got):\n%s", diff)
+	}
+}
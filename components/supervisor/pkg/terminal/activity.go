@@ -0,0 +1,28 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import "io"
+
+// ActivityListener is notified of the input to and output of terminals.
+// Implementations must not block, as they're called for every write.
+type ActivityListener interface {
+	TerminalInput(alias string)
+	TerminalOutput(alias string)
+}
+
+// activityWriter notifies a listener of terminal output
+type activityWriter struct {
+	out      io.Writer
+	alias    string
+	listener ActivityListener
+}
+
+func (w *activityWriter) Write(p []byte) (n int, err error) {
+	if len(p) > 0 {
+		w.listener.TerminalOutput(w.alias)
+	}
+	return w.out.Write(p)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+const (
+	// starterParticipantName is the name of the participant who started a shared terminal
+	starterParticipantName = "starter"
+
+	maxParticipants = 32
+)
+
+var (
+	// ErrParticipantExists is returned when a participant joins with a name that's already taken
+	ErrParticipantExists = xerrors.New("participant exists already")
+	// ErrParticipantNotFound is returned when there's no participant with the given name or token
+	ErrParticipantNotFound = xerrors.New("participant not found")
+	// ErrTooManyParticipants is returned when a terminal has reached its maximum number of participants
+	ErrTooManyParticipants = xerrors.New("too many participants")
+)
+
+type participant struct {
+	Name      string
+	Role      api.TerminalRole
+	Listeners int
+}
+
+// participants tracks who takes part in a shared terminal and notifies subscribers about their presence
+type participants struct {
+	mu          sync.RWMutex
+	byToken     map[string]*participant
+	subscribers map[chan *api.TerminalPresence]struct{}
+}
+
+func newParticipants(starterToken string) *participants {
+	return &participants{
+		byToken: map[string]*participant{
+			starterToken: {Name: starterParticipantName, Role: api.TerminalRole_read_write},
+		},
+		subscribers: make(map[chan *api.TerminalPresence]struct{}),
+	}
+}
+
+// Join adds a read-only participant and returns its token
+func (p *participants) Join(name string) (token string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.byToken) >= maxParticipants {
+		return "", ErrTooManyParticipants
+	}
+	if p.find(name) != nil {
+		return "", ErrParticipantExists
+	}
+	tkn, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	token = tkn.String()
+	p.byToken[token] = &participant{Name: name, Role: api.TerminalRole_read_only}
+	p.notify()
+	return token, nil
+}
+
+// SetRole changes the role of a participant
+func (p *participants) SetRole(name string, role api.TerminalRole) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pt := p.find(name)
+	if pt == nil {
+		return ErrParticipantNotFound
+	}
+	if pt.Role == role {
+		return nil
+	}
+	pt.Role = role
+	p.notify()
+	return nil
+}
+
+// CanWrite returns true if the token belongs to a participant with the read_write role
+func (p *participants) CanWrite(token string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pt, ok := p.byToken[token]
+	return ok && pt.Role == api.TerminalRole_read_write
+}
+
+// Attach marks the participant as listening until the returned detach function is called
+func (p *participants) Attach(token string) (detach func(), err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pt, ok := p.byToken[token]
+	if !ok {
+		return nil, ErrParticipantNotFound
+	}
+	pt.Listeners++
+	p.notify()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			pt.Listeners--
+			p.notify()
+		})
+	}, nil
+}
+
+// List returns all participants sorted by name
+func (p *participants) List() []*api.TerminalParticipant {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.list()
+}
+
+// Subscribe returns a channel which receives the participants whenever they change.
+// Subscribers which don't keep up miss intermediate updates, but always receive the latest one.
+func (p *participants) Subscribe() (updates <-chan *api.TerminalPresence, unsubscribe func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c := make(chan *api.TerminalPresence, 1)
+	p.subscribers[c] = struct{}{}
+	return c, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.subscribers, c)
+	}
+}
+
+// find returns the participant with the given name. Callers must hold mu.
+func (p *participants) find(name string) *participant {
+	for _, pt := range p.byToken {
+		if pt.Name == name {
+			return pt
+		}
+	}
+	return nil
+}
+
+// list returns all participants sorted by name. Callers must hold mu.
+func (p *participants) list() []*api.TerminalParticipant {
+	res := make([]*api.TerminalParticipant, 0, len(p.byToken))
+	for _, pt := range p.byToken {
+		res = append(res, &api.TerminalParticipant{
+			Name:     pt.Name,
+			Role:     pt.Role,
+			Attached: pt.Listeners > 0,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// notify sends the current participants to all subscribers. Callers must hold mu.
+func (p *participants) notify() {
+	presence := &api.TerminalPresence{Participants: p.list()}
+	for c := range p.subscribers {
+		select {
+		case <-c:
+		default:
+		}
+		select {
+		case c <- presence:
+		default:
+		}
+	}
+}
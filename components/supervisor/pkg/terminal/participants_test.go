@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/gitpod-io/gitpod/supervisor/api"
+)
+
+func TestParticipants(t *testing.T) {
+	p := newParticipants("starter-token")
+	updates, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	if !p.CanWrite("starter-token") {
+		t.Error("starter cannot write")
+	}
+
+	token, err := p.Join("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.CanWrite(token) {
+		t.Error("new participant can write")
+	}
+	if _, err := p.Join("alice"); err != ErrParticipantExists {
+		t.Errorf("expected ErrParticipantExists, got %v", err)
+	}
+	if err := p.SetRole("bob", api.TerminalRole_read_write); err != ErrParticipantNotFound {
+		t.Errorf("expected ErrParticipantNotFound, got %v", err)
+	}
+
+	err = p.SetRole("alice", api.TerminalRole_read_write)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.CanWrite(token) {
+		t.Error("read-write participant cannot write")
+	}
+	if p.CanWrite("unknown-token") {
+		t.Error("unknown token can write")
+	}
+
+	detach, err := p.Attach(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectation := &api.TerminalPresence{Participants: []*api.TerminalParticipant{
+		{Name: "alice", Role: api.TerminalRole_read_write, Attached: true},
+		{Name: starterParticipantName, Role: api.TerminalRole_read_write},
+	}}
+	if diff := cmp.Diff(expectation, <-updates, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected presence (-want +got):\n%s", diff)
+	}
+
+	detach()
+	detach()
+	expectation.Participants[0].Attached = false
+	if diff := cmp.Diff(expectation, <-updates, protocmp.Transform()); diff != "" {
+		t.Errorf("unexpected presence after detach (-want +got):\n%s", diff)
+	}
+
+	if _, err := p.Attach("unknown-token"); err != ErrParticipantNotFound {
+		t.Errorf("expected ErrParticipantNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/creack/pty"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+)
+
+const (
+	// recordingExtension is the file extension of asciicast recordings
+	recordingExtension = ".cast"
+	// previousRecordingSuffix is appended to the filename of a recording once it's rotated
+	previousRecordingSuffix = ".1"
+
+	// DefaultRecordingMaxSize is the size at which recordings are rotated unless configured otherwise
+	DefaultRecordingMaxSize = 10 << 20
+	// DefaultRecordingRetention is the number of recordings which are kept unless configured otherwise
+	DefaultRecordingRetention = 10
+
+	recordingFlushInterval = 1 * time.Second
+)
+
+// asciicastHeader is the first line of an asciicast v2 file.
+// See https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// asciicast event types
+const (
+	asciicastOutput = "o"
+	asciicastResize = "r"
+)
+
+// RecordingFilename returns the file a terminal session is recorded to
+func RecordingFilename(location, alias string) (string, error) {
+	if alias == "" || alias != filepath.Base(alias) || strings.HasPrefix(alias, ".") {
+		return "", xerrors.Errorf("invalid terminal alias: %s", alias)
+	}
+	return filepath.Join(location, alias+recordingExtension), nil
+}
+
+// previousRecordings returns the files of a recording in the order they were written
+func previousRecordings(fn string) []string {
+	return []string{fn + previousRecordingSuffix, fn}
+}
+
+// pruneRecordings removes the oldest recordings in location, s.t. at most keep recordings remain.
+// Recordings of the terminals in live are never removed, nor counted.
+func pruneRecordings(location string, keep int, live map[string]*Term) error {
+	files, err := os.ReadDir(location)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	type recording struct {
+		fn      string
+		modTime time.Time
+	}
+	var recs []recording
+	for _, f := range files {
+		alias := strings.TrimSuffix(f.Name(), recordingExtension)
+		if f.IsDir() || alias == f.Name() {
+			continue
+		}
+		if _, ok := live[alias]; ok {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		recs = append(recs, recording{fn: filepath.Join(location, f.Name()), modTime: info.ModTime()})
+	}
+	if len(recs) <= keep {
+		return nil
+	}
+
+	sort.Slice(recs, func(i, j int) bool { return recs[i].modTime.After(recs[j].modTime) })
+	for _, rec := range recs[keep:] {
+		for _, part := range previousRecordings(rec.fn) {
+			err := os.Remove(part)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recorder writes an asciicast v2 recording of a terminal session.
+//
+// Once a recording exceeds its max size it's rotated: the recording is renamed to <alias>.cast.1, replacing
+// the one rotated before, and a new recording starts. Hence a terminal never takes up more than twice the
+// max size on disk.
+type recorder struct {
+	mu      sync.Mutex
+	fn      string
+	maxSize int64
+	hdr     asciicastHeader
+	f       *os.File
+	out     *bufio.Writer
+	written int64
+	start   time.Time
+	pending []byte
+	failed  bool
+	closed  bool
+	stop    chan struct{}
+}
+
+// newRecorder starts a recording. If maxSize is zero the recording is never rotated.
+func newRecorder(fn string, size *pty.Winsize, title string, maxSize int64) (*recorder, error) {
+	err := os.MkdirAll(filepath.Dir(fn), 0755)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot create recording location: %w", err)
+	}
+	// a recording left over from an earlier terminal with the same alias must not be replayed as part of this one
+	_ = os.Remove(fn + previousRecordingSuffix)
+
+	rec := &recorder{
+		fn:      fn,
+		maxSize: maxSize,
+		hdr: asciicastHeader{
+			Version: 2,
+			Width:   80,
+			Height:  24,
+			Title:   title,
+			Env:     map[string]string{"TERM": "xterm-color"},
+		},
+		stop: make(chan struct{}),
+	}
+	if size != nil && size.Cols > 0 && size.Rows > 0 {
+		rec.hdr.Width = int(size.Cols)
+		rec.hdr.Height = int(size.Rows)
+	}
+	err = rec.create()
+	if err != nil {
+		return nil, err
+	}
+
+	go rec.flushPeriodically()
+	return rec, nil
+}
+
+// create starts a new recording file. Callers must hold mu, unless the recorder isn't shared yet.
+func (r *recorder) create() error {
+	f, err := os.OpenFile(r.fn, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return xerrors.Errorf("cannot create recording: %w", err)
+	}
+	r.f = f
+	r.out = bufio.NewWriter(f)
+	r.start = time.Now()
+	r.hdr.Timestamp = r.start.Unix()
+
+	hdr, err := json.Marshal(r.hdr)
+	if err == nil {
+		hdr = append(hdr, '\n')
+		_, err = r.out.Write(hdr)
+	}
+	if err != nil {
+		f.Close()
+		return xerrors.Errorf("cannot write recording header: %w", err)
+	}
+	r.written = int64(len(hdr))
+	return nil
+}
+
+// rotate replaces the previous recording with the current one and starts a new recording. Callers must hold mu.
+func (r *recorder) rotate() error {
+	err := r.out.Flush()
+	cerr := r.f.Close()
+	if err != nil {
+		return err
+	}
+	if cerr != nil {
+		return cerr
+	}
+	err = os.Rename(r.fn, r.fn+previousRecordingSuffix)
+	if err != nil {
+		return err
+	}
+	return r.create()
+}
+
+// Write records terminal output. Write never fails so that a broken recording does not break the terminal.
+func (r *recorder) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// asciicast stores output as JSON strings - we must not split UTF-8 sequences across events
+	data := append(r.pending, p...)
+	valid := len(data)
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if !utf8.RuneStart(data[len(data)-i]) {
+			continue
+		}
+		if !utf8.FullRune(data[len(data)-i:]) {
+			valid = len(data) - i
+		}
+		break
+	}
+	r.pending = append([]byte(nil), data[valid:]...)
+	if valid > 0 {
+		r.event(asciicastOutput, string(data[:valid]))
+	}
+	return len(p), nil
+}
+
+// Resize records a change of the terminal size
+func (r *recorder) Resize(size *pty.Winsize) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hdr.Width, r.hdr.Height = int(size.Cols), int(size.Rows)
+	r.event(asciicastResize, fmt.Sprintf("%dx%d", size.Cols, size.Rows))
+}
+
+// event writes a single event. Callers must hold mu.
+func (r *recorder) event(tpe, data string) {
+	if r.failed || r.closed {
+		return
+	}
+	ev, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), tpe, data})
+	if err == nil && r.maxSize > 0 && r.written+int64(len(ev))+1 > r.maxSize {
+		err = r.rotate()
+		if err == nil {
+			// the new recording starts with the current terminal size, timestamps restart
+			ev, err = json.Marshal([]interface{}{time.Since(r.start).Seconds(), tpe, data})
+		}
+	}
+	if err == nil {
+		ev = append(ev, '\n')
+		_, err = r.out.Write(ev)
+		r.written += int64(len(ev))
+	}
+	if err != nil {
+		log.WithError(err).WithField("recording", r.f.Name()).Warn("cannot write terminal recording - stopping recording")
+		r.failed = true
+	}
+}
+
+func (r *recorder) flushPeriodically() {
+	t := time.NewTicker(recordingFlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-t.C:
+		}
+
+		r.mu.Lock()
+		if !r.failed {
+			_ = r.out.Flush()
+		}
+		r.mu.Unlock()
+	}
+}
+
+// Close finishes the recording
+func (r *recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	if len(r.pending) > 0 {
+		r.event(asciicastOutput, string(r.pending))
+	}
+	r.closed = true
+	close(r.stop)
+
+	err := r.out.Flush()
+	cerr := r.f.Close()
+	if err != nil {
+		return err
+	}
+	return cerr
+}
+
+// ReplayOptions configure the replay of a recording
+type ReplayOptions struct {
+	// Speed is the playback speed factor. Zero means 1.
+	Speed float64
+	// MaxIdle caps the pauses between events. Zero replays pauses as recorded.
+	MaxIdle time.Duration
+}
+
+// ReplayEvent is a single output or resize event of a recording
+type ReplayEvent struct {
+	Data []byte
+	Size *pty.Winsize
+}
+
+// Replay reads an asciicast recording and calls onEvent for each event, honouring the recorded timing.
+// The first event is the initial terminal size.
+func Replay(ctx context.Context, in io.Reader, opts ReplayOptions, onEvent func(ReplayEvent) error) error {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	scanner := bufio.NewScanner(in)
+	// output events can be as large as the PTY read buffer plus JSON escaping
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return xerrors.Errorf("recording is empty")
+	}
+	var hdr asciicastHeader
+	err := json.Unmarshal(scanner.Bytes(), &hdr)
+	if err != nil {
+		return xerrors.Errorf("cannot parse recording header: %w", err)
+	}
+	if hdr.Version != 2 {
+		return xerrors.Errorf("unsupported asciicast version: %d", hdr.Version)
+	}
+	err = onEvent(ReplayEvent{Size: &pty.Winsize{Cols: uint16(hdr.Width), Rows: uint16(hdr.Height)}})
+	if err != nil {
+		return err
+	}
+
+	var last float64
+	for scanner.Scan() {
+		var ev []interface{}
+		err := json.Unmarshal(scanner.Bytes(), &ev)
+		if err != nil || len(ev) != 3 {
+			return xerrors.Errorf("invalid recording event: %s", scanner.Text())
+		}
+		ts, _ := ev[0].(float64)
+		tpe, _ := ev[1].(string)
+		data, _ := ev[2].(string)
+
+		delay := time.Duration((ts - last) / speed * float64(time.Second))
+		if opts.MaxIdle > 0 && delay > opts.MaxIdle {
+			delay = opts.MaxIdle
+		}
+		last = ts
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		var rev ReplayEvent
+		switch tpe {
+		case asciicastOutput:
+			rev.Data = []byte(data)
+		case asciicastResize:
+			var cols, rows uint16
+			_, err := fmt.Sscanf(data, "%dx%d", &cols, &rows)
+			if err != nil {
+				continue
+			}
+			rev.Size = &pty.Winsize{Cols: cols, Rows: rows}
+		default:
+			// input and marker events are not replayed
+			continue
+		}
+		err = onEvent(rev)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordingReplay(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "recordings", "alias"+recordingExtension)
+	rec, err := newRecorder(fn, &pty.Winsize{Cols: 120, Rows: 40}, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	euro := []byte("€")
+	_, _ = rec.Write([]byte("hello "))
+	// split a multi-byte character across two writes
+	_, _ = rec.Write(euro[:1])
+	_, _ = rec.Write(euro[1:])
+	rec.Resize(&pty.Winsize{Cols: 80, Rows: 24})
+	_, _ = rec.Write([]byte("\r\nbye"))
+	err = rec.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var act []ReplayEvent
+	err = Replay(context.Background(), f, ReplayOptions{MaxIdle: time.Millisecond}, func(ev ReplayEvent) error {
+		act = append(act, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectation := []ReplayEvent{
+		{Size: &pty.Winsize{Cols: 120, Rows: 40}},
+		{Data: []byte("hello ")},
+		{Data: []byte("€")},
+		{Size: &pty.Winsize{Cols: 80, Rows: 24}},
+		{Data: []byte("\r\nbye")},
+	}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("unexpected replay (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecordingRotation(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "alias"+recordingExtension)
+	// leftover of an earlier terminal with the same alias
+	err := os.WriteFile(fn+previousRecordingSuffix, []byte("stale"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const maxSize = 512
+	rec, err := newRecorder(fn, &pty.Winsize{Cols: 120, Rows: 40}, "test", maxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fn + previousRecordingSuffix); !os.IsNotExist(err) {
+		t.Fatal("stale recording was not removed")
+	}
+	rec.Resize(&pty.Winsize{Cols: 80, Rows: 24})
+	for i := 0; i < 100; i++ {
+		_, _ = rec.Write([]byte(fmt.Sprintf("line %03d\r\n", i)))
+	}
+	err = rec.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		sizes []*pty.Winsize
+		data  string
+	)
+	for _, part := range previousRecordings(fn) {
+		stat, err := os.Stat(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat.Size() > maxSize {
+			t.Errorf("%s is %d bytes, expected at most %d", part, stat.Size(), maxSize)
+		}
+
+		f, err := os.Open(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Replay(context.Background(), f, ReplayOptions{MaxIdle: time.Millisecond}, func(ev ReplayEvent) error {
+			if ev.Size != nil {
+				sizes = append(sizes, ev.Size)
+			}
+			data += string(ev.Data)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the current recording starts with the size the terminal had when it was rotated
+	if diff := cmp.Diff(&pty.Winsize{Cols: 80, Rows: 24}, sizes[len(sizes)-1]); diff != "" {
+		t.Errorf("unexpected size of rotated recording (-want +got):\n%s", diff)
+	}
+	if !strings.HasSuffix(data, "line 099\r\n") {
+		t.Errorf("rotated recording misses the latest output: %q", data)
+	}
+	if strings.Contains(data, "line 000") {
+		t.Errorf("recording was not rotated more than once: %q", data)
+	}
+}
+
+func TestPruneRecordings(t *testing.T) {
+	loc := t.TempDir()
+	now := time.Now()
+	for i, alias := range []string{"oldest", "live", "older", "newer", "newest"} {
+		fn := filepath.Join(loc, alias+recordingExtension)
+		for _, part := range previousRecordings(fn) {
+			err := os.WriteFile(part, []byte(alias), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mtime := now.Add(time.Duration(i) * time.Minute)
+			err = os.Chtimes(part, mtime, mtime)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	err := pruneRecordings(loc, 2, map[string]*Term{"live": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var act []string
+	for _, f := range files {
+		act = append(act, f.Name())
+	}
+	expectation := []string{
+		"live" + recordingExtension, "live" + recordingExtension + previousRecordingSuffix,
+		"newer" + recordingExtension, "newer" + recordingExtension + previousRecordingSuffix,
+		"newest" + recordingExtension, "newest" + recordingExtension + previousRecordingSuffix,
+	}
+	if diff := cmp.Diff(expectation, act); diff != "" {
+		t.Errorf("unexpected recordings (-want +got):\n%s", diff)
+	}
+
+	err = pruneRecordings(filepath.Join(loc, "does-not-exist"), 2, nil)
+	if err != nil {
+		t.Errorf("unexpected error for missing location: %v", err)
+	}
+}
+
+func TestRecordingFilename(t *testing.T) {
+	tests := []struct {
+		Alias string
+		Error bool
+	}{
+		{Alias: "7b0b6a5e-0d3a-4a43-9c8e-4c3d2f1e0a9b"},
+		{Alias: "", Error: true},
+		{Alias: "..", Error: true},
+		{Alias: "../etc/passwd", Error: true},
+	}
+	for _, test := range tests {
+		_, err := RecordingFilename("/recordings", test.Alias)
+		if (err != nil) != test.Error {
+			t.Errorf("unexpected error for alias %q: %v", test.Alias, err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// minRedactedSecretLength is the length below which secrets are not redacted.
+	// Redacting very short secrets would mangle the output while hardly protecting them.
+	minRedactedSecretLength = 4
+
+	// redactedSecret replaces secrets in the output
+	redactedSecret = "********"
+
+	// redactorFlushDelay is how long output which might be the beginning of a secret is held back
+	redactorFlushDelay = 50 * time.Millisecond
+)
+
+// Redactor replaces secrets in terminal output. The zero value is ready to use.
+type Redactor struct {
+	mu sync.RWMutex
+	// secrets are ordered by length, longest first, so that secrets which contain others are redacted as a whole
+	secrets [][]byte
+}
+
+// Add adds secrets which are redacted from now on
+func (r *Redactor) Add(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) < minRedactedSecretLength || r.contains(secret) {
+			continue
+		}
+		r.secrets = append(r.secrets, []byte(secret))
+	}
+	sort.SliceStable(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+}
+
+// contains returns true if the secret is redacted already. Callers are expected to hold mu.
+func (r *Redactor) contains(secret string) bool {
+	for _, s := range r.secrets {
+		if string(s) == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns p with all secrets replaced
+func (r *Redactor) Redact(p []byte) []byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, secret := range r.secrets {
+		if bytes.Contains(p, secret) {
+			p = bytes.ReplaceAll(p, secret, []byte(redactedSecret))
+		}
+	}
+	return p
+}
+
+// partialSecretLength returns the length of the longest suffix of p which is the beginning of a secret
+func (r *Redactor) partialSecretLength(p []byte) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var res int
+	for _, secret := range r.secrets {
+		n := len(secret) - 1
+		if n > len(p) {
+			n = len(p)
+		}
+		for ; n > res; n-- {
+			if bytes.HasSuffix(p, secret[:n]) {
+				res = n
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Writer returns a writer which redacts secrets before writing to out.
+// Output which might be the beginning of a secret is held back until the next write, or until redactorFlushDelay has passed.
+// Closing the writer flushes the output held back, but does not close out.
+func (r *Redactor) Writer(out io.Writer) io.WriteCloser {
+	return &redactingWriter{
+		redactor: r,
+		out:      out,
+	}
+}
+
+type redactingWriter struct {
+	redactor *Redactor
+	out      io.Writer
+
+	mu      sync.Mutex
+	pending []byte
+	flush   *time.Timer
+}
+
+func (w *redactingWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.flush != nil {
+		w.flush.Stop()
+		w.flush = nil
+	}
+
+	data := w.redactor.Redact(append(w.pending, p...))
+	w.pending = nil
+	if n := w.redactor.partialSecretLength(data); n > 0 {
+		w.pending = append([]byte(nil), data[len(data)-n:]...)
+		data = data[:len(data)-n]
+		w.flush = time.AfterFunc(redactorFlushDelay, func() { _ = w.flushPending() })
+	}
+	if len(data) > 0 {
+		_, err = w.out.Write(data)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *redactingWriter) flushPending() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.pending)
+	w.pending = nil
+	return err
+}
+
+func (w *redactingWriter) Close() error {
+	w.mu.Lock()
+	if w.flush != nil {
+		w.flush.Stop()
+		w.flush = nil
+	}
+	w.mu.Unlock()
+	return w.flushPending()
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package terminal
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedactor(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Secrets     []string
+		Writes      []string
+		Expectation string
+	}{
+		{
+			Name:        "no secrets",
+			Writes:      []string{"hello world"},
+			Expectation: "hello world",
+		},
+		{
+			Name:        "single write",
+			Secrets:     []string{"s3cr3t"},
+			Writes:      []string{"the password is s3cr3t\n"},
+			Expectation: "the password is ********\n",
+		},
+		{
+			Name:        "split across writes",
+			Secrets:     []string{"s3cr3t"},
+			Writes:      []string{"the password is s3c", "r3t\n"},
+			Expectation: "the password is ********\n",
+		},
+		{
+			Name:        "overlapping secrets",
+			Secrets:     []string{"token", "token-with-suffix"},
+			Writes:      []string{"token-with-suffix token"},
+			Expectation: "******** ********",
+		},
+		{
+			Name:        "short secrets are not redacted",
+			Secrets:     []string{"abc"},
+			Writes:      []string{"abc"},
+			Expectation: "abc",
+		},
+		{
+			Name:        "partial secret is flushed on close",
+			Secrets:     []string{"s3cr3t"},
+			Writes:      []string{"s3c"},
+			Expectation: "s3c",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var (
+				redactor Redactor
+				out      bytes.Buffer
+			)
+			redactor.Add(test.Secrets...)
+			w := redactor.Writer(&out)
+			for _, p := range test.Writes {
+				n, err := w.Write([]byte(p))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != len(p) {
+					t.Errorf("short write: %d instead of %d", n, len(p))
+				}
+			}
+			err := w.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.Expectation, out.String()); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRedactorFlushesHeldBackOutput(t *testing.T) {
+	var (
+		redactor Redactor
+		out      safeBuffer
+	)
+	redactor.Add("s3cr3t")
+	w := redactor.Writer(&out)
+	defer w.Close()
+
+	_, err := w.Write([]byte("prompt s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act := out.String(); act != "prompt " {
+		t.Errorf("unexpected output before flush: %q", act)
+	}
+
+	time.Sleep(2 * redactorFlushDelay)
+	if act := out.String(); act != "prompt s" {
+		t.Errorf("unexpected output after flush: %q", act)
+	}
+}
+
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -6,6 +6,7 @@ package terminal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -57,6 +58,16 @@ type MuxTerminalService struct {
 	Env          []string
 	DefaultCreds *syscall.Credential
 
+	// RecordingLocation is the directory terminal recordings are written to.
+	// Terminals cannot be recorded if empty.
+	RecordingLocation string
+
+	// RecordingMaxSize is the size in bytes at which recordings are rotated. Zero disables rotation.
+	RecordingMaxSize int64
+
+	// RecordingRetention is the number of recordings kept in RecordingLocation. Zero keeps all recordings.
+	RecordingRetention int
+
 	api.UnimplementedTerminalServiceServer
 }
 
@@ -106,6 +117,15 @@ func (srv *MuxTerminalService) OpenWithOptions(ctx context.Context, req *api.Ope
 	for k, v := range req.Annotations {
 		options.Annotations[k] = v
 	}
+	options.Shared = req.Shared
+	if req.Record {
+		if srv.RecordingLocation == "" {
+			return nil, status.Error(codes.FailedPrecondition, "terminal recording is disabled")
+		}
+		options.RecordingLocation = srv.RecordingLocation
+		options.RecordingMaxSize = srv.RecordingMaxSize
+		options.RecordingRetention = srv.RecordingRetention
+	}
 	if req.Size != nil {
 		options.Size = &pty.Winsize{
 			Cols: uint16(req.Size.Cols),
@@ -138,6 +158,16 @@ func (srv *MuxTerminalService) OpenWithOptions(ctx context.Context, req *api.Ope
 
 // Close closes a terminal for the given alias
 func (srv *MuxTerminalService) Shutdown(ctx context.Context, req *api.ShutdownTerminalRequest) (*api.ShutdownTerminalResponse, error) {
+	srv.Mux.mu.RLock()
+	term, ok := srv.Mux.terms[req.Alias]
+	srv.Mux.mu.RUnlock()
+	if !ok {
+		return nil, status.Error(codes.NotFound, ErrNotFound.Error())
+	}
+	if term.Shared() && !term.participants.CanWrite(req.Token) {
+		return nil, status.Error(codes.PermissionDenied, "participant cannot shut down this terminal")
+	}
+
 	err := srv.Mux.CloseTerminal(req.Alias, closeTerminaldefaultGracePeriod)
 	if err == ErrNotFound {
 		return nil, status.Error(codes.NotFound, err.Error())
@@ -203,6 +233,11 @@ func (srv *MuxTerminalService) get(alias string) (*api.Terminal, bool) {
 		log.WithError(err).WithField("pid", pid).Warn("unable to resolve terminal's title")
 	}
 
+	var participants []*api.TerminalParticipant
+	if term.Shared() {
+		participants = term.participants.List()
+	}
+
 	return &api.Terminal{
 		Alias:          alias,
 		Command:        term.Command.Args,
@@ -212,6 +247,9 @@ func (srv *MuxTerminalService) get(alias string) (*api.Terminal, bool) {
 		Annotations:    term.GetAnnotations(),
 		Title:          title,
 		TitleSource:    titleSource,
+		Recorded:       term.Recorded(),
+		Shared:         term.Shared(),
+		Participants:   participants,
 	}, true
 }
 
@@ -223,6 +261,19 @@ func (srv *MuxTerminalService) Listen(req *api.ListenTerminalRequest, resp api.T
 	if !ok {
 		return status.Error(codes.NotFound, "terminal not found")
 	}
+	var presence <-chan *api.TerminalPresence
+	if term.Shared() {
+		// subscribe first so that the listener learns about its own presence
+		updates, unsubscribe := term.participants.Subscribe()
+		defer unsubscribe()
+		presence = updates
+
+		detach, err := term.participants.Attach(req.Token)
+		if err != nil {
+			return status.Error(codes.PermissionDenied, "shared terminals require a participant token")
+		}
+		defer detach()
+	}
 	stdout := term.Stdout.Listen()
 	defer stdout.Close()
 
@@ -245,13 +296,13 @@ func (srv *MuxTerminalService) Listen(req *api.ListenTerminalRequest, resp api.T
 			messages <- &api.ListenTerminalResponse{Output: &api.ListenTerminalResponse_Data{Data: buf[:n]}}
 		}
 
-		state, err := term.Wait()
+		exitCode, err := term.Wait()
 		if err != nil {
 			errchan <- err
 			return
 		}
 
-		messages <- &api.ListenTerminalResponse{Output: &api.ListenTerminalResponse_ExitCode{ExitCode: int32(state.ExitCode())}}
+		messages <- &api.ListenTerminalResponse{Output: &api.ListenTerminalResponse_ExitCode{ExitCode: int32(exitCode)}}
 		errchan <- io.EOF
 	}()
 	go func() {
@@ -280,6 +331,8 @@ func (srv *MuxTerminalService) Listen(req *api.ListenTerminalRequest, resp api.T
 		select {
 		case message := <-messages:
 			err = resp.Send(message)
+		case p := <-presence:
+			err = resp.Send(&api.ListenTerminalResponse{Output: &api.ListenTerminalResponse_Presence{Presence: p}})
 		case err = <-errchan:
 		case <-resp.Context().Done():
 			return status.Error(codes.DeadlineExceeded, resp.Context().Err().Error())
@@ -302,11 +355,17 @@ func (srv *MuxTerminalService) Write(ctx context.Context, req *api.WriteTerminal
 	if !ok {
 		return nil, status.Error(codes.NotFound, "terminal not found")
 	}
+	if term.Shared() && !term.participants.CanWrite(req.Token) {
+		return nil, status.Error(codes.PermissionDenied, "participant cannot write to this terminal")
+	}
 
 	n, err := term.PTY.Write(req.Stdin)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if term.activity != nil {
+		term.activity.TerminalInput(req.Alias)
+	}
 	return &api.WriteTerminalResponse{BytesWritten: uint32(n)}, nil
 }
 
@@ -321,11 +380,15 @@ func (srv *MuxTerminalService) SetSize(ctx context.Context, req *api.SetTerminal
 
 	// Setting the size only works with the starter token or when forcing it.
 	// This protects us from multiple listener mangling the terminal.
-	if !(req.GetForce() || req.GetToken() == term.StarterToken) {
+	if term.Shared() {
+		if !term.participants.CanWrite(req.GetToken()) {
+			return nil, status.Error(codes.PermissionDenied, "participant cannot resize this terminal")
+		}
+	} else if !(req.GetForce() || req.GetToken() == term.StarterToken) {
 		return nil, status.Error(codes.FailedPrecondition, "wrong token or force not set")
 	}
 
-	err := pty.Setsize(term.PTY, &pty.Winsize{
+	err := term.Resize(&pty.Winsize{
 		Cols: uint16(req.Size.Cols),
 		Rows: uint16(req.Size.Rows),
 		X:    uint16(req.Size.WidthPx),
@@ -346,6 +409,9 @@ func (srv *MuxTerminalService) SetTitle(ctx context.Context, req *api.SetTermina
 	if !ok {
 		return nil, status.Error(codes.NotFound, "terminal not found")
 	}
+	if term.Shared() && !term.participants.CanWrite(req.Token) {
+		return nil, status.Error(codes.PermissionDenied, "participant cannot set the title of this terminal")
+	}
 	term.SetTitle(req.Title)
 	return &api.SetTerminalTitleResponse{}, nil
 }
@@ -358,6 +424,128 @@ func (srv *MuxTerminalService) UpdateAnnotations(ctx context.Context, req *api.U
 	if !ok {
 		return nil, status.Error(codes.NotFound, "terminal not found")
 	}
+	if term.Shared() && !term.participants.CanWrite(req.Token) {
+		return nil, status.Error(codes.PermissionDenied, "participant cannot annotate this terminal")
+	}
 	term.UpdateAnnotations(req.Changed, req.Deleted)
 	return &api.UpdateTerminalAnnotationsResponse{}, nil
 }
+
+// Replay replays the recording of a terminal session
+func (srv *MuxTerminalService) Replay(req *api.ReplayTerminalRequest, resp api.TerminalService_ReplayServer) error {
+	if srv.RecordingLocation == "" {
+		return status.Error(codes.FailedPrecondition, "terminal recording is disabled")
+	}
+	if req.Speed < 0 || req.MaxIdle < 0 {
+		return status.Error(codes.InvalidArgument, "speed and max_idle must not be negative")
+	}
+	fn, err := RecordingFilename(srv.RecordingLocation, req.Alias)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := os.Stat(fn); os.IsNotExist(err) {
+		return status.Error(codes.NotFound, "recording not found")
+	}
+
+	// a rotated recording is replayed in full, i.e. the previous part first
+	for _, part := range previousRecordings(fn) {
+		err = srv.replayFile(resp, part, req)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if errors.Is(err, context.Canceled) {
+			return status.Error(codes.Canceled, err.Error())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return status.Error(codes.DeadlineExceeded, err.Error())
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
+func (srv *MuxTerminalService) replayFile(resp api.TerminalService_ReplayServer, fn string, req *api.ReplayTerminalRequest) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Replay(resp.Context(), f, ReplayOptions{
+		Speed:   req.Speed,
+		MaxIdle: time.Duration(req.MaxIdle * float64(time.Second)),
+	}, func(ev ReplayEvent) error {
+		if ev.Size != nil {
+			return resp.Send(&api.ReplayTerminalResponse{Output: &api.ReplayTerminalResponse_Size{Size: &api.TerminalSize{
+				Cols: uint32(ev.Size.Cols),
+				Rows: uint32(ev.Size.Rows),
+			}}})
+		}
+		return resp.Send(&api.ReplayTerminalResponse{Output: &api.ReplayTerminalResponse_Data{Data: ev.Data}})
+	})
+}
+
+// Join adds a participant to a shared terminal
+func (srv *MuxTerminalService) Join(ctx context.Context, req *api.JoinTerminalRequest) (*api.JoinTerminalResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	srv.Mux.mu.RLock()
+	term, ok := srv.Mux.terms[req.Alias]
+	srv.Mux.mu.RUnlock()
+	if !ok {
+		return nil, status.Error(codes.NotFound, "terminal not found")
+	}
+	if !term.Shared() {
+		return nil, status.Error(codes.FailedPrecondition, "terminal is not shared")
+	}
+	// participants are invited by someone who can write, read-only participants cannot let others in
+	if !term.participants.CanWrite(req.Token) {
+		return nil, status.Error(codes.PermissionDenied, "participant cannot invite others to this terminal")
+	}
+
+	token, err := term.participants.Join(req.Name)
+	if err == ErrParticipantExists {
+		return nil, status.Errorf(codes.AlreadyExists, "participant %s exists already", req.Name)
+	}
+	if err == ErrTooManyParticipants {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &api.JoinTerminalResponse{
+		Token: token,
+		Role:  api.TerminalRole_read_only,
+	}, nil
+}
+
+// SetParticipantRole changes the role of a participant of a shared terminal
+func (srv *MuxTerminalService) SetParticipantRole(ctx context.Context, req *api.SetTerminalParticipantRoleRequest) (*api.SetTerminalParticipantRoleResponse, error) {
+	srv.Mux.mu.RLock()
+	term, ok := srv.Mux.terms[req.Alias]
+	srv.Mux.mu.RUnlock()
+	if !ok {
+		return nil, status.Error(codes.NotFound, "terminal not found")
+	}
+	if !term.Shared() {
+		return nil, status.Error(codes.FailedPrecondition, "terminal is not shared")
+	}
+	if req.StarterToken != term.StarterToken {
+		return nil, status.Error(codes.PermissionDenied, "only the starter of a terminal can change roles")
+	}
+	if req.Name == starterParticipantName {
+		return nil, status.Error(codes.InvalidArgument, "cannot change the role of the starter")
+	}
+
+	err := term.participants.SetRole(req.Name, req.Role)
+	if err == ErrParticipantNotFound {
+		return nil, status.Errorf(codes.NotFound, "participant %s not found", req.Name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &api.SetTerminalParticipantRoleResponse{}, nil
+}
@@ -34,6 +34,12 @@ func NewMux() *Mux {
 
 // Mux can mux pseudo-terminals
 type Mux struct {
+	// Redactor removes secrets from the output of all terminals if set
+	Redactor *Redactor
+
+	// Activity is notified of the input to and output of all terminals if set
+	Activity ActivityListener
+
 	aliases []string
 	terms   map[string]*Term
 	mu      sync.RWMutex
@@ -53,22 +59,49 @@ func (m *Mux) Start(cmd *exec.Cmd, options TermOptions) (alias string, err error
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	pty, err := pty.StartWithSize(cmd, options.Size)
-	if err != nil {
-		return "", xerrors.Errorf("cannot start PTY: %w", err)
-	}
-
 	uid, err := uuid.NewRandom()
 	if err != nil {
 		return "", xerrors.Errorf("cannot produce alias: %w", err)
 	}
 	alias = uid.String()
 
-	term, err := newTerm(alias, pty, cmd, options)
+	ptmx, err := pty.StartWithSize(cmd, options.Size)
 	if err != nil {
-		pty.Close()
+		return "", xerrors.Errorf("cannot start PTY: %w", err)
+	}
+
+	var rec *recorder
+	if options.RecordingLocation != "" {
+		if options.RecordingRetention > 0 {
+			// make room for the new recording
+			err := pruneRecordings(options.RecordingLocation, options.RecordingRetention-1, m.terms)
+			if err != nil {
+				log.WithError(err).Warn("cannot remove old terminal recordings")
+			}
+		}
+		fn, err := RecordingFilename(options.RecordingLocation, alias)
+		if err == nil {
+			rec, err = newRecorder(fn, options.Size, options.Title, options.RecordingMaxSize)
+		}
+		if err != nil {
+			ptmx.Close()
+			return "", err
+		}
+	}
+
+	term, err := newTerm(alias, ptmx, cmd, options)
+	if err != nil {
+		ptmx.Close()
+		if rec != nil {
+			rec.Close()
+		}
 		return "", err
 	}
+	term.recorder = rec
+	term.redactor = m.Redactor
+	term.activity = m.Activity
+	//nolint:errcheck
+	go term.copyOutput(ptmx)
 	m.aliases = append(m.aliases, alias)
 	m.terms[alias] = term
 
@@ -76,6 +109,7 @@ func (m *Mux) Start(cmd *exec.Cmd, options TermOptions) (alias string, err error
 
 	go func() {
 		term.waitErr = cmd.Wait()
+		term.exitCode = cmd.ProcessState.ExitCode()
 		close(term.waitDone)
 		_ = m.CloseTerminal(alias, 0*time.Second)
 	}()
@@ -122,6 +156,13 @@ func (m *Mux) doClose(alias string, gracePeriod time.Duration) error {
 
 	log := log.WithField("alias", alias)
 	log.Info("closing terminal")
+	select {
+	case <-term.waitDone:
+	default:
+		term.mu.Lock()
+		term.closedExplicitly = true
+		term.mu.Unlock()
+	}
 	err := term.gracefullyShutdownProcess(gracePeriod)
 	if err != nil {
 		log.WithError(err).Warn("did not gracefully shut down terminal")
@@ -208,6 +249,7 @@ func newTerm(alias string, pty *os.File, cmd *exec.Cmd, options TermOptions) (*T
 	res := &Term{
 		PTY:     pty,
 		Command: cmd,
+		alias:   alias,
 		Stdout: &multiWriter{
 			timeout:   timeout,
 			listener:  make(map[*multiWriterListener]struct{}),
@@ -225,6 +267,9 @@ func newTerm(alias string, pty *os.File, cmd *exec.Cmd, options TermOptions) (*T
 	if res.annotations == nil {
 		res.annotations = make(map[string]string)
 	}
+	if options.Shared {
+		res.participants = newParticipants(res.StarterToken)
+	}
 
 	rawConn, err := pty.SyscallConn()
 	if err != nil {
@@ -238,11 +283,51 @@ func newTerm(alias string, pty *os.File, cmd *exec.Cmd, options TermOptions) (*T
 		return nil, err
 	}
 
-	//nolint:errcheck
-	go io.Copy(res.Stdout, pty)
 	return res, nil
 }
 
+// copyOutput forwards the terminal output to its listeners and the recording until the output is closed
+func (term *Term) copyOutput(output io.Reader) error {
+	var out io.Writer = term.Stdout
+	if term.recorder != nil {
+		defer term.recorder.Close()
+		out = io.MultiWriter(term.Stdout, term.recorder)
+	}
+	if term.redactor != nil {
+		redacted := term.redactor.Writer(out)
+		defer redacted.Close()
+		out = redacted
+	}
+	if term.activity != nil {
+		out = &activityWriter{out: out, alias: term.alias, listener: term.activity}
+	}
+
+	_, err := io.Copy(out, output)
+	return err
+}
+
+// Resize sets the size of the terminal
+func (term *Term) Resize(size *pty.Winsize) error {
+	err := pty.Setsize(term.PTY, size)
+	if err != nil {
+		return err
+	}
+	if term.recorder != nil {
+		term.recorder.Resize(size)
+	}
+	return nil
+}
+
+// Shared returns true if access to the terminal is restricted to its participants
+func (term *Term) Shared() bool {
+	return term.participants != nil
+}
+
+// Recorded returns true if the terminal session is recorded
+func (term *Term) Recorded() bool {
+	return term.recorder != nil
+}
+
 // TermOptions is a pseudo-terminal configuration
 type TermOptions struct {
 	// timeout after which a listener is dropped. Use 0 for no timeout.
@@ -259,6 +344,20 @@ type TermOptions struct {
 
 	// LogToStdout forwards the terminal's stdout to supervisor's stdout
 	LogToStdout bool
+
+	// RecordingLocation is the directory an asciicast recording of the terminal session is written to.
+	// The session is not recorded if empty.
+	RecordingLocation string
+
+	// RecordingMaxSize is the size in bytes at which the recording is rotated. Zero disables rotation.
+	RecordingMaxSize int64
+
+	// RecordingRetention is the number of recordings kept in RecordingLocation, the oldest ones of terminals
+	// which have been closed are removed. Zero keeps all recordings.
+	RecordingRetention int
+
+	// Shared restricts input and size changes to the terminal's participants with the read-write role
+	Shared bool
 }
 
 // Term is a pseudo-terminal
@@ -267,6 +366,8 @@ type Term struct {
 	Command      *exec.Cmd
 	StarterToken string
 
+	alias string
+
 	mu           sync.RWMutex
 	annotations  map[string]string
 	defaultTitle string
@@ -274,12 +375,31 @@ type Term struct {
 
 	Stdout *multiWriter
 
+	exitCode int
 	waitErr  error
 	waitDone chan struct{}
 
+	recorder *recorder
+	redactor *Redactor
+	activity ActivityListener
+
+	// participants is nil unless the terminal is shared
+	participants *participants
+
+	// closedExplicitly is true if the terminal was closed while its process was still running
+	closedExplicitly bool
+
 	fd int
 }
 
+// ClosedExplicitly returns true if the terminal was closed through the mux, e.g. by a user,
+// rather than its process exiting by itself.
+func (term *Term) ClosedExplicitly() bool {
+	term.mu.RLock()
+	defer term.mu.RUnlock()
+	return term.closedExplicitly
+}
+
 func (term *Term) GetTitle() (string, api.TerminalTitleSource, error) {
 	term.mu.RLock()
 	title := term.title
@@ -325,6 +445,18 @@ func (term *Term) UpdateAnnotations(changed map[string]string, deleted []string)
 	}
 }
 
+// Busy returns true if the shell of the terminal runs a command in the foreground
+func (term *Term) Busy() bool {
+	if term.Command == nil || term.Command.Process == nil {
+		return false
+	}
+	pgrp, err := unix.IoctlGetInt(term.fd, unix.TIOCGPGRP)
+	if err != nil {
+		return false
+	}
+	return pgrp != term.Command.Process.Pid
+}
+
 func (term *Term) resolveForegroundCommand() (string, error) {
 	pgrp, err := unix.IoctlGetInt(term.fd, unix.TIOCGPGRP)
 	if err != nil {
@@ -345,10 +477,11 @@ func (term *Term) resolveForegroundCommand() (string, error) {
 	return string(content), nil
 }
 
-// Wait waits for the terminal to exit and returns the resulted process state
-func (term *Term) Wait() (*os.ProcessState, error) {
+// Wait waits for the terminal to exit and returns the exit code of its process.
+// The error is non-nil if the process did not exit successfully.
+func (term *Term) Wait() (exitCode int, err error) {
 	<-term.waitDone
-	return term.Command.ProcessState, term.waitErr
+	return term.exitCode, term.waitErr
 }
 
 // multiWriter is like io.MultiWriter, except that we can listener at runtime.
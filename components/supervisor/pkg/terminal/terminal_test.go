@@ -17,6 +17,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/gitpod-io/gitpod/supervisor/api"
 )
@@ -380,3 +382,82 @@ func TestWorkDirProvider(t *testing.T) {
 		expectedWorkDir: providedWorkDir,
 	})
 }
+
+func TestSharedTerminalRoles(t *testing.T) {
+	type Request func(srv *MuxTerminalService, alias, token string) error
+	requests := map[string]Request{
+		"shutdown": func(srv *MuxTerminalService, alias, token string) error {
+			_, err := srv.Shutdown(context.Background(), &api.ShutdownTerminalRequest{Alias: alias, Token: token})
+			return err
+		},
+		"set title": func(srv *MuxTerminalService, alias, token string) error {
+			_, err := srv.SetTitle(context.Background(), &api.SetTerminalTitleRequest{Alias: alias, Title: "changed", Token: token})
+			return err
+		},
+		"update annotations": func(srv *MuxTerminalService, alias, token string) error {
+			_, err := srv.UpdateAnnotations(context.Background(), &api.UpdateTerminalAnnotationsRequest{Alias: alias, Changed: map[string]string{"foo": "bar"}, Token: token})
+			return err
+		},
+		"join": func(srv *MuxTerminalService, alias, token string) error {
+			_, err := srv.Join(context.Background(), &api.JoinTerminalRequest{Alias: alias, Name: "guest", Token: token})
+			return err
+		},
+		"write": func(srv *MuxTerminalService, alias, token string) error {
+			_, err := srv.Write(context.Background(), &api.WriteTerminalRequest{Alias: alias, Stdin: []byte("\n"), Token: token})
+			return err
+		},
+	}
+
+	tests := []struct {
+		Desc        string
+		Token       string
+		Expectation codes.Code
+	}{
+		{Desc: "starter", Token: "starter", Expectation: codes.OK},
+		{Desc: "read_write participant", Token: "writer", Expectation: codes.OK},
+		{Desc: "read_only participant", Token: "reader", Expectation: codes.PermissionDenied},
+		{Desc: "no token", Expectation: codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		for name, req := range requests {
+			t.Run(test.Desc+"/"+name, func(t *testing.T) {
+				mux := NewMux()
+				defer mux.Close()
+				srv := NewMuxTerminalService(mux)
+
+				term, err := srv.Open(context.Background(), &api.OpenTerminalRequest{
+					// unlike an interactive shell, sleep ends on SIGTERM which keeps closing the terminal fast
+					Shell:     "sleep",
+					ShellArgs: []string{"60"},
+					Workdir:   t.TempDir(),
+					Shared:    true,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				alias := term.Terminal.Alias
+				tokens := map[string]string{"starter": term.StarterToken}
+				for _, role := range []api.TerminalRole{api.TerminalRole_read_only, api.TerminalRole_read_write} {
+					resp, err := srv.Join(context.Background(), &api.JoinTerminalRequest{Alias: alias, Name: role.String(), Token: term.StarterToken})
+					if err != nil {
+						t.Fatal(err)
+					}
+					_, err = srv.SetParticipantRole(context.Background(), &api.SetTerminalParticipantRoleRequest{Alias: alias, Name: role.String(), Role: role, StarterToken: term.StarterToken})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if role == api.TerminalRole_read_write {
+						tokens["writer"] = resp.Token
+					} else {
+						tokens["reader"] = resp.Token
+					}
+				}
+
+				err = req(srv, alias, tokens[test.Token])
+				if diff := cmp.Diff(test.Expectation, status.Code(err)); diff != "" {
+					t.Errorf("unexpected status (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
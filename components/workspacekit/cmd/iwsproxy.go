@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	daemonapi "github.com/gitpod-io/gitpod/ws-daemon/api"
+)
+
+const (
+	// ring2IWSSocket is the location of the in-workspace service proxy as seen from within ring2.
+	// Keep in sync with supervisor's hosts service.
+	ring2IWSSocket = "/tmp/workspacekit-iws/iws.socket"
+)
+
+// serveIWSProxy makes a subset of the in-workspace service available to ring2.
+// Ring2 must not have access to the full service, e.g. MountProc is only safe to call
+// after ring1 checked the request.
+func serveIWSProxy(socketFN string) error {
+	// Only root in ring2 (i.e. supervisor) can use the proxy. The socket is created with the default permissions,
+	// hence it lives in a directory only root can enter. Mkdir fails if someone else created the directory first.
+	err := os.Mkdir(filepath.Dir(socketFN), 0700)
+	if err != nil {
+		return err
+	}
+	skt, err := net.Listen("unix", socketFN)
+	if err != nil {
+		return err
+	}
+	defer skt.Close()
+
+	err = os.Chmod(socketFN, 0600)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer()
+	daemonapi.RegisterInWorkspaceServiceServer(srv, &iwsProxy{})
+	return srv.Serve(skt)
+}
+
+type iwsProxy struct {
+	daemonapi.UnimplementedInWorkspaceServiceServer
+}
+
+// SetHosts forwards to ws-daemon, which in turn validates the request
+func (p *iwsProxy) SetHosts(ctx context.Context, req *daemonapi.SetHostsRequest) (*daemonapi.SetHostsResponse, error) {
+	client, err := connectToInWorkspaceDaemonService(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot connect to daemon: %v", err)
+	}
+	defer client.Close()
+
+	return client.SetHosts(ctx, req)
+}
@@ -316,6 +316,14 @@ var ring1Cmd = &cobra.Command{
 			}
 		}
 
+		go func() {
+			// ring2's /tmp is the tmpfs we've just mounted
+			err := serveIWSProxy(filepath.Join(ring2Root, ring2IWSSocket))
+			if err != nil {
+				log.WithError(err).Error("failed to serve in-workspace service proxy for ring2")
+			}
+		}()
+
 		env := make([]string, 0, len(os.Environ()))
 		for _, e := range os.Environ() {
 			if strings.HasPrefix(e, "WORKSPACEKIT_") {
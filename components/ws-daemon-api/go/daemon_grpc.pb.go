@@ -30,6 +30,9 @@ type WorkspaceContentServiceClient interface {
 	WaitForInit(ctx context.Context, in *WaitForInitRequest, opts ...grpc.CallOption) (*WaitForInitResponse, error)
 	// TakeSnapshot creates a backup/snapshot of a workspace
 	TakeSnapshot(ctx context.Context, in *TakeSnapshotRequest, opts ...grpc.CallOption) (*TakeSnapshotResponse, error)
+	// CheckpointWorkspace dumps the workspace processes using CRIU and stores the image alongside the backup.
+	// The checkpoint can only be taken while the workspace container is running, i.e. before the workspace pod is deleted.
+	CheckpointWorkspace(ctx context.Context, in *CheckpointWorkspaceRequest, opts ...grpc.CallOption) (*CheckpointWorkspaceResponse, error)
 	// disposeWorkspace cleans up a workspace, possibly after taking a final backup
 	DisposeWorkspace(ctx context.Context, in *DisposeWorkspaceRequest, opts ...grpc.CallOption) (*DisposeWorkspaceResponse, error)
 }
@@ -69,6 +72,15 @@ func (c *workspaceContentServiceClient) TakeSnapshot(ctx context.Context, in *Ta
 	return out, nil
 }
 
+func (c *workspaceContentServiceClient) CheckpointWorkspace(ctx context.Context, in *CheckpointWorkspaceRequest, opts ...grpc.CallOption) (*CheckpointWorkspaceResponse, error) {
+	out := new(CheckpointWorkspaceResponse)
+	err := c.cc.Invoke(ctx, "/wsdaemon.WorkspaceContentService/CheckpointWorkspace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *workspaceContentServiceClient) DisposeWorkspace(ctx context.Context, in *DisposeWorkspaceRequest, opts ...grpc.CallOption) (*DisposeWorkspaceResponse, error) {
 	out := new(DisposeWorkspaceResponse)
 	err := c.cc.Invoke(ctx, "/wsdaemon.WorkspaceContentService/DisposeWorkspace", in, out, opts...)
@@ -90,6 +102,9 @@ type WorkspaceContentServiceServer interface {
 	WaitForInit(context.Context, *WaitForInitRequest) (*WaitForInitResponse, error)
 	// TakeSnapshot creates a backup/snapshot of a workspace
 	TakeSnapshot(context.Context, *TakeSnapshotRequest) (*TakeSnapshotResponse, error)
+	// CheckpointWorkspace dumps the workspace processes using CRIU and stores the image alongside the backup.
+	// The checkpoint can only be taken while the workspace container is running, i.e. before the workspace pod is deleted.
+	CheckpointWorkspace(context.Context, *CheckpointWorkspaceRequest) (*CheckpointWorkspaceResponse, error)
 	// disposeWorkspace cleans up a workspace, possibly after taking a final backup
 	DisposeWorkspace(context.Context, *DisposeWorkspaceRequest) (*DisposeWorkspaceResponse, error)
 	mustEmbedUnimplementedWorkspaceContentServiceServer()
@@ -108,6 +123,9 @@ func (UnimplementedWorkspaceContentServiceServer) WaitForInit(context.Context, *
 func (UnimplementedWorkspaceContentServiceServer) TakeSnapshot(context.Context, *TakeSnapshotRequest) (*TakeSnapshotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method TakeSnapshot not implemented")
 }
+func (UnimplementedWorkspaceContentServiceServer) CheckpointWorkspace(context.Context, *CheckpointWorkspaceRequest) (*CheckpointWorkspaceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckpointWorkspace not implemented")
+}
 func (UnimplementedWorkspaceContentServiceServer) DisposeWorkspace(context.Context, *DisposeWorkspaceRequest) (*DisposeWorkspaceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DisposeWorkspace not implemented")
 }
@@ -179,6 +197,24 @@ func _WorkspaceContentService_TakeSnapshot_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkspaceContentService_CheckpointWorkspace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckpointWorkspaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceContentServiceServer).CheckpointWorkspace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wsdaemon.WorkspaceContentService/CheckpointWorkspace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceContentServiceServer).CheckpointWorkspace(ctx, req.(*CheckpointWorkspaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _WorkspaceContentService_DisposeWorkspace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DisposeWorkspaceRequest)
 	if err := dec(in); err != nil {
@@ -216,6 +252,10 @@ var WorkspaceContentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "TakeSnapshot",
 			Handler:    _WorkspaceContentService_TakeSnapshot_Handler,
 		},
+		{
+			MethodName: "CheckpointWorkspace",
+			Handler:    _WorkspaceContentService_CheckpointWorkspace_Handler,
+		},
 		{
 			MethodName: "DisposeWorkspace",
 			Handler:    _WorkspaceContentService_DisposeWorkspace_Handler,
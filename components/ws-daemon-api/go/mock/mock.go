@@ -40,6 +40,26 @@ func (m *MockWorkspaceContentServiceClient) EXPECT() *MockWorkspaceContentServic
 	return m.recorder
 }
 
+// CheckpointWorkspace mocks base method.
+func (m *MockWorkspaceContentServiceClient) CheckpointWorkspace(arg0 context.Context, arg1 *api.CheckpointWorkspaceRequest, arg2 ...grpc.CallOption) (*api.CheckpointWorkspaceResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CheckpointWorkspace", varargs...)
+	ret0, _ := ret[0].(*api.CheckpointWorkspaceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckpointWorkspace indicates an expected call of CheckpointWorkspace.
+func (mr *MockWorkspaceContentServiceClientMockRecorder) CheckpointWorkspace(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckpointWorkspace", reflect.TypeOf((*MockWorkspaceContentServiceClient)(nil).CheckpointWorkspace), varargs...)
+}
+
 // DisposeWorkspace mocks base method.
 func (m *MockWorkspaceContentServiceClient) DisposeWorkspace(arg0 context.Context, arg1 *api.DisposeWorkspaceRequest, arg2 ...grpc.CallOption) (*api.DisposeWorkspaceResponse, error) {
 	m.ctrl.T.Helper()
@@ -144,6 +164,21 @@ func (m *MockWorkspaceContentServiceServer) EXPECT() *MockWorkspaceContentServic
 	return m.recorder
 }
 
+// CheckpointWorkspace mocks base method.
+func (m *MockWorkspaceContentServiceServer) CheckpointWorkspace(arg0 context.Context, arg1 *api.CheckpointWorkspaceRequest) (*api.CheckpointWorkspaceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckpointWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(*api.CheckpointWorkspaceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckpointWorkspace indicates an expected call of CheckpointWorkspace.
+func (mr *MockWorkspaceContentServiceServerMockRecorder) CheckpointWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckpointWorkspace", reflect.TypeOf((*MockWorkspaceContentServiceServer)(nil).CheckpointWorkspace), arg0, arg1)
+}
+
 // DisposeWorkspace mocks base method.
 func (m *MockWorkspaceContentServiceServer) DisposeWorkspace(arg0 context.Context, arg1 *api.DisposeWorkspaceRequest) (*api.DisposeWorkspaceResponse, error) {
 	m.ctrl.T.Helper()
@@ -299,6 +334,26 @@ func (mr *MockInWorkspaceServiceClientMockRecorder) PrepareForUserNS(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareForUserNS", reflect.TypeOf((*MockInWorkspaceServiceClient)(nil).PrepareForUserNS), varargs...)
 }
 
+// SetHosts mocks base method.
+func (m *MockInWorkspaceServiceClient) SetHosts(arg0 context.Context, arg1 *api.SetHostsRequest, arg2 ...grpc.CallOption) (*api.SetHostsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetHosts", varargs...)
+	ret0, _ := ret[0].(*api.SetHostsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetHosts indicates an expected call of SetHosts.
+func (mr *MockInWorkspaceServiceClientMockRecorder) SetHosts(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHosts", reflect.TypeOf((*MockInWorkspaceServiceClient)(nil).SetHosts), varargs...)
+}
+
 // Teardown mocks base method.
 func (m *MockInWorkspaceServiceClient) Teardown(arg0 context.Context, arg1 *api.TeardownRequest, arg2 ...grpc.CallOption) (*api.TeardownResponse, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +51,9 @@ type InWorkspaceServiceClient interface {
 	// Teardown prepares workspace content backups and unmounts shiftfs mounts. The canary is supposed to be triggered
 	// when the workspace is about to shut down, e.g. using the PreStop hook of a Kubernetes container.
 	Teardown(ctx context.Context, in *TeardownRequest, opts ...grpc.CallOption) (*TeardownResponse, error)
+	// SetHosts replaces the entries ws-daemon maintains in the workspace's /etc/hosts file.
+	// Entries which are not managed by ws-daemon remain untouched.
+	SetHosts(ctx context.Context, in *SetHostsRequest, opts ...grpc.CallOption) (*SetHostsResponse, error)
 }
 
 type inWorkspaceServiceClient struct {
@@ -124,6 +127,15 @@ func (c *inWorkspaceServiceClient) Teardown(ctx context.Context, in *TeardownReq
 	return out, nil
 }
 
+func (c *inWorkspaceServiceClient) SetHosts(ctx context.Context, in *SetHostsRequest, opts ...grpc.CallOption) (*SetHostsResponse, error) {
+	out := new(SetHostsResponse)
+	err := c.cc.Invoke(ctx, "/iws.InWorkspaceService/SetHosts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InWorkspaceServiceServer is the server API for InWorkspaceService service.
 // All implementations must embed UnimplementedInWorkspaceServiceServer
 // for forward compatibility
@@ -157,6 +169,9 @@ type InWorkspaceServiceServer interface {
 	// Teardown prepares workspace content backups and unmounts shiftfs mounts. The canary is supposed to be triggered
 	// when the workspace is about to shut down, e.g. using the PreStop hook of a Kubernetes container.
 	Teardown(context.Context, *TeardownRequest) (*TeardownResponse, error)
+	// SetHosts replaces the entries ws-daemon maintains in the workspace's /etc/hosts file.
+	// Entries which are not managed by ws-daemon remain untouched.
+	SetHosts(context.Context, *SetHostsRequest) (*SetHostsResponse, error)
 	mustEmbedUnimplementedInWorkspaceServiceServer()
 }
 
@@ -185,6 +200,9 @@ func (UnimplementedInWorkspaceServiceServer) UmountSysfs(context.Context, *Umoun
 func (UnimplementedInWorkspaceServiceServer) Teardown(context.Context, *TeardownRequest) (*TeardownResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Teardown not implemented")
 }
+func (UnimplementedInWorkspaceServiceServer) SetHosts(context.Context, *SetHostsRequest) (*SetHostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetHosts not implemented")
+}
 func (UnimplementedInWorkspaceServiceServer) mustEmbedUnimplementedInWorkspaceServiceServer() {}
 
 // UnsafeInWorkspaceServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -324,6 +342,24 @@ func _InWorkspaceService_Teardown_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InWorkspaceService_SetHosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetHostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InWorkspaceServiceServer).SetHosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/iws.InWorkspaceService/SetHosts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InWorkspaceServiceServer).SetHosts(ctx, req.(*SetHostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // InWorkspaceService_ServiceDesc is the grpc.ServiceDesc for InWorkspaceService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -359,6 +395,10 @@ var InWorkspaceService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Teardown",
 			Handler:    _InWorkspaceService_Teardown_Handler,
 		},
+		{
+			MethodName: "SetHosts",
+			Handler:    _InWorkspaceService_SetHosts_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "workspace_daemon.proto",
@@ -18,6 +18,7 @@ interface IWorkspaceContentServiceService extends grpc.ServiceDefinition<grpc.Un
     initWorkspace: IWorkspaceContentServiceService_IInitWorkspace;
     waitForInit: IWorkspaceContentServiceService_IWaitForInit;
     takeSnapshot: IWorkspaceContentServiceService_ITakeSnapshot;
+    checkpointWorkspace: IWorkspaceContentServiceService_ICheckpointWorkspace;
     disposeWorkspace: IWorkspaceContentServiceService_IDisposeWorkspace;
 }
 
@@ -48,6 +49,15 @@ interface IWorkspaceContentServiceService_ITakeSnapshot extends grpc.MethodDefin
     responseSerialize: grpc.serialize<daemon_pb.TakeSnapshotResponse>;
     responseDeserialize: grpc.deserialize<daemon_pb.TakeSnapshotResponse>;
 }
+interface IWorkspaceContentServiceService_ICheckpointWorkspace extends grpc.MethodDefinition<daemon_pb.CheckpointWorkspaceRequest, daemon_pb.CheckpointWorkspaceResponse> {
+    path: "/wsdaemon.WorkspaceContentService/CheckpointWorkspace";
+    requestStream: false;
+    responseStream: false;
+    requestSerialize: grpc.serialize<daemon_pb.CheckpointWorkspaceRequest>;
+    requestDeserialize: grpc.deserialize<daemon_pb.CheckpointWorkspaceRequest>;
+    responseSerialize: grpc.serialize<daemon_pb.CheckpointWorkspaceResponse>;
+    responseDeserialize: grpc.deserialize<daemon_pb.CheckpointWorkspaceResponse>;
+}
 interface IWorkspaceContentServiceService_IDisposeWorkspace extends grpc.MethodDefinition<daemon_pb.DisposeWorkspaceRequest, daemon_pb.DisposeWorkspaceResponse> {
     path: "/wsdaemon.WorkspaceContentService/DisposeWorkspace";
     requestStream: false;
@@ -64,6 +74,7 @@ export interface IWorkspaceContentServiceServer extends grpc.UntypedServiceImple
     initWorkspace: grpc.handleUnaryCall<daemon_pb.InitWorkspaceRequest, daemon_pb.InitWorkspaceResponse>;
     waitForInit: grpc.handleUnaryCall<daemon_pb.WaitForInitRequest, daemon_pb.WaitForInitResponse>;
     takeSnapshot: grpc.handleUnaryCall<daemon_pb.TakeSnapshotRequest, daemon_pb.TakeSnapshotResponse>;
+    checkpointWorkspace: grpc.handleUnaryCall<daemon_pb.CheckpointWorkspaceRequest, daemon_pb.CheckpointWorkspaceResponse>;
     disposeWorkspace: grpc.handleUnaryCall<daemon_pb.DisposeWorkspaceRequest, daemon_pb.DisposeWorkspaceResponse>;
 }
 
@@ -77,6 +88,9 @@ export interface IWorkspaceContentServiceClient {
     takeSnapshot(request: daemon_pb.TakeSnapshotRequest, callback: (error: grpc.ServiceError | null, response: daemon_pb.TakeSnapshotResponse) => void): grpc.ClientUnaryCall;
     takeSnapshot(request: daemon_pb.TakeSnapshotRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: daemon_pb.TakeSnapshotResponse) => void): grpc.ClientUnaryCall;
     takeSnapshot(request: daemon_pb.TakeSnapshotRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: daemon_pb.TakeSnapshotResponse) => void): grpc.ClientUnaryCall;
+    checkpointWorkspace(request: daemon_pb.CheckpointWorkspaceRequest, callback: (error: grpc.ServiceError | null, response: daemon_pb.CheckpointWorkspaceResponse) => void): grpc.ClientUnaryCall;
+    checkpointWorkspace(request: daemon_pb.CheckpointWorkspaceRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: daemon_pb.CheckpointWorkspaceResponse) => void): grpc.ClientUnaryCall;
+    checkpointWorkspace(request: daemon_pb.CheckpointWorkspaceRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: daemon_pb.CheckpointWorkspaceResponse) => void): grpc.ClientUnaryCall;
     disposeWorkspace(request: daemon_pb.DisposeWorkspaceRequest, callback: (error: grpc.ServiceError | null, response: daemon_pb.DisposeWorkspaceResponse) => void): grpc.ClientUnaryCall;
     disposeWorkspace(request: daemon_pb.DisposeWorkspaceRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: daemon_pb.DisposeWorkspaceResponse) => void): grpc.ClientUnaryCall;
     disposeWorkspace(request: daemon_pb.DisposeWorkspaceRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: daemon_pb.DisposeWorkspaceResponse) => void): grpc.ClientUnaryCall;
@@ -93,6 +107,9 @@ export class WorkspaceContentServiceClient extends grpc.Client implements IWorks
     public takeSnapshot(request: daemon_pb.TakeSnapshotRequest, callback: (error: grpc.ServiceError | null, response: daemon_pb.TakeSnapshotResponse) => void): grpc.ClientUnaryCall;
     public takeSnapshot(request: daemon_pb.TakeSnapshotRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: daemon_pb.TakeSnapshotResponse) => void): grpc.ClientUnaryCall;
     public takeSnapshot(request: daemon_pb.TakeSnapshotRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: daemon_pb.TakeSnapshotResponse) => void): grpc.ClientUnaryCall;
+    public checkpointWorkspace(request: daemon_pb.CheckpointWorkspaceRequest, callback: (error: grpc.ServiceError | null, response: daemon_pb.CheckpointWorkspaceResponse) => void): grpc.ClientUnaryCall;
+    public checkpointWorkspace(request: daemon_pb.CheckpointWorkspaceRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: daemon_pb.CheckpointWorkspaceResponse) => void): grpc.ClientUnaryCall;
+    public checkpointWorkspace(request: daemon_pb.CheckpointWorkspaceRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: daemon_pb.CheckpointWorkspaceResponse) => void): grpc.ClientUnaryCall;
     public disposeWorkspace(request: daemon_pb.DisposeWorkspaceRequest, callback: (error: grpc.ServiceError | null, response: daemon_pb.DisposeWorkspaceResponse) => void): grpc.ClientUnaryCall;
     public disposeWorkspace(request: daemon_pb.DisposeWorkspaceRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: daemon_pb.DisposeWorkspaceResponse) => void): grpc.ClientUnaryCall;
     public disposeWorkspace(request: daemon_pb.DisposeWorkspaceRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: daemon_pb.DisposeWorkspaceResponse) => void): grpc.ClientUnaryCall;
@@ -11,6 +11,28 @@ var grpc = require('@grpc/grpc-js');
 var daemon_pb = require('./daemon_pb.js');
 var content$service$api_initializer_pb = require('@gitpod/content-service/lib');
 
+function serialize_wsdaemon_CheckpointWorkspaceRequest(arg) {
+  if (!(arg instanceof daemon_pb.CheckpointWorkspaceRequest)) {
+    throw new Error('Expected argument of type wsdaemon.CheckpointWorkspaceRequest');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_wsdaemon_CheckpointWorkspaceRequest(buffer_arg) {
+  return daemon_pb.CheckpointWorkspaceRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_wsdaemon_CheckpointWorkspaceResponse(arg) {
+  if (!(arg instanceof daemon_pb.CheckpointWorkspaceResponse)) {
+    throw new Error('Expected argument of type wsdaemon.CheckpointWorkspaceResponse');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_wsdaemon_CheckpointWorkspaceResponse(buffer_arg) {
+  return daemon_pb.CheckpointWorkspaceResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_wsdaemon_DisposeWorkspaceRequest(arg) {
   if (!(arg instanceof daemon_pb.DisposeWorkspaceRequest)) {
     throw new Error('Expected argument of type wsdaemon.DisposeWorkspaceRequest');
@@ -139,6 +161,19 @@ takeSnapshot: {
     responseSerialize: serialize_wsdaemon_TakeSnapshotResponse,
     responseDeserialize: deserialize_wsdaemon_TakeSnapshotResponse,
   },
+  // CheckpointWorkspace dumps the workspace processes using CRIU and stores the image alongside the backup.
+// The checkpoint can only be taken while the workspace container is running, i.e. before the workspace pod is deleted.
+checkpointWorkspace: {
+    path: '/wsdaemon.WorkspaceContentService/CheckpointWorkspace',
+    requestStream: false,
+    responseStream: false,
+    requestType: daemon_pb.CheckpointWorkspaceRequest,
+    responseType: daemon_pb.CheckpointWorkspaceResponse,
+    requestSerialize: serialize_wsdaemon_CheckpointWorkspaceRequest,
+    requestDeserialize: deserialize_wsdaemon_CheckpointWorkspaceRequest,
+    responseSerialize: serialize_wsdaemon_CheckpointWorkspaceResponse,
+    responseDeserialize: deserialize_wsdaemon_CheckpointWorkspaceResponse,
+  },
   // disposeWorkspace cleans up a workspace, possibly after taking a final backup
 disposeWorkspace: {
     path: '/wsdaemon.WorkspaceContentService/DisposeWorkspace',
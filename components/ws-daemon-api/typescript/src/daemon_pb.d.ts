@@ -34,6 +34,8 @@ export class InitWorkspaceRequest extends jspb.Message {
     setContentManifest(value: Uint8Array | string): InitWorkspaceRequest;
     getRemoteStorageDisabled(): boolean;
     setRemoteStorageDisabled(value: boolean): InitWorkspaceRequest;
+    getRestoreCheckpoint(): boolean;
+    setRestoreCheckpoint(value: boolean): InitWorkspaceRequest;
 
     serializeBinary(): Uint8Array;
     toObject(includeInstance?: boolean): InitWorkspaceRequest.AsObject;
@@ -53,6 +55,7 @@ export namespace InitWorkspaceRequest {
         fullWorkspaceBackup: boolean,
         contentManifest: Uint8Array | string,
         remoteStorageDisabled: boolean,
+        restoreCheckpoint: boolean,
     }
 }
 
@@ -173,6 +176,43 @@ export namespace TakeSnapshotResponse {
     }
 }
 
+export class CheckpointWorkspaceRequest extends jspb.Message {
+    getId(): string;
+    setId(value: string): CheckpointWorkspaceRequest;
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): CheckpointWorkspaceRequest.AsObject;
+    static toObject(includeInstance: boolean, msg: CheckpointWorkspaceRequest): CheckpointWorkspaceRequest.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: CheckpointWorkspaceRequest, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): CheckpointWorkspaceRequest;
+    static deserializeBinaryFromReader(message: CheckpointWorkspaceRequest, reader: jspb.BinaryReader): CheckpointWorkspaceRequest;
+}
+
+export namespace CheckpointWorkspaceRequest {
+    export type AsObject = {
+        id: string,
+    }
+}
+
+export class CheckpointWorkspaceResponse extends jspb.Message {
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): CheckpointWorkspaceResponse.AsObject;
+    static toObject(includeInstance: boolean, msg: CheckpointWorkspaceResponse): CheckpointWorkspaceResponse.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: CheckpointWorkspaceResponse, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): CheckpointWorkspaceResponse;
+    static deserializeBinaryFromReader(message: CheckpointWorkspaceResponse, reader: jspb.BinaryReader): CheckpointWorkspaceResponse;
+}
+
+export namespace CheckpointWorkspaceResponse {
+    export type AsObject = {
+    }
+}
+
 export class DisposeWorkspaceRequest extends jspb.Message {
     getId(): string;
     setId(value: string): DisposeWorkspaceRequest;
@@ -21,6 +21,7 @@ interface IInWorkspaceServiceService extends grpc.ServiceDefinition<grpc.Untyped
     mountSysfs: IInWorkspaceServiceService_IMountSysfs;
     umountSysfs: IInWorkspaceServiceService_IUmountSysfs;
     teardown: IInWorkspaceServiceService_ITeardown;
+    setHosts: IInWorkspaceServiceService_ISetHosts;
 }
 
 interface IInWorkspaceServiceService_IPrepareForUserNS extends grpc.MethodDefinition<workspace_daemon_pb.PrepareForUserNSRequest, workspace_daemon_pb.PrepareForUserNSResponse> {
@@ -86,6 +87,15 @@ interface IInWorkspaceServiceService_ITeardown extends grpc.MethodDefinition<wor
     responseSerialize: grpc.serialize<workspace_daemon_pb.TeardownResponse>;
     responseDeserialize: grpc.deserialize<workspace_daemon_pb.TeardownResponse>;
 }
+interface IInWorkspaceServiceService_ISetHosts extends grpc.MethodDefinition<workspace_daemon_pb.SetHostsRequest, workspace_daemon_pb.SetHostsResponse> {
+    path: "/iws.InWorkspaceService/SetHosts";
+    requestStream: false;
+    responseStream: false;
+    requestSerialize: grpc.serialize<workspace_daemon_pb.SetHostsRequest>;
+    requestDeserialize: grpc.deserialize<workspace_daemon_pb.SetHostsRequest>;
+    responseSerialize: grpc.serialize<workspace_daemon_pb.SetHostsResponse>;
+    responseDeserialize: grpc.deserialize<workspace_daemon_pb.SetHostsResponse>;
+}
 
 export const InWorkspaceServiceService: IInWorkspaceServiceService;
 
@@ -97,6 +107,7 @@ export interface IInWorkspaceServiceServer extends grpc.UntypedServiceImplementa
     mountSysfs: grpc.handleUnaryCall<workspace_daemon_pb.MountProcRequest, workspace_daemon_pb.MountProcResponse>;
     umountSysfs: grpc.handleUnaryCall<workspace_daemon_pb.UmountProcRequest, workspace_daemon_pb.UmountProcResponse>;
     teardown: grpc.handleUnaryCall<workspace_daemon_pb.TeardownRequest, workspace_daemon_pb.TeardownResponse>;
+    setHosts: grpc.handleUnaryCall<workspace_daemon_pb.SetHostsRequest, workspace_daemon_pb.SetHostsResponse>;
 }
 
 export interface IInWorkspaceServiceClient {
@@ -121,6 +132,9 @@ export interface IInWorkspaceServiceClient {
     teardown(request: workspace_daemon_pb.TeardownRequest, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.TeardownResponse) => void): grpc.ClientUnaryCall;
     teardown(request: workspace_daemon_pb.TeardownRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.TeardownResponse) => void): grpc.ClientUnaryCall;
     teardown(request: workspace_daemon_pb.TeardownRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.TeardownResponse) => void): grpc.ClientUnaryCall;
+    setHosts(request: workspace_daemon_pb.SetHostsRequest, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.SetHostsResponse) => void): grpc.ClientUnaryCall;
+    setHosts(request: workspace_daemon_pb.SetHostsRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.SetHostsResponse) => void): grpc.ClientUnaryCall;
+    setHosts(request: workspace_daemon_pb.SetHostsRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.SetHostsResponse) => void): grpc.ClientUnaryCall;
 }
 
 export class InWorkspaceServiceClient extends grpc.Client implements IInWorkspaceServiceClient {
@@ -146,4 +160,7 @@ export class InWorkspaceServiceClient extends grpc.Client implements IInWorkspac
     public teardown(request: workspace_daemon_pb.TeardownRequest, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.TeardownResponse) => void): grpc.ClientUnaryCall;
     public teardown(request: workspace_daemon_pb.TeardownRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.TeardownResponse) => void): grpc.ClientUnaryCall;
     public teardown(request: workspace_daemon_pb.TeardownRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.TeardownResponse) => void): grpc.ClientUnaryCall;
+    public setHosts(request: workspace_daemon_pb.SetHostsRequest, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.SetHostsResponse) => void): grpc.ClientUnaryCall;
+    public setHosts(request: workspace_daemon_pb.SetHostsRequest, metadata: grpc.Metadata, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.SetHostsResponse) => void): grpc.ClientUnaryCall;
+    public setHosts(request: workspace_daemon_pb.SetHostsRequest, metadata: grpc.Metadata, options: Partial<grpc.CallOptions>, callback: (error: grpc.ServiceError | null, response: workspace_daemon_pb.SetHostsResponse) => void): grpc.ClientUnaryCall;
 }
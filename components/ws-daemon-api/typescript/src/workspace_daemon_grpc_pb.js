@@ -54,6 +54,28 @@ function deserialize_iws_PrepareForUserNSResponse(buffer_arg) {
   return workspace_daemon_pb.PrepareForUserNSResponse.deserializeBinary(new Uint8Array(buffer_arg));
 }
 
+function serialize_iws_SetHostsRequest(arg) {
+  if (!(arg instanceof workspace_daemon_pb.SetHostsRequest)) {
+    throw new Error('Expected argument of type iws.SetHostsRequest');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_iws_SetHostsRequest(buffer_arg) {
+  return workspace_daemon_pb.SetHostsRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_iws_SetHostsResponse(arg) {
+  if (!(arg instanceof workspace_daemon_pb.SetHostsResponse)) {
+    throw new Error('Expected argument of type iws.SetHostsResponse');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_iws_SetHostsResponse(buffer_arg) {
+  return workspace_daemon_pb.SetHostsResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_iws_TeardownRequest(arg) {
   if (!(arg instanceof workspace_daemon_pb.TeardownRequest)) {
     throw new Error('Expected argument of type iws.TeardownRequest');
@@ -221,6 +243,19 @@ teardown: {
     responseSerialize: serialize_iws_TeardownResponse,
     responseDeserialize: deserialize_iws_TeardownResponse,
   },
+  // SetHosts replaces the entries ws-daemon maintains in the workspace's /etc/hosts file.
+// Entries which are not managed by ws-daemon remain untouched.
+setHosts: {
+    path: '/iws.InWorkspaceService/SetHosts',
+    requestStream: false,
+    responseStream: false,
+    requestType: workspace_daemon_pb.SetHostsRequest,
+    responseType: workspace_daemon_pb.SetHostsResponse,
+    requestSerialize: serialize_iws_SetHostsRequest,
+    requestDeserialize: deserialize_iws_SetHostsRequest,
+    responseSerialize: serialize_iws_SetHostsResponse,
+    responseDeserialize: deserialize_iws_SetHostsResponse,
+  },
 };
 
 exports.InWorkspaceServiceClient = grpc.makeGenericClientConstructor(InWorkspaceServiceService);
@@ -248,6 +248,68 @@ export namespace TeardownResponse {
     }
 }
 
+export class SetHostsRequest extends jspb.Message {
+    clearHostsList(): void;
+    getHostsList(): Array<HostEntry>;
+    setHostsList(value: Array<HostEntry>): SetHostsRequest;
+    addHosts(value?: HostEntry, index?: number): HostEntry;
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): SetHostsRequest.AsObject;
+    static toObject(includeInstance: boolean, msg: SetHostsRequest): SetHostsRequest.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: SetHostsRequest, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): SetHostsRequest;
+    static deserializeBinaryFromReader(message: SetHostsRequest, reader: jspb.BinaryReader): SetHostsRequest;
+}
+
+export namespace SetHostsRequest {
+    export type AsObject = {
+        hostsList: Array<HostEntry.AsObject>,
+    }
+}
+
+export class SetHostsResponse extends jspb.Message {
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): SetHostsResponse.AsObject;
+    static toObject(includeInstance: boolean, msg: SetHostsResponse): SetHostsResponse.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: SetHostsResponse, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): SetHostsResponse;
+    static deserializeBinaryFromReader(message: SetHostsResponse, reader: jspb.BinaryReader): SetHostsResponse;
+}
+
+export namespace SetHostsResponse {
+    export type AsObject = {
+    }
+}
+
+export class HostEntry extends jspb.Message {
+    getName(): string;
+    setName(value: string): HostEntry;
+    getAddr(): string;
+    setAddr(value: string): HostEntry;
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): HostEntry.AsObject;
+    static toObject(includeInstance: boolean, msg: HostEntry): HostEntry.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: HostEntry, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): HostEntry;
+    static deserializeBinaryFromReader(message: HostEntry, reader: jspb.BinaryReader): HostEntry;
+}
+
+export namespace HostEntry {
+    export type AsObject = {
+        name: string,
+        addr: string,
+    }
+}
+
 export enum FSShiftMethod {
     SHIFTFS = 0,
     FUSE = 1,
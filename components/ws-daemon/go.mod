@@ -35,6 +35,7 @@ require (
 	k8s.io/api v0.22.0
 	k8s.io/apimachinery v0.22.0
 	k8s.io/client-go v0.22.0
+	k8s.io/cri-api v0.22.0
 )
 
 require (
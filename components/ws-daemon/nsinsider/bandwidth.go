@@ -0,0 +1,139 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// bandwidthBurstDuration is the time worth of traffic we allow to burst beyond the rate limit
+	bandwidthBurstDuration = 0.1 // seconds
+	// bandwidthMinBurst is the minimal burst size in bytes. The burst must be larger than the MTU, otherwise no packet ever passes.
+	bandwidthMinBurst = 64 * 1024
+	// bandwidthLatency is the time a packet may wait in the egress queue before it's dropped
+	bandwidthLatency = 0.05 // seconds
+	// bandwidthPoliceMTU is the largest packet the ingress policer accepts. With GRO, packets can be up to 64k.
+	bandwidthPoliceMTU = 64 * 1024
+)
+
+var (
+	egressHandle  = netlink.MakeHandle(1, 0)
+	ingressHandle = netlink.MakeHandle(0xffff, 0)
+)
+
+// setupBandwidthLimit limits the egress and ingress bandwidth of the network interface holding the default route.
+// Rates are in bits per second. A rate of zero removes the corresponding limit.
+//
+// Egress traffic is shaped using a token bucket filter on the root qdisc. Ingress traffic cannot be shaped,
+// hence we police it using a matchall filter on the ingress qdisc which drops packets exceeding the rate.
+func setupBandwidthLimit(egress, ingress uint64) error {
+	link, err := defaultRouteLink()
+	if err != nil {
+		return err
+	}
+	idx := link.Attrs().Index
+
+	egressQdisc := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: idx,
+			Handle:    egressHandle,
+			Parent:    netlink.HANDLE_ROOT,
+		},
+	}
+	if egress == 0 {
+		err = netlink.QdiscDel(egressQdisc)
+		if err != nil && !isNoSuchQdisc(err) {
+			return fmt.Errorf("cannot remove egress limit: %w", err)
+		}
+	} else {
+		rate := egress / 8
+		burst := burstSize(rate)
+		egressQdisc.Rate = rate
+		egressQdisc.Buffer = netlink.Xmittime(rate, burst)
+		egressQdisc.Limit = uint32(math.Min(float64(rate)*bandwidthLatency+float64(burst), math.MaxUint32))
+		err = netlink.QdiscReplace(egressQdisc)
+		if err != nil {
+			return fmt.Errorf("cannot set egress limit: %w", err)
+		}
+	}
+
+	// Removing the ingress qdisc removes all its filters, too. We re-create it from scratch
+	// to make sure there's exactly one policing filter.
+	ingressQdisc := &netlink.Ingress{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: idx,
+			Handle:    ingressHandle,
+			Parent:    netlink.HANDLE_INGRESS,
+		},
+	}
+	err = netlink.QdiscDel(ingressQdisc)
+	if err != nil && !isNoSuchQdisc(err) {
+		return fmt.Errorf("cannot remove ingress limit: %w", err)
+	}
+	if ingress == 0 {
+		return nil
+	}
+
+	err = netlink.QdiscAdd(ingressQdisc)
+	if err != nil {
+		return fmt.Errorf("cannot add ingress qdisc: %w", err)
+	}
+	rate := ingress / 8
+	if rate > math.MaxUint32 {
+		rate = math.MaxUint32
+	}
+	police := netlink.NewPoliceAction()
+	police.Rate = uint32(rate)
+	police.Burst = burstSize(rate)
+	police.Mtu = bandwidthPoliceMTU
+	police.ExceedAction = netlink.TC_POLICE_SHOT
+	err = netlink.FilterAdd(&netlink.MatchAll{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: idx,
+			Parent:    ingressHandle,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []netlink.Action{police},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot set ingress limit: %w", err)
+	}
+
+	return nil
+}
+
+// defaultRouteLink finds the network interface which holds the default route
+func defaultRouteLink() (netlink.Link, error) {
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list routes: %w", err)
+	}
+	for _, r := range routes {
+		if r.Dst != nil {
+			continue
+		}
+		return netlink.LinkByIndex(r.LinkIndex)
+	}
+	return nil, fmt.Errorf("no default route found")
+}
+
+func burstSize(rate uint64) uint32 {
+	burst := float64(rate) * bandwidthBurstDuration
+	if burst < bandwidthMinBurst {
+		return bandwidthMinBurst
+	}
+	return uint32(math.Min(burst, math.MaxUint32))
+}
+
+func isNoSuchQdisc(err error) bool {
+	return errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EINVAL)
+}
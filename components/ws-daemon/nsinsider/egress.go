@@ -0,0 +1,267 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	egressTableName       = "gitpod-egress"
+	egressChainName       = "output"
+	egressViolationsName  = "violations"
+	nftObjectTypeCounter  = 1
+	egressActionDeny      = "deny"
+	egressDNSPort         = 53
+	egressIfnameLoopback  = "lo"
+	ifnameSize            = 16
+	ipv4DestinationOffset = 16
+	ipv6DestinationOffset = 24
+)
+
+// egressPolicy is the resolved egress policy ws-daemon hands to us. All destinations are CIDR ranges or IP addresses.
+type egressPolicy struct {
+	DefaultAction string `json:"defaultAction"`
+	Rules         []struct {
+		Action   string   `json:"action"`
+		Networks []string `json:"networks"`
+		Ports    []uint16 `json:"ports,omitempty"`
+	} `json:"rules"`
+	// Resolvers are the DNS servers the workspace may always reach on port 53
+	Resolvers []string `json:"resolvers,omitempty"`
+}
+
+var egressTable = &nftables.Table{
+	Name:   egressTableName,
+	Family: nftables.TableFamilyINet,
+}
+
+// setupEgressFirewall replaces the egress firewall of the network namespace with one implementing the policy.
+//
+// Established connections, loopback traffic and DNS to the policy's resolvers are always allowed. DNS to any
+// other server is subject to the policy rules, so that it cannot be used to tunnel traffic past the policy.
+// Denied connections are rejected and counted in a named counter which can be read using egressViolations.
+func setupEgressFirewall(rawPolicy string) error {
+	var policy egressPolicy
+	err := json.Unmarshal([]byte(rawPolicy), &policy)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal policy: %w", err)
+	}
+
+	conn := &nftables.Conn{}
+
+	// Adding the table before deleting it makes sure the deletion does not fail if the table does not exist yet.
+	// All of this happens in a single batch, hence the firewall is replaced atomically.
+	conn.AddTable(egressTable)
+	conn.DelTable(egressTable)
+	conn.AddTable(egressTable)
+	conn.AddObj(&nftables.CounterObj{
+		Table: egressTable,
+		Name:  egressViolationsName,
+	})
+	chain := conn.AddChain(&nftables.Chain{
+		Name:     egressChainName,
+		Table:    egressTable,
+		Type:     nftables.ChainTypeFilter,
+		Hooknum:  nftables.ChainHookOutput,
+		Priority: nftables.ChainPriorityFilter,
+	})
+	addRule := func(exprs ...expr.Any) {
+		conn.AddRule(&nftables.Rule{
+			Table: egressTable,
+			Chain: chain,
+			Exprs: exprs,
+		})
+	}
+
+	// ct state established,related accept
+	addRule(
+		&expr.Ct{Register: 1, Key: expr.CtKeySTATE},
+		&expr.Bitwise{
+			SourceRegister: 1,
+			DestRegister:   1,
+			Len:            4,
+			Mask:           binaryutil.NativeEndian.PutUint32(expr.CtStateBitESTABLISHED | expr.CtStateBitRELATED),
+			Xor:            binaryutil.NativeEndian.PutUint32(0),
+		},
+		&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: binaryutil.NativeEndian.PutUint32(0)},
+		&expr.Verdict{Kind: expr.VerdictAccept},
+	)
+	// oifname "lo" accept
+	addRule(
+		&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ifname(egressIfnameLoopback)},
+		&expr.Verdict{Kind: expr.VerdictAccept},
+	)
+	// ip daddr <resolver> meta l4proto { tcp, udp } th dport 53 accept
+	for _, resolver := range policy.Resolvers {
+		dst, err := matchDestination(resolver)
+		if err != nil {
+			return fmt.Errorf("resolver: %w", err)
+		}
+		for _, proto := range []byte{unix.IPPROTO_TCP, unix.IPPROTO_UDP} {
+			addRule(concatExprs(dst, matchPort(proto, egressDNSPort), []expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}})...)
+		}
+	}
+
+	for i, rule := range policy.Rules {
+		verdict := []expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}
+		if rule.Action == egressActionDeny {
+			verdict = rejectAndCount()
+		}
+
+		for _, network := range rule.Networks {
+			dst, err := matchDestination(network)
+			if err != nil {
+				return fmt.Errorf("rule %d: %w", i, err)
+			}
+			if len(rule.Ports) == 0 {
+				addRule(concatExprs(dst, verdict)...)
+				continue
+			}
+			for _, port := range rule.Ports {
+				for _, proto := range []byte{unix.IPPROTO_TCP, unix.IPPROTO_UDP} {
+					addRule(concatExprs(dst, matchPort(proto, port), verdict)...)
+				}
+			}
+		}
+	}
+
+	if policy.DefaultAction == egressActionDeny {
+		addRule(rejectAndCount()...)
+	}
+
+	err = conn.Flush()
+	if err != nil {
+		return fmt.Errorf("cannot install egress firewall: %w", err)
+	}
+	return nil
+}
+
+// removeEgressFirewall removes the egress firewall from the network namespace
+func removeEgressFirewall() error {
+	conn := &nftables.Conn{}
+	conn.AddTable(egressTable)
+	conn.DelTable(egressTable)
+	err := conn.Flush()
+	if err != nil {
+		return fmt.Errorf("cannot remove egress firewall: %w", err)
+	}
+	return nil
+}
+
+// egressViolations returns the number of connection attempts the egress firewall rejected since the last call
+func egressViolations() (uint64, error) {
+	conn := &nftables.Conn{}
+	obj, err := conn.ResetObject(&nftables.CounterObj{
+		Table: egressTable,
+		Name:  egressViolationsName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot read violation counter: %w", err)
+	}
+	counter, ok := obj.(*nftables.CounterObj)
+	if !ok {
+		return 0, fmt.Errorf("violation counter has unexpected type %T", obj)
+	}
+	return counter.Packets, nil
+}
+
+// matchDestination produces expressions matching the destination address against a CIDR range or IP address
+func matchDestination(network string) ([]expr.Any, error) {
+	_, ipnet, err := net.ParseCIDR(network)
+	if err != nil {
+		ip := net.ParseIP(network)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid network %s", network)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			bits = 8 * net.IPv4len
+		}
+		ipnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+
+	var (
+		family = byte(unix.NFPROTO_IPV6)
+		offset = uint32(ipv6DestinationOffset)
+		addr   = ipnet.IP.To16()
+		mask   = []byte(ipnet.Mask)
+	)
+	if ip4 := ipnet.IP.To4(); ip4 != nil {
+		family = unix.NFPROTO_IPV4
+		offset = ipv4DestinationOffset
+		addr = ip4
+		if len(mask) == net.IPv6len {
+			mask = mask[12:]
+		}
+	}
+
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{family}},
+		&expr.Payload{
+			DestRegister: 1,
+			Base:         expr.PayloadBaseNetworkHeader,
+			Offset:       offset,
+			Len:          uint32(len(addr)),
+		},
+		&expr.Bitwise{
+			SourceRegister: 1,
+			DestRegister:   1,
+			Len:            uint32(len(addr)),
+			Mask:           mask,
+			Xor:            make([]byte, len(addr)),
+		},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: addr.Mask(mask)},
+	}, nil
+}
+
+// matchPort produces expressions matching the transport protocol and destination port
+func matchPort(proto byte, port uint16) []expr.Any {
+	dport := make([]byte, 2)
+	binary.BigEndian.PutUint16(dport, port)
+
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{proto}},
+		&expr.Payload{
+			DestRegister: 1,
+			Base:         expr.PayloadBaseTransportHeader,
+			Offset:       2,
+			Len:          2,
+		},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: dport},
+	}
+}
+
+func rejectAndCount() []expr.Any {
+	return []expr.Any{
+		&expr.Objref{Type: nftObjectTypeCounter, Name: egressViolationsName},
+		&expr.Reject{Type: unix.NFT_REJECT_ICMPX_UNREACH, Code: unix.NFT_REJECT_ICMPX_ADMIN_PROHIBITED},
+	}
+}
+
+func concatExprs(exprs ...[]expr.Any) []expr.Any {
+	var res []expr.Any
+	for _, e := range exprs {
+		res = append(res, e...)
+	}
+	return res
+}
+
+func ifname(n string) []byte {
+	b := make([]byte, ifnameSize)
+	copy(b, n+"\x00")
+	return b
+}
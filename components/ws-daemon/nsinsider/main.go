@@ -258,6 +258,56 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:  "setup-bandwidth-limit",
+				Usage: "limits the egress and ingress bandwidth of the default network interface",
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  "egress",
+						Usage: "egress rate limit in bits per second - zero removes the limit",
+					},
+					&cli.Uint64Flag{
+						Name:  "ingress",
+						Usage: "ingress rate limit in bits per second - zero removes the limit",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return setupBandwidthLimit(c.Uint64("egress"), c.Uint64("ingress"))
+				},
+			},
+			{
+				Name:  "setup-egress-firewall",
+				Usage: "installs an nftables firewall restricting outgoing connections",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "policy",
+						Usage:    "resolved egress policy in JSON",
+						Required: true,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return setupEgressFirewall(c.String("policy"))
+				},
+			},
+			{
+				Name:  "remove-egress-firewall",
+				Usage: "removes the nftables firewall restricting outgoing connections",
+				Action: func(c *cli.Context) error {
+					return removeEgressFirewall()
+				},
+			},
+			{
+				Name:  "egress-violations",
+				Usage: "prints the number of connections the egress firewall rejected since the last call",
+				Action: func(c *cli.Context) error {
+					n, err := egressViolations()
+					if err != nil {
+						return err
+					}
+					fmt.Println(n)
+					return nil
+				},
+			},
 		},
 	}
 
@@ -33,6 +33,12 @@ type Config struct {
 
 	// Containerd contains the containerd CRI config if runtime == RuntimeContainerd
 	Containerd *ContainerdConfig `json:"containerd,omitempty"`
+
+	// CRIO contains the CRI-O config if runtime == RuntimeCRIO
+	CRIO *CRIOConfig `json:"crio,omitempty"`
+
+	// Local contains the local runtime config if runtime == RuntimeLocal
+	Local *LocalConfig `json:"local,omitempty"`
 }
 
 // RuntimeType lists the supported container runtimes
@@ -41,6 +47,12 @@ type RuntimeType string
 const (
 	// RuntimeContainerd connects to containerd
 	RuntimeContainerd RuntimeType = "containerd"
+
+	// RuntimeCRIO connects to CRI-O
+	RuntimeCRIO RuntimeType = "crio"
+
+	// RuntimeLocal runs containers as local processes. This runtime is meant for integration tests only.
+	RuntimeLocal RuntimeType = "local"
 )
 
 // ContainerdConfig configures access to containerd
@@ -48,6 +60,26 @@ type ContainerdConfig struct {
 	// SocketPath is the path in the local file system pointing to the containerd socket.
 	// If this field is not set, full workspace backups are not available.
 	SocketPath string `json:"socket"`
+
+	// CRIUPath is the path to the criu binary the container runtime checkpoints processes with.
+	// If this field is not set, checkpointing processes is not available.
+	CRIUPath string `json:"criu,omitempty"`
+}
+
+// CRIOConfig configures access to CRI-O
+type CRIOConfig struct {
+	// SocketPath is the path in the local file system pointing to the CRI-O socket
+	SocketPath string `json:"socket"`
+
+	// PollInterval is the interval at which we list the workspace containers, e.g. "2s".
+	// Defaults to two seconds.
+	PollInterval string `json:"pollInterval,omitempty"`
+}
+
+// LocalConfig configures the local process-based runtime
+type LocalConfig struct {
+	// WorkingArea is the directory in which the container rootfs and upperdirs are created
+	WorkingArea string `json:"workingArea"`
 }
 
 // FromConfig produces a container runtime interface instance from the configuration
@@ -67,6 +99,16 @@ func FromConfig(cfg *Config) (rt Runtime, err error) {
 			return nil, xerrors.Errorf("runtime is set to containerd, but not containerd config is provided")
 		}
 		return NewContainerd(cfg.Containerd, mounts, cfg.Mapping)
+	case RuntimeCRIO:
+		if cfg.CRIO == nil {
+			return nil, xerrors.Errorf("runtime is set to crio, but no crio config is provided")
+		}
+		return NewCRIO(cfg.CRIO, mounts, cfg.Mapping)
+	case RuntimeLocal:
+		if cfg.Local == nil {
+			return nil, xerrors.Errorf("runtime is set to local, but no local config is provided")
+		}
+		return NewLocal(cfg.Local)
 	default:
 		return nil, xerrors.Errorf("unknown runtime type: %s", cfg.Runtime)
 	}
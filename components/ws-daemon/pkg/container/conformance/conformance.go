@@ -0,0 +1,227 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+// Package conformance provides a test suite every container.Runtime implementation has to pass.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
+)
+
+// Harness controls the workspace containers of the runtime under test
+type Harness interface {
+	// Runtime returns the runtime under test
+	Runtime() container.Runtime
+
+	// StartWorkspace creates a workspace container for the instance ID
+	StartWorkspace(ctx context.Context, instanceID string) error
+
+	// StopWorkspace removes the workspace container of the instance ID
+	StopWorkspace(ctx context.Context, instanceID string) error
+}
+
+// Options configure the conformance test run
+type Options struct {
+	// Timeout is the time we wait for a container to appear or disappear. Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// SupportsUpperdir is true if the runtime is expected to report an upperdir for workspace containers.
+	// If false, ContainerUpperdir may return ErrNoUpperdir.
+	SupportsUpperdir bool
+}
+
+// Run runs the conformance test suite against the runtime provided by the harness
+func Run(t *testing.T, h Harness, opts Options) {
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	t.Run("WaitForContainer", func(t *testing.T) {
+		rt := h.Runtime()
+		instanceID := newInstanceID()
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+
+		type result struct {
+			ID  container.ID
+			Err error
+		}
+		res := make(chan result, 1)
+		go func() {
+			id, err := rt.WaitForContainer(ctx, instanceID)
+			res <- result{id, err}
+		}()
+
+		startWorkspace(ctx, t, h, instanceID)
+		r := <-res
+		if r.Err != nil {
+			t.Fatalf("WaitForContainer failed: %v", r.Err)
+		}
+		if r.ID == "" {
+			t.Fatal("WaitForContainer returned an empty ID")
+		}
+
+		exists, err := rt.ContainerExists(ctx, r.ID)
+		if err != nil {
+			t.Fatalf("ContainerExists failed: %v", err)
+		}
+		if !exists {
+			t.Error("container returned by WaitForContainer does not exist")
+		}
+
+		id, err := rt.WaitForContainer(ctx, instanceID)
+		if err != nil {
+			t.Fatalf("WaitForContainer failed for an existing container: %v", err)
+		}
+		if id != r.ID {
+			t.Errorf("WaitForContainer returned %s for an existing container, expected %s", id, r.ID)
+		}
+	})
+
+	t.Run("WaitForContainer respects context cancelation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := h.Runtime().WaitForContainer(ctx, newInstanceID())
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("WaitForContainer returned %v for a non-existent container, expected %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("WaitForContainerStop", func(t *testing.T) {
+		rt := h.Runtime()
+		instanceID := newInstanceID()
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+
+		startWorkspace(ctx, t, h, instanceID)
+		id, err := rt.WaitForContainer(ctx, instanceID)
+		if err != nil {
+			t.Fatalf("WaitForContainer failed: %v", err)
+		}
+
+		err = h.StopWorkspace(ctx, instanceID)
+		if err != nil {
+			t.Fatalf("cannot stop workspace: %v", err)
+		}
+		err = rt.WaitForContainerStop(ctx, instanceID)
+		if err != nil {
+			t.Fatalf("WaitForContainerStop failed: %v", err)
+		}
+
+		_, err = rt.ContainerRootfs(ctx, id, container.OptsContainerRootfs{})
+		if !errors.Is(err, container.ErrNotFound) {
+			t.Errorf("ContainerRootfs returned %v for a stopped container, expected %v", err, container.ErrNotFound)
+		}
+	})
+
+	t.Run("ContainerRootfs", func(t *testing.T) {
+		rt := h.Runtime()
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+
+		id := runningContainer(ctx, t, h)
+
+		loc, err := rt.ContainerRootfs(ctx, id, container.OptsContainerRootfs{})
+		if err != nil {
+			t.Fatalf("ContainerRootfs failed: %v", err)
+		}
+		if _, err := os.Stat(loc); err != nil {
+			t.Errorf("mapped rootfs %s is not accessible: %v", loc, err)
+		}
+
+		unmapped, err := rt.ContainerRootfs(ctx, id, container.OptsContainerRootfs{Unmapped: true})
+		if err != nil {
+			t.Fatalf("ContainerRootfs failed for unmapped location: %v", err)
+		}
+		if unmapped == "" {
+			t.Error("ContainerRootfs returned an empty unmapped location")
+		}
+
+		_, err = rt.ContainerRootfs(ctx, container.ID("does-not-exist"), container.OptsContainerRootfs{})
+		if !errors.Is(err, container.ErrNotFound) {
+			t.Errorf("ContainerRootfs returned %v for a non-existent container, expected %v", err, container.ErrNotFound)
+		}
+	})
+
+	t.Run("ContainerUpperdir", func(t *testing.T) {
+		rt := h.Runtime()
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+
+		id := runningContainer(ctx, t, h)
+
+		loc, err := rt.ContainerUpperdir(ctx, id, container.OptsContainerUpperdir{})
+		if errors.Is(err, container.ErrNoUpperdir) && !opts.SupportsUpperdir {
+			// that's ok - the runtime does not support upperdirs
+		} else if err != nil {
+			t.Fatalf("ContainerUpperdir failed: %v", err)
+		} else if _, err := os.Stat(loc); err != nil {
+			t.Errorf("mapped upperdir %s is not accessible: %v", loc, err)
+		}
+
+		_, err = rt.ContainerUpperdir(ctx, container.ID("does-not-exist"), container.OptsContainerUpperdir{})
+		if !errors.Is(err, container.ErrNotFound) {
+			t.Errorf("ContainerUpperdir returned %v for a non-existent container, expected %v", err, container.ErrNotFound)
+		}
+	})
+
+	t.Run("ContainerPID", func(t *testing.T) {
+		rt := h.Runtime()
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+
+		id := runningContainer(ctx, t, h)
+
+		pid, err := rt.ContainerPID(ctx, id)
+		if err != nil {
+			t.Fatalf("ContainerPID failed: %v", err)
+		}
+		if pid == 0 {
+			t.Error("ContainerPID returned 0 for a running container")
+		}
+
+		_, err = rt.ContainerPID(ctx, container.ID("does-not-exist"))
+		if !errors.Is(err, container.ErrNotFound) {
+			t.Errorf("ContainerPID returned %v for a non-existent container, expected %v", err, container.ErrNotFound)
+		}
+	})
+}
+
+// runningContainer starts a workspace, waits for its container and stops it again once the test is done
+func runningContainer(ctx context.Context, t *testing.T, h Harness) container.ID {
+	instanceID := newInstanceID()
+	startWorkspace(ctx, t, h, instanceID)
+
+	id, err := h.Runtime().WaitForContainer(ctx, instanceID)
+	if err != nil {
+		t.Fatalf("WaitForContainer failed: %v", err)
+	}
+	return id
+}
+
+func startWorkspace(ctx context.Context, t *testing.T, h Harness, instanceID string) {
+	err := h.StartWorkspace(ctx, instanceID)
+	if err != nil {
+		t.Fatalf("cannot start workspace: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = h.StopWorkspace(ctx, instanceID)
+	})
+}
+
+func newInstanceID() string {
+	return fmt.Sprintf("conformance-%d", time.Now().UnixNano())
+}
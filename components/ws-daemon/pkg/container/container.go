@@ -33,6 +33,13 @@ type Runtime interface {
 	// If the container, or its rootfs, is not found ErrNotFound is returned.
 	ContainerRootfs(ctx context.Context, id ID, opts OptsContainerRootfs) (loc string, err error)
 
+	// ContainerUpperdir finds the upperdir of the workspace container's rootfs overlay. As with ContainerRootfs, the location
+	// returned here has to be accessible from the calling process, unless opts.Unmapped == true.
+	//
+	// If the container is not found ErrNotFound is returned.
+	// If the container's rootfs has no upperdir ErrNoUpperdir is returned.
+	ContainerUpperdir(ctx context.Context, id ID, opts OptsContainerUpperdir) (loc string, err error)
+
 	// ContainerCGroupPath finds the container's cgroup path on the node. Note: this path is not the complete path to the container's cgroup,
 	// but merely the suffix. To make it a complete path you need to add the cgroup base path (e.g. /sys/fs/cgroup) and the type of cgroup
 	// you care for, e.g. cpu: filepath.Join("/sys/fs/cgroup", "cpu", cgroupPath).
@@ -46,6 +53,22 @@ type Runtime interface {
 
 	// IsContainerdReady returns is the status of containerd.
 	IsContainerdReady(ctx context.Context) (bool, error)
+
+	// CheckpointContainer dumps the process tree of a running container using CRIU into opts.ImagePath.
+	// The location is relative to the root mount namespace, i.e. not the container. The container stays
+	// frozen once the checkpoint has been taken, and is thawed if taking it fails.
+	//
+	// If the container is not found ErrNotFound is returned. If the runtime cannot take checkpoints
+	// ErrCheckpointUnsupported is returned.
+	CheckpointContainer(ctx context.Context, id ID, opts OptsCheckpoint) (err error)
+
+	// RestoreContainer restores a process tree previously dumped using CheckpointContainer into a running container.
+	// The processes must end up in the container's PID, mount and user namespaces and its cgroup.
+	// The location in opts.ImagePath has to be accessible from the calling process.
+	//
+	// If the container is not found ErrNotFound is returned. If the runtime cannot restore processes into
+	// the container ErrCheckpointUnsupported is returned.
+	RestoreContainer(ctx context.Context, id ID, opts OptsRestore) (err error)
 }
 
 var (
@@ -57,6 +80,9 @@ var (
 
 	// ErrNoCGroup means the container has no cgroup
 	ErrNoCGroup = fmt.Errorf("no cgroup available")
+
+	// ErrCheckpointUnsupported means the runtime cannot checkpoint or restore containers
+	ErrCheckpointUnsupported = fmt.Errorf("checkpoint/restore is not supported")
 )
 
 // ID represents the ID of a CRI container
@@ -66,3 +92,20 @@ type ID string
 type OptsContainerRootfs struct {
 	Unmapped bool
 }
+
+// OptsContainerUpperdir provides options for the ContainerUpperdir function
+type OptsContainerUpperdir struct {
+	Unmapped bool
+}
+
+// OptsCheckpoint provides options for the CheckpointContainer function
+type OptsCheckpoint struct {
+	// ImagePath is the node-level location where the CRIU image is written to
+	ImagePath string
+}
+
+// OptsRestore provides options for the RestoreContainer function
+type OptsRestore struct {
+	// ImagePath is the location of the CRIU image produced by CheckpointContainer
+	ImagePath string
+}
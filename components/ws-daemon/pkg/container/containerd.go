@@ -48,9 +48,10 @@ func NewContainerd(cfg *ContainerdConfig, mounts *NodeMountsLookup, pathMapping
 	}
 
 	res := &Containerd{
-		Client:  cc,
-		Mounts:  mounts,
-		Mapping: pathMapping,
+		Client:   cc,
+		Mounts:   mounts,
+		Mapping:  pathMapping,
+		CRIUPath: cfg.CRIUPath,
 
 		cond:   sync.NewCond(&sync.Mutex{}),
 		cntIdx: make(map[string]*containerInfo),
@@ -64,9 +65,10 @@ func NewContainerd(cfg *ContainerdConfig, mounts *NodeMountsLookup, pathMapping
 
 // Containerd implements the ws-daemon CRI for containerd
 type Containerd struct {
-	Client  *containerd.Client
-	Mounts  *NodeMountsLookup
-	Mapping PathMapping
+	Client   *containerd.Client
+	Mounts   *NodeMountsLookup
+	Mapping  PathMapping
+	CRIUPath string
 
 	cond   *sync.Cond
 	podIdx map[string]*containerInfo
@@ -426,6 +428,25 @@ func (s *Containerd) ContainerRootfs(ctx context.Context, id ID, opts OptsContai
 	return s.Mapping.Translate(mnt)
 }
 
+// ContainerUpperdir finds the upperdir of the workspace container's rootfs overlay.
+func (s *Containerd) ContainerUpperdir(ctx context.Context, id ID, opts OptsContainerUpperdir) (loc string, err error) {
+	s.cond.L.Lock()
+	info, ok := s.cntIdx[string(id)]
+	s.cond.L.Unlock()
+	if !ok {
+		return "", ErrNotFound
+	}
+	if info.UpperDir == "" {
+		return "", ErrNoUpperdir
+	}
+
+	if opts.Unmapped {
+		return info.UpperDir, nil
+	}
+
+	return s.Mapping.Translate(info.UpperDir)
+}
+
 // ContainerCGroupPath finds the container's cgroup path suffix
 func (s *Containerd) ContainerCGroupPath(ctx context.Context, id ID) (loc string, err error) {
 	info, ok := s.cntIdx[string(id)]
@@ -455,6 +476,65 @@ func (s *Containerd) IsContainerdReady(ctx context.Context) (bool, error) {
 	return s.Client.IsServing(ctx)
 }
 
+// CheckpointContainer dumps the process tree of a running container using CRIU
+func (s *Containerd) CheckpointContainer(ctx context.Context, id ID, opts OptsCheckpoint) (err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CheckpointContainer")
+	defer tracing.FinishSpan(span, &err)
+
+	if s.CRIUPath == "" {
+		return ErrCheckpointUnsupported
+	}
+
+	s.cond.L.Lock()
+	_, ok := s.cntIdx[string(id)]
+	s.cond.L.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+
+	cnt, err := s.Client.LoadContainer(ctx, string(id))
+	if errdefs.IsNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return xerrors.Errorf("cannot load container: %w", err)
+	}
+	task, err := cnt.Task(ctx, nil)
+	if errdefs.IsNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return xerrors.Errorf("cannot load container task: %w", err)
+	}
+
+	// We freeze the task ourselves, because containerd resumes tasks it paused for a checkpoint. Processes which keep
+	// running after the dump would change the workspace content their checkpoint does not know about.
+	err = task.Pause(ctx)
+	if err != nil {
+		return xerrors.Errorf("cannot freeze container: %w", err)
+	}
+	// Because we pass an image path, containerd does not create a checkpoint image in its content store.
+	_, err = task.Checkpoint(ctx, containerd.WithCheckpointImagePath(opts.ImagePath))
+	if err != nil {
+		if rerr := task.Resume(ctx); rerr != nil {
+			log.WithError(rerr).WithField("containerID", id).Warn("cannot thaw container after failed checkpoint")
+		}
+		return xerrors.Errorf("cannot checkpoint container: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreContainer refuses to restore checkpoints. Running criu restore from ws-daemon would place the restored
+// processes in the PID, mount and user namespaces and the cgroup of ws-daemon rather than the workspace container:
+// CRIU can join a container's network, IPC and UTS namespaces only. Root within the workspace would come back
+// as root on the node, and the resource limits of the workspace would not apply. Containerd can only restore
+// a container's task when it creates it, which is up to the kubelet.
+func (s *Containerd) RestoreContainer(ctx context.Context, id ID, opts OptsRestore) (err error) {
+	return ErrCheckpointUnsupported
+}
+
 // ExtractCGroupPathFromContainer retrieves the CGroupPath from the linux section
 // in a container's OCI spec.
 func ExtractCGroupPathFromContainer(container containers.Container) (cgroupPath string, err error) {
@@ -0,0 +1,172 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+//go:build linux && conformance
+// +build linux,conformance
+
+package container_test
+
+import (
+	"context"
+	"flag"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	criapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+
+	wsk8s "github.com/gitpod-io/gitpod/common-go/kubernetes"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container/conformance"
+)
+
+// The CRI conformance run needs a node with containerd or CRI-O and has to run as root, e.g.
+//
+//	go test -tags conformance -run TestCRIConformance ./pkg/container -runtime containerd
+//	go test -tags conformance -run TestCRIConformance ./pkg/container -runtime crio
+//
+// Both runtimes implement the Kubernetes CRI, which is what we use to create the workspace pods the runtime under test looks for.
+var (
+	criRuntime = flag.String("runtime", "", "container runtime to run the conformance suite against: containerd or crio")
+	criSocket  = flag.String("runtime-socket", "", "socket of the container runtime, defaults to the runtime's default socket")
+	criImage   = flag.String("runtime-image", "docker.io/library/busybox:1.34", "image the workspace containers run")
+)
+
+var defaultRuntimeSockets = map[container.RuntimeType]string{
+	container.RuntimeContainerd: "/run/containerd/containerd.sock",
+	container.RuntimeCRIO:       "/var/run/crio/crio.sock",
+}
+
+type criHarness struct {
+	rt     container.Runtime
+	client criapi.RuntimeServiceClient
+	image  string
+}
+
+func (h *criHarness) Runtime() container.Runtime { return h.rt }
+
+func (h *criHarness) StartWorkspace(ctx context.Context, instanceID string) error {
+	podName := "ws-" + instanceID
+	sbcfg := &criapi.PodSandboxConfig{
+		Metadata: &criapi.PodSandboxMetadata{
+			Name:      podName,
+			Uid:       instanceID,
+			Namespace: "default",
+		},
+		Labels: map[string]string{
+			"io.kubernetes.pod.name": podName,
+			wsk8s.WorkspaceIDLabel:   instanceID,
+			wsk8s.MetaIDLabel:        instanceID,
+			wsk8s.OwnerLabel:         "conformance",
+		},
+		Linux: &criapi.LinuxPodSandboxConfig{},
+	}
+	sb, err := h.client.RunPodSandbox(ctx, &criapi.RunPodSandboxRequest{Config: sbcfg})
+	if err != nil {
+		return err
+	}
+
+	cnt, err := h.client.CreateContainer(ctx, &criapi.CreateContainerRequest{
+		PodSandboxId: sb.PodSandboxId,
+		Config: &criapi.ContainerConfig{
+			Metadata: &criapi.ContainerMetadata{Name: "workspace"},
+			Image:    &criapi.ImageSpec{Image: h.image},
+			Command:  []string{"sleep", "3600"},
+			Labels: map[string]string{
+				// kubelet sets this label on every container - the runtimes rely on it to find the workspace container
+				"io.kubernetes.container.name": "workspace",
+			},
+			Linux: &criapi.LinuxContainerConfig{},
+		},
+		SandboxConfig: sbcfg,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = h.client.StartContainer(ctx, &criapi.StartContainerRequest{ContainerId: cnt.ContainerId})
+	return err
+}
+
+func (h *criHarness) StopWorkspace(ctx context.Context, instanceID string) error {
+	sbs, err := h.client.ListPodSandbox(ctx, &criapi.ListPodSandboxRequest{
+		Filter: &criapi.PodSandboxFilter{
+			LabelSelector: map[string]string{wsk8s.WorkspaceIDLabel: instanceID},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, sb := range sbs.Items {
+		_, err = h.client.StopPodSandbox(ctx, &criapi.StopPodSandboxRequest{PodSandboxId: sb.Id})
+		if err != nil {
+			return err
+		}
+		_, err = h.client.RemovePodSandbox(ctx, &criapi.RemovePodSandboxRequest{PodSandboxId: sb.Id})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCRIConformance(t *testing.T) {
+	tpe := container.RuntimeType(*criRuntime)
+	socket := *criSocket
+	if socket == "" {
+		socket = defaultRuntimeSockets[tpe]
+	}
+
+	// The tests run on the node itself, hence node and container paths are the same.
+	cfg := &container.Config{
+		Runtime: tpe,
+		Mounts:  container.NodeMountsLookupConfig{ProcLoc: "/proc/self/mounts"},
+		Mapping: map[string]string{"/": "/"},
+	}
+	switch tpe {
+	case container.RuntimeContainerd:
+		cfg.Containerd = &container.ContainerdConfig{SocketPath: socket}
+	case container.RuntimeCRIO:
+		cfg.CRIO = &container.CRIOConfig{SocketPath: socket}
+	case "":
+		t.Skip("no runtime selected, use -runtime containerd or -runtime crio")
+	default:
+		t.Fatalf("unsupported runtime %q, use -runtime containerd or -runtime crio", tpe)
+	}
+
+	rt, err := container.FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, socket,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("cannot connect to CRI at %s: %v", socket, err)
+	}
+	defer conn.Close()
+
+	_, err = criapi.NewImageServiceClient(conn).PullImage(ctx, &criapi.PullImageRequest{Image: &criapi.ImageSpec{Image: *criImage}})
+	if err != nil {
+		t.Fatalf("cannot pull %s: %v", *criImage, err)
+	}
+
+	h := &criHarness{
+		rt:     rt,
+		client: criapi.NewRuntimeServiceClient(conn),
+		image:  *criImage,
+	}
+	conformance.Run(t, h, conformance.Options{
+		Timeout:          time.Minute,
+		SupportsUpperdir: true,
+	})
+}
@@ -0,0 +1,395 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	ocispecs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	criapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+
+	wsk8s "github.com/gitpod-io/gitpod/common-go/kubernetes"
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/common-go/tracing"
+)
+
+const (
+	// crioDefaultPollInterval is the interval at which we list pods and containers if no other interval is configured.
+	// The CRI has no notion of events, hence we have to poll.
+	crioDefaultPollInterval = 2 * time.Second
+
+	// crioInfoKey is the key in the verbose container status info under which CRI-O reports the container details
+	crioInfoKey = "info"
+)
+
+// NewCRIO creates a new CRI-O adapter
+func NewCRIO(cfg *CRIOConfig, mounts *NodeMountsLookup, pathMapping PathMapping) (*CRIO, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, cfg.SocketPath,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot connect to CRI-O at %s: %w", cfg.SocketPath, err)
+	}
+	client := criapi.NewRuntimeServiceClient(conn)
+	_, err = client.Version(ctx, &criapi.VersionRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, xerrors.Errorf("cannot connect to CRI-O: %w", err)
+	}
+
+	pollInterval := crioDefaultPollInterval
+	if cfg.PollInterval != "" {
+		pollInterval, err = time.ParseDuration(cfg.PollInterval)
+		if err != nil {
+			conn.Close()
+			return nil, xerrors.Errorf("invalid poll interval: %w", err)
+		}
+	}
+
+	res := &CRIO{
+		Client:       client,
+		Mounts:       mounts,
+		Mapping:      pathMapping,
+		PollInterval: pollInterval,
+
+		cond:   sync.NewCond(&sync.Mutex{}),
+		cntIdx: make(map[string]*containerInfo),
+		wsiIdx: make(map[string]*containerInfo),
+	}
+	go res.start()
+
+	return res, nil
+}
+
+// CRIO implements the ws-daemon CRI for CRI-O.
+// Contrary to containerd, CRI-O offers no event stream. Instead we periodically list the workspace
+// pod sandboxes and containers through the Kubernetes container runtime interface.
+type CRIO struct {
+	Client       criapi.RuntimeServiceClient
+	Mounts       *NodeMountsLookup
+	Mapping      PathMapping
+	PollInterval time.Duration
+
+	cond   *sync.Cond
+	wsiIdx map[string]*containerInfo
+	cntIdx map[string]*containerInfo
+}
+
+// crioContainerInfo is the verbose container info CRI-O reports on ContainerStatus
+type crioContainerInfo struct {
+	SandboxID   string        `json:"sandboxID"`
+	PID         uint32        `json:"pid"`
+	RuntimeSpec ocispecs.Spec `json:"runtimeSpec"`
+	Privileged  bool          `json:"privileged"`
+}
+
+// start polling CRI-O
+func (s *CRIO) start() {
+	for {
+		err := s.sync()
+		if err != nil {
+			log.WithError(err).Error("cannot sync with CRI-O - will try again")
+		}
+		time.Sleep(s.PollInterval)
+	}
+}
+
+// sync lists all running workspace containers and updates the index
+func (s *CRIO) sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sbs, err := s.Client.ListPodSandbox(ctx, &criapi.ListPodSandboxRequest{
+		Filter: &criapi.PodSandboxFilter{
+			State: &criapi.PodSandboxStateValue{State: criapi.PodSandboxState_SANDBOX_READY},
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("cannot list pod sandboxes: %w", err)
+	}
+	sandboxes := make(map[string]*criapi.PodSandbox, len(sbs.Items))
+	for _, sb := range sbs.Items {
+		if sb.Labels[wsk8s.WorkspaceIDLabel] == "" {
+			continue
+		}
+		sandboxes[sb.Id] = sb
+	}
+
+	cnts, err := s.Client.ListContainers(ctx, &criapi.ListContainersRequest{
+		Filter: &criapi.ContainerFilter{
+			State:         &criapi.ContainerStateValue{State: criapi.ContainerState_CONTAINER_RUNNING},
+			LabelSelector: map[string]string{containerLabelK8sContainerName: "workspace"},
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("cannot list containers: %w", err)
+	}
+
+	s.cond.L.Lock()
+	known := s.cntIdx
+	s.cond.L.Unlock()
+
+	var (
+		wsiIdx = make(map[string]*containerInfo, len(cnts.Containers))
+		cntIdx = make(map[string]*containerInfo, len(cnts.Containers))
+	)
+	for _, c := range cnts.Containers {
+		sb, ok := sandboxes[c.PodSandboxId]
+		if !ok {
+			// not a workspace pod
+			continue
+		}
+
+		info, ok := known[c.Id]
+		if !ok {
+			info, err = s.inspectContainer(ctx, c, sb)
+			if err != nil {
+				log.WithError(err).WithField("ID", c.Id).Warn("cannot inspect workspace container")
+				continue
+			}
+			log.WithField("podname", info.PodName).WithFields(log.OWI(info.OwnerID, info.WorkspaceID, info.InstanceID)).WithField("ID", c.Id).Debug("found workspace container - updating label cache")
+		}
+
+		cntIdx[info.ID] = info
+		wsiIdx[info.InstanceID] = info
+	}
+
+	s.cond.L.Lock()
+	s.cntIdx = cntIdx
+	s.wsiIdx = wsiIdx
+	s.cond.Broadcast()
+	s.cond.L.Unlock()
+
+	return nil
+}
+
+func (s *CRIO) inspectContainer(ctx context.Context, c *criapi.Container, sb *criapi.PodSandbox) (*containerInfo, error) {
+	resp, err := s.Client.ContainerStatus(ctx, &criapi.ContainerStatusRequest{ContainerId: c.Id, Verbose: true})
+	if err != nil {
+		return nil, xerrors.Errorf("cannot get container status: %w", err)
+	}
+
+	var ci crioContainerInfo
+	err = json.Unmarshal([]byte(resp.Info[crioInfoKey]), &ci)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot unmarshal container info: %w", err)
+	}
+
+	info := &containerInfo{
+		ID:          c.Id,
+		InstanceID:  sb.Labels[wsk8s.WorkspaceIDLabel],
+		OwnerID:     sb.Labels[wsk8s.OwnerLabel],
+		WorkspaceID: sb.Labels[wsk8s.MetaIDLabel],
+		PID:         ci.PID,
+		SeenTask:    true,
+	}
+	if sb.Metadata != nil {
+		info.PodName = sb.Metadata.Name
+	}
+	if ci.RuntimeSpec.Root != nil {
+		info.Rootfs = ci.RuntimeSpec.Root.Path
+	}
+	if ci.RuntimeSpec.Linux != nil {
+		info.CGroupPath = ci.RuntimeSpec.Linux.CgroupsPath
+	}
+	if info.Rootfs != "" {
+		info.UpperDir, err = s.Mounts.GetUpperdir(func(mountPoint string) bool { return mountPoint == info.Rootfs })
+		if err != nil {
+			log.WithError(err).WithFields(log.OWI(info.OwnerID, info.WorkspaceID, info.InstanceID)).Warn("cannot find upperdir")
+		}
+	}
+
+	return info, nil
+}
+
+// WaitForContainer waits for workspace container to come into existence.
+func (s *CRIO) WaitForContainer(ctx context.Context, workspaceInstanceID string) (cid ID, err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WaitForContainer")
+	defer tracing.FinishSpan(span, &err)
+
+	rchan := make(chan ID, 1)
+	go func() {
+		s.cond.L.Lock()
+		defer s.cond.L.Unlock()
+
+		for {
+			info, ok := s.wsiIdx[workspaceInstanceID]
+			if ok {
+				rchan <- ID(info.ID)
+				break
+			}
+
+			if ctx.Err() != nil {
+				break
+			}
+
+			s.cond.Wait()
+		}
+	}()
+
+	select {
+	case cid = <-rchan:
+		return
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+}
+
+// WaitForContainerStop waits for workspace container to be deleted.
+func (s *CRIO) WaitForContainerStop(ctx context.Context, workspaceInstanceID string) (err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WaitForContainerStop")
+	defer tracing.FinishSpan(span, &err)
+
+	rchan := make(chan struct{}, 1)
+	go func() {
+		s.cond.L.Lock()
+		defer s.cond.L.Unlock()
+
+		for {
+			_, ok := s.wsiIdx[workspaceInstanceID]
+			if !ok {
+				rchan <- struct{}{}
+				break
+			}
+
+			if ctx.Err() != nil {
+				break
+			}
+
+			s.cond.Wait()
+		}
+	}()
+
+	select {
+	case <-rchan:
+		return
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+}
+
+// ContainerExists finds out if a container with the given ID exists.
+func (s *CRIO) ContainerExists(ctx context.Context, id ID) (exists bool, err error) {
+	_, err = s.Client.ContainerStatus(ctx, &criapi.ContainerStatusRequest{ContainerId: string(id)})
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ContainerRootfs finds the workspace container's rootfs.
+func (s *CRIO) ContainerRootfs(ctx context.Context, id ID, opts OptsContainerRootfs) (loc string, err error) {
+	info, ok := s.getInfo(id)
+	if !ok || info.Rootfs == "" {
+		return "", ErrNotFound
+	}
+
+	if opts.Unmapped {
+		return info.Rootfs, nil
+	}
+
+	return s.Mapping.Translate(info.Rootfs)
+}
+
+// ContainerUpperdir finds the upperdir of the workspace container's rootfs overlay.
+func (s *CRIO) ContainerUpperdir(ctx context.Context, id ID, opts OptsContainerUpperdir) (loc string, err error) {
+	info, ok := s.getInfo(id)
+	if !ok {
+		return "", ErrNotFound
+	}
+	if info.UpperDir == "" {
+		return "", ErrNoUpperdir
+	}
+
+	if opts.Unmapped {
+		return info.UpperDir, nil
+	}
+
+	return s.Mapping.Translate(info.UpperDir)
+}
+
+// ContainerCGroupPath finds the container's cgroup path suffix
+func (s *CRIO) ContainerCGroupPath(ctx context.Context, id ID) (loc string, err error) {
+	info, ok := s.getInfo(id)
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	if info.CGroupPath == "" {
+		return "", ErrNoCGroup
+	}
+
+	return info.CGroupPath, nil
+}
+
+// ContainerPID finds the workspace container's PID
+func (s *CRIO) ContainerPID(ctx context.Context, id ID) (pid uint64, err error) {
+	info, ok := s.getInfo(id)
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	return uint64(info.PID), nil
+}
+
+// IsContainerdReady returns true if CRI-O reports its runtime as ready
+func (s *CRIO) IsContainerdReady(ctx context.Context) (bool, error) {
+	resp, err := s.Client.Status(ctx, &criapi.StatusRequest{})
+	if err != nil {
+		return false, err
+	}
+	if resp.Status == nil {
+		return false, nil
+	}
+	for _, c := range resp.Status.Conditions {
+		if c.Type == criapi.RuntimeReady {
+			return c.Status, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CheckpointContainer is not supported by CRI-O
+func (s *CRIO) CheckpointContainer(ctx context.Context, id ID, opts OptsCheckpoint) (err error) {
+	return ErrCheckpointUnsupported
+}
+
+// RestoreContainer is not supported by CRI-O
+func (s *CRIO) RestoreContainer(ctx context.Context, id ID, opts OptsRestore) (err error) {
+	return ErrCheckpointUnsupported
+}
+
+func (s *CRIO) getInfo(id ID) (info *containerInfo, ok bool) {
+	s.cond.L.Lock()
+	defer s.cond.L.Unlock()
+
+	info, ok = s.cntIdx[string(id)]
+	return
+}
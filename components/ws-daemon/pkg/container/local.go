@@ -0,0 +1,261 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/common-go/tracing"
+)
+
+// NewLocal creates a new process-based local runtime
+func NewLocal(cfg *LocalConfig) (*Local, error) {
+	if cfg.WorkingArea == "" {
+		return nil, xerrors.Errorf("working area must not be empty")
+	}
+	err := os.MkdirAll(cfg.WorkingArea, 0755)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot create working area: %w", err)
+	}
+
+	return &Local{
+		WorkingArea: cfg.WorkingArea,
+
+		cond:   sync.NewCond(&sync.Mutex{}),
+		cntIdx: make(map[string]*localContainer),
+		wsiIdx: make(map[string]*localContainer),
+	}, nil
+}
+
+// Local is a fake container runtime which runs "containers" as plain processes. It's meant for
+// integration tests which run without Kubernetes or a real container runtime.
+//
+// Each container gets a directory in the working area which holds its rootfs and upperdir.
+// The container process runs with its working directory set to the rootfs. Containers are
+// created using StartContainer and removed using StopContainer, or when their process exits.
+type Local struct {
+	WorkingArea string
+
+	cond    *sync.Cond
+	counter int
+	wsiIdx  map[string]*localContainer
+	cntIdx  map[string]*localContainer
+}
+
+type localContainer struct {
+	ID         string
+	InstanceID string
+	Rootfs     string
+	UpperDir   string
+	Cmd        *exec.Cmd
+}
+
+// StartContainer creates a new container for the workspace instance and starts its process.
+// If no command is given, the container runs "sleep infinity".
+func (l *Local) StartContainer(ctx context.Context, workspaceInstanceID string, command ...string) (id ID, err error) {
+	if len(command) == 0 {
+		command = []string{"sleep", "infinity"}
+	}
+
+	l.cond.L.Lock()
+	defer l.cond.L.Unlock()
+
+	if _, exists := l.wsiIdx[workspaceInstanceID]; exists {
+		return "", xerrors.Errorf("workspace %s already has a container", workspaceInstanceID)
+	}
+
+	l.counter++
+	cid := fmt.Sprintf("local-%s-%d", workspaceInstanceID, l.counter)
+	var (
+		base = filepath.Join(l.WorkingArea, cid)
+		cnt  = &localContainer{
+			ID:         cid,
+			InstanceID: workspaceInstanceID,
+			Rootfs:     filepath.Join(base, "rootfs"),
+			UpperDir:   filepath.Join(base, "upper"),
+		}
+	)
+	for _, d := range []string{cnt.Rootfs, cnt.UpperDir} {
+		err = os.MkdirAll(d, 0755)
+		if err != nil {
+			return "", xerrors.Errorf("cannot create container directory: %w", err)
+		}
+	}
+
+	cnt.Cmd = exec.Command(command[0], command[1:]...)
+	cnt.Cmd.Dir = cnt.Rootfs
+	err = cnt.Cmd.Start()
+	if err != nil {
+		os.RemoveAll(base)
+		return "", xerrors.Errorf("cannot start container process: %w", err)
+	}
+
+	l.cntIdx[cid] = cnt
+	l.wsiIdx[workspaceInstanceID] = cnt
+	l.cond.Broadcast()
+
+	go func() {
+		err := cnt.Cmd.Wait()
+		log.WithError(err).WithField("ID", cid).Debug("local container process exited")
+
+		l.cond.L.Lock()
+		delete(l.cntIdx, cid)
+		if l.wsiIdx[workspaceInstanceID] == cnt {
+			delete(l.wsiIdx, workspaceInstanceID)
+		}
+		l.cond.Broadcast()
+		l.cond.L.Unlock()
+
+		os.RemoveAll(base)
+	}()
+
+	return ID(cid), nil
+}
+
+// StopContainer kills the container process of a workspace instance and waits for the container to be removed
+func (l *Local) StopContainer(ctx context.Context, workspaceInstanceID string) error {
+	l.cond.L.Lock()
+	cnt, ok := l.wsiIdx[workspaceInstanceID]
+	l.cond.L.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+
+	err := cnt.Cmd.Process.Kill()
+	if err != nil && err != os.ErrProcessDone {
+		return xerrors.Errorf("cannot stop container process: %w", err)
+	}
+
+	return l.WaitForContainerStop(ctx, workspaceInstanceID)
+}
+
+// WaitForContainer waits for workspace container to come into existence.
+func (l *Local) WaitForContainer(ctx context.Context, workspaceInstanceID string) (cid ID, err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WaitForContainer")
+	defer tracing.FinishSpan(span, &err)
+
+	err = l.waitFor(ctx, func() bool {
+		cnt, ok := l.wsiIdx[workspaceInstanceID]
+		if ok {
+			cid = ID(cnt.ID)
+		}
+		return ok
+	})
+	return
+}
+
+// WaitForContainerStop waits for workspace container to be deleted.
+func (l *Local) WaitForContainerStop(ctx context.Context, workspaceInstanceID string) (err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "WaitForContainerStop")
+	defer tracing.FinishSpan(span, &err)
+
+	return l.waitFor(ctx, func() bool {
+		_, ok := l.wsiIdx[workspaceInstanceID]
+		return !ok
+	})
+}
+
+// waitFor waits until cond returns true or the context is canceled. cond is called with the lock held.
+func (l *Local) waitFor(ctx context.Context, cond func() bool) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// wake up the waiting loop below once the context is canceled
+		select {
+		case <-ctx.Done():
+			l.cond.L.Lock()
+			l.cond.Broadcast()
+			l.cond.L.Unlock()
+		case <-done:
+		}
+	}()
+
+	l.cond.L.Lock()
+	defer l.cond.L.Unlock()
+	for !cond() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		l.cond.Wait()
+	}
+	return nil
+}
+
+// ContainerExists finds out if a container with the given ID exists.
+func (l *Local) ContainerExists(ctx context.Context, id ID) (exists bool, err error) {
+	_, exists = l.getContainer(id)
+	return exists, nil
+}
+
+// ContainerRootfs finds the workspace container's rootfs. The local runtime has no path mapping, hence
+// the mapped and unmapped locations are the same.
+func (l *Local) ContainerRootfs(ctx context.Context, id ID, opts OptsContainerRootfs) (loc string, err error) {
+	cnt, ok := l.getContainer(id)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return cnt.Rootfs, nil
+}
+
+// ContainerUpperdir finds the workspace container's upperdir
+func (l *Local) ContainerUpperdir(ctx context.Context, id ID, opts OptsContainerUpperdir) (loc string, err error) {
+	cnt, ok := l.getContainer(id)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return cnt.UpperDir, nil
+}
+
+// ContainerCGroupPath always returns ErrNoCGroup as local containers do not get their own cgroup
+func (l *Local) ContainerCGroupPath(ctx context.Context, id ID) (loc string, err error) {
+	_, ok := l.getContainer(id)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return "", ErrNoCGroup
+}
+
+// ContainerPID returns the PID of the container process
+func (l *Local) ContainerPID(ctx context.Context, id ID) (pid uint64, err error) {
+	cnt, ok := l.getContainer(id)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return uint64(cnt.Cmd.Process.Pid), nil
+}
+
+// IsContainerdReady always returns true
+func (l *Local) IsContainerdReady(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// CheckpointContainer is not supported by the local runtime
+func (l *Local) CheckpointContainer(ctx context.Context, id ID, opts OptsCheckpoint) (err error) {
+	return ErrCheckpointUnsupported
+}
+
+// RestoreContainer is not supported by the local runtime
+func (l *Local) RestoreContainer(ctx context.Context, id ID, opts OptsRestore) (err error) {
+	return ErrCheckpointUnsupported
+}
+
+func (l *Local) getContainer(id ID) (cnt *localContainer, ok bool) {
+	l.cond.L.Lock()
+	defer l.cond.L.Unlock()
+
+	cnt, ok = l.cntIdx[string(id)]
+	return
+}
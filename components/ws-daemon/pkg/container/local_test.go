@@ -0,0 +1,42 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package container_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container/conformance"
+)
+
+type localHarness struct {
+	rt *container.Local
+}
+
+func (h *localHarness) Runtime() container.Runtime { return h.rt }
+
+func (h *localHarness) StartWorkspace(ctx context.Context, instanceID string) error {
+	_, err := h.rt.StartContainer(ctx, instanceID)
+	return err
+}
+
+func (h *localHarness) StopWorkspace(ctx context.Context, instanceID string) error {
+	err := h.rt.StopContainer(ctx, instanceID)
+	if errors.Is(err, container.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func TestLocalConformance(t *testing.T) {
+	rt, err := container.NewLocal(&container.LocalConfig{WorkingArea: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conformance.Run(t, &localHarness{rt: rt}, conformance.Options{SupportsUpperdir: true})
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/common-go/tracing"
+	"github.com/gitpod-io/gitpod/content-service/pkg/storage"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/internal/session"
+)
+
+const (
+	// checkpointDir is the name of the directory in the workspace's service location that holds the CRIU image
+	checkpointDir = "checkpoint"
+
+	// checkpointContainerTimeout is the time we wait for the workspace container when taking a checkpoint.
+	// If the container is not around by then, it's already gone and there's nothing left to checkpoint.
+	checkpointContainerTimeout = 5 * time.Second
+
+	// restoreContainerTimeout is the time we wait for the workspace container to appear before restoring a checkpoint
+	restoreContainerTimeout = 5 * time.Minute
+)
+
+// checkpointWorkspace dumps the workspace processes using CRIU and uploads the image to remote storage.
+// Any previous checkpoint is deleted first, so that a failed checkpoint never leaves a stale one behind
+// which would be restored on top of newer workspace content.
+func (s *WorkspaceService) checkpointWorkspace(ctx context.Context, sess *session.Workspace) (err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "checkpointWorkspace")
+	defer tracing.FinishSpan(span, &err)
+
+	if s.runtime == nil {
+		return xerrors.Errorf("not connected to container runtime")
+	}
+	rs, ok := sess.NonPersistentAttrs[session.AttrRemoteStorage].(storage.DirectAccess)
+	if rs == nil || !ok {
+		return xerrors.Errorf("no remote storage configured")
+	}
+
+	err = rs.Delete(ctx, storage.DefaultCheckpoint)
+	if err != nil {
+		return xerrors.Errorf("cannot delete previous checkpoint: %w", err)
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, checkpointContainerTimeout)
+	cid, err := s.runtime.WaitForContainer(wctx, sess.InstanceID)
+	cancel()
+	if err != nil {
+		return xerrors.Errorf("workspace container is gone: %w", err)
+	}
+
+	var (
+		loc     = filepath.Join(sess.ServiceLocDaemon, checkpointDir)
+		nodeLoc = filepath.Join(sess.ServiceLocNode, checkpointDir)
+	)
+	err = os.RemoveAll(loc)
+	if err != nil {
+		return xerrors.Errorf("cannot remove previous checkpoint location: %w", err)
+	}
+	err = os.MkdirAll(loc, 0755)
+	if err != nil {
+		return xerrors.Errorf("cannot create checkpoint location: %w", err)
+	}
+	defer os.RemoveAll(loc)
+
+	err = s.runtime.CheckpointContainer(ctx, cid, container.OptsCheckpoint{ImagePath: nodeLoc})
+	if err != nil {
+		return err
+	}
+
+	tmpf, err := os.CreateTemp(s.config.TmpDir, fmt.Sprintf("checkpoint-%s-*.tar", sess.InstanceID))
+	if err != nil {
+		return xerrors.Errorf("cannot create checkpoint archive: %w", err)
+	}
+	tmpf.Close()
+	defer os.Remove(tmpf.Name())
+
+	err = BuildTarbal(ctx, loc, tmpf.Name(), false)
+	if err != nil {
+		return xerrors.Errorf("cannot create checkpoint archive: %w", err)
+	}
+
+	err = retryIfErr(ctx, s.config.Backup.Attempts, log.WithFields(sess.OWI()).WithField("op", "upload checkpoint"), func(ctx context.Context) (err error) {
+		_, _, err = rs.Upload(ctx, tmpf.Name(), storage.DefaultCheckpoint)
+		return
+	})
+	if err != nil {
+		return xerrors.Errorf("cannot upload checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// restoreWorkspace downloads the last checkpoint of a workspace and restores it once the workspace container is running.
+// The checkpoint is deleted once downloaded, so that it's restored at most once: the next stop takes a fresh one, if any.
+// Failing to restore the checkpoint is not an error from the workspace's point of view - it simply starts without its
+// previous processes.
+func (s *WorkspaceService) restoreWorkspace(ctx context.Context, sess *session.Workspace) {
+	var err error
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "restoreWorkspace")
+	defer tracing.FinishSpan(span, &err)
+	log := log.WithFields(sess.OWI())
+
+	if s.runtime == nil {
+		return
+	}
+	rs, ok := sess.NonPersistentAttrs[session.AttrRemoteStorage].(storage.DirectAccess)
+	if rs == nil || !ok {
+		return
+	}
+
+	loc := filepath.Join(sess.ServiceLocDaemon, checkpointDir)
+	defer os.RemoveAll(loc)
+
+	found, err := rs.Download(ctx, loc, storage.DefaultCheckpoint, nil)
+	if err != nil {
+		log.WithError(err).Warn("cannot download checkpoint - starting without restore")
+		return
+	}
+	if !found {
+		log.Debug("workspace has no checkpoint - starting without restore")
+		return
+	}
+	err = rs.Delete(ctx, storage.DefaultCheckpoint)
+	if err != nil {
+		log.WithError(err).Warn("cannot delete restored checkpoint")
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, restoreContainerTimeout)
+	cid, err := s.runtime.WaitForContainer(wctx, sess.InstanceID)
+	cancel()
+	if err != nil {
+		log.WithError(err).Warn("workspace container did not appear - starting without restore")
+		return
+	}
+
+	err = s.runtime.RestoreContainer(ctx, cid, container.OptsRestore{ImagePath: loc})
+	if errors.Is(err, container.ErrCheckpointUnsupported) {
+		log.Info("container runtime cannot restore checkpoints - starting without restore")
+		return
+	}
+	if err != nil {
+		log.WithError(err).Warn("cannot restore checkpoint - starting without restore")
+		return
+	}
+	log.Info("restored workspace processes from checkpoint")
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package content
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+
+	"github.com/gitpod-io/gitpod/content-service/pkg/archive"
+	"github.com/gitpod-io/gitpod/content-service/pkg/storage"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/internal/session"
+)
+
+const checkpointImageFile = "core-1.img"
+
+func TestCheckpointWorkspace(t *testing.T) {
+	tests := []struct {
+		Desc               string
+		PreviousCheckpoint bool
+		ContainerGone      bool
+		CheckpointErr      error
+		ExpectErr          bool
+		ExpectCheckpoint   bool
+	}{
+		{
+			Desc:             "no previous checkpoint",
+			ExpectCheckpoint: true,
+		},
+		{
+			Desc:               "replaces previous checkpoint",
+			PreviousCheckpoint: true,
+			ExpectCheckpoint:   true,
+		},
+		{
+			Desc:               "failed checkpoint removes stale one",
+			PreviousCheckpoint: true,
+			CheckpointErr:      xerrors.Errorf("criu failed"),
+			ExpectErr:          true,
+		},
+		{
+			Desc:               "container gone removes stale one",
+			PreviousCheckpoint: true,
+			ContainerGone:      true,
+			ExpectErr:          true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			rs := &fakeCheckpointStorage{Objects: make(map[string]string)}
+			if test.PreviousCheckpoint {
+				rs.Objects[storage.DefaultCheckpoint] = "stale"
+			}
+			rt := &fakeCheckpointRuntime{ContainerGone: test.ContainerGone, CheckpointErr: test.CheckpointErr}
+			svc, sess := newCheckpointTestService(t, rt, rs)
+
+			err := svc.checkpointWorkspace(context.Background(), sess)
+			if (err != nil) != test.ExpectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, exists := rs.Objects[storage.DefaultCheckpoint]
+			if exists != test.ExpectCheckpoint {
+				t.Errorf("checkpoint exists: %v, expected %v", exists, test.ExpectCheckpoint)
+			}
+			if exists && rs.Objects[storage.DefaultCheckpoint] == "stale" {
+				t.Error("previous checkpoint was not replaced")
+			}
+			if _, err := os.Stat(filepath.Join(sess.ServiceLocDaemon, checkpointDir)); !os.IsNotExist(err) {
+				t.Error("checkpoint location was not cleaned up")
+			}
+		})
+	}
+}
+
+func TestRestoreWorkspace(t *testing.T) {
+	tests := []struct {
+		Desc          string
+		Checkpoint    bool
+		ContainerGone bool
+		RestoreErr    error
+		ExpectRestore bool
+	}{
+		{
+			Desc:          "restores checkpoint",
+			Checkpoint:    true,
+			ExpectRestore: true,
+		},
+		{
+			Desc: "no checkpoint",
+		},
+		{
+			Desc:          "container does not appear",
+			Checkpoint:    true,
+			ContainerGone: true,
+		},
+		{
+			Desc:          "failed restore",
+			Checkpoint:    true,
+			RestoreErr:    xerrors.Errorf("criu failed"),
+			ExpectRestore: true,
+		},
+		{
+			Desc:          "runtime cannot restore",
+			Checkpoint:    true,
+			RestoreErr:    container.ErrCheckpointUnsupported,
+			ExpectRestore: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Desc, func(t *testing.T) {
+			rs := &fakeCheckpointStorage{Objects: make(map[string]string)}
+			if test.Checkpoint {
+				rs.Objects[storage.DefaultCheckpoint] = "checkpoint"
+			}
+			rt := &fakeCheckpointRuntime{ContainerGone: test.ContainerGone, RestoreErr: test.RestoreErr}
+			svc, sess := newCheckpointTestService(t, rt, rs)
+
+			svc.restoreWorkspace(context.Background(), sess)
+
+			var expectRestored []string
+			if test.ExpectRestore {
+				expectRestored = []string{"checkpoint"}
+			}
+			if diff := cmp.Diff(expectRestored, rt.Restored); diff != "" {
+				t.Errorf("unexpected restored images (-want +got):\n%s", diff)
+			}
+			// a checkpoint must never be restored twice, even if restoring it failed
+			if _, exists := rs.Objects[storage.DefaultCheckpoint]; exists {
+				t.Error("checkpoint was not deleted after download")
+			}
+		})
+	}
+}
+
+func newCheckpointTestService(t *testing.T, rt container.Runtime, rs storage.DirectAccess) (*WorkspaceService, *session.Workspace) {
+	loc := t.TempDir()
+	svc := &WorkspaceService{
+		config:  Config{TmpDir: t.TempDir()},
+		runtime: rt,
+	}
+	sess := &session.Workspace{
+		InstanceID:       "instance",
+		ServiceLocDaemon: loc,
+		ServiceLocNode:   loc,
+		NonPersistentAttrs: map[string]interface{}{
+			session.AttrRemoteStorage: rs,
+		},
+	}
+	return svc, sess
+}
+
+// fakeCheckpointRuntime writes a checkpoint image containing "checkpoint" and records the images it restores
+type fakeCheckpointRuntime struct {
+	container.Runtime
+
+	ContainerGone bool
+	CheckpointErr error
+	RestoreErr    error
+
+	Restored []string
+}
+
+func (rt *fakeCheckpointRuntime) WaitForContainer(ctx context.Context, workspaceInstanceID string) (id container.ID, err error) {
+	if rt.ContainerGone {
+		return "", context.DeadlineExceeded
+	}
+	return container.ID(workspaceInstanceID), nil
+}
+
+func (rt *fakeCheckpointRuntime) CheckpointContainer(ctx context.Context, id container.ID, opts container.OptsCheckpoint) error {
+	if rt.CheckpointErr != nil {
+		return rt.CheckpointErr
+	}
+	return os.WriteFile(filepath.Join(opts.ImagePath, checkpointImageFile), []byte("checkpoint"), 0644)
+}
+
+func (rt *fakeCheckpointRuntime) RestoreContainer(ctx context.Context, id container.ID, opts container.OptsRestore) error {
+	content, err := os.ReadFile(filepath.Join(opts.ImagePath, checkpointImageFile))
+	if err != nil {
+		return err
+	}
+	rt.Restored = append(rt.Restored, string(content))
+	return rt.RestoreErr
+}
+
+// fakeCheckpointStorage keeps the content of the checkpoint image per object name
+type fakeCheckpointStorage struct {
+	storage.DirectAccess
+
+	Objects map[string]string
+}
+
+func (rs *fakeCheckpointStorage) Upload(ctx context.Context, source string, name string, opts ...storage.UploadOption) (bucket, obj string, err error) {
+	dst, err := os.MkdirTemp("", "checkpoint-upload")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(dst)
+
+	f, err := os.Open(source)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	err = archive.ExtractTarbal(ctx, f, dst)
+	if err != nil {
+		return "", "", err
+	}
+	content, err := os.ReadFile(filepath.Join(dst, checkpointImageFile))
+	if err != nil {
+		return "", "", err
+	}
+
+	rs.Objects[name] = string(content)
+	return "bucket", name, nil
+}
+
+func (rs *fakeCheckpointStorage) Download(ctx context.Context, destination string, name string, mappings []archive.IDMapping) (bool, error) {
+	content, exists := rs.Objects[name]
+	if !exists {
+		return false, nil
+	}
+	err := os.MkdirAll(destination, 0755)
+	if err != nil {
+		return true, err
+	}
+	return true, os.WriteFile(filepath.Join(destination, checkpointImageFile), []byte(content), 0644)
+}
+
+func (rs *fakeCheckpointStorage) Delete(ctx context.Context, name string) error {
+	delete(rs.Objects, name)
+	return nil
+}
@@ -364,6 +364,11 @@ func (rs *remoteContentStorage) Upload(ctx context.Context, source string, name
 	return "", "", fmt.Errorf("not implemented")
 }
 
+// Delete does nothing
+func (rs *remoteContentStorage) Delete(ctx context.Context, name string) error {
+	return fmt.Errorf("not implemented")
+}
+
 // UploadInstance takes all files from a local location and uploads it to the remote storage
 func (rs *remoteContentStorage) UploadInstance(ctx context.Context, source string, name string, options ...storage.UploadOption) (bucket, obj string, err error) {
 	return "", "", fmt.Errorf("not implemented")
@@ -238,6 +238,11 @@ func (s *WorkspaceService) InitWorkspace(ctx context.Context, req *api.InitWorks
 		return nil, status.Error(codes.Internal, fmt.Sprintf("cannot finish workspace init: %v", err))
 	}
 
+	if req.RestoreCheckpoint && !req.RemoteStorageDisabled {
+		// The checkpoint can only be restored into the workspace container, which won't start before we're done here.
+		go s.restoreWorkspace(s.ctx, workspace)
+	}
+
 	return &api.InitWorkspaceResponse{}, nil
 }
 
@@ -705,6 +710,37 @@ func (s *WorkspaceService) TakeSnapshot(ctx context.Context, req *api.TakeSnapsh
 	}, nil
 }
 
+// CheckpointWorkspace dumps the processes of a running workspace and stores them alongside its backup.
+// This has to happen before the workspace pod is deleted, i.e. while the workspace container still runs.
+func (s *WorkspaceService) CheckpointWorkspace(ctx context.Context, req *api.CheckpointWorkspaceRequest) (res *api.CheckpointWorkspaceResponse, err error) {
+	//nolint:ineffassign
+	span, ctx := opentracing.StartSpanFromContext(ctx, "CheckpointWorkspace")
+	span.SetTag("workspace", req.Id)
+	defer tracing.FinishSpan(span, &err)
+
+	sess := s.store.Get(req.Id)
+	if sess == nil {
+		return nil, status.Error(codes.NotFound, "workspace does not exist")
+	}
+	if !sess.IsReady() {
+		return nil, status.Error(codes.FailedPrecondition, "workspace is not ready")
+	}
+	if sess.RemoteStorageDisabled {
+		return nil, status.Error(codes.FailedPrecondition, "workspace has no remote storage")
+	}
+
+	err = s.checkpointWorkspace(ctx, sess)
+	if errors.Is(err, container.ErrCheckpointUnsupported) {
+		return nil, status.Error(codes.Unimplemented, "container runtime cannot checkpoint workspace processes")
+	}
+	if err != nil {
+		log.WithError(err).WithFields(sess.OWI()).Error("cannot checkpoint workspace processes")
+		return nil, status.Error(codes.Internal, "cannot checkpoint workspace processes")
+	}
+
+	return &api.CheckpointWorkspaceResponse{}, nil
+}
+
 // Close ends this service and its housekeeping
 func (s *WorkspaceService) Close() error {
 	s.stopService()
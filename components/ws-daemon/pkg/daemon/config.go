@@ -8,8 +8,10 @@ import (
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/content"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/diskguard"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/firewall"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/hosts"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/iws"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/netlimit"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/resources"
 )
 
@@ -32,4 +34,6 @@ type Config struct {
 	Resources      resources.Config    `json:"resources"`
 	Hosts          hosts.Config        `json:"hosts"`
 	DiskSpaceGuard diskguard.Config    `json:"disk"`
+	NetLimit       netlimit.Config     `json:"netlimit"`
+	Firewall       firewall.Config     `json:"firewall"`
 }
@@ -0,0 +1,380 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package firewall
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	wsk8s "github.com/gitpod-io/gitpod/common-go/kubernetes"
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/common-go/util"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/dispatch"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/nsinsider"
+)
+
+const (
+	// ActionAllow lets connections pass
+	ActionAllow = "EGRESS_ACTION_ALLOW"
+	// ActionDeny rejects connections
+	ActionDeny = "EGRESS_ACTION_DENY"
+
+	defaultInterval = 30 * time.Second
+
+	resolvConf = "/etc/resolv.conf"
+)
+
+// Config configures the egress firewall
+type Config struct {
+	// Enabled enables the egress firewall. If false, the egress policy annotation is ignored.
+	Enabled bool `json:"enabled"`
+
+	// Interval is the period in which we re-resolve the policy destinations and check for violations
+	Interval util.Duration `json:"interval,omitempty"`
+
+	// Resolvers are the DNS servers workspaces may always reach on port 53, typically the cluster DNS.
+	// Defaults to the nameservers in ws-daemon's /etc/resolv.conf. DNS traffic to any other server is
+	// subject to the egress policy like all other traffic.
+	Resolvers []string `json:"resolvers,omitempty"`
+}
+
+// Policy is the egress policy of a workspace as serialized by ws-manager
+type Policy struct {
+	DefaultAction string `json:"defaultAction,omitempty"`
+	Rules         []Rule `json:"rules,omitempty"`
+}
+
+// Rule is a single rule of an egress policy.
+//
+// Hostname destinations are resolved by ws-daemon, not the workspace, and re-resolved every Config.Interval.
+// The rule only matches the addresses ws-daemon's resolver returns, hence hosts whose addresses rotate faster
+// than that, or differ per client (e.g. CDNs), may be reachable by the workspace under addresses the rule misses.
+type Rule struct {
+	Action      string   `json:"action,omitempty"`
+	Destination string   `json:"destination"`
+	Ports       []uint32 `json:"ports,omitempty"`
+}
+
+// PolicyFromAnnotations parses the egress policy from the workspace pod annotations.
+// Returns nil if the workspace has no egress policy.
+func PolicyFromAnnotations(annotations map[string]string) (*Policy, error) {
+	v, ok := annotations[wsk8s.EgressPolicyAnnotation]
+	if !ok || v == "" {
+		return nil, nil
+	}
+
+	var res Policy
+	err := json.Unmarshal([]byte(v), &res)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot parse %s: %w", wsk8s.EgressPolicyAnnotation, err)
+	}
+	if !isValidAction(res.DefaultAction) {
+		return nil, xerrors.Errorf("invalid default action: %s", res.DefaultAction)
+	}
+	for i, r := range res.Rules {
+		if !isValidAction(r.Action) {
+			return nil, xerrors.Errorf("rule %d: invalid action: %s", i, r.Action)
+		}
+		if r.Destination == "" {
+			return nil, xerrors.Errorf("rule %d: destination is required", i)
+		}
+	}
+	return &res, nil
+}
+
+func isValidAction(action string) bool {
+	return action == "" || action == ActionAllow || action == ActionDeny
+}
+
+// resolvedPolicy is the policy as nsinsider expects it, with all destinations resolved to IP addresses or CIDR ranges
+type resolvedPolicy struct {
+	DefaultAction string         `json:"defaultAction"`
+	Rules         []resolvedRule `json:"rules"`
+	Resolvers     []string       `json:"resolvers,omitempty"`
+}
+
+type resolvedRule struct {
+	Action   string   `json:"action"`
+	Networks []string `json:"networks"`
+	Ports    []uint32 `json:"ports,omitempty"`
+}
+
+// LookupFunc resolves a hostname to its IP addresses
+type LookupFunc func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+// resolve resolves the hostnames of the policy destinations.
+//
+// If an allow rule's hostname cannot be resolved we drop that rule, which makes the policy stricter.
+// An unresolvable deny rule would weaken the policy, hence we fail instead.
+func (p *Policy) resolve(ctx context.Context, lookup LookupFunc, resolvers []string) (res *resolvedPolicy, err error) {
+	res = &resolvedPolicy{
+		DefaultAction: nsinsiderAction(p.DefaultAction),
+		Rules:         make([]resolvedRule, 0, len(p.Rules)),
+		Resolvers:     resolvers,
+	}
+	for _, r := range p.Rules {
+		var networks []string
+		if _, _, err := net.ParseCIDR(r.Destination); err == nil {
+			networks = []string{r.Destination}
+		} else if ip := net.ParseIP(r.Destination); ip != nil {
+			networks = []string{ip.String()}
+		} else {
+			addrs, err := lookup(ctx, r.Destination)
+			if err != nil && r.Action == ActionDeny {
+				return nil, xerrors.Errorf("cannot resolve %s: %w", r.Destination, err)
+			}
+			if err != nil {
+				log.WithError(err).WithField("destination", r.Destination).Warn("cannot resolve egress policy destination - ignoring rule")
+				continue
+			}
+			for _, addr := range addrs {
+				networks = append(networks, addr.IP.String())
+			}
+			// resolvers rotate the order of addresses - we don't want that to cause a re-install
+			sort.Strings(networks)
+		}
+
+		res.Rules = append(res.Rules, resolvedRule{
+			Action:   nsinsiderAction(r.Action),
+			Networks: networks,
+			Ports:    r.Ports,
+		})
+	}
+	return res, nil
+}
+
+func nsinsiderAction(action string) string {
+	if action == ActionDeny {
+		return "deny"
+	}
+	return "allow"
+}
+
+// readNameservers returns the nameserver addresses listed in a resolv.conf file
+func readNameservers(r io.Reader) ([]string, error) {
+	var res []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		ip := net.ParseIP(fields[1])
+		if ip == nil {
+			continue
+		}
+		res = append(res, ip.String())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NewDispatchListener creates a new egress firewall dispatch listener
+func NewDispatchListener(cfg *Config, prom prometheus.Registerer) *DispatchListener {
+	resolvers := cfg.Resolvers
+	if cfg.Enabled && len(resolvers) == 0 {
+		f, err := os.Open(resolvConf)
+		if err == nil {
+			resolvers, err = readNameservers(f)
+			f.Close()
+		}
+		if err != nil {
+			log.WithError(err).Warn("cannot read nameservers - workspaces with an egress policy can only use the DNS servers the policy allows")
+		}
+	}
+
+	d := &DispatchListener{
+		Config:    cfg,
+		Lookup:    net.DefaultResolver.LookupIPAddr,
+		Resolvers: resolvers,
+		violations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "gitpod",
+			Subsystem: "ws_daemon",
+			Name:      "egress_violations_total",
+			Help:      "The total amount of connection attempts rejected by workspace egress policies.",
+		}),
+	}
+	prom.MustRegister(d.violations)
+
+	return d
+}
+
+// DispatchListener installs the egress firewall of workspace pods within the workspace's network namespace,
+// and reports violations of the egress policy
+type DispatchListener struct {
+	Config *Config
+	Lookup LookupFunc
+
+	// Resolvers are the DNS servers workspaces may always reach
+	Resolvers []string
+
+	violations prometheus.Counter
+}
+
+// WorkspaceAdded installs the egress firewall of a workspace and starts watching for violations
+func (d *DispatchListener) WorkspaceAdded(ctx context.Context, ws *dispatch.Workspace) error {
+	if !d.Config.Enabled {
+		return nil
+	}
+
+	policy, err := PolicyFromAnnotations(ws.Pod.Annotations)
+	if err != nil {
+		return xerrors.Errorf("cannot install egress firewall: %w", err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	disp := dispatch.GetFromContext(ctx)
+	if disp == nil {
+		return xerrors.Errorf("no dispatch available")
+	}
+	pid, err := disp.Runtime.ContainerPID(context.Background(), ws.ContainerID)
+	if err != nil {
+		return xerrors.Errorf("cannot find workspace container PID: %w", err)
+	}
+
+	installed, err := d.install(ctx, ws, int(pid), policy, "")
+	if err != nil {
+		return err
+	}
+
+	interval := time.Duration(d.Config.Interval)
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		var total uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			d.checkViolations(ctx, disp, ws, int(pid), &total)
+
+			// DNS records change, hence we re-install the firewall when the destinations resolve differently.
+			// Re-installing the firewall resets the violation counter, which is why we check for violations first.
+			ninstalled, err := d.install(ctx, ws, int(pid), policy, installed)
+			if err != nil {
+				log.WithError(err).WithFields(ws.OWI()).Warn("cannot update egress firewall")
+				continue
+			}
+			installed = ninstalled
+		}
+	}()
+
+	return nil
+}
+
+// checkViolations reads the violations since the last check, and reports them if there were any
+func (d *DispatchListener) checkViolations(ctx context.Context, disp *dispatch.Dispatch, ws *dispatch.Workspace, pid int, total *uint64) {
+	n, err := readViolations(ws, pid)
+	if err != nil {
+		log.WithError(err).WithFields(ws.OWI()).Warn("cannot read egress policy violations")
+		return
+	}
+	if n == 0 {
+		return
+	}
+	d.violations.Add(float64(n))
+	*total += n
+
+	log.WithFields(ws.OWI()).WithField("violations", n).Info("egress policy rejected connections")
+	err = reportViolation(ctx, disp, ws, fmt.Sprintf("egress policy blocked %d connection attempts", *total))
+	if err != nil {
+		log.WithError(err).WithFields(ws.OWI()).Warn("cannot report egress policy violation")
+	}
+}
+
+// install resolves and installs the policy unless it resolves to the one that's currently installed.
+// Returns the installed resolved policy.
+func (d *DispatchListener) install(ctx context.Context, ws *dispatch.Workspace, pid int, policy *Policy, current string) (installed string, err error) {
+	resolved, err := policy.resolve(ctx, d.Lookup, d.Resolvers)
+	if err != nil {
+		return "", xerrors.Errorf("cannot install egress firewall: %w", err)
+	}
+	rawPolicy, err := json.Marshal(resolved)
+	if err != nil {
+		return "", xerrors.Errorf("cannot install egress firewall: %w", err)
+	}
+	if string(rawPolicy) == current {
+		return current, nil
+	}
+
+	err = nsinsider.Nsinsider(ws.InstanceID, pid, func(c *exec.Cmd) {
+		c.Args = append(c.Args, "setup-egress-firewall", "--policy", string(rawPolicy))
+	}, nsinsider.EnterMountNS(false), nsinsider.EnterNetNS(true))
+	if err != nil {
+		return "", xerrors.Errorf("cannot install egress firewall: %w", err)
+	}
+
+	log.WithFields(ws.OWI()).WithField("policy", string(rawPolicy)).Info("installed egress firewall")
+	return string(rawPolicy), nil
+}
+
+// readViolations returns the number of connections the egress firewall rejected since the last call
+func readViolations(ws *dispatch.Workspace, pid int) (uint64, error) {
+	var out bytes.Buffer
+	err := nsinsider.Nsinsider(ws.InstanceID, pid, func(c *exec.Cmd) {
+		c.Args = append(c.Args, "egress-violations")
+		c.Stdout = &out
+	}, nsinsider.EnterMountNS(false), nsinsider.EnterNetNS(true))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseUint(strings.TrimSpace(out.String()), 10, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("cannot parse violation count: %w", err)
+	}
+	return n, nil
+}
+
+// reportViolation sets the egress violation annotation on the workspace pod, which ws-manager turns into a workspace condition
+func reportViolation(ctx context.Context, disp *dispatch.Dispatch, ws *dispatch.Workspace, msg string) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		pods := disp.Kubernetes.CoreV1().Pods(disp.KubernetesNamespace)
+		pod, err := pods.Get(ctx, ws.Pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if pod.Annotations[wsk8s.EgressViolationAnnotation] == msg {
+			return nil
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[wsk8s.EgressViolationAnnotation] = msg
+
+		_, err = pods.Update(ctx, pod, metav1.UpdateOptions{})
+		return err
+	})
+}
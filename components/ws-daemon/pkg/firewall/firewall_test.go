@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package firewall
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+
+	wsk8s "github.com/gitpod-io/gitpod/common-go/kubernetes"
+)
+
+func TestPolicyFromAnnotations(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Annotations map[string]string
+		Expectation *Policy
+		Error       bool
+	}{
+		{Name: "no annotation"},
+		{
+			Name: "valid policy",
+			Annotations: map[string]string{
+				wsk8s.EgressPolicyAnnotation: `{"defaultAction":"EGRESS_ACTION_DENY","rules":[{"destination":"git.example.com","ports":[443]}]}`,
+			},
+			Expectation: &Policy{
+				DefaultAction: ActionDeny,
+				Rules:         []Rule{{Destination: "git.example.com", Ports: []uint32{443}}},
+			},
+		},
+		{
+			Name:        "invalid JSON",
+			Annotations: map[string]string{wsk8s.EgressPolicyAnnotation: `{`},
+			Error:       true,
+		},
+		{
+			Name:        "invalid action",
+			Annotations: map[string]string{wsk8s.EgressPolicyAnnotation: `{"rules":[{"action":"MAYBE","destination":"10.0.0.1"}]}`},
+			Error:       true,
+		},
+		{
+			Name:        "missing destination",
+			Annotations: map[string]string{wsk8s.EgressPolicyAnnotation: `{"rules":[{"action":"EGRESS_ACTION_DENY"}]}`},
+			Error:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act, err := PolicyFromAnnotations(test.Annotations)
+			if (err != nil) != test.Error {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected policy (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	lookup := func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "git.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("10.0.0.2")}, {IP: net.ParseIP("10.0.0.1")}}, nil
+		default:
+			return nil, xerrors.Errorf("no such host")
+		}
+	}
+
+	tests := []struct {
+		Name        string
+		Policy      Policy
+		Resolvers   []string
+		Expectation *resolvedPolicy
+		Error       bool
+	}{
+		{
+			Name: "addresses and networks",
+			Policy: Policy{
+				DefaultAction: ActionDeny,
+				Rules: []Rule{
+					{Destination: "192.168.0.0/16", Ports: []uint32{22}},
+					{Action: ActionDeny, Destination: "fd00::1"},
+				},
+			},
+			Expectation: &resolvedPolicy{
+				DefaultAction: "deny",
+				Rules: []resolvedRule{
+					{Action: "allow", Networks: []string{"192.168.0.0/16"}, Ports: []uint32{22}},
+					{Action: "deny", Networks: []string{"fd00::1"}},
+				},
+			},
+		},
+		{
+			Name:   "hostname",
+			Policy: Policy{Rules: []Rule{{Destination: "git.example.com"}}},
+			Expectation: &resolvedPolicy{
+				DefaultAction: "allow",
+				Rules:         []resolvedRule{{Action: "allow", Networks: []string{"10.0.0.1", "10.0.0.2"}}},
+			},
+		},
+		{
+			Name:      "resolvers",
+			Policy:    Policy{DefaultAction: ActionDeny},
+			Resolvers: []string{"10.96.0.10"},
+			Expectation: &resolvedPolicy{
+				DefaultAction: "deny",
+				Rules:         []resolvedRule{},
+				Resolvers:     []string{"10.96.0.10"},
+			},
+		},
+		{
+			Name:   "unresolvable allow rule",
+			Policy: Policy{DefaultAction: ActionDeny, Rules: []Rule{{Destination: "unknown.example.com"}}},
+			Expectation: &resolvedPolicy{
+				DefaultAction: "deny",
+				Rules:         []resolvedRule{},
+			},
+		},
+		{
+			Name:   "unresolvable deny rule",
+			Policy: Policy{Rules: []Rule{{Action: ActionDeny, Destination: "unknown.example.com"}}},
+			Error:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act, err := test.Policy.resolve(context.Background(), lookup, test.Resolvers)
+			if (err != nil) != test.Error {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.Error {
+				return
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected resolved policy (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadNameservers(t *testing.T) {
+	resolvConf := `# generated by kubelet
+nameserver 10.96.0.10
+nameserver fd00::a
+nameserver not-an-ip
+search default.svc.cluster.local svc.cluster.local
+options ndots:5
+`
+	act, err := readNameservers(strings.NewReader(resolvConf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"10.96.0.10", "fd00::a"}, act); diff != "" {
+		t.Errorf("unexpected nameservers (-want +got):\n%s", diff)
+	}
+}
@@ -138,6 +138,7 @@ func (g *DirectController) updateHostsFile(inc <-chan hostUpdate) {
 		select {
 		case <-g.stop:
 			defer log.Info("hosts updater shutting down")
+			_ = g.hostsFD.Close()
 			return
 		case update = <-inc:
 		}
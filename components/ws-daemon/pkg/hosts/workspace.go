@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package hosts
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+const maxHostnameLength = 253
+
+var hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*$`)
+
+// Validate ensures the host can be written to a hosts file, i.e. that the address is an IP address
+// and the name a valid hostname.
+func (h Host) Validate() error {
+	if net.ParseIP(h.Addr) == nil {
+		return xerrors.Errorf("%q is not a valid IP address", h.Addr)
+	}
+	if len(h.Name) > maxHostnameLength || !hostnameRegexp.MatchString(h.Name) {
+		return xerrors.Errorf("%q is not a valid hostname", h.Name)
+	}
+	return nil
+}
+
+// NewWorkspaceSource creates a new source for hosts which are set from within a workspace
+func NewWorkspaceSource(alias string) *WorkspaceSource {
+	return &WorkspaceSource{
+		Alias: alias,
+		c:     make(chan []Host),
+		stop:  make(chan struct{}),
+	}
+}
+
+// WorkspaceSource is a host source whose hosts change during the lifetime of a workspace.
+// Every call to Set replaces the hosts this source provides.
+type WorkspaceSource struct {
+	Alias string
+
+	c        chan []Host
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// Name returns the ID of this source
+func (ws *WorkspaceSource) Name() string {
+	return ws.Alias
+}
+
+// Start starts the source
+func (ws *WorkspaceSource) Start() error {
+	return nil
+}
+
+// Source provides hosts on the channel
+func (ws *WorkspaceSource) Source() <-chan []Host {
+	return ws.c
+}
+
+// Stop stops this source from providing hosts
+func (ws *WorkspaceSource) Stop() {
+	ws.stopOnce.Do(func() {
+		close(ws.stop)
+	})
+}
+
+// Set replaces the hosts this source provides. Set blocks until the hosts were handed to the controller.
+func (ws *WorkspaceSource) Set(ctx context.Context, hosts []Host) error {
+	if hosts == nil {
+		// a nil update is the signal for controllers that a source is done
+		hosts = []Host{}
+	}
+
+	select {
+	case ws.c <- hosts:
+		return nil
+	case <-ws.stop:
+		return xerrors.Errorf("hosts source %s is stopped", ws.Alias)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
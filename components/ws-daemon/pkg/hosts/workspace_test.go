@@ -0,0 +1,36 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHostValidate(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Host  Host
+		Valid bool
+	}{
+		{Name: "ipv4", Host: Host{Addr: "10.0.0.1", Name: "db.example.com"}, Valid: true},
+		{Name: "ipv6", Host: Host{Addr: "fd00::1", Name: "db"}, Valid: true},
+		{Name: "invalid address", Host: Host{Addr: "10.0.0", Name: "db.example.com"}},
+		{Name: "empty name", Host: Host{Addr: "10.0.0.1"}},
+		{Name: "name with whitespace", Host: Host{Addr: "10.0.0.1", Name: "db example.com"}},
+		{Name: "name with newline", Host: Host{Addr: "10.0.0.1", Name: "db\n10.0.0.2 example.com"}},
+		{Name: "name with comment", Host: Host{Addr: "10.0.0.1", Name: "db#example.com"}},
+		{Name: "name too long", Host: Host{Addr: "10.0.0.1", Name: strings.Repeat("a.", 127) + "a"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := test.Host.Validate()
+			if (err == nil) != test.Valid {
+				t.Errorf("unexpected validation result: valid=%v, err=%v", test.Valid, err)
+			}
+		})
+	}
+}
@@ -30,7 +30,9 @@ import (
 	wsinit "github.com/gitpod-io/gitpod/content-service/pkg/initializer"
 	"github.com/gitpod-io/gitpod/ws-daemon/api"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/container"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/hosts"
 	"github.com/gitpod-io/gitpod/ws-daemon/pkg/internal/session"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/nsinsider"
 )
 
 //
@@ -75,6 +77,11 @@ var (
 	}
 )
 
+const (
+	// maxHostEntries is the maximum number of hosts a workspace can add to its /etc/hosts file
+	maxHostEntries = 256
+)
+
 // ServeWorkspace establishes the IWS server for a workspace
 func ServeWorkspace(uidmapper *Uidmapper, fsshift api.FSShiftMethod) func(ctx context.Context, ws *session.Workspace) error {
 	return func(ctx context.Context, ws *session.Workspace) (err error) {
@@ -133,6 +140,10 @@ type InWorkspaceServiceServer struct {
 	srv  *grpc.Server
 	sckt io.Closer
 
+	hostsMu  sync.Mutex
+	hostsCtl *hosts.DirectController
+	hostsSrc *hosts.WorkspaceSource
+
 	api.UnimplementedInWorkspaceServiceServer
 }
 
@@ -172,6 +183,9 @@ func (wbs *InWorkspaceServiceServer) Start() error {
 		"/iws.InWorkspaceService/Teardown": ratelimit{
 			UseOnce: true,
 		},
+		"/iws.InWorkspaceService/SetHosts": ratelimit{
+			Limiter: rate.NewLimiter(rate.Every(time.Second), 5),
+		},
 	}
 
 	wbs.srv = grpc.NewServer(grpc.ChainUnaryInterceptor(limits.UnaryInterceptor()))
@@ -189,6 +203,13 @@ func (wbs *InWorkspaceServiceServer) Start() error {
 func (wbs *InWorkspaceServiceServer) Stop() {
 	defer wbs.sckt.Close()
 	wbs.srv.GracefulStop()
+
+	wbs.hostsMu.Lock()
+	defer wbs.hostsMu.Unlock()
+	if wbs.hostsCtl != nil {
+		wbs.hostsCtl.Close()
+		wbs.hostsCtl = nil
+	}
 }
 
 // PrepareForUserNS mounts the workspace's shiftfs mark
@@ -222,14 +243,14 @@ func (wbs *InWorkspaceServiceServer) PrepareForUserNS(ctx context.Context, req *
 	// Development leading up to this point:
 	//   - https://lists.linuxcontainers.org/pipermail/lxc-devel/2014-July/009797.html
 	//   - https://lists.linuxcontainers.org/pipermail/lxc-users/2014-October/007948.html
-	err = nsinsider(wbs.Session.InstanceID, int(containerPID), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(containerPID), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "mknod-fuse", "--uid", strconv.Itoa(wsinit.GitpodUID), "--gid", strconv.Itoa(wsinit.GitpodGID))
 	})
 	if err != nil {
 		log.WithError(err).WithFields(wbs.Session.OWI()).Error("PrepareForUserNS: cannot mknod fuse")
 		return nil, status.Errorf(codes.Internal, "cannot prepare FUSE")
 	}
-	err = nsinsider(wbs.Session.InstanceID, int(containerPID), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(containerPID), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "mknod-devnettun")
 	})
 	if err != nil {
@@ -245,7 +266,7 @@ func (wbs *InWorkspaceServiceServer) PrepareForUserNS(ctx context.Context, req *
 	mountpoint := filepath.Join(wbs.Session.ServiceLocNode, "mark")
 
 	if wbs.FSShift == api.FSShiftMethod_FUSE || wbs.Session.FullWorkspaceBackup {
-		err = nsinsider(wbs.Session.InstanceID, int(1), func(c *exec.Cmd) {
+		err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(1), func(c *exec.Cmd) {
 			// In case of any change in the user mapping, the next line must be updated.
 			mappings := fmt.Sprintf("0:%v:1:1:100000:65534", wsinit.GitpodUID)
 			c.Args = append(c.Args, "mount-fusefs-mark",
@@ -270,7 +291,7 @@ func (wbs *InWorkspaceServiceServer) PrepareForUserNS(ctx context.Context, req *
 
 	// We cannot use the nsenter syscall here because mount namespaces affect the whole process, not just the current thread.
 	// That's why we resort to exec'ing "nsenter ... mount ...".
-	err = nsinsider(wbs.Session.InstanceID, int(1), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(1), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "make-shared", "--target", "/")
 	})
 	if err != nil {
@@ -279,7 +300,7 @@ func (wbs *InWorkspaceServiceServer) PrepareForUserNS(ctx context.Context, req *
 	}
 	log.WithField("containerPID", containerPID).Info("mount shared")
 
-	err = nsinsider(wbs.Session.InstanceID, int(1), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(1), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "mount-shiftfs-mark", "--source", rootfs, "--target", mountpoint)
 	})
 	if err != nil {
@@ -333,9 +354,9 @@ func (wbs *InWorkspaceServiceServer) MountProc(ctx context.Context, req *api.Mou
 	if err != nil {
 		return nil, xerrors.Errorf("cannot prepare proc staging: %w", err)
 	}
-	err = nsinsider(wbs.Session.InstanceID, int(procPID), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(procPID), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "mount-proc", "--target", nodeStaging)
-	}, enterMountNS(false), enterPidNS(true))
+	}, nsinsider.EnterMountNS(false), nsinsider.EnterPidNS(true))
 	if err != nil {
 		return nil, xerrors.Errorf("mount new proc at %s: %w", nodeStaging, err)
 	}
@@ -479,7 +500,7 @@ func (wbs *InWorkspaceServiceServer) UmountProc(ctx context.Context, req *api.Um
 		return nil, err
 	}
 
-	err = nsinsider(wbs.Session.InstanceID, int(procPID), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(procPID), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "open-tree", "--target", req.Target, "--pipe-fd", "3")
 		c.ExtraFiles = append(c.ExtraFiles, connFD)
 	})
@@ -552,9 +573,9 @@ func (wbs *InWorkspaceServiceServer) MountSysfs(ctx context.Context, req *api.Mo
 	if err != nil {
 		return nil, xerrors.Errorf("cannot prepare proc staging: %w", err)
 	}
-	err = nsinsider(wbs.Session.InstanceID, int(procPID), func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(wbs.Session.InstanceID, int(procPID), func(c *exec.Cmd) {
 		c.Args = append(c.Args, "mount-sysfs", "--target", nodeStaging)
-	}, enterMountNS(false), enterNetNS(true))
+	}, nsinsider.EnterMountNS(false), nsinsider.EnterNetNS(true))
 	if err != nil {
 		return nil, xerrors.Errorf("mount new sysfs at %s: %w", nodeStaging, err)
 	}
@@ -584,101 +605,16 @@ func moveMount(instanceID string, targetPid int, source, target string) error {
 
 	// Note(cw): we also need to enter the target PID namespace because the mount target
 	// 			 might refer to proc.
-	err = nsinsider(instanceID, targetPid, func(c *exec.Cmd) {
+	err = nsinsider.Nsinsider(instanceID, targetPid, func(c *exec.Cmd) {
 		c.Args = append(c.Args, "move-mount", "--target", target, "--pipe-fd", "3")
 		c.ExtraFiles = append(c.ExtraFiles, mntf)
-	}, enterPidNS(true))
+	}, nsinsider.EnterPidNS(true))
 	if err != nil {
 		return xerrors.Errorf("cannot move mount: %w", err)
 	}
 	return nil
 }
 
-type nsinsiderOpts struct {
-	MountNS bool
-	PidNS   bool
-	NetNS   bool
-}
-
-func enterMountNS(enter bool) nsinsiderOpt {
-	return func(o *nsinsiderOpts) {
-		o.MountNS = enter
-	}
-}
-
-func enterPidNS(enter bool) nsinsiderOpt {
-	return func(o *nsinsiderOpts) {
-		o.PidNS = enter
-	}
-}
-
-func enterNetNS(enter bool) nsinsiderOpt {
-	return func(o *nsinsiderOpts) {
-		o.NetNS = enter
-	}
-}
-
-type nsinsiderOpt func(*nsinsiderOpts)
-
-func nsinsider(instanceID string, targetPid int, mod func(*exec.Cmd), opts ...nsinsiderOpt) error {
-	cfg := nsinsiderOpts{
-		MountNS: true,
-	}
-	for _, o := range opts {
-		o(&cfg)
-	}
-
-	base, err := os.Executable()
-	if err != nil {
-		return err
-	}
-
-	type mnt struct {
-		Env    string
-		Source string
-		Flags  int
-	}
-	var nss []mnt
-	if cfg.MountNS {
-		nss = append(nss,
-			mnt{"_LIBNSENTER_ROOTFD", fmt.Sprintf("/proc/%d/root", targetPid), unix.O_PATH},
-			mnt{"_LIBNSENTER_CWDFD", fmt.Sprintf("/proc/%d/cwd", targetPid), unix.O_PATH},
-			mnt{"_LIBNSENTER_MNTNSFD", fmt.Sprintf("/proc/%d/ns/mnt", targetPid), os.O_RDONLY},
-		)
-	}
-	if cfg.PidNS {
-		nss = append(nss, mnt{"_LIBNSENTER_PIDNSFD", fmt.Sprintf("/proc/%d/ns/pid", targetPid), os.O_RDONLY})
-	}
-	if cfg.NetNS {
-		nss = append(nss, mnt{"_LIBNSENTER_NETNSFD", fmt.Sprintf("/proc/%d/ns/net", targetPid), os.O_RDONLY})
-	}
-
-	stdioFdCount := 3
-	cmd := exec.Command(filepath.Join(filepath.Dir(base), "nsinsider"))
-	mod(cmd)
-	cmd.Env = append(cmd.Env, "_LIBNSENTER_INIT=1", "GITPOD_INSTANCE_ID="+instanceID)
-	for _, ns := range nss {
-		f, err := os.OpenFile(ns.Source, ns.Flags, 0)
-		if err != nil {
-			return fmt.Errorf("cannot open %s: %w", ns.Source, err)
-		}
-		defer f.Close()
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", ns.Env, stdioFdCount+len(cmd.ExtraFiles)))
-		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
-	}
-
-	rw := log.Writer(log.WithFields(log.OWI("", "", instanceID)))
-	defer rw.Close()
-
-	cmd.Stdout = rw
-	cmd.Stderr = rw
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("cannot run nsinsider: %w", err)
-	}
-	return nil
-}
-
 // maskPath masks the top of the specified path inside a container to avoid
 // security issues from processes reading information from non-namespace aware
 // mounts ( proc/kcore ).
@@ -743,9 +679,76 @@ func (wbs *InWorkspaceServiceServer) Teardown(ctx context.Context, req *api.Tear
 	return &api.TeardownResponse{Success: success}, nil
 }
 
+// SetHosts replaces the ws-daemon managed entries of the workspace's /etc/hosts file
+func (wbs *InWorkspaceServiceServer) SetHosts(ctx context.Context, req *api.SetHostsRequest) (*api.SetHostsResponse, error) {
+	if len(req.Hosts) > maxHostEntries {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot set more than %d hosts", maxHostEntries)
+	}
+	entries := make([]hosts.Host, 0, len(req.Hosts))
+	for _, h := range req.Hosts {
+		entry := hosts.Host{Addr: h.Addr, Name: h.Name}
+		err := entry.Validate()
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		entries = append(entries, entry)
+	}
+
+	src, err := wbs.workspaceHosts(ctx)
+	if err != nil {
+		log.WithError(err).WithFields(wbs.Session.OWI()).Error("cannot set hosts")
+		return nil, status.Error(codes.FailedPrecondition, "cannot access the workspace's hosts file")
+	}
+	err = src.Set(ctx, entries)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	log.WithFields(wbs.Session.OWI()).WithField("hosts", entries).Debug("updated workspace hosts")
+	return &api.SetHostsResponse{}, nil
+}
+
+// workspaceHosts returns the hosts source of the workspace's /etc/hosts file, starting a
+// hosts controller if there is none yet.
+func (wbs *InWorkspaceServiceServer) workspaceHosts(ctx context.Context) (*hosts.WorkspaceSource, error) {
+	wbs.hostsMu.Lock()
+	defer wbs.hostsMu.Unlock()
+
+	if wbs.hostsSrc != nil {
+		return wbs.hostsSrc, nil
+	}
+
+	rt := wbs.Uidmapper.Runtime
+	if rt == nil {
+		return nil, xerrors.Errorf("not connected to container runtime")
+	}
+	wscontainerID, err := rt.WaitForContainer(ctx, wbs.Session.InstanceID)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot find workspace container: %w", err)
+	}
+	containerPID, err := rt.ContainerPID(ctx, wscontainerID)
+	if err != nil {
+		return nil, xerrors.Errorf("cannot find container PID for containerID %v: %w", wscontainerID, err)
+	}
+
+	// The workspace's /etc/hosts is bind-mounted into all rings, hence writing it through
+	// the root of the container's mount namespace changes it for all workspace processes.
+	hostsFile := filepath.Join(wbs.Uidmapper.Config.ProcLocation, strconv.FormatUint(containerPID, 10), "root", "etc", "hosts")
+	src := hosts.NewWorkspaceSource("workspace")
+	ctl, err := hosts.NewDirectController(wbs.Session.InstanceID, hostsFile, src)
+	if err != nil {
+		return nil, err
+	}
+	go ctl.Start()
+
+	wbs.hostsCtl = ctl
+	wbs.hostsSrc = src
+	return src, nil
+}
+
 func (wbs *InWorkspaceServiceServer) unPrepareForUserNS() error {
 	mountpoint := filepath.Join(wbs.Session.ServiceLocNode, "mark")
-	err := nsinsider(wbs.Session.InstanceID, 1, func(c *exec.Cmd) {
+	err := nsinsider.Nsinsider(wbs.Session.InstanceID, 1, func(c *exec.Cmd) {
 		c.Args = append(c.Args, "unmount", "--target", mountpoint)
 	})
 	if err != nil {
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package netlimit
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	wsk8s "github.com/gitpod-io/gitpod/common-go/kubernetes"
+	"github.com/gitpod-io/gitpod/common-go/log"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/dispatch"
+	"github.com/gitpod-io/gitpod/ws-daemon/pkg/nsinsider"
+)
+
+// Config configures the network bandwidth limiting
+type Config struct {
+	// Enabled enables bandwidth limiting. If false, the bandwidth annotations are ignored.
+	Enabled bool `json:"enabled"`
+}
+
+// Limits are the bandwidth limits of a workspace in bits per second. Zero means unlimited.
+type Limits struct {
+	Egress  int64
+	Ingress int64
+}
+
+// LimitsFromAnnotations parses the bandwidth limits from the workspace pod annotations
+func LimitsFromAnnotations(annotations map[string]string) (res Limits, err error) {
+	parse := func(annotation string) (int64, error) {
+		v, ok := annotations[annotation]
+		if !ok || v == "" {
+			return 0, nil
+		}
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return 0, xerrors.Errorf("cannot parse %s: %w", annotation, err)
+		}
+		if q.Sign() < 0 {
+			return 0, xerrors.Errorf("%s must not be negative", annotation)
+		}
+		return q.Value(), nil
+	}
+
+	res.Egress, err = parse(wsk8s.EgressBandwidthAnnotation)
+	if err != nil {
+		return
+	}
+	res.Ingress, err = parse(wsk8s.IngressBandwidthAnnotation)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// NewDispatchListener creates a new bandwidth limiting dispatch listener
+func NewDispatchListener(cfg *Config, prom prometheus.Registerer) *DispatchListener {
+	d := &DispatchListener{
+		Config:     cfg,
+		workspaces: make(map[string]Limits),
+	}
+	prom.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "netlimit_workspaces_total",
+			Help: "Number of workspaces with a network bandwidth limit",
+		}, func() float64 {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+
+			return float64(len(d.workspaces))
+		}),
+	)
+
+	return d
+}
+
+// DispatchListener applies the bandwidth limits of workspace pods within the workspace's network namespace
+type DispatchListener struct {
+	Config *Config
+
+	workspaces map[string]Limits
+	mu         sync.Mutex
+}
+
+// WorkspaceAdded applies the initial bandwidth limits of a workspace
+func (d *DispatchListener) WorkspaceAdded(ctx context.Context, ws *dispatch.Workspace) error {
+	if !d.Config.Enabled {
+		return nil
+	}
+
+	err := d.apply(ctx, ws)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		d.mu.Lock()
+		delete(d.workspaces, ws.InstanceID)
+		d.mu.Unlock()
+	}()
+	return nil
+}
+
+// WorkspaceUpdated applies changed bandwidth limits
+func (d *DispatchListener) WorkspaceUpdated(ctx context.Context, ws *dispatch.Workspace) error {
+	if !d.Config.Enabled {
+		return nil
+	}
+
+	return d.apply(ctx, ws)
+}
+
+func (d *DispatchListener) apply(ctx context.Context, ws *dispatch.Workspace) error {
+	limits, err := LimitsFromAnnotations(ws.Pod.Annotations)
+	if err != nil {
+		return xerrors.Errorf("cannot apply bandwidth limit: %w", err)
+	}
+
+	// Multiple updates for the same workspace could race each other. We hold the lock while applying
+	// the limits to make sure that the last update wins.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// workspaces we have not seen yet have no limits applied, which is what the zero value expresses
+	if d.workspaces[ws.InstanceID] == limits {
+		return nil
+	}
+
+	disp := dispatch.GetFromContext(ctx)
+	if disp == nil {
+		return xerrors.Errorf("no dispatch available")
+	}
+	pid, err := disp.Runtime.ContainerPID(context.Background(), ws.ContainerID)
+	if err != nil {
+		return xerrors.Errorf("cannot find workspace container PID: %w", err)
+	}
+
+	err = nsinsider.Nsinsider(ws.InstanceID, int(pid), func(c *exec.Cmd) {
+		c.Args = append(c.Args, "setup-bandwidth-limit",
+			"--egress", strconv.FormatInt(limits.Egress, 10),
+			"--ingress", strconv.FormatInt(limits.Ingress, 10),
+		)
+	}, nsinsider.EnterMountNS(false), nsinsider.EnterNetNS(true))
+	if err != nil {
+		return xerrors.Errorf("cannot apply bandwidth limit: %w", err)
+	}
+
+	if limits == (Limits{}) {
+		delete(d.workspaces, ws.InstanceID)
+	} else {
+		d.workspaces[ws.InstanceID] = limits
+	}
+	log.WithFields(ws.OWI()).WithField("egress", limits.Egress).WithField("ingress", limits.Ingress).Info("applied network bandwidth limit")
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package netlimit
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	wsk8s "github.com/gitpod-io/gitpod/common-go/kubernetes"
+)
+
+func TestLimitsFromAnnotations(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Annotations map[string]string
+		Expectation Limits
+		Error       bool
+	}{
+		{Name: "no annotations"},
+		{
+			Name: "both limits",
+			Annotations: map[string]string{
+				wsk8s.EgressBandwidthAnnotation:  "100M",
+				wsk8s.IngressBandwidthAnnotation: "1G",
+			},
+			Expectation: Limits{Egress: 100000000, Ingress: 1000000000},
+		},
+		{
+			Name:        "egress only",
+			Annotations: map[string]string{wsk8s.EgressBandwidthAnnotation: "500k"},
+			Expectation: Limits{Egress: 500000},
+		},
+		{
+			Name:        "empty value",
+			Annotations: map[string]string{wsk8s.IngressBandwidthAnnotation: ""},
+		},
+		{
+			Name:        "invalid value",
+			Annotations: map[string]string{wsk8s.EgressBandwidthAnnotation: "fast"},
+			Error:       true,
+		},
+		{
+			Name:        "negative value",
+			Annotations: map[string]string{wsk8s.IngressBandwidthAnnotation: "-10M"},
+			Error:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			act, err := LimitsFromAnnotations(test.Annotations)
+			if (err != nil) != test.Error {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.Error {
+				return
+			}
+			if diff := cmp.Diff(test.Expectation, act); diff != "" {
+				t.Errorf("unexpected limits (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
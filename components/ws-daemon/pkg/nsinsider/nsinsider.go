@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Gitpod GmbH. All rights reserved.
+// Licensed under the GNU Affero General Public License (AGPL).
+// See License-AGPL.txt in the project root for license information.
+
+package nsinsider
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/gitpod-io/gitpod/common-go/log"
+)
+
+// Opts configures the namespaces nsinsider enters
+type Opts struct {
+	MountNS bool
+	PidNS   bool
+	NetNS   bool
+}
+
+// EnterMountNS enters the mount namespace of the target process. This is the default.
+func EnterMountNS(enter bool) Opt {
+	return func(o *Opts) {
+		o.MountNS = enter
+	}
+}
+
+// EnterPidNS enters the PID namespace of the target process
+func EnterPidNS(enter bool) Opt {
+	return func(o *Opts) {
+		o.PidNS = enter
+	}
+}
+
+// EnterNetNS enters the network namespace of the target process
+func EnterNetNS(enter bool) Opt {
+	return func(o *Opts) {
+		o.NetNS = enter
+	}
+}
+
+// Opt configures an nsinsider invocation
+type Opt func(*Opts)
+
+// Nsinsider runs the nsinsider binary, which is expected next to the current executable, in the namespaces of targetPid.
+// Use mod to add the nsinsider command and its arguments. Unless mod sets the command's stdout, output is logged.
+func Nsinsider(instanceID string, targetPid int, mod func(*exec.Cmd), opts ...Opt) error {
+	cfg := Opts{
+		MountNS: true,
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	base, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	type mnt struct {
+		Env    string
+		Source string
+		Flags  int
+	}
+	var nss []mnt
+	if cfg.MountNS {
+		nss = append(nss,
+			mnt{"_LIBNSENTER_ROOTFD", fmt.Sprintf("/proc/%d/root", targetPid), unix.O_PATH},
+			mnt{"_LIBNSENTER_CWDFD", fmt.Sprintf("/proc/%d/cwd", targetPid), unix.O_PATH},
+			mnt{"_LIBNSENTER_MNTNSFD", fmt.Sprintf("/proc/%d/ns/mnt", targetPid), os.O_RDONLY},
+		)
+	}
+	if cfg.PidNS {
+		nss = append(nss, mnt{"_LIBNSENTER_PIDNSFD", fmt.Sprintf("/proc/%d/ns/pid", targetPid), os.O_RDONLY})
+	}
+	if cfg.NetNS {
+		nss = append(nss, mnt{"_LIBNSENTER_NETNSFD", fmt.Sprintf("/proc/%d/ns/net", targetPid), os.O_RDONLY})
+	}
+
+	stdioFdCount := 3
+	cmd := exec.Command(filepath.Join(filepath.Dir(base), "nsinsider"))
+	mod(cmd)
+	cmd.Env = append(cmd.Env, "_LIBNSENTER_INIT=1", "GITPOD_INSTANCE_ID="+instanceID)
+	for _, ns := range nss {
+		f, err := os.OpenFile(ns.Source, ns.Flags, 0)
+		if err != nil {
+			return fmt.Errorf("cannot open %s: %w", ns.Source, err)
+		}
+		defer f.Close()
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", ns.Env, stdioFdCount+len(cmd.ExtraFiles)))
+		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+	}
+
+	rw := log.Writer(log.WithFields(log.OWI("", "", instanceID)))
+	defer rw.Close()
+
+	if cmd.Stdout == nil {
+		cmd.Stdout = rw
+	}
+	cmd.Stderr = rw
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("cannot run nsinsider: %w", err)
+	}
+	return nil
+}
@@ -571,6 +571,11 @@ export class WorkspaceSpec extends jspb.Message {
     getTimeout(): string;
     setTimeout(value: string): WorkspaceSpec;
 
+    hasEgressPolicy(): boolean;
+    clearEgressPolicy(): void;
+    getEgressPolicy(): EgressPolicy | undefined;
+    setEgressPolicy(value?: EgressPolicy): WorkspaceSpec;
+
     serializeBinary(): Uint8Array;
     toObject(includeInstance?: boolean): WorkspaceSpec.AsObject;
     static toObject(includeInstance: boolean, msg: WorkspaceSpec): WorkspaceSpec.AsObject;
@@ -590,6 +595,7 @@ export namespace WorkspaceSpec {
         exposedPortsList: Array<PortSpec.AsObject>,
         type: WorkspaceType,
         timeout: string,
+        egressPolicy?: EgressPolicy.AsObject,
     }
 }
 
@@ -646,6 +652,8 @@ export class WorkspaceConditions extends jspb.Message {
     setFirstUserActivity(value?: google_protobuf_timestamp_pb.Timestamp): WorkspaceConditions;
     getHeadlessTaskFailed(): string;
     setHeadlessTaskFailed(value: string): WorkspaceConditions;
+    getEgressViolation(): string;
+    setEgressViolation(value: string): WorkspaceConditions;
 
     serializeBinary(): Uint8Array;
     toObject(includeInstance?: boolean): WorkspaceConditions.AsObject;
@@ -669,6 +677,7 @@ export namespace WorkspaceConditions {
         networkNotReady: WorkspaceConditionBool,
         firstUserActivity?: google_protobuf_timestamp_pb.Timestamp.AsObject,
         headlessTaskFailed: string,
+        egressViolation: string,
     }
 }
 
@@ -791,6 +800,15 @@ export class StartWorkspaceSpec extends jspb.Message {
     getAdmission(): AdmissionLevel;
     setAdmission(value: AdmissionLevel): StartWorkspaceSpec;
 
+    hasEgressPolicy(): boolean;
+    clearEgressPolicy(): void;
+    getEgressPolicy(): EgressPolicy | undefined;
+    setEgressPolicy(value?: EgressPolicy): StartWorkspaceSpec;
+    clearIdeBackendsList(): void;
+    getIdeBackendsList(): Array<string>;
+    setIdeBackendsList(value: Array<string>): StartWorkspaceSpec;
+    addIdeBackends(value: string, index?: number): string;
+
     serializeBinary(): Uint8Array;
     toObject(includeInstance?: boolean): StartWorkspaceSpec.AsObject;
     static toObject(includeInstance: boolean, msg: StartWorkspaceSpec): StartWorkspaceSpec.AsObject;
@@ -814,6 +832,8 @@ export namespace StartWorkspaceSpec {
         git?: GitSpec.AsObject,
         timeout: string,
         admission: AdmissionLevel,
+        egressPolicy?: EgressPolicy.AsObject,
+        ideBackendsList: Array<string>,
     }
 }
 
@@ -840,6 +860,59 @@ export namespace GitSpec {
     }
 }
 
+export class EgressPolicy extends jspb.Message {
+    getDefaultAction(): EgressAction;
+    setDefaultAction(value: EgressAction): EgressPolicy;
+    clearRulesList(): void;
+    getRulesList(): Array<EgressRule>;
+    setRulesList(value: Array<EgressRule>): EgressPolicy;
+    addRules(value?: EgressRule, index?: number): EgressRule;
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): EgressPolicy.AsObject;
+    static toObject(includeInstance: boolean, msg: EgressPolicy): EgressPolicy.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: EgressPolicy, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): EgressPolicy;
+    static deserializeBinaryFromReader(message: EgressPolicy, reader: jspb.BinaryReader): EgressPolicy;
+}
+
+export namespace EgressPolicy {
+    export type AsObject = {
+        defaultAction: EgressAction,
+        rulesList: Array<EgressRule.AsObject>,
+    }
+}
+
+export class EgressRule extends jspb.Message {
+    getAction(): EgressAction;
+    setAction(value: EgressAction): EgressRule;
+    getDestination(): string;
+    setDestination(value: string): EgressRule;
+    clearPortsList(): void;
+    getPortsList(): Array<number>;
+    setPortsList(value: Array<number>): EgressRule;
+    addPorts(value: number, index?: number): number;
+
+    serializeBinary(): Uint8Array;
+    toObject(includeInstance?: boolean): EgressRule.AsObject;
+    static toObject(includeInstance: boolean, msg: EgressRule): EgressRule.AsObject;
+    static extensions: {[key: number]: jspb.ExtensionFieldInfo<jspb.Message>};
+    static extensionsBinary: {[key: number]: jspb.ExtensionFieldBinaryInfo<jspb.Message>};
+    static serializeBinaryToWriter(message: EgressRule, writer: jspb.BinaryWriter): void;
+    static deserializeBinary(bytes: Uint8Array): EgressRule;
+    static deserializeBinaryFromReader(message: EgressRule, reader: jspb.BinaryReader): EgressRule;
+}
+
+export namespace EgressRule {
+    export type AsObject = {
+        action: EgressAction,
+        destination: string,
+        portsList: Array<number>,
+    }
+}
+
 export class EnvironmentVariable extends jspb.Message {
     getName(): string;
     setName(value: string): EnvironmentVariable;
@@ -899,6 +972,12 @@ export enum WorkspaceFeatureFlag {
     NOOP = 0,
     FULL_WORKSPACE_BACKUP = 4,
     FIXED_RESOURCES = 5,
+    PROCESS_CHECKPOINT = 7,
+}
+
+export enum EgressAction {
+    EGRESS_ACTION_ALLOW = 0,
+    EGRESS_ACTION_DENY = 1,
 }
 
 export enum WorkspaceType {
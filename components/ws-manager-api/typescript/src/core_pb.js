@@ -32,6 +32,9 @@ goog.exportSymbol('proto.wsman.ControlPortRequest', null, global);
 goog.exportSymbol('proto.wsman.ControlPortResponse', null, global);
 goog.exportSymbol('proto.wsman.DescribeWorkspaceRequest', null, global);
 goog.exportSymbol('proto.wsman.DescribeWorkspaceResponse', null, global);
+goog.exportSymbol('proto.wsman.EgressAction', null, global);
+goog.exportSymbol('proto.wsman.EgressPolicy', null, global);
+goog.exportSymbol('proto.wsman.EgressRule', null, global);
 goog.exportSymbol('proto.wsman.EnvironmentVariable', null, global);
 goog.exportSymbol('proto.wsman.GetWorkspacesRequest', null, global);
 goog.exportSymbol('proto.wsman.GetWorkspacesResponse', null, global);
@@ -693,6 +696,48 @@ if (goog.DEBUG && !COMPILED) {
    */
   proto.wsman.GitSpec.displayName = 'proto.wsman.GitSpec';
 }
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.wsman.EgressPolicy = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, proto.wsman.EgressPolicy.repeatedFields_, null);
+};
+goog.inherits(proto.wsman.EgressPolicy, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  /**
+   * @public
+   * @override
+   */
+  proto.wsman.EgressPolicy.displayName = 'proto.wsman.EgressPolicy';
+}
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.wsman.EgressRule = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, proto.wsman.EgressRule.repeatedFields_, null);
+};
+goog.inherits(proto.wsman.EgressRule, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  /**
+   * @public
+   * @override
+   */
+  proto.wsman.EgressRule.displayName = 'proto.wsman.EgressRule';
+}
 /**
  * Generated by JsPbCodeGenerator.
  * @param {Array=} opt_data Optional initial data array, typically from a
@@ -4474,7 +4519,8 @@ proto.wsman.WorkspaceSpec.toObject = function(includeInstance, msg) {
     exposedPortsList: jspb.Message.toObjectList(msg.getExposedPortsList(),
     proto.wsman.PortSpec.toObject, includeInstance),
     type: jspb.Message.getFieldWithDefault(msg, 6, 0),
-    timeout: jspb.Message.getFieldWithDefault(msg, 7, "")
+    timeout: jspb.Message.getFieldWithDefault(msg, 7, ""),
+    egressPolicy: (f = msg.getEgressPolicy()) && proto.wsman.EgressPolicy.toObject(includeInstance, f)
   };
 
   if (includeInstance) {
@@ -4540,6 +4586,11 @@ proto.wsman.WorkspaceSpec.deserializeBinaryFromReader = function(msg, reader) {
       var value = /** @type {string} */ (reader.readString());
       msg.setTimeout(value);
       break;
+    case 8:
+      var value = new proto.wsman.EgressPolicy;
+      reader.readMessage(value,proto.wsman.EgressPolicy.deserializeBinaryFromReader);
+      msg.setEgressPolicy(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -4619,6 +4670,14 @@ proto.wsman.WorkspaceSpec.serializeBinaryToWriter = function(message, writer) {
       f
     );
   }
+  f = message.getEgressPolicy();
+  if (f != null) {
+    writer.writeMessage(
+      8,
+      f,
+      proto.wsman.EgressPolicy.serializeBinaryToWriter
+    );
+  }
 };
 
 
@@ -4768,6 +4827,43 @@ proto.wsman.WorkspaceSpec.prototype.setTimeout = function(value) {
 };
 
 
+/**
+ * optional EgressPolicy egress_policy = 8;
+ * @return {?proto.wsman.EgressPolicy}
+ */
+proto.wsman.WorkspaceSpec.prototype.getEgressPolicy = function() {
+  return /** @type{?proto.wsman.EgressPolicy} */ (
+    jspb.Message.getWrapperField(this, proto.wsman.EgressPolicy, 8));
+};
+
+
+/**
+ * @param {?proto.wsman.EgressPolicy|undefined} value
+ * @return {!proto.wsman.WorkspaceSpec} returns this
+*/
+proto.wsman.WorkspaceSpec.prototype.setEgressPolicy = function(value) {
+  return jspb.Message.setWrapperField(this, 8, value);
+};
+
+
+/**
+ * Clears the message field making it undefined.
+ * @return {!proto.wsman.WorkspaceSpec} returns this
+ */
+proto.wsman.WorkspaceSpec.prototype.clearEgressPolicy = function() {
+  return this.setEgressPolicy(undefined);
+};
+
+
+/**
+ * Returns whether this field is set.
+ * @return {boolean}
+ */
+proto.wsman.WorkspaceSpec.prototype.hasEgressPolicy = function() {
+  return jspb.Message.getField(this, 8) != null;
+};
+
+
 
 
 
@@ -5029,7 +5125,8 @@ proto.wsman.WorkspaceConditions.toObject = function(includeInstance, msg) {
     deployed: jspb.Message.getFieldWithDefault(msg, 7, 0),
     networkNotReady: jspb.Message.getFieldWithDefault(msg, 8, 0),
     firstUserActivity: (f = msg.getFirstUserActivity()) && google_protobuf_timestamp_pb.Timestamp.toObject(includeInstance, f),
-    headlessTaskFailed: jspb.Message.getFieldWithDefault(msg, 10, "")
+    headlessTaskFailed: jspb.Message.getFieldWithDefault(msg, 10, ""),
+    egressViolation: jspb.Message.getFieldWithDefault(msg, 11, "")
   };
 
   if (includeInstance) {
@@ -5107,6 +5204,10 @@ proto.wsman.WorkspaceConditions.deserializeBinaryFromReader = function(msg, read
       var value = /** @type {string} */ (reader.readString());
       msg.setHeadlessTaskFailed(value);
       break;
+    case 11:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setEgressViolation(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -5207,6 +5308,13 @@ proto.wsman.WorkspaceConditions.serializeBinaryToWriter = function(message, writ
       f
     );
   }
+  f = message.getEgressViolation();
+  if (f.length > 0) {
+    writer.writeString(
+      11,
+      f
+    );
+  }
 };
 
 
@@ -5409,6 +5517,24 @@ proto.wsman.WorkspaceConditions.prototype.setHeadlessTaskFailed = function(value
 };
 
 
+/**
+ * optional string egress_violation = 11;
+ * @return {string}
+ */
+proto.wsman.WorkspaceConditions.prototype.getEgressViolation = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 11, ""));
+};
+
+
+/**
+ * @param {string} value
+ * @return {!proto.wsman.WorkspaceConditions} returns this
+ */
+proto.wsman.WorkspaceConditions.prototype.setEgressViolation = function(value) {
+  return jspb.Message.setProto3StringField(this, 11, value);
+};
+
+
 
 
 
@@ -6009,7 +6135,7 @@ proto.wsman.WorkspaceAuthentication.prototype.setOwnerToken = function(value) {
  * @private {!Array<number>}
  * @const
  */
-proto.wsman.StartWorkspaceSpec.repeatedFields_ = [3,5,6];
+proto.wsman.StartWorkspaceSpec.repeatedFields_ = [3,5,6,13];
 
 
 
@@ -6054,7 +6180,9 @@ proto.wsman.StartWorkspaceSpec.toObject = function(includeInstance, msg) {
     workspaceLocation: jspb.Message.getFieldWithDefault(msg, 8, ""),
     git: (f = msg.getGit()) && proto.wsman.GitSpec.toObject(includeInstance, f),
     timeout: jspb.Message.getFieldWithDefault(msg, 10, ""),
-    admission: jspb.Message.getFieldWithDefault(msg, 11, 0)
+    admission: jspb.Message.getFieldWithDefault(msg, 11, 0),
+    egressPolicy: (f = msg.getEgressPolicy()) && proto.wsman.EgressPolicy.toObject(includeInstance, f),
+    ideBackendsList: (f = jspb.Message.getRepeatedField(msg, 13)) == null ? undefined : f
   };
 
   if (includeInstance) {
@@ -6141,6 +6269,15 @@ proto.wsman.StartWorkspaceSpec.deserializeBinaryFromReader = function(msg, reade
       var value = /** @type {!proto.wsman.AdmissionLevel} */ (reader.readEnum());
       msg.setAdmission(value);
       break;
+    case 12:
+      var value = new proto.wsman.EgressPolicy;
+      reader.readMessage(value,proto.wsman.EgressPolicy.deserializeBinaryFromReader);
+      msg.setEgressPolicy(value);
+      break;
+    case 13:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addIdeBackends(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -6251,6 +6388,21 @@ proto.wsman.StartWorkspaceSpec.serializeBinaryToWriter = function(message, write
       f
     );
   }
+  f = message.getEgressPolicy();
+  if (f != null) {
+    writer.writeMessage(
+      12,
+      f,
+      proto.wsman.EgressPolicy.serializeBinaryToWriter
+    );
+  }
+  f = message.getIdeBackendsList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      13,
+      f
+    );
+  }
 };
 
 
@@ -6549,6 +6701,80 @@ proto.wsman.StartWorkspaceSpec.prototype.setAdmission = function(value) {
 };
 
 
+/**
+ * optional EgressPolicy egress_policy = 12;
+ * @return {?proto.wsman.EgressPolicy}
+ */
+proto.wsman.StartWorkspaceSpec.prototype.getEgressPolicy = function() {
+  return /** @type{?proto.wsman.EgressPolicy} */ (
+    jspb.Message.getWrapperField(this, proto.wsman.EgressPolicy, 12));
+};
+
+
+/**
+ * @param {?proto.wsman.EgressPolicy|undefined} value
+ * @return {!proto.wsman.StartWorkspaceSpec} returns this
+*/
+proto.wsman.StartWorkspaceSpec.prototype.setEgressPolicy = function(value) {
+  return jspb.Message.setWrapperField(this, 12, value);
+};
+
+
+/**
+ * Clears the message field making it undefined.
+ * @return {!proto.wsman.StartWorkspaceSpec} returns this
+ */
+proto.wsman.StartWorkspaceSpec.prototype.clearEgressPolicy = function() {
+  return this.setEgressPolicy(undefined);
+};
+
+
+/**
+ * Returns whether this field is set.
+ * @return {boolean}
+ */
+proto.wsman.StartWorkspaceSpec.prototype.hasEgressPolicy = function() {
+  return jspb.Message.getField(this, 12) != null;
+};
+
+
+/**
+ * repeated string ide_backends = 13;
+ * @return {!Array<string>}
+ */
+proto.wsman.StartWorkspaceSpec.prototype.getIdeBackendsList = function() {
+  return /** @type {!Array<string>} */ (jspb.Message.getRepeatedField(this, 13));
+};
+
+
+/**
+ * @param {!Array<string>} value
+ * @return {!proto.wsman.StartWorkspaceSpec} returns this
+ */
+proto.wsman.StartWorkspaceSpec.prototype.setIdeBackendsList = function(value) {
+  return jspb.Message.setField(this, 13, value || []);
+};
+
+
+/**
+ * @param {string} value
+ * @param {number=} opt_index
+ * @return {!proto.wsman.StartWorkspaceSpec} returns this
+ */
+proto.wsman.StartWorkspaceSpec.prototype.addIdeBackends = function(value, opt_index) {
+  return jspb.Message.addToRepeatedField(this, 13, value, opt_index);
+};
+
+
+/**
+ * Clears the list making it empty but non-null.
+ * @return {!proto.wsman.StartWorkspaceSpec} returns this
+ */
+proto.wsman.StartWorkspaceSpec.prototype.clearIdeBackendsList = function() {
+  return this.setIdeBackendsList([]);
+};
+
+
 
 
 
@@ -6710,6 +6936,415 @@ proto.wsman.GitSpec.prototype.setEmail = function(value) {
 
 
 
+/**
+ * List of repeated fields within this message type.
+ * @private {!Array<number>}
+ * @const
+ */
+proto.wsman.EgressPolicy.repeatedFields_ = [2];
+
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * Optional fields that are not set will be set to undefined.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     net/proto2/compiler/js/internal/generator.cc#kKeyword.
+ * @param {boolean=} opt_includeInstance Deprecated. whether to include the
+ *     JSPB instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.wsman.EgressPolicy.prototype.toObject = function(opt_includeInstance) {
+  return proto.wsman.EgressPolicy.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Deprecated. Whether to include
+ *     the JSPB instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.wsman.EgressPolicy} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.wsman.EgressPolicy.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    defaultAction: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    rulesList: jspb.Message.toObjectList(msg.getRulesList(),
+    proto.wsman.EgressRule.toObject, includeInstance)
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.wsman.EgressPolicy}
+ */
+proto.wsman.EgressPolicy.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.wsman.EgressPolicy;
+  return proto.wsman.EgressPolicy.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.wsman.EgressPolicy} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.wsman.EgressPolicy}
+ */
+proto.wsman.EgressPolicy.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {!proto.wsman.EgressAction} */ (reader.readEnum());
+      msg.setDefaultAction(value);
+      break;
+    case 2:
+      var value = new proto.wsman.EgressRule;
+      reader.readMessage(value,proto.wsman.EgressRule.deserializeBinaryFromReader);
+      msg.addRules(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.wsman.EgressPolicy.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.wsman.EgressPolicy.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.wsman.EgressPolicy} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.wsman.EgressPolicy.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getDefaultAction();
+  if (f !== 0.0) {
+    writer.writeEnum(
+      1,
+      f
+    );
+  }
+  f = message.getRulesList();
+  if (f.length > 0) {
+    writer.writeRepeatedMessage(
+      2,
+      f,
+      proto.wsman.EgressRule.serializeBinaryToWriter
+    );
+  }
+};
+
+
+/**
+ * optional EgressAction default_action = 1;
+ * @return {!proto.wsman.EgressAction}
+ */
+proto.wsman.EgressPolicy.prototype.getDefaultAction = function() {
+  return /** @type {!proto.wsman.EgressAction} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/**
+ * @param {!proto.wsman.EgressAction} value
+ * @return {!proto.wsman.EgressPolicy} returns this
+ */
+proto.wsman.EgressPolicy.prototype.setDefaultAction = function(value) {
+  return jspb.Message.setProto3EnumField(this, 1, value);
+};
+
+
+/**
+ * repeated EgressRule rules = 2;
+ * @return {!Array<!proto.wsman.EgressRule>}
+ */
+proto.wsman.EgressPolicy.prototype.getRulesList = function() {
+  return /** @type{!Array<!proto.wsman.EgressRule>} */ (
+    jspb.Message.getRepeatedWrapperField(this, proto.wsman.EgressRule, 2));
+};
+
+
+/**
+ * @param {!Array<!proto.wsman.EgressRule>} value
+ * @return {!proto.wsman.EgressPolicy} returns this
+*/
+proto.wsman.EgressPolicy.prototype.setRulesList = function(value) {
+  return jspb.Message.setRepeatedWrapperField(this, 2, value);
+};
+
+
+/**
+ * @param {!proto.wsman.EgressRule=} opt_value
+ * @param {number=} opt_index
+ * @return {!proto.wsman.EgressRule}
+ */
+proto.wsman.EgressPolicy.prototype.addRules = function(opt_value, opt_index) {
+  return jspb.Message.addToRepeatedWrapperField(this, 2, opt_value, proto.wsman.EgressRule, opt_index);
+};
+
+
+/**
+ * Clears the list making it empty but non-null.
+ * @return {!proto.wsman.EgressPolicy} returns this
+ */
+proto.wsman.EgressPolicy.prototype.clearRulesList = function() {
+  return this.setRulesList([]);
+};
+
+
+
+
+/**
+ * List of repeated fields within this message type.
+ * @private {!Array<number>}
+ * @const
+ */
+proto.wsman.EgressRule.repeatedFields_ = [3];
+
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * Optional fields that are not set will be set to undefined.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     net/proto2/compiler/js/internal/generator.cc#kKeyword.
+ * @param {boolean=} opt_includeInstance Deprecated. whether to include the
+ *     JSPB instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.wsman.EgressRule.prototype.toObject = function(opt_includeInstance) {
+  return proto.wsman.EgressRule.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Deprecated. Whether to include
+ *     the JSPB instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.wsman.EgressRule} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.wsman.EgressRule.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    action: jspb.Message.getFieldWithDefault(msg, 1, 0),
+    destination: jspb.Message.getFieldWithDefault(msg, 2, ""),
+    portsList: (f = jspb.Message.getRepeatedField(msg, 3)) == null ? undefined : f
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.wsman.EgressRule}
+ */
+proto.wsman.EgressRule.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.wsman.EgressRule;
+  return proto.wsman.EgressRule.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.wsman.EgressRule} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.wsman.EgressRule}
+ */
+proto.wsman.EgressRule.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {!proto.wsman.EgressAction} */ (reader.readEnum());
+      msg.setAction(value);
+      break;
+    case 2:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setDestination(value);
+      break;
+    case 3:
+      var values = /** @type {!Array<number>} */ (reader.isDelimited() ? reader.readPackedUint32() : [reader.readUint32()]);
+      for (var i = 0; i < values.length; i++) {
+        msg.addPorts(values[i]);
+      }
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.wsman.EgressRule.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.wsman.EgressRule.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.wsman.EgressRule} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.wsman.EgressRule.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getAction();
+  if (f !== 0.0) {
+    writer.writeEnum(
+      1,
+      f
+    );
+  }
+  f = message.getDestination();
+  if (f.length > 0) {
+    writer.writeString(
+      2,
+      f
+    );
+  }
+  f = message.getPortsList();
+  if (f.length > 0) {
+    writer.writePackedUint32(
+      3,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional EgressAction action = 1;
+ * @return {!proto.wsman.EgressAction}
+ */
+proto.wsman.EgressRule.prototype.getAction = function() {
+  return /** @type {!proto.wsman.EgressAction} */ (jspb.Message.getFieldWithDefault(this, 1, 0));
+};
+
+
+/**
+ * @param {!proto.wsman.EgressAction} value
+ * @return {!proto.wsman.EgressRule} returns this
+ */
+proto.wsman.EgressRule.prototype.setAction = function(value) {
+  return jspb.Message.setProto3EnumField(this, 1, value);
+};
+
+
+/**
+ * optional string destination = 2;
+ * @return {string}
+ */
+proto.wsman.EgressRule.prototype.getDestination = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/**
+ * @param {string} value
+ * @return {!proto.wsman.EgressRule} returns this
+ */
+proto.wsman.EgressRule.prototype.setDestination = function(value) {
+  return jspb.Message.setProto3StringField(this, 2, value);
+};
+
+
+/**
+ * repeated uint32 ports = 3;
+ * @return {!Array<number>}
+ */
+proto.wsman.EgressRule.prototype.getPortsList = function() {
+  return /** @type {!Array<number>} */ (jspb.Message.getRepeatedField(this, 3));
+};
+
+
+/**
+ * @param {!Array<number>} value
+ * @return {!proto.wsman.EgressRule} returns this
+ */
+proto.wsman.EgressRule.prototype.setPortsList = function(value) {
+  return jspb.Message.setField(this, 3, value || []);
+};
+
+
+/**
+ * @param {number} value
+ * @param {number=} opt_index
+ * @return {!proto.wsman.EgressRule} returns this
+ */
+proto.wsman.EgressRule.prototype.addPorts = function(value, opt_index) {
+  return jspb.Message.addToRepeatedField(this, 3, value, opt_index);
+};
+
+
+/**
+ * Clears the list making it empty but non-null.
+ * @return {!proto.wsman.EgressRule} returns this
+ */
+proto.wsman.EgressRule.prototype.clearPortsList = function() {
+  return this.setPortsList([]);
+};
+
+
+
 
 
 if (jspb.Message.GENERATE_TO_OBJECT) {
@@ -6922,7 +7557,16 @@ proto.wsman.WorkspacePhase = {
 proto.wsman.WorkspaceFeatureFlag = {
   NOOP: 0,
   FULL_WORKSPACE_BACKUP: 4,
-  FIXED_RESOURCES: 5
+  FIXED_RESOURCES: 5,
+  PROCESS_CHECKPOINT: 7
+};
+
+/**
+ * @enum {number}
+ */
+proto.wsman.EgressAction = {
+  EGRESS_ACTION_ALLOW: 0,
+  EGRESS_ACTION_DENY: 1
 };
 
 /**
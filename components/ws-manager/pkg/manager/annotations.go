@@ -68,6 +68,9 @@ const (
 	// fullWorkspaceBackupAnnotation is set on workspaces which operate using a full workspace backup
 	fullWorkspaceBackupAnnotation = "gitpod/fullWorkspaceBackup"
 
+	// processCheckpointAnnotation is set on workspaces whose processes are checkpointed before the pod is deleted and restored on start
+	processCheckpointAnnotation = "gitpod/processCheckpoint"
+
 	// ownerTokenAnnotation contains the owner token of the workspace
 	ownerTokenAnnotation = "gitpod/ownerToken"
 
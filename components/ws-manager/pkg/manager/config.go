@@ -81,6 +81,15 @@ type Configuration struct {
 	RegistryFacadeHost string `json:"registryFacadeHost"`
 	// Cluster host under which workspaces are served, e.g. ws-eu11.gitpod.io
 	WorkspaceClusterHost string `json:"workspaceClusterHost"`
+	// NetworkLimits configures the bandwidth limits ws-daemon enforces on workspaces
+	NetworkLimits NetworkLimitConfiguration `json:"networkLimits,omitempty"`
+}
+
+// NetworkLimitConfiguration configures the network bandwidth of workspaces.
+// Limits are quantities in bits per second, e.g. "100M". Empty values disable the respective limit.
+type NetworkLimitConfiguration struct {
+	Egress  string `json:"egress,omitempty"`
+	Ingress string `json:"ingress,omitempty"`
 }
 
 // AllContainerConfiguration contains the configuration for all container in a workspace pod
@@ -184,6 +193,14 @@ func (c *Configuration) Validate() error {
 		return xerrors.Errorf("workspacePodTemplate: %w", err)
 	}
 
+	err = validation.ValidateStruct(&c.NetworkLimits,
+		validation.Field(&c.NetworkLimits.Egress, validBandwidth),
+		validation.Field(&c.NetworkLimits.Ingress, validBandwidth),
+	)
+	if err != nil {
+		return xerrors.Errorf("networkLimits: %w", err)
+	}
+
 	err = validation.ValidateStruct(c,
 		validation.Field(&c.WorkspaceURLTemplate, validation.Required, validWorkspaceURLTemplate),
 		validation.Field(&c.WorkspaceHostPath, validation.Required),
@@ -204,6 +221,25 @@ var validPodTemplate = validation.By(func(o interface{}) error {
 	return err
 })
 
+var validBandwidth = validation.By(func(o interface{}) error {
+	s, ok := o.(string)
+	if !ok {
+		return xerrors.Errorf("field should be string")
+	}
+	if s == "" {
+		return nil
+	}
+
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return xerrors.Errorf("cannot parse bandwidth quantity: %w", err)
+	}
+	if q.Sign() < 0 {
+		return xerrors.Errorf("bandwidth must not be negative")
+	}
+	return nil
+})
+
 var validWorkspaceURLTemplate = validation.By(func(o interface{}) error {
 	s, ok := o.(string)
 	if !ok {
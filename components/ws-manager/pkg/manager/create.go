@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/url"
 	"path/filepath"
 	"reflect"
 	"strconv"
@@ -19,6 +20,7 @@ import (
 	"github.com/imdario/mergo"
 	"github.com/opentracing/opentracing-go"
 	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -303,6 +305,19 @@ func (m *Manager) createDefiniteWorkspacePod(startContext *startWorkspaceContext
 	for k, v := range req.Metadata.Annotations {
 		annotations[workspaceAnnotationPrefix+k] = v
 	}
+	if m.Config.NetworkLimits.Egress != "" {
+		annotations[wsk8s.EgressBandwidthAnnotation] = m.Config.NetworkLimits.Egress
+	}
+	if m.Config.NetworkLimits.Ingress != "" {
+		annotations[wsk8s.IngressBandwidthAnnotation] = m.Config.NetworkLimits.Ingress
+	}
+	if req.Spec.EgressPolicy != nil {
+		policy, err := renderEgressPolicy(req.Spec.EgressPolicy, m.Config.GitpodHostURL)
+		if err != nil {
+			return nil, xerrors.Errorf("cannot render egress policy: %w", err)
+		}
+		annotations[wsk8s.EgressPolicyAnnotation] = policy
+	}
 
 	// By default we embue our workspace pods with some tolerance towards pressure taints,
 	// see https://kubernetes.io/docs/concepts/configuration/taint-and-toleration/#taint-based-evictions
@@ -393,6 +408,10 @@ func (m *Manager) createDefiniteWorkspacePod(startContext *startWorkspaceContext
 			}
 			pod.Annotations[wsk8s.CPULimitAnnotation] = cpuLimit
 
+		case api.WorkspaceFeatureFlag_PROCESS_CHECKPOINT:
+			pod.Labels[processCheckpointAnnotation] = "true"
+			pod.Annotations[processCheckpointAnnotation] = "true"
+
 		case api.WorkspaceFeatureFlag_NOOP:
 
 		default:
@@ -522,6 +541,8 @@ func (m *Manager) createWorkspaceEnvironment(startContext *startWorkspaceContext
 	result = append(result, corev1.EnvVar{Name: "GITPOD_WORKSPACE_URL", Value: startContext.WorkspaceURL})
 	result = append(result, corev1.EnvVar{Name: "GITPOD_WORKSPACE_CLUSTER_HOST", Value: m.Config.WorkspaceClusterHost})
 	result = append(result, corev1.EnvVar{Name: "THEIA_SUPERVISOR_ENDPOINT", Value: fmt.Sprintf(":%d", startContext.SupervisorPort)})
+	result = append(result, corev1.EnvVar{Name: "THEIA_SUPERVISOR_OWNER_TOKEN", Value: startContext.OwnerToken})
+	result = append(result, corev1.EnvVar{Name: "SUPERVISOR_IDE_BACKENDS", Value: strings.Join(spec.IdeBackends, ",")})
 	// TODO(ak) remove THEIA_WEBVIEW_EXTERNAL_ENDPOINT and THEIA_MINI_BROWSER_HOST_PATTERN when Theia is removed
 	result = append(result, corev1.EnvVar{Name: "THEIA_WEBVIEW_EXTERNAL_ENDPOINT", Value: "webview-{{hostname}}"})
 	result = append(result, corev1.EnvVar{Name: "THEIA_MINI_BROWSER_HOST_PATTERN", Value: "browser-{{hostname}}"})
@@ -680,6 +701,42 @@ func (m *Manager) createPortsService(workspaceID string, metaID string, serviceP
 	}, nil
 }
 
+// renderEgressPolicy serializes the egress policy ws-daemon installs in the workspace. Workspaces must always be able to
+// reach Gitpod itself, hence we prepend a rule which allows connections to the Gitpod host.
+func renderEgressPolicy(policy *api.EgressPolicy, gitpodHostURL string) (string, error) {
+	host, err := url.Parse(gitpodHostURL)
+	if err != nil {
+		return "", xerrors.Errorf("cannot parse Gitpod host URL: %w", err)
+	}
+	port := uint32(443)
+	if p := host.Port(); p != "" {
+		pv, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return "", xerrors.Errorf("invalid Gitpod host port: %w", err)
+		}
+		port = uint32(pv)
+	} else if host.Scheme == "http" {
+		port = 80
+	}
+
+	rules := make([]*api.EgressRule, 0, len(policy.Rules)+1)
+	rules = append(rules, &api.EgressRule{
+		Action:      api.EgressAction_EGRESS_ACTION_ALLOW,
+		Destination: host.Hostname(),
+		Ports:       []uint32{port},
+	})
+	rules = append(rules, policy.Rules...)
+
+	res, err := protojson.Marshal(&api.EgressPolicy{
+		DefaultAction: policy.DefaultAction,
+		Rules:         rules,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
 func (m *Manager) newStartWorkspaceContext(ctx context.Context, req *api.StartWorkspaceRequest) (res *startWorkspaceContext, err error) {
 	// we deliberately do not shadow ctx here as we need the original context later to extract the TraceID
 	span, ctx := tracing.FromContext(ctx, "newStartWorkspaceContext")
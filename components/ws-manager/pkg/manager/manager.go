@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -59,6 +60,10 @@ type Manager struct {
 	activity     map[string]time.Time
 	activityLock sync.Mutex
 
+	// checkpoints are the IDs of workspaces whose processes are being checkpointed before their pod is deleted
+	checkpoints    map[string]struct{}
+	checkpointLock sync.Mutex
+
 	wsdaemonPool *grpcpool.Pool
 
 	subscribers    map[string]chan *api.SubscribeResponse
@@ -106,6 +111,10 @@ const (
 
 	// kubernetesOperationTimeout is the time we give Kubernetes operations in general.
 	kubernetesOperationTimeout = 5 * time.Second
+
+	// processCheckpointTimeout is the time we give ws-daemon to checkpoint the workspace processes before we delete the pod anyway.
+	// Stopping a workspace does not wait for the checkpoint, it happens in the background.
+	processCheckpointTimeout = 2 * time.Minute
 )
 
 // New creates a new workspace manager
@@ -117,6 +126,7 @@ func New(config Configuration, client client.Client, rawClient kubernetes.Interf
 		RawClient:    rawClient,
 		Content:      cp,
 		activity:     make(map[string]time.Time),
+		checkpoints:  make(map[string]struct{}),
 		subscribers:  make(map[string]chan *api.SubscribeResponse),
 		wsdaemonPool: grpcpool.New(wsdaemonConnfactory),
 	}
@@ -263,6 +273,8 @@ func validateStartWorkspaceRequest(req *api.StartWorkspaceRequest) error {
 		validation.Field(&req.Spec.Ports, validation.By(areValidPorts)),
 		validation.Field(&req.Spec.Initializer, validation.Required),
 		validation.Field(&req.Spec.FeatureFlags, validation.By(areValidFeatureFlags)),
+		validation.Field(&req.Spec.EgressPolicy, validation.By(isValidEgressPolicy)),
+		validation.Field(&req.Spec.IdeBackends, validation.By(areValidIDEBackends)),
 	)
 	if err != nil {
 		return xerrors.Errorf("invalid request: %w", err)
@@ -332,6 +344,58 @@ func areValidFeatureFlags(value interface{}) error {
 	return nil
 }
 
+func isValidEgressPolicy(value interface{}) error {
+	p, ok := value.(*api.EgressPolicy)
+	if !ok {
+		return xerrors.Errorf("value is not an egress policy")
+	}
+	if p == nil {
+		return nil
+	}
+
+	if _, ok := api.EgressAction_name[int32(p.DefaultAction)]; !ok {
+		return xerrors.Errorf("default action %d is out of range", p.DefaultAction)
+	}
+	for i, r := range p.Rules {
+		if _, ok := api.EgressAction_name[int32(r.Action)]; !ok {
+			return xerrors.Errorf("rule %d: action %d is out of range", i, r.Action)
+		}
+		if r.Destination == "" {
+			return xerrors.Errorf("rule %d: destination is required", i)
+		}
+		for _, port := range r.Ports {
+			if port == 0 || port > 65535 {
+				return xerrors.Errorf("rule %d: port %d is out of range", i, port)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ideBackendNameRegexp matches the names of IDE backends, which supervisor uses as directory names
+var ideBackendNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+func areValidIDEBackends(value interface{}) error {
+	s, ok := value.([]string)
+	if !ok {
+		return xerrors.Errorf("value is not an IDE backend list")
+	}
+
+	idx := make(map[string]struct{}, len(s))
+	for _, name := range s {
+		if !ideBackendNameRegexp.MatchString(name) {
+			return xerrors.Errorf("%q is not a valid IDE backend name", name)
+		}
+		if _, exists := idx[name]; exists {
+			return xerrors.Errorf("IDE backend %s is listed twice", name)
+		}
+		idx[name] = struct{}{}
+	}
+
+	return nil
+}
+
 // StopWorkspace stops a running workspace
 func (m *Manager) StopWorkspace(ctx context.Context, req *api.StopWorkspaceRequest) (res *api.StopWorkspaceResponse, err error) {
 	span, ctx := tracing.FromContext(ctx, "StopWorkspace")
@@ -389,6 +453,35 @@ func (m *Manager) stopWorkspace(ctx context.Context, workspaceID string, gracePe
 		return xerrors.Errorf("stopWorkspace: pod %s has no %s annotation", pod.Name, servicePrefixAnnotation)
 	}
 
+	if _, doCheckpoint := pod.Labels[processCheckpointAnnotation]; doCheckpoint && status != nil && status.Phase == api.WorkspacePhase_RUNNING {
+		if !m.beginCheckpoint(workspaceID) {
+			// the workspace is being checkpointed and will be deleted afterwards
+			return nil
+		}
+		// Checkpointing takes a while, we don't make the caller wait for it. The pod is deleted once the checkpoint is taken.
+		go func(ctx context.Context) {
+			defer m.endCheckpoint(workspaceID)
+
+			if m.checkpointWorkspace(ctx, workspaceID, pod) {
+				// the dumped processes stay frozen - there's nothing left to shut down gracefully
+				gracePeriod = stopWorkspaceImmediatelyGracePeriod
+			}
+			err := m.deleteWorkspaceObjects(ctx, pod.Name, servicePrefix, gracePeriod)
+			if err != nil {
+				log.WithError(err).WithFields(wsk8s.GetOWIFromObject(&pod.ObjectMeta)).Error("cannot stop workspace after checkpointing its processes")
+			}
+		}(opentracing.ContextWithSpan(context.Background(), workspaceSpan))
+		return nil
+	}
+
+	return m.deleteWorkspaceObjects(ctx, pod.Name, servicePrefix, gracePeriod)
+}
+
+// deleteWorkspaceObjects deletes the pod and services of a workspace
+func (m *Manager) deleteWorkspaceObjects(ctx context.Context, podName, servicePrefix string, gracePeriod time.Duration) (err error) {
+	span, ctx := tracing.FromContext(ctx, "deleteWorkspaceObjects")
+	defer tracing.FinishSpan(span, &err)
+
 	gracePeriodSeconds := int64(gracePeriod.Seconds())
 	propagationPolicy := metav1.DeletePropagationForeground
 
@@ -422,7 +515,7 @@ func (m *Manager) stopWorkspace(ctx context.Context, workspaceID string, gracePe
 	podErr := m.Clientset.Delete(ctx,
 		&corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      pod.Name,
+				Name:      podName,
 				Namespace: m.Config.Namespace,
 			},
 		},
@@ -1121,6 +1214,53 @@ func isKubernetesObjNotFoundError(err error) bool {
 	return false
 }
 
+// beginCheckpoint marks a workspace as being checkpointed. It returns false if the workspace is checkpointed already.
+func (m *Manager) beginCheckpoint(workspaceID string) bool {
+	m.checkpointLock.Lock()
+	defer m.checkpointLock.Unlock()
+
+	if _, ok := m.checkpoints[workspaceID]; ok {
+		return false
+	}
+	m.checkpoints[workspaceID] = struct{}{}
+	return true
+}
+
+func (m *Manager) endCheckpoint(workspaceID string) {
+	m.checkpointLock.Lock()
+	defer m.checkpointLock.Unlock()
+
+	delete(m.checkpoints, workspaceID)
+}
+
+// checkpointWorkspace asks ws-daemon to dump the processes of a workspace so that they can be restored on its next start.
+// This has to happen before the pod is deleted, because the workspace processes are terminated once that happens.
+// ws-daemon leaves the dumped processes frozen, s.t. the final backup has the content they were dumped with.
+// Failing to take a checkpoint does not prevent the workspace from stopping - it simply starts afresh next time.
+// Returns true if the checkpoint was taken.
+func (m *Manager) checkpointWorkspace(ctx context.Context, workspaceID string, pod *corev1.Pod) bool {
+	var err error
+	span, ctx := tracing.FromContext(ctx, "checkpointWorkspace")
+	defer tracing.FinishSpan(span, &err)
+	log := log.WithFields(wsk8s.GetOWIFromObject(&pod.ObjectMeta))
+
+	ctx, cancel := context.WithTimeout(ctx, processCheckpointTimeout)
+	defer cancel()
+
+	snc, err := m.connectToWorkspaceDaemon(ctx, workspaceObjects{Pod: pod})
+	if err != nil {
+		log.WithError(err).Warn("cannot connect to workspace daemon - not checkpointing workspace processes")
+		return false
+	}
+	_, err = snc.CheckpointWorkspace(ctx, &wsdaemon.CheckpointWorkspaceRequest{Id: workspaceID})
+	if err != nil {
+		log.WithError(err).Warn("cannot checkpoint workspace processes")
+		return false
+	}
+	span.LogKV("event", "workspace processes checkpointed")
+	return true
+}
+
 // connectToWorkspaceDaemon establishes a connection to the ws-daemon daemon running on the node of the pod/workspace.
 func (m *Manager) connectToWorkspaceDaemon(ctx context.Context, wso workspaceObjects) (wcsClient wsdaemon.WorkspaceContentServiceClient, err error) {
 	//nolint:ineffassign
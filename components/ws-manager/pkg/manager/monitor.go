@@ -643,6 +643,7 @@ func (m *Monitor) probeWorkspaceReady(ctx context.Context, pod *corev1.Pod) (res
 // prior to this call this function returns once initialization is complete.
 func (m *Monitor) initializeWorkspaceContent(ctx context.Context, pod *corev1.Pod) (err error) {
 	_, fullWorkspaceBackup := pod.Labels[fullWorkspaceBackupAnnotation]
+	_, restoreCheckpoint := pod.Labels[processCheckpointAnnotation]
 
 	workspaceID, ok := pod.Annotations[workspaceIDAnnotation]
 	if !ok {
@@ -730,6 +731,7 @@ func (m *Monitor) initializeWorkspaceContent(ctx context.Context, pod *corev1.Po
 			FullWorkspaceBackup:   fullWorkspaceBackup,
 			ContentManifest:       contentManifest,
 			RemoteStorageDisabled: shouldDisableRemoteStorage(pod),
+			RestoreCheckpoint:     restoreCheckpoint,
 		})
 		return err
 	})
@@ -14,6 +14,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -262,6 +263,15 @@ func (m *Manager) getWorkspaceStatus(wso workspaceObjects) (*api.WorkspaceStatus
 		admission = api.AdmissionLevel(av)
 	}
 
+	var egressPolicy *api.EgressPolicy
+	if ep, ok := wso.Pod.Annotations[wsk8s.EgressPolicyAnnotation]; ok {
+		egressPolicy = &api.EgressPolicy{}
+		err := protojson.Unmarshal([]byte(ep), egressPolicy)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid egress policy: %w", err)
+		}
+	}
+
 	status = &api.WorkspaceStatus{
 		Id:       id,
 		Metadata: getWorkspaceMetadata(wso.Pod),
@@ -272,9 +282,11 @@ func (m *Manager) getWorkspaceStatus(wso workspaceObjects) (*api.WorkspaceStatus
 			Url:            wsurl,
 			Type:           tpe,
 			Timeout:        timeout,
+			EgressPolicy:   egressPolicy,
 		},
 		Conditions: &api.WorkspaceConditions{
-			Snapshot: wso.Pod.Annotations[workspaceSnapshotAnnotation],
+			Snapshot:        wso.Pod.Annotations[workspaceSnapshotAnnotation],
+			EgressViolation: wso.Pod.Annotations[wsk8s.EgressViolationAnnotation],
 		},
 		Runtime: &api.WorkspaceRuntimeInfo{
 			NodeName: wso.Pod.Spec.NodeName,